@@ -14,9 +14,11 @@ import (
 	"github.com/gorilla/mux"
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bundle/tap"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/httpserver"
 	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 )
 
 // Config contains the configuration fields for the Benthos API.
@@ -29,6 +31,12 @@ type Config struct {
 	KeyFile        string                     `json:"key_file" yaml:"key_file"`
 	CORS           httpserver.CORSConfig      `json:"cors" yaml:"cors"`
 	BasicAuth      httpserver.BasicAuthConfig `json:"basic_auth" yaml:"basic_auth"`
+	Taps           TapsConfig                 `json:"taps" yaml:"taps"`
+}
+
+// TapsConfig contains the configuration fields for live tap endpoints.
+type TapsConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
 }
 
 // NewConfig creates a new API config with default values.
@@ -42,6 +50,9 @@ func NewConfig() Config {
 		KeyFile:        "",
 		CORS:           httpserver.NewServerCORSConfig(),
 		BasicAuth:      httpserver.NewBasicAuthConfig(),
+		Taps: TapsConfig{
+			Enabled: false,
+		},
 	}
 }
 
@@ -57,6 +68,20 @@ func OptWithMiddleware(m func(http.Handler) http.Handler) OptFunc {
 	}
 }
 
+// OptWithTaps registers an endpoint for attaching live taps to the components
+// of the running stream via the provided registry. The endpoint is only
+// registered when taps are enabled within the config.
+func OptWithTaps(r *tap.Registry) OptFunc {
+	return func(t *Type) {
+		if !t.conf.Taps.Enabled {
+			return
+		}
+		t.RegisterEndpoint("/tap/{label}", tap.EndpointDescription, func(w http.ResponseWriter, req *http.Request) {
+			r.Serve(w, req, "", mux.Vars(req)["label"])
+		})
+	}
+}
+
 // OptWithTLS replaces the tls options of the HTTP server.
 func OptWithTLS(tls *tls.Config) OptFunc {
 	return func(t *Type) {
@@ -128,7 +153,7 @@ func New(
 	handleStackTrace := func(w http.ResponseWriter, r *http.Request) {
 		stackSlice := make([]byte, 1024*100)
 		s := runtime.Stack(stackSlice, true)
-		_, _ = w.Write(stackSlice[:s])
+		_, _ = w.Write(secrets.RedactBytes(stackSlice[:s]))
 	}
 
 	handlePrintJSONConfig := func(w http.ResponseWriter, r *http.Request) {
@@ -147,7 +172,7 @@ func New(
 			w.WriteHeader(http.StatusBadGateway)
 			return
 		}
-		_, _ = w.Write(resBytes)
+		_, _ = w.Write(secrets.RedactBytes(resBytes))
 	}
 
 	handlePrintYAMLConfig := func(w http.ResponseWriter, r *http.Request) {
@@ -156,7 +181,7 @@ func New(
 			w.WriteHeader(http.StatusBadGateway)
 			return
 		}
-		_, _ = w.Write(resBytes)
+		_, _ = w.Write(secrets.RedactBytes(resBytes))
 	}
 
 	handleVersion := func(w http.ResponseWriter, r *http.Request) {
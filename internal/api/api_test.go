@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/api"
+	"github.com/benthosdev/benthos/v4/internal/bundle/tap"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/log"
 
@@ -183,3 +184,25 @@ func TestAPIBasicAuth(t *testing.T) {
 		}(tc))
 	}
 }
+
+func TestAPITapsOptIn(t *testing.T) {
+	taps := tap.NewRegistry()
+
+	conf := api.NewConfig()
+	s, err := api.New("", "", conf, nil, log.Noop(), metrics.Noop(), api.OptWithTaps(taps))
+	require.NoError(t, err)
+
+	request, _ := http.NewRequest("GET", "/tap/foo?count=1", http.NoBody)
+	response := httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	conf.Taps.Enabled = true
+	s, err = api.New("", "", conf, nil, log.Noop(), metrics.Noop(), api.OptWithTaps(taps))
+	require.NoError(t, err)
+
+	request, _ = http.NewRequest("GET", "/tap/foo?sample=2", http.NoBody)
+	response = httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
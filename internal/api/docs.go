@@ -25,6 +25,9 @@ func Spec() docs.FieldSpecs {
 		docs.FieldString("key_file", "An optional key file for enabling TLS.").Advanced().HasDefault(""),
 		httpserver.ServerCORSFieldSpec(),
 		httpserver.BasicAuthFieldSpec(),
+		docs.FieldObject("taps", "Allows live taps to be attached to the components of running streams via the HTTP server, which stream the contents of the messages they observe. Since tapped messages may contain sensitive data it is recommended to also enable `basic_auth` when taps are enabled.").WithChildren(
+			docs.FieldBool("enabled", "Whether to enable endpoints for attaching live taps to components.").HasDefault(false),
+		).Advanced(),
 	}
 }
 
@@ -68,6 +71,8 @@ http:
     password_hash: ""
     algorithm: "sha256"
     salt: ""
+  taps:
+    enabled: false
 `,
 	})
 
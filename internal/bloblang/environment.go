@@ -178,6 +178,16 @@ func (e *Environment) WithMaxMapRecursion(n int) *Environment {
 	return &env
 }
 
+// WithTracing returns a copy of the environment where parsed mappings record
+// the results of their assignments, method calls, match cases and if branches
+// to the tracer of the function context they are executed with. This adds
+// overhead to executions and is intended for debugging mappings.
+func (e *Environment) WithTracing() *Environment {
+	env := *e
+	env.pCtx = env.pCtx.WithTracing()
+	return &env
+}
+
 // WalkFunctions executes a provided function argument for every function that
 // has been registered to the environment.
 func (e *Environment) WalkFunctions(fn func(name string, spec query.FunctionSpec)) {
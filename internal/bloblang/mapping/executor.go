@@ -44,6 +44,11 @@ type Statement struct {
 	input      []rune
 	assignment Assignment
 	query      query.Function
+
+	// When true the statement assigns the root of the document and no
+	// subsequent statements mutate it, and therefore the value does not need
+	// to be cloned when mapping a message.
+	borrow bool
 }
 
 // NewStatement initialises a new mapping statement from an Assignment and
@@ -57,6 +62,26 @@ func NewStatement(input []rune, assignment Assignment, query query.Function) Sta
 	}
 }
 
+func (s Statement) trace(tracer *query.Tracer, res any, err error) {
+	var desc string
+	target := s.assignment.Target()
+	switch target.Type {
+	case TargetValue:
+		desc = strings.Join(append([]string{"root"}, target.Path...), ".")
+	case TargetMetadata:
+		desc = strings.Join(append([]string{"meta"}, target.Path...), " ")
+	case TargetVariable:
+		desc = strings.Join(append([]string{"let"}, target.Path...), " ")
+	}
+	tracer.Record(query.TraceEvent{
+		Kind:        query.TraceAssignment,
+		Description: desc,
+		Input:       s.input,
+		Value:       res,
+		Err:         err,
+	})
+}
+
 //------------------------------------------------------------------------------
 
 // Executor is a parsed bloblang mapping that can be executed on a Benthos
@@ -77,6 +102,7 @@ const defaultMaxMapStacks = 5000
 // is an optional slice pointing to the parsed expression that created the
 // executor.
 func NewExecutor(annotation string, input []rune, maps map[string]query.Function, statements ...Statement) *Executor {
+	markBorrowedStatements(statements)
 	return &Executor{
 		annotation:   annotation,
 		input:        input,
@@ -86,6 +112,25 @@ func NewExecutor(annotation string, input []rune, maps map[string]query.Function
 	}
 }
 
+// markBorrowedStatements flags statements that assign to the root of the
+// document where no subsequent statement assigns to a path within it. The
+// values of these statements can be assigned without being cloned, as nothing
+// within the mapping will mutate them.
+func markBorrowedStatements(statements []Statement) {
+	mutated := false
+	for i := len(statements) - 1; i >= 0; i-- {
+		j, isJSON := statements[i].assignment.(*JSONAssignment)
+		if !isJSON {
+			continue
+		}
+		if len(j.path) > 0 {
+			mutated = true
+		} else if !mutated {
+			statements[i].borrow = true
+		}
+	}
+}
+
 // SetMaxMapRecursion configures the maximum recursion allowed for maps, if the
 // execution of this mapping matches this number of recursive map calls the
 // mapping will error out.
@@ -176,6 +221,7 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 	}
 
 	vars := map[string]any{}
+	borrowed := false
 
 	for _, stmt := range e.statements {
 		res, err := stmt.query.Exec(query.FunctionContext{
@@ -205,6 +251,14 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 			// Skip assignment entirely
 			continue
 		}
+		if stmt.borrow {
+			// The value is never mutated by the mapping and so we avoid
+			// cloning it, the resulting message data is instead set as
+			// read-only.
+			newValue = res
+			borrowed = true
+			continue
+		}
 		if err = stmt.assignment.Apply(res, AssignmentContext{
 			Vars:  vars,
 			Meta:  newPart,
@@ -231,7 +285,11 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 		case []byte:
 			newPart.SetBytes(t)
 		default:
-			newPart.SetStructuredMut(newValue)
+			if borrowed {
+				newPart.SetStructured(newValue)
+			} else {
+				newPart.SetStructuredMut(newValue)
+			}
 		}
 	}
 	return newPart, nil
@@ -275,6 +333,9 @@ func (e *Executor) Exec(ctx query.FunctionContext) (any, error) {
 
 	for _, stmt := range e.statements {
 		res, err := stmt.query.Exec(ctx)
+		if ctx.Tracer != nil {
+			stmt.trace(ctx.Tracer, res, err)
+		}
 		if err != nil {
 			return nil, formatExecErr(err, true, e.input, stmt.input)
 		}
@@ -298,6 +359,9 @@ func (e *Executor) Exec(ctx query.FunctionContext) (any, error) {
 func (e *Executor) ExecOnto(ctx query.FunctionContext, onto AssignmentContext) error {
 	for _, stmt := range e.statements {
 		res, err := stmt.query.Exec(ctx)
+		if ctx.Tracer != nil {
+			stmt.trace(ctx.Tracer, res, err)
+		}
 		if err != nil {
 			return formatExecErr(err, true, e.input, stmt.input)
 		}
@@ -0,0 +1,84 @@
+package mapping_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+const benchInput = `{
+  "id": "A1B2-C3D4",
+  "user": {
+    "name": "  Ash Ketchum  ",
+    "email": "ASH@EXAMPLE.COM",
+    "address": {"city": "Pallet Town", "region": "Kanto"}
+  },
+  "tags": ["one","two","three","four","five"],
+  "items": [
+    {"sku":"a","price":10.5,"quantity":2},
+    {"sku":"b","price":3.25,"quantity":10},
+    {"sku":"c","price":99.99,"quantity":1}
+  ]
+}`
+
+func benchmarkMapping(b *testing.B, mapping string) {
+	b.Helper()
+
+	exec, err := bloblang.GlobalEnvironment().NewMapping(mapping)
+	require.NoError(b, err)
+
+	inputPart := message.NewPart([]byte(benchInput))
+	_, err = inputPart.AsStructured()
+	require.NoError(b, err)
+	batch := message.Batch{inputPart}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		res, err := exec.MapPart(0, batch)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = res
+	}
+}
+
+func BenchmarkMappingRootPassthrough(b *testing.B) {
+	benchmarkMapping(b, `root = this
+meta source = "bench"`)
+}
+
+func BenchmarkMappingRootMutation(b *testing.B) {
+	benchmarkMapping(b, `root = this
+root.user.name = this.user.name.trim()
+root.processed = true`)
+}
+
+func BenchmarkMappingPathLookups(b *testing.B) {
+	benchmarkMapping(b, `root.city = this.user.address.city
+root.region = this.user.address.region
+root.first_sku = this.items.0.sku
+root.email = this.user.email`)
+}
+
+func BenchmarkMappingMethodChain(b *testing.B) {
+	benchmarkMapping(b, `root.id = this.id.lowercase().replace_all("-", "").trim().uppercase()
+root.name = this.user.name.trim().lowercase().capitalize()
+root.email = this.user.email.lowercase().trim()`)
+}
+
+func BenchmarkMappingConstants(b *testing.B) {
+	benchmarkMapping(b, `root.kind = "order".uppercase() + "_" + "Event".lowercase()
+root.version = "1.2.3".split(".").index(0).number()
+root.limits = [1, 2, 3].sum() * 10`)
+}
+
+func BenchmarkMappingArrays(b *testing.B) {
+	benchmarkMapping(b, `root.total = this.items.map_each(item -> item.price * item.quantity).sum()
+root.skus = this.items.map_each(item -> item.sku.uppercase()).join(",")
+root.tags = this.tags.filter(t -> t.length() > 3)`)
+}
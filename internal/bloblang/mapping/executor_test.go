@@ -278,6 +278,44 @@ func TestAssignments(t *testing.T) {
 	}
 }
 
+func TestAssignmentsBorrowRoot(t *testing.T) {
+	metaKey := func(k string) *string {
+		return &k
+	}
+
+	inputPart := message.NewPart(nil)
+	inputPart.SetStructuredMut(map[string]any{
+		"foo": map[string]any{"bar": "baz"},
+	})
+	inputValue, err := inputPart.AsStructured()
+	require.NoError(t, err)
+
+	borrowExec := NewExecutor("", nil, nil,
+		NewStatement(nil, NewJSONAssignment(), query.NewFieldFunction("")),
+		NewStatement(nil, NewMetaAssignment(metaKey("foo")), query.NewLiteralFunction("", "bar")),
+	)
+	assert.True(t, borrowExec.statements[0].borrow)
+
+	resPart, err := borrowExec.MapPart(0, message.Batch{inputPart})
+	require.NoError(t, err)
+
+	resValue, err := resPart.AsStructuredMut()
+	require.NoError(t, err)
+	resValue.(map[string]any)["foo"].(map[string]any)["bar"] = "changed"
+	assert.Equal(t, map[string]any{"foo": map[string]any{"bar": "baz"}}, inputValue)
+
+	mutateExec := NewExecutor("", nil, nil,
+		NewStatement(nil, NewJSONAssignment(), query.NewFieldFunction("")),
+		NewStatement(nil, NewJSONAssignment("foo", "bar"), query.NewLiteralFunction("", "changed")),
+	)
+	assert.False(t, mutateExec.statements[0].borrow)
+
+	resPart, err = mutateExec.MapPart(0, message.Batch{inputPart})
+	require.NoError(t, err)
+	assert.Equal(t, `{"foo":{"bar":"changed"}}`, string(resPart.AsBytes()))
+	assert.Equal(t, map[string]any{"foo": map[string]any{"bar": "baz"}}, inputValue)
+}
+
 func TestTargets(t *testing.T) {
 	function := func(name string, args ...any) query.Function {
 		t.Helper()
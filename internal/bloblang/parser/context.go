@@ -16,6 +16,7 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+	tracing      bool
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -89,6 +90,15 @@ func (pCtx Context) WithImporterRelativeToFile(pathStr string) Context {
 	return pCtx
 }
 
+// WithTracing returns a Context where parsed mappings record the values of
+// method calls, match cases and if branches to the tracer of a function context
+// during execution. This adds overhead to every execution and should therefore
+// only be used when debugging mappings.
+func (pCtx Context) WithTracing() Context {
+	pCtx.tracing = true
+	return pCtx
+}
+
 // Deactivated returns a version of the parser context where all functions and
 // methods exist but can no longer be instantiated. This means it's possible to
 // parse and validate mappings but not execute them. If the context also has an
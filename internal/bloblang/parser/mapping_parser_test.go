@@ -9,6 +9,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
@@ -502,3 +504,77 @@ root = this.apply("foo")`, goodMapFile),
 		})
 	}
 }
+
+func TestMappingTracing(t *testing.T) {
+	blobl := `root.name = this.name.uppercase()
+let kind = match this.type {
+  "a" => "alpha"
+  this.has_prefix("b") => "beta"
+  _ => "other"
+}
+root.kind = $kind
+root.size = if this.n > 10 {
+  "big"
+} else if this.n > 5 {
+  "medium"
+} else {
+  "small"
+}
+root.nope = deleted()`
+
+	type event struct {
+		kind  query.TraceEventKind
+		desc  string
+		value any
+	}
+
+	exec, perr := ParseMapping(GlobalContext().WithTracing(), blobl)
+	require.Nil(t, perr)
+
+	tracer := query.NewTracer()
+	_, err := exec.Exec(query.FunctionContext{
+		Vars:     map[string]any{},
+		MsgBatch: message.QuickBatch(nil),
+		Tracer:   tracer,
+	}.WithValue(map[string]any{
+		"name": "foo",
+		"type": "bar",
+		"n":    7,
+	}))
+	require.NoError(t, err)
+
+	var events []event
+	for _, e := range tracer.Events() {
+		require.NoError(t, e.Err)
+		events = append(events, event{kind: e.Kind, desc: e.Description, value: e.Value})
+	}
+	assert.Equal(t, []event{
+		{kind: query.TraceMethod, desc: "uppercase()", value: "FOO"},
+		{kind: query.TraceAssignment, desc: "root.name", value: "FOO"},
+		{kind: query.TraceMethod, desc: "has_prefix()", value: true},
+		{kind: query.TraceMatchCase, desc: `this.has_prefix("b")`, value: "beta"},
+		{kind: query.TraceAssignment, desc: "let kind", value: "beta"},
+		{kind: query.TraceAssignment, desc: "root.kind", value: "beta"},
+		{kind: query.TraceIfBranch, desc: "else if this.n > 5", value: "medium"},
+		{kind: query.TraceAssignment, desc: "root.size", value: "medium"},
+		{kind: query.TraceAssignment, desc: "root.nope", value: query.Delete(nil)},
+	}, events)
+
+	line, _ := mapping.LineAndColOf([]rune(blobl), tracer.Events()[6].Input)
+	assert.Equal(t, 10, line)
+
+	tracer = query.NewTracer()
+	exec, perr = ParseMapping(GlobalContext(), blobl)
+	require.Nil(t, perr)
+
+	_, err = exec.Exec(query.FunctionContext{
+		Vars:     map[string]any{},
+		MsgBatch: message.QuickBatch(nil),
+		Tracer:   tracer,
+	}.WithValue(map[string]any{"name": "foo", "type": "a", "n": 1}))
+	require.NoError(t, err)
+
+	for _, e := range tracer.Events() {
+		assert.Equal(t, query.TraceAssignment, e.Kind, "only assignments are traced without parser tracing enabled")
+	}
+}
@@ -2,37 +2,114 @@ package parser
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 )
 
+// patternMatchCaseParser parses a match case consisting of a structural pattern
+// and an optional guard, where values captured by the pattern are available to
+// both the guard and the case query as named contexts. Cases that do not contain
+// a structural pattern or guard result in a non-fatal error so that they are
+// instead parsed as a regular query case.
+func patternMatchCaseParser(pCtx Context) Func {
+	whitespace := SpacesAndTabs()
+	guardOrArrow := OneOf(
+		Sequence(Term("if"), SpacesAndTabs()),
+		Term("=>"),
+	)
+
+	return func(input []rune) Result {
+		res := patternParser()(input)
+		if res.Err != nil {
+			return res
+		}
+		parsed := res.Payload.(parsedPattern)
+
+		res = Sequence(Optional(whitespace), guardOrArrow)(res.Remaining)
+		if res.Err != nil {
+			return Fail(NewError(res.Remaining, "match case"), input)
+		}
+		_, hasGuard := res.Payload.([]any)[1].([]any)
+
+		if !hasGuard && (!parsed.structural || parsed.bare) {
+			return Fail(NewError(input, "match case"), input)
+		}
+
+		seen := map[string]struct{}{}
+		for _, name := range parsed.pattern.Captures() {
+			if _, exists := seen[name]; exists {
+				return Fail(NewFatalError(input, fmt.Errorf("capture name `%v` is used more than once", name)), input)
+			}
+			seen[name] = struct{}{}
+			if name == "root" || name == "this" {
+				return Fail(NewFatalError(input, fmt.Errorf("capture name `%v` is not allowed", name)), input)
+			}
+			if pCtx.HasNamedContext(name) {
+				return Fail(NewFatalError(input, fmt.Errorf("capture name `%v` would shadow a parent context", name)), input)
+			}
+			pCtx = pCtx.WithNamedContext(name)
+		}
+
+		var guardFn query.Function
+		if hasGuard {
+			if res = MustBe(queryParser(pCtx))(res.Remaining); res.Err != nil {
+				return res
+			}
+			guardFn = res.Payload.(query.Function)
+			if res = MustBe(Sequence(Optional(whitespace), Term("=>")))(res.Remaining); res.Err != nil {
+				return res
+			}
+		}
+		pattern := strings.TrimSpace(strings.TrimSuffix(string(input[:len(input)-len(res.Remaining)]), "=>"))
+
+		res = Sequence(Optional(whitespace), MustBe(queryParser(pCtx)))(res.Remaining)
+		if res.Err != nil {
+			return res
+		}
+
+		resultFn := res.Payload.([]any)[1].(query.Function)
+		if pCtx.tracing {
+			resultFn = query.NewTracedFunction(query.TraceMatchCase, pattern, input, resultFn)
+		}
+		return Success(query.NewPatternMatchCase(parsed.pattern, guardFn, resultFn), res.Remaining)
+	}
+}
+
 func matchCaseParser(pCtx Context) Func {
 	whitespace := SpacesAndTabs()
 
-	p := Sequence(
-		OneOf(
-			Sequence(
-				Expect(
-					Char('_'),
-					"match case",
-				),
-				Optional(whitespace),
-				Term("=>"),
+	patternParser := OneOf(
+		Sequence(
+			Expect(
+				Char('_'),
+				"match case",
 			),
-			Sequence(
-				Expect(
-					queryParser(pCtx),
-					"match case",
-				),
-				Optional(whitespace),
-				Term("=>"),
+			Optional(whitespace),
+			Term("=>"),
+		),
+		Sequence(
+			Expect(
+				queryParser(pCtx),
+				"match case",
 			),
+			Optional(whitespace),
+			Term("=>"),
 		),
+	)
+
+	p := Sequence(
+		patternParser,
 		Optional(whitespace),
 		queryParser(pCtx),
 	)
 
 	return func(input []rune) Result {
+		if res := patternMatchCaseParser(pCtx)(input); res.Err == nil || res.Err.IsFatal() {
+			return res
+		}
+
 		res := p(input)
 		if res.Err != nil {
 			return res
@@ -58,8 +135,16 @@ func matchCaseParser(pCtx Context) Func {
 			caseFn = query.NewLiteralFunction("", true)
 		}
 
+		resultFn := seqSlice[2].(query.Function)
+		if pCtx.tracing {
+			patternRes := patternParser(input)
+			pattern := string(input[:len(input)-len(patternRes.Remaining)])
+			pattern = strings.TrimSpace(strings.TrimSuffix(pattern, "=>"))
+			resultFn = query.NewTracedFunction(query.TraceMatchCase, pattern, input, resultFn)
+		}
+
 		return Success(
-			query.NewMatchCase(caseFn, seqSlice[2].(query.Function)),
+			query.NewMatchCase(caseFn, resultFn),
 			res.Remaining,
 		)
 	}
@@ -172,10 +257,14 @@ func ifExpressionParser(pCtx Context) Func {
 		seqSlice := res.Payload.([]any)
 		queryFn := seqSlice[2].(query.Function)
 		ifFn := seqSlice[6].(query.Function)
+		if pCtx.tracing {
+			ifFn = tracedBranch(input, res.Remaining, ifFn)
+		}
 
 		var elseIfs []query.ElseIf
 		for {
-			res = elseIfParser(res.Remaining)
+			branchInput := res.Remaining
+			res = elseIfParser(branchInput)
 			if res.Err != nil {
 				return res
 			}
@@ -183,20 +272,28 @@ func ifExpressionParser(pCtx Context) Func {
 				break
 			}
 			seqSlice = res.Payload.([]any)
+			mapFn := seqSlice[7].(query.Function)
+			if pCtx.tracing {
+				mapFn = tracedBranch(branchInput, res.Remaining, mapFn)
+			}
 			elseIfs = append(elseIfs, query.ElseIf{
 				QueryFn: seqSlice[3].(query.Function),
-				MapFn:   seqSlice[7].(query.Function),
+				MapFn:   mapFn,
 			})
 		}
 
 		var elseFn query.Function
 
-		res = elseParser(res.Remaining)
+		branchInput := res.Remaining
+		res = elseParser(branchInput)
 		if res.Err != nil {
 			return res
 		}
 		if res.Payload != nil {
 			elseFn, _ = res.Payload.([]any)[5].(query.Function)
+			if pCtx.tracing && elseFn != nil {
+				elseFn = tracedBranch(branchInput, res.Remaining, elseFn)
+			}
 		}
 
 		res.Payload = query.NewIfFunction(queryFn, ifFn, elseIfs, elseFn)
@@ -204,6 +301,24 @@ func ifExpressionParser(pCtx Context) Func {
 	}
 }
 
+// tracedBranch wraps the function of an if expression branch such that its
+// result is traced, described by the branch source up until its opening brace.
+func tracedBranch(input, remaining []rune, fn query.Function) query.Function {
+	input = trimLeadingSpace(input)
+	branch := string(input[:len(input)-len(remaining)])
+	if i := strings.IndexRune(branch, '{'); i >= 0 {
+		branch = branch[:i]
+	}
+	return query.NewTracedFunction(query.TraceIfBranch, strings.Join(strings.Fields(branch), " "), input, fn)
+}
+
+func trimLeadingSpace(input []rune) []rune {
+	for len(input) > 0 && unicode.IsSpace(input[0]) {
+		input = input[1:]
+	}
+	return input
+}
+
 func bracketsExpressionParser(pCtx Context) Func {
 	whitespace := DiscardAll(
 		OneOf(
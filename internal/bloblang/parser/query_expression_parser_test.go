@@ -250,6 +250,121 @@ func TestExpressionsParser(t *testing.T) {
 			output:   `first`,
 			messages: []easyMsg{},
 		},
+		"match object pattern": {
+			input: `match json() {
+  {"type": "refund", "items": items} => "refund of " + items.length().string()
+  {"type": "order", "items": items} => "order of " + items.length().string()
+  _ => "unknown"
+}`,
+			output: `order of 2`,
+			messages: []easyMsg{
+				{content: `{"type":"order","items":["a","b"],"id":"foo"}`},
+			},
+		},
+		"match object pattern rest": {
+			input: `match json() {
+  {"id": id, ...rest} => id + ": " + rest.keys().sort().join(",")
+}`,
+			output: `foo: items,type`,
+			messages: []easyMsg{
+				{content: `{"type":"order","items":["a","b"],"id":"foo"}`},
+			},
+		},
+		"match nested object pattern": {
+			input: `match json() {
+  {"user": {"name": name, "roles": ["admin", ..._]}} => name + " is an admin"
+  {"user": {"name": name}} => name + " is not an admin"
+}`,
+			output: `bob is not an admin`,
+			messages: []easyMsg{
+				{content: `{"user":{"name":"bob","roles":["viewer","admin"]}}`},
+			},
+		},
+		"match array pattern rest": {
+			input: `match json() {
+  [] => "empty"
+  [first] => "just " + first
+  [first, ...rest] => first + " then " + rest.join(",")
+}`,
+			output: `a then b,c`,
+			messages: []easyMsg{
+				{content: `["a","b","c"]`},
+			},
+		},
+		"match array pattern exact length": {
+			input: `match json() {
+  [first, second] => "pair"
+  [first, ...rest] => "more"
+}`,
+			output: `more`,
+			messages: []easyMsg{
+				{content: `["a","b","c"]`},
+			},
+		},
+		"match type patterns": {
+			input: `match json("value") {
+  number(n) => "number " + (n * 2).string()
+  string(s) => "string " + s.uppercase()
+  array([string(s), ..._]) => "strings starting with " + s
+  _ => "other"
+}`,
+			output: `strings starting with foo`,
+			messages: []easyMsg{
+				{content: `{"value":["foo",5]}`},
+			},
+		},
+		"match type patterns 2": {
+			input: `match json("value") {
+  number(n) => "number " + (n * 2).string()
+  string(s) => "string " + s.uppercase()
+  _ => "other"
+}`,
+			output: `string FOO`,
+			messages: []easyMsg{
+				{content: `{"value":"foo"}`},
+			},
+		},
+		"match pattern guards": {
+			input: `match json("value") {
+  number(n) if n > 10 => "big"
+  number(n) if n > 5 => "medium"
+  n if n.type() == "number" => "small"
+  _ => "other"
+}`,
+			output: `medium`,
+			messages: []easyMsg{
+				{content: `{"value":7}`},
+			},
+		},
+		"match pattern guard on this": {
+			input: `match json() {
+  {"name": name} if this.age >= 18 => name + " is an adult"
+  {"name": name} => name + " is a minor"
+}`,
+			output: `bob is a minor`,
+			messages: []easyMsg{
+				{content: `{"name":"bob","age":12}`},
+			},
+		},
+		"match pattern with literal fields": {
+			input: `match json() {
+  {"a": 1, "b": [true, null]} => "first"
+  _ => "second"
+}`,
+			output: `second`,
+			messages: []easyMsg{
+				{content: `{"a":1,"b":[true,null],"c":"extra"}`},
+			},
+		},
+		"match pattern with lambda": {
+			input: `match json() {
+  {"factor": f, "values": vs} => vs.map_each(v -> v * f).sum().string()
+}`,
+			output: `12`,
+			messages: []easyMsg{
+				{content: `{"factor":2,"values":[1,2,3]}`},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -286,3 +401,40 @@ func TestExpressionsParser(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchPatternErrors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		err   string
+	}{
+		"duplicate capture": {
+			input: `match { [a, a] => a }`,
+			err:   "capture name `a` is used more than once",
+		},
+		"capture this": {
+			input: `match { {"a": this} => "foo" }`,
+			err:   "capture name `this` is not allowed",
+		},
+		"shadowed capture": {
+			input: `[1].map_each(a -> match a { [a] => a })`,
+			err:   "capture name `a` would shadow a parent context",
+		},
+		"array rest not last": {
+			input: `match { [...a, b] => a }`,
+			err:   "a rest pattern must be the last element of an array pattern",
+		},
+		"multiple object rests": {
+			input: `match { {...a, ...b} => a }`,
+			err:   "an object pattern can only contain one rest pattern",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			_, err := tryParseQuery(test.input)
+			require.NotNil(t, err)
+			assert.Contains(t, err.ErrorAtPosition([]rune(test.input)), test.err)
+		})
+	}
+}
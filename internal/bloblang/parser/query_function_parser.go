@@ -366,6 +366,9 @@ func methodParser(fn query.Function, pCtx Context) Func {
 		if err != nil {
 			return Fail(NewFatalError(input, err), input)
 		}
+		if pCtx.tracing {
+			method = query.NewTracedFunction(query.TraceMethod, targetMethod+"()", input, method)
+		}
 		return Success(method, res.Remaining)
 	}
 }
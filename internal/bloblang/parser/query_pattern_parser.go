@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"errors"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// parsedPattern is the payload of a pattern parser, where structural indicates
+// whether the pattern does more than compare a value against a literal, and bare
+// indicates that the pattern is a lone wildcard or capture.
+type parsedPattern struct {
+	pattern    query.Pattern
+	structural bool
+	bare       bool
+}
+
+// restPattern is the payload of a rest element (`...name`) within an array or
+// object pattern.
+type restPattern struct {
+	pattern query.Pattern
+}
+
+var patternValueTypes = map[string]query.ValueType{
+	"string":    query.ValueString,
+	"bytes":     query.ValueBytes,
+	"number":    query.ValueNumber,
+	"decimal":   query.ValueDecimal,
+	"bool":      query.ValueBool,
+	"timestamp": query.ValueTimestamp,
+	"array":     query.ValueArray,
+	"object":    query.ValueObject,
+}
+
+func patternWhitespace() Func {
+	return DiscardAll(
+		OneOf(
+			NewlineAllowComment(),
+			SpacesAndTabs(),
+		),
+	)
+}
+
+func patternRestParser() Func {
+	return func(input []rune) Result {
+		res := Term("...")(input)
+		if res.Err != nil {
+			return res
+		}
+		nameRes := varNameParser()(res.Remaining)
+		if nameRes.Err != nil || nameRes.Payload.(string) == "_" {
+			if nameRes.Err == nil {
+				res.Remaining = nameRes.Remaining
+			}
+			return Success(restPattern{pattern: query.NewWildcardPattern()}, res.Remaining)
+		}
+		return Success(restPattern{pattern: query.NewCapturePattern(nameRes.Payload.(string))}, nameRes.Remaining)
+	}
+}
+
+func arrayPatternParser() Func {
+	whitespace := patternWhitespace()
+	return func(input []rune) Result {
+		res := DelimitedPattern(
+			Expect(Sequence(
+				Char('['),
+				whitespace,
+			), "array pattern"),
+			OneOf(
+				patternRestParser(),
+				patternParser(),
+			),
+			Sequence(
+				Discard(SpacesAndTabs()),
+				Char(','),
+				whitespace,
+			),
+			Sequence(
+				whitespace,
+				Char(']'),
+			),
+			true,
+		)(input)
+		if res.Err != nil {
+			return res
+		}
+
+		elements := res.Payload.([]any)
+		p := parsedPattern{}
+
+		var patterns []query.Pattern
+		var rest query.Pattern
+		for i, e := range elements {
+			switch t := e.(type) {
+			case restPattern:
+				if i != len(elements)-1 {
+					return Fail(NewFatalError(input, errors.New("a rest pattern must be the last element of an array pattern")), input)
+				}
+				rest = t.pattern
+				p.structural = true
+			case parsedPattern:
+				patterns = append(patterns, t.pattern)
+				p.structural = p.structural || t.structural
+			}
+		}
+
+		p.pattern = query.NewArrayPattern(patterns, rest)
+		return Success(p, res.Remaining)
+	}
+}
+
+func objectPatternParser() Func {
+	whitespace := patternWhitespace()
+	return func(input []rune) Result {
+		res := DelimitedPattern(
+			Expect(Sequence(
+				Char('{'),
+				whitespace,
+			), "object pattern"),
+			OneOf(
+				patternRestParser(),
+				Sequence(
+					QuotedString(),
+					Discard(SpacesAndTabs()),
+					Char(':'),
+					Discard(whitespace),
+					patternParser(),
+				),
+			),
+			Sequence(
+				Discard(SpacesAndTabs()),
+				Char(','),
+				whitespace,
+			),
+			Sequence(
+				whitespace,
+				Char('}'),
+			),
+			true,
+		)(input)
+		if res.Err != nil {
+			return res
+		}
+
+		p := parsedPattern{}
+
+		var fields []query.ObjectPatternField
+		var rest query.Pattern
+		for _, e := range res.Payload.([]any) {
+			switch t := e.(type) {
+			case restPattern:
+				if rest != nil {
+					return Fail(NewFatalError(input, errors.New("an object pattern can only contain one rest pattern")), input)
+				}
+				rest = t.pattern
+				p.structural = true
+			case []any:
+				value := t[4].(parsedPattern)
+				fields = append(fields, query.ObjectPatternField{
+					Key:     t[0].(string),
+					Pattern: value.pattern,
+				})
+				p.structural = p.structural || value.structural
+			}
+		}
+
+		p.pattern = query.NewObjectPattern(fields, rest)
+		return Success(p, res.Remaining)
+	}
+}
+
+// patternParser parses a structural pattern of a match case, which is either a
+// literal value, a wildcard (`_`), a named capture, a type pattern such as
+// `string(s)`, or an array or object containing further patterns.
+func patternParser() Func {
+	whitespace := patternWhitespace()
+	return func(input []rune) Result {
+		if res := OneOf(
+			QuotedString(),
+			Number(),
+		)(input); res.Err == nil {
+			return Success(parsedPattern{pattern: query.NewLiteralPattern(res.Payload)}, res.Remaining)
+		} else if res.Err.IsFatal() {
+			return res
+		}
+
+		if res := OneOf(
+			arrayPatternParser(),
+			objectPatternParser(),
+		)(input); res.Err == nil || res.Err.IsFatal() || (len(input) > 0 && (input[0] == '[' || input[0] == '{')) {
+			return res
+		}
+
+		res := Expect(varNameParser(), "pattern")(input)
+		if res.Err != nil {
+			return res
+		}
+
+		name := res.Payload.(string)
+		switch name {
+		case "_":
+			return Success(parsedPattern{pattern: query.NewWildcardPattern(), structural: true, bare: true}, res.Remaining)
+		case "true", "false":
+			return Success(parsedPattern{pattern: query.NewLiteralPattern(name == "true")}, res.Remaining)
+		case "null":
+			return Success(parsedPattern{pattern: query.NewLiteralPattern(nil)}, res.Remaining)
+		}
+
+		if t, isType := patternValueTypes[name]; isType {
+			if typeRes := Sequence(
+				Char('('),
+				whitespace,
+				patternParser(),
+				whitespace,
+				Char(')'),
+			)(res.Remaining); typeRes.Err == nil {
+				inner := typeRes.Payload.([]any)[2].(parsedPattern)
+				return Success(parsedPattern{
+					pattern:    query.NewTypePattern(t, inner.pattern),
+					structural: true,
+				}, typeRes.Remaining)
+			} else if typeRes.Err.IsFatal() {
+				return typeRes
+			}
+		}
+
+		return Success(parsedPattern{pattern: query.NewCapturePattern(name), structural: true, bare: true}, res.Remaining)
+	}
+}
@@ -91,15 +91,26 @@ func arithmeticFunc(lhs, rhs Function, op arithmeticOpFunc) (Function, error) {
 var ErrDivideByZero = errors.New("attempted to divide by zero")
 
 type (
-	intArithmeticFunc   func(left, right int64) (int64, error)
-	floatArithmeticFunc func(left, right float64) (float64, error)
+	intArithmeticFunc     func(left, right int64) (int64, error)
+	floatArithmeticFunc   func(left, right float64) (float64, error)
+	decimalArithmeticFunc func(left, right Decimal) (Decimal, error)
 )
 
-// Takes two arithmetic funcs, one for integer values and one for float values
-// and returns a generic arithmetic func. If both values can be represented as
-// integers the integer func is called, otherwise the float func is called.
-func numberDegradationFunc(op ArithmeticOperator, iFn intArithmeticFunc, fFn floatArithmeticFunc) arithmeticOpFunc {
+// Takes three arithmetic funcs, one for integer values, one for float values
+// and one for decimal values, and returns a generic arithmetic func. If either
+// value is a decimal the decimal func is called, if both values can be
+// represented as integers the integer func is called, otherwise the float func
+// is called.
+func numberDegradationFunc(op ArithmeticOperator, iFn intArithmeticFunc, fFn floatArithmeticFunc, dFn decimalArithmeticFunc) arithmeticOpFunc {
 	return func(lhs, rhs Function, left, right any) (any, error) {
+		if isDecimalOperand(left, right) {
+			leftDec, rightDec, ok := decimalPair(left, right)
+			if !ok {
+				return nil, NewTypeMismatch(op.String(), lhs, rhs, left, right)
+			}
+			return dFn(leftDec, rightDec)
+		}
+
 		left = ISanitize(left)
 		right = ISanitize(right)
 
@@ -141,10 +152,23 @@ func prodOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 			func(lhs, rhs float64) (float64, error) {
 				return lhs * rhs, nil
 			},
+			func(lhs, rhs Decimal) (Decimal, error) {
+				return NewDecimal(lhs.Mul(rhs.Decimal)), nil
+			},
 		), true
 	case ArithmeticDiv:
-		// Only executes on float values.
+		// Only executes on float or decimal values.
 		return func(lFn, rFn Function, left, right any) (any, error) {
+			if isDecimalOperand(left, right) {
+				lhs, rhs, ok := decimalPair(left, right)
+				if !ok {
+					return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
+				}
+				if rhs.IsZero() {
+					return nil, ErrFrom(ErrDivideByZero, rFn)
+				}
+				return NewDecimal(lhs.Div(rhs.Decimal)).normalize(), nil
+			}
 			lhs, err := IGetNumber(left)
 			if err != nil {
 				return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
@@ -159,8 +183,18 @@ func prodOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 			return lhs / rhs, nil
 		}, true
 	case ArithmeticMod:
-		// Only executes on integer values.
+		// Only executes on integer or decimal values.
 		return func(lFn, rFn Function, left, right any) (any, error) {
+			if isDecimalOperand(left, right) {
+				lhs, rhs, ok := decimalPair(left, right)
+				if !ok {
+					return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
+				}
+				if rhs.IsZero() {
+					return nil, ErrFrom(ErrDivideByZero, rFn)
+				}
+				return NewDecimal(lhs.Mod(rhs.Decimal)), nil
+			}
 			lhs, err := IGetInt(left)
 			if err != nil {
 				return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
@@ -188,10 +222,13 @@ func sumOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 			func(left, right float64) (float64, error) {
 				return left + right, nil
 			},
+			func(left, right Decimal) (Decimal, error) {
+				return NewDecimal(left.Add(right.Decimal)), nil
+			},
 		)
 		return func(lFn, rFn Function, left, right any) (any, error) {
 			switch left.(type) {
-			case float64, int, int64, uint64, json.Number:
+			case float64, int, int64, uint64, json.Number, Decimal:
 				return numberAdd(lFn, rFn, left, right)
 			case string, []byte:
 				lhs, err := IGetString(left)
@@ -214,6 +251,9 @@ func sumOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 			func(lhs, rhs float64) (float64, error) {
 				return lhs - rhs, nil
 			},
+			func(lhs, rhs Decimal) (Decimal, error) {
+				return NewDecimal(lhs.Sub(rhs.Decimal)), nil
+			},
 		), true
 	}
 	return nil, false
@@ -320,6 +360,12 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 		boolOpFn := compareBoolFn(op)
 		genericOpFn := compareGenericFn(op)
 		return func(lFn, rFn Function, left, right any) (any, error) {
+			if lhs, rhs, ok := decimalPair(left, right); ok {
+				if numOpFn == nil {
+					return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
+				}
+				return numOpFn(float64(lhs.Cmp(rhs.Decimal)), 0), nil
+			}
 			switch lhs := restrictForComparison(left).(type) {
 			case string:
 				if strOpFn == nil {
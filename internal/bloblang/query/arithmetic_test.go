@@ -19,6 +19,9 @@ func TestArithmeticNumberDegradation(t *testing.T) {
 		func(left, right float64) (float64, error) {
 			return left / right, nil
 		},
+		func(left, right Decimal) (Decimal, error) {
+			return NewDecimal(left.Div(right.Decimal)).normalize(), nil
+		},
 	)
 
 	testCases := []struct {
@@ -76,6 +79,24 @@ func TestArithmeticNumberDegradation(t *testing.T) {
 			right:  json.Number("3"),
 			result: 4.0,
 		},
+		{
+			name:   "left is decimal",
+			left:   mustDecimal("7.5"),
+			right:  json.Number("2.5"),
+			result: mustDecimal("3"),
+		},
+		{
+			name:   "right is decimal",
+			left:   int64(9),
+			right:  mustDecimal("4"),
+			result: mustDecimal("2.25"),
+		},
+		{
+			name:  "decimal with invalid number",
+			left:  mustDecimal("7.5"),
+			right: "not a number",
+			err:   "cannot add types decimal (from left) and string (from right)",
+		},
 		{
 			name:  "left is invalid int",
 			left:  "not a number",
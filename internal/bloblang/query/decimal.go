@@ -0,0 +1,186 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// Decimal is an arbitrary-precision decimal number. Arithmetic between
+// decimals is exact and preserves the scale of its operands, and decimals are
+// serialized as JSON numbers without passing through a floating point
+// representation.
+type Decimal struct {
+	decimal.Decimal
+}
+
+// NewDecimal wraps a decimal.Decimal as a Bloblang value.
+func NewDecimal(d decimal.Decimal) Decimal {
+	return Decimal{Decimal: d}
+}
+
+// String returns the decimal as a string, retaining any trailing zeros of its
+// scale.
+func (d Decimal) String() string {
+	if exp := d.Exponent(); exp < 0 {
+		return d.StringFixed(-exp)
+	}
+	return d.Decimal.String()
+}
+
+// MarshalJSON serializes the decimal as a JSON number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// normalize removes trailing zeros from the fractional part of a decimal.
+func (d Decimal) normalize() Decimal {
+	n, err := decimal.NewFromString(d.Decimal.String())
+	if err != nil {
+		return d
+	}
+	return Decimal{Decimal: n}
+}
+
+// IGetDecimal takes a boxed value and attempts to extract a decimal from it.
+// Floating point values are converted using the shortest representation that
+// identifies them, and json.Number values are converted exactly.
+func IGetDecimal(v any) (Decimal, error) {
+	switch t := v.(type) {
+	case Decimal:
+		return t, nil
+	case int:
+		return NewDecimal(decimal.NewFromInt(int64(t))), nil
+	case int64:
+		return NewDecimal(decimal.NewFromInt(t)), nil
+	case uint64:
+		return NewDecimal(decimal.RequireFromString(strconv.FormatUint(t, 10))), nil
+	case float64:
+		if math.IsInf(t, 0) || math.IsNaN(t) {
+			return Decimal{}, fmt.Errorf("cannot convert %v to a decimal", t)
+		}
+		return NewDecimal(decimal.NewFromFloat(t)), nil
+	case json.Number:
+		d, err := decimal.NewFromString(t.String())
+		if err != nil {
+			return Decimal{}, err
+		}
+		return NewDecimal(d), nil
+	}
+	return Decimal{}, NewTypeError(v, ValueNumber)
+}
+
+// IToDecimal takes a boxed value and attempts to extract a decimal from it or
+// parse one.
+func IToDecimal(v any) (Decimal, error) {
+	switch t := v.(type) {
+	case []byte:
+		d, err := decimal.NewFromString(string(t))
+		if err != nil {
+			return Decimal{}, err
+		}
+		return NewDecimal(d), nil
+	case string:
+		d, err := decimal.NewFromString(t)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return NewDecimal(d), nil
+	}
+	return IGetDecimal(v)
+}
+
+// numbersToDecimals walks a structured value and converts all json.Number
+// values into decimals.
+func numbersToDecimals(v any) (any, error) {
+	var err error
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			if t[k], err = numbersToDecimals(e); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, e := range t {
+			if t[i], err = numbersToDecimals(e); err != nil {
+				return nil, err
+			}
+		}
+	case json.Number:
+		return IGetDecimal(t)
+	}
+	return v, nil
+}
+
+// decimalToInt attempts to convert a decimal into a signed integer, returning
+// an error if the decimal has a fractional part or is out of bounds.
+func decimalToInt(d Decimal) (int64, error) {
+	if !d.IsInteger() {
+		return 0, errors.New("decimal value contains decimals and therefore cannot be cast as a signed integer, if you intend to round the value then call `.round_decimal()` explicitly before this cast")
+	}
+	bi := d.BigInt()
+	if !bi.IsInt64() {
+		return 0, errors.New("decimal value is out of bounds of a signed integer")
+	}
+	return bi.Int64(), nil
+}
+
+//------------------------------------------------------------------------------
+
+// DecimalRoundingModes are the rounding modes supported by decimal methods.
+var DecimalRoundingModes = []string{
+	"half_up", "half_even", "up", "down", "ceil", "floor",
+}
+
+func decimalRoundingFunc(mode string) (func(d decimal.Decimal, places int32) decimal.Decimal, error) {
+	switch mode {
+	case "half_up":
+		return decimal.Decimal.Round, nil
+	case "half_even":
+		return decimal.Decimal.RoundBank, nil
+	case "up":
+		return decimal.Decimal.RoundUp, nil
+	case "down":
+		return decimal.Decimal.RoundDown, nil
+	case "ceil":
+		return decimal.Decimal.RoundCeil, nil
+	case "floor":
+		return decimal.Decimal.RoundFloor, nil
+	}
+	return nil, fmt.Errorf("unrecognised rounding mode %q, expected one of: %v", mode, DecimalRoundingModes)
+}
+
+func isDecimalOperand(left, right any) bool {
+	_, leftIsDec := left.(Decimal)
+	_, rightIsDec := right.(Decimal)
+	return leftIsDec || rightIsDec
+}
+
+// decimalPair returns both values as decimals when at least one of them is a
+// decimal and the other is a number.
+func decimalPair(left, right any) (lhs, rhs Decimal, ok bool) {
+	if !isDecimalOperand(left, right) {
+		return
+	}
+	var err error
+	if lhs, err = iGetDecimalSanitized(left); err != nil {
+		return
+	}
+	if rhs, err = iGetDecimalSanitized(right); err != nil {
+		return
+	}
+	ok = true
+	return
+}
+
+func iGetDecimalSanitized(v any) (Decimal, error) {
+	if d, err := IGetDecimal(v); err == nil {
+		return d, nil
+	}
+	return IGetDecimal(ISanitize(v))
+}
@@ -0,0 +1,152 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecimal(s string) Decimal {
+	return NewDecimal(decimal.RequireFromString(s))
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	dec := func(s string) Function {
+		return NewLiteralFunction("", mustDecimal(s))
+	}
+
+	tests := []struct {
+		name   string
+		fns    []Function
+		ops    []ArithmeticOperator
+		output string
+	}{
+		{
+			name:   "add preserves precision",
+			fns:    []Function{dec("0.1"), NewLiteralFunction("", 0.2)},
+			ops:    []ArithmeticOperator{ArithmeticAdd},
+			output: "0.3",
+		},
+		{
+			name:   "add preserves scale",
+			fns:    []Function{dec("1.10"), dec("2.20")},
+			ops:    []ArithmeticOperator{ArithmeticAdd},
+			output: "3.30",
+		},
+		{
+			name:   "subtract large values",
+			fns:    []Function{dec("12345678901234567890.01"), NewLiteralFunction("", int64(1))},
+			ops:    []ArithmeticOperator{ArithmeticSub},
+			output: "12345678901234567889.01",
+		},
+		{
+			name:   "multiply",
+			fns:    []Function{dec("19.99"), NewLiteralFunction("", int64(3))},
+			ops:    []ArithmeticOperator{ArithmeticMul},
+			output: "59.97",
+		},
+		{
+			name:   "divide",
+			fns:    []Function{dec("10"), NewLiteralFunction("", int64(4))},
+			ops:    []ArithmeticOperator{ArithmeticDiv},
+			output: "2.5",
+		},
+		{
+			name:   "divide recurring",
+			fns:    []Function{dec("1"), dec("3")},
+			ops:    []ArithmeticOperator{ArithmeticDiv},
+			output: "0.3333333333333333",
+		},
+		{
+			name:   "modulo",
+			fns:    []Function{dec("10.5"), NewLiteralFunction("", int64(4))},
+			ops:    []ArithmeticOperator{ArithmeticMod},
+			output: "2.5",
+		},
+		{
+			name:   "json number operand",
+			fns:    []Function{NewLiteralFunction("", json.Number("99999999999999999999.5")), dec("0.5")},
+			ops:    []ArithmeticOperator{ArithmeticAdd},
+			output: "100000000000000000000.0",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := NewArithmeticExpression(test.fns, test.ops)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(FunctionContext{})
+			require.NoError(t, err)
+			require.IsType(t, Decimal{}, res)
+			assert.Equal(t, test.output, IToString(res))
+		})
+	}
+}
+
+func TestDecimalComparison(t *testing.T) {
+	tests := []struct {
+		left, right any
+		op          ArithmeticOperator
+		output      bool
+	}{
+		{left: mustDecimal("0.3"), right: 0.3, op: ArithmeticEq, output: true},
+		{left: mustDecimal("1.50"), right: mustDecimal("1.5"), op: ArithmeticEq, output: true},
+		{left: mustDecimal("1.50"), right: "1.5", op: ArithmeticEq, output: false},
+		{left: int64(2), right: mustDecimal("1.99"), op: ArithmeticGt, output: true},
+		{left: mustDecimal("12345678901234567890"), right: mustDecimal("12345678901234567891"), op: ArithmeticLt, output: true},
+		{left: mustDecimal("12345678901234567890"), right: mustDecimal("12345678901234567891"), op: ArithmeticNeq, output: true},
+	}
+
+	for _, test := range tests {
+		fn, err := NewArithmeticExpression(
+			[]Function{NewLiteralFunction("", test.left), NewLiteralFunction("", test.right)},
+			[]ArithmeticOperator{test.op},
+		)
+		require.NoError(t, err)
+
+		res, err := fn.Exec(FunctionContext{})
+		require.NoError(t, err)
+		assert.Equal(t, test.output, res, "%v %v %v", test.left, test.op, test.right)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	b, err := json.Marshal(map[string]any{
+		"a": mustDecimal("0.10"),
+		"b": mustDecimal("12345678901234567890.123456789"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":0.10,"b":12345678901234567890.123456789}`, string(b))
+}
+
+func TestDecimalRoundingModes(t *testing.T) {
+	tests := []struct {
+		mode    string
+		value   string
+		results [2]string
+	}{
+		{mode: "half_up", value: "2.345", results: [2]string{"2.35", "-2.35"}},
+		{mode: "half_even", value: "2.345", results: [2]string{"2.34", "-2.34"}},
+		{mode: "up", value: "2.341", results: [2]string{"2.35", "-2.35"}},
+		{mode: "down", value: "2.349", results: [2]string{"2.34", "-2.34"}},
+		{mode: "ceil", value: "2.341", results: [2]string{"2.35", "-2.34"}},
+		{mode: "floor", value: "2.349", results: [2]string{"2.34", "-2.35"}},
+	}
+
+	for _, test := range tests {
+		fn, err := decimalRoundingFunc(test.mode)
+		require.NoError(t, err)
+
+		d := decimal.RequireFromString(test.value)
+		assert.Equal(t, test.results[0], fn(d, 2).StringFixed(2), test.mode)
+		assert.Equal(t, test.results[1], fn(d.Neg(), 2).StringFixed(2), test.mode)
+	}
+
+	_, err := decimalRoundingFunc("nope")
+	require.EqualError(t, err, `unrecognised rounding mode "nope", expected one of: [half_up half_even up down ceil floor]`)
+}
@@ -153,6 +153,7 @@ var (
 	MethodCategoryCoercion       = "Type Coercion"
 	MethodCategoryParsing        = "Parsing"
 	MethodCategoryObjectAndArray = "Object & Array Manipulation"
+	MethodCategoryNetwork        = "Network"
 	MethodCategoryGeoIP          = "GeoIP"
 	MethodCategoryDeprecated     = "Deprecated"
 	MethodCategoryPlugin         = "Plugin"
@@ -151,3 +151,51 @@ func NewTypeMismatch(operation string, lfn, rfn Function, left, right any) *Type
 		Operation: operation,
 	}
 }
+
+//------------------------------------------------------------------------------
+
+// ErrThrown is an error raised explicitly by a mapping with the throw function,
+// which carries the value that was thrown. Thrown values may be structured, in
+// which case the error message is a JSON serialization of the value.
+type ErrThrown struct {
+	Value any
+}
+
+// Error implements the standard error interface for ErrThrown.
+func (e *ErrThrown) Error() string {
+	return IToString(e.Value)
+}
+
+// ErrorValue returns a value that represents an error within a mapping. When
+// the error was raised by the throw function with a structured value then that
+// value is returned, otherwise the error message is returned.
+func ErrorValue(err error) any {
+	var tErr *ErrThrown
+	if errors.As(err, &tErr) {
+		if _, isStr := tErr.Value.(string); !isStr {
+			return tErr.Value
+		}
+	}
+	return err.Error()
+}
+
+// ErrSourced is implemented by errors that carry details of the component that
+// produced them, such as the errors that processors attach to messages.
+type ErrSourced interface {
+	error
+
+	// ErrorSourceName returns the type of the component that produced the
+	// error, such as `http`.
+	ErrorSourceName() string
+
+	// ErrorSourceLabel returns the label of the component that produced the
+	// error, which is empty when the component was not given a label.
+	ErrorSourceLabel() string
+
+	// ErrorSourcePath returns the dot path of the component that produced the
+	// error within the config.
+	ErrorSourcePath() string
+
+	// ErrorKind returns a short classification of the error, such as `timeout`.
+	ErrorKind() string
+}
@@ -7,6 +7,7 @@ import (
 // MatchCase represents a single match case of a match expression, where a case
 // query is checked and, if true, the underlying query is executed and returned.
 type MatchCase struct {
+	pattern Pattern
 	caseFn  Function
 	queryFn Function
 }
@@ -20,6 +21,19 @@ func NewMatchCase(caseFn, queryFn Function) MatchCase {
 	}
 }
 
+// NewPatternMatchCase creates a single match case of a match expression, where
+// the value being matched is checked against a structural pattern. When the
+// value matches, the values captured by the pattern are added to the context as
+// named values and an optional guard query is checked before the underlying
+// query is executed and returned.
+func NewPatternMatchCase(pattern Pattern, guardFn, queryFn Function) MatchCase {
+	return MatchCase{
+		pattern: pattern,
+		caseFn:  guardFn,
+		queryFn: queryFn,
+	}
+}
+
 // NewMatchFunction takes a contextual mapping and a list of MatchCases, when
 // the function is executed.
 func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
@@ -39,6 +53,18 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 		}
 		for i, c := range cases {
 			caseCtx := ctx.WithValue(ctxVal)
+			if c.pattern != nil {
+				captures := map[string]any{}
+				if !c.pattern.Match(ctxVal, captures) {
+					continue
+				}
+				for _, name := range c.pattern.Captures() {
+					caseCtx = caseCtx.WithNamedValue(name, captures[name])
+				}
+				if c.caseFn == nil {
+					return c.queryFn.Exec(caseCtx)
+				}
+			}
 			var caseVal any
 			if caseVal, err = c.caseFn.Exec(caseCtx); err != nil {
 				return nil, fmt.Errorf("failed to check match case %v: %w", i, err)
@@ -54,8 +80,10 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 
 		var targets []TargetPath
 		for _, c := range cases {
-			_, caseTargets := c.caseFn.QueryTargets(contextCtx)
-			targets = append(targets, caseTargets...)
+			if c.caseFn != nil {
+				_, caseTargets := c.caseFn.QueryTargets(contextCtx)
+				targets = append(targets, caseTargets...)
+			}
 
 			// TODO: Include new current targets in returned context
 			_, queryTargets := c.queryFn.QueryTargets(contextCtx)
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -73,7 +75,34 @@ func (f *fieldFunction) Exec(ctx FunctionContext) (any, error) {
 	if len(f.path) == 0 {
 		return target, nil
 	}
-	return gabs.Wrap(target).S(f.path...).Data(), nil
+	return walkPath(target, f.path), nil
+}
+
+// walkPath resolves a path within a value following the same rules as
+// gabs.Search, but without allocating a container for each lookup unless the
+// path contains an array wildcard.
+func walkPath(v any, path []string) any {
+	for i, seg := range path {
+		switch t := v.(type) {
+		case map[string]any:
+			var exists bool
+			if v, exists = t[seg]; !exists {
+				return nil
+			}
+		case []any:
+			if seg == "*" {
+				return gabs.Wrap(t).S(path[i:]...).Data()
+			}
+			index, err := strconv.Atoi(seg)
+			if err != nil || index < 0 || index >= len(t) {
+				return nil
+			}
+			v = t[index]
+		default:
+			return nil
+		}
+	}
+	return v
 }
 
 func (f *fieldFunction) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
@@ -364,6 +393,65 @@ var _ = registerSimpleFunction(
 	},
 )
 
+var _ = registerSimpleFunction(
+	NewFunctionSpec(
+		FunctionCategoryMessage, "error_value",
+		"If an error has occurred during the processing of a message this function returns the value that was thrown when the error originated from the [`throw` function](#throw) with a structured value, otherwise the reported cause of the error as a string. If no error has occurred then `null` is returned. For more information about error handling patterns read [here][error_handling].",
+		NewExampleSpec("",
+			`root.retry = error_value().retry | false`,
+		),
+	),
+	func(ctx FunctionContext) (any, error) {
+		if v := ctx.MsgBatch.Get(ctx.Index).ErrorGet(); v != nil {
+			return ErrorValue(v), nil
+		}
+		return nil, nil
+	},
+)
+
+func registerErrorSourceFunction(name, description string, fn func(ErrSourced) string) struct{} {
+	return registerSimpleFunction(
+		NewFunctionSpec(
+			FunctionCategoryMessage, name,
+			description+" For more information about error handling patterns read [here][error_handling].",
+			NewExampleSpec("",
+				`root.doc.`+strings.TrimPrefix(name, "error_")+` = `+name+`()`,
+			),
+		),
+		func(ctx FunctionContext) (any, error) {
+			var sErr ErrSourced
+			if v := ctx.MsgBatch.Get(ctx.Index).ErrorGet(); v != nil && errors.As(v, &sErr) {
+				return fn(sErr), nil
+			}
+			return nil, nil
+		},
+	)
+}
+
+var _ = registerErrorSourceFunction(
+	"error_source_label",
+	"Returns the label of the processor that caused the current error of a message, which is an empty string when the processor has no label. If no error has occurred, or the error did not originate from a processor, then `null` is returned.",
+	ErrSourced.ErrorSourceLabel,
+)
+
+var _ = registerErrorSourceFunction(
+	"error_source_name",
+	"Returns the type of the processor that caused the current error of a message, such as `http`. If no error has occurred, or the error did not originate from a processor, then `null` is returned.",
+	ErrSourced.ErrorSourceName,
+)
+
+var _ = registerErrorSourceFunction(
+	"error_source_path",
+	"Returns the path of the processor that caused the current error of a message within the config, such as `root.pipeline.processors.0`. If no error has occurred, or the error did not originate from a processor, then `null` is returned.",
+	ErrSourced.ErrorSourcePath,
+)
+
+var _ = registerErrorSourceFunction(
+	"error_kind",
+	"Returns a classification of the current error of a message, which is one of `thrown` for errors raised with the [`throw` function](#throw), `type` for mappings that encountered a value of an unexpected type, `timeout`, `canceled`, `not_found` for lookups of missing keys, or `other`. If no error has occurred, or the error did not originate from a processor, then `null` is returned.",
+	ErrSourced.ErrorKind,
+)
+
 //------------------------------------------------------------------------------
 
 var _ = registerFunction(
@@ -776,7 +864,7 @@ var _ = registerSimpleFunction(
 var _ = registerFunction(
 	NewFunctionSpec(
 		FunctionCategoryGeneral, "throw",
-		"Throws an error similar to a regular mapping error. This is useful for abandoning a mapping entirely given certain conditions.",
+		"Throws an error similar to a regular mapping error. This is useful for abandoning a mapping entirely given certain conditions. The value thrown can be a string or any structured value, such as an object containing an error code, which is then provided to the [`catch` method](/docs/guides/bloblang/methods#catch) and the [`error_value` function](#error_value) as it was thrown.",
 		NewExampleSpec("",
 			`root.doc.type = match {
   this.exists("header.id") => "foo"
@@ -789,14 +877,25 @@ root.doc.contents = (this.body.content | this.thing.body)`,
 			`{"nothing":"matches"}`,
 			`Error("failed assignment (line 1): unknown type")`,
 		),
-	).Param(ParamString("why", "A string explanation for why an error was thrown, this will be added to the resulting error message.")),
+		NewExampleSpec("Structured values can be thrown and then inspected when caught.",
+			`root.result = match {
+  this.status == 429 => throw({"code": 429, "retry": true})
+  this.status >= 400 => throw({"code": this.status, "retry": false})
+  _ => "ok"
+}.catch(err -> if err.retry { "retry later" } else { "failed with " + err.code.string() })`,
+			`{"status":429}`,
+			`{"result":"retry later"}`,
+			`{"status":404}`,
+			`{"result":"failed with 404"}`,
+		),
+	).Param(ParamAny("why", "An explanation for why an error was thrown. Strings are added to the resulting error message, and structured values are serialized as JSON.")),
 	func(args *ParsedParams) (Function, error) {
-		msg, err := args.FieldString("why")
+		why, err := args.Field("why")
 		if err != nil {
 			return nil, err
 		}
 		return ClosureFunction("function throw", func(_ FunctionContext) (any, error) {
-			return nil, errors.New(msg)
+			return nil, &ErrThrown{Value: why}
 		}, nil), nil
 	},
 )
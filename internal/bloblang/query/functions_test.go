@@ -1,6 +1,7 @@
 package query
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -51,6 +52,17 @@ func TestFunctions(t *testing.T) {
 			),
 			output: "bar",
 		},
+		"check throw function structured": {
+			input: mustFunc("throw", map[string]any{"code": int64(429)}),
+			err:   `{"code":429}`,
+		},
+		"check throw function structured catch": {
+			input: mustMethod(
+				mustFunc("throw", map[string]any{"code": int64(429)}),
+				"catch", NewFieldFunction("code"),
+			),
+			output: int64(429),
+		},
 		"check var function": {
 			input: mustMethod(
 				mustFunc("var", "foo"),
@@ -187,6 +199,47 @@ func TestFunctions(t *testing.T) {
 	}
 }
 
+type sourcedErr struct {
+	error
+}
+
+func (sourcedErr) ErrorSourceName() string  { return "http" }
+func (sourcedErr) ErrorSourceLabel() string { return "fetch" }
+func (sourcedErr) ErrorSourcePath() string  { return "root.pipeline.processors.0" }
+func (sourcedErr) ErrorKind() string        { return "timeout" }
+
+func TestErrorFunctions(t *testing.T) {
+	msg := message.QuickBatch([][]byte{[]byte("first"), []byte("second"), []byte("third")})
+	msg.Get(1).ErrorSet(sourcedErr{errors.New("request timed out")})
+	msg.Get(2).ErrorSet(fmt.Errorf("wrapped: %w", &ErrThrown{Value: map[string]any{"code": int64(429)}}))
+
+	tests := []struct {
+		name    string
+		outputs []any
+	}{
+		{name: "error", outputs: []any{nil, "request timed out", `wrapped: {"code":429}`}},
+		{name: "error_value", outputs: []any{nil, "request timed out", map[string]any{"code": int64(429)}}},
+		{name: "error_source_name", outputs: []any{nil, "http", nil}},
+		{name: "error_source_label", outputs: []any{nil, "fetch", nil}},
+		{name: "error_source_path", outputs: []any{nil, "root.pipeline.processors.0", nil}},
+		{name: "error_kind", outputs: []any{nil, "timeout", nil}},
+	}
+
+	for _, test := range tests {
+		fn, err := InitFunctionHelper(test.name)
+		require.NoError(t, err)
+
+		for i, exp := range test.outputs {
+			res, err := fn.Exec(FunctionContext{
+				Index:    i,
+				MsgBatch: msg,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, exp, res, "%v: %v", test.name, i)
+		}
+	}
+}
+
 func TestFunctionTargets(t *testing.T) {
 	function := func(name string, args ...any) Function {
 		t.Helper()
@@ -5,7 +5,8 @@ import (
 	"fmt"
 )
 
-var errEndOfIter = errors.New("iterator reached the end")
+// ErrEndOfIter is returned by an Iterator when there are no more elements.
+var ErrEndOfIter = errors.New("iterator reached the end")
 
 // Iterator allows traversal of a Bloblang function result in iterations.
 type Iterator interface {
@@ -48,7 +49,7 @@ func arrayIterator(arr []any) Iterator {
 	return closureIterator{
 		next: func() (any, error) {
 			if len(arr) == 0 {
-				return nil, errEndOfIter
+				return nil, ErrEndOfIter
 			}
 			v := arr[0]
 			arr = arr[1:]
@@ -68,7 +69,7 @@ func drainIter(iter Iterator) ([]any, error) {
 	for {
 		v, err := iter.Next()
 		if err != nil {
-			if errors.Is(err, errEndOfIter) {
+			if errors.Is(err, ErrEndOfIter) {
 				return arr, nil
 			}
 			return nil, err
@@ -132,7 +133,7 @@ func (f *filterMethod) TryIterate(ctx FunctionContext) (Iterator, any, error) {
 			for {
 				v, err := iter.Next()
 				if err != nil {
-					if err != errEndOfIter {
+					if err != ErrEndOfIter {
 						err = ErrFrom(err, f.target)
 					}
 					return nil, err
@@ -224,7 +225,7 @@ func (m *mapEachMethod) TryIterate(ctx FunctionContext) (Iterator, any, error) {
 			for {
 				v, err := iter.Next()
 				if err != nil {
-					if err != errEndOfIter {
+					if err != ErrEndOfIter {
 						err = ErrFrom(err, m.target)
 					}
 					return nil, err
@@ -0,0 +1,179 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSONArrayIterator is an Iterator that walks the elements of a JSON array
+// read from a stream, where each element is decoded only once it is reached and
+// therefore the document as a whole is never held in memory.
+//
+// The array can be nested within objects, in which case it is located by a
+// path of object keys. The fields of those objects that precede the array
+// within the stream are collected as the context of the array, and any fields
+// that follow it are never read.
+type JSONArrayIterator struct {
+	dec  *json.Decoder
+	path []string
+
+	context map[string]any
+	started bool
+	ended   bool
+}
+
+var _ Iterator = &JSONArrayIterator{}
+
+// NewJSONArrayIterator creates an iterator over the elements of a JSON array
+// read from a stream, which is located by following a path of object keys from
+// the root of the document. An empty path means the root of the document must
+// be the array.
+func NewJSONArrayIterator(r io.Reader, path []string) *JSONArrayIterator {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &JSONArrayIterator{
+		dec:     dec,
+		path:    path,
+		context: map[string]any{},
+	}
+}
+
+func jsonTokenDescription(tok json.Token) string {
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return "object"
+		case '[':
+			return "array"
+		}
+		return string(t)
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "bool"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", tok)
+}
+
+func (j *JSONArrayIterator) expectDelim(delim json.Delim, depth int) error {
+	tok, err := j.dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		exp := ValueObject
+		if delim == '[' {
+			exp = ValueArray
+		}
+		location := "root"
+		if depth > 0 {
+			location = "path " + strings.Join(j.path[:depth], ".")
+		}
+		return fmt.Errorf("expected %v at %v, found %v", exp, location, jsonTokenDescription(tok))
+	}
+	return nil
+}
+
+// seek reads the stream up to the opening of the array, collecting any fields
+// of parent objects that precede it.
+func (j *JSONArrayIterator) seek() error {
+	context := j.context
+	for i, key := range j.path {
+		if err := j.expectDelim('{', i); err != nil {
+			return err
+		}
+		for {
+			if !j.dec.More() {
+				return fmt.Errorf("path %v was not found", strings.Join(j.path[:i+1], "."))
+			}
+			tok, err := j.dec.Token()
+			if err != nil {
+				return err
+			}
+			k, _ := tok.(string)
+			if k == key {
+				break
+			}
+			var v any
+			if err := j.dec.Decode(&v); err != nil {
+				return err
+			}
+			context[k] = v
+		}
+		if i < len(j.path)-1 {
+			child := map[string]any{}
+			context[key] = child
+			context = child
+		}
+	}
+	return j.expectDelim('[', len(j.path))
+}
+
+func (j *JSONArrayIterator) next(v any) error {
+	if !j.started {
+		j.started = true
+		if err := j.seek(); err != nil {
+			j.ended = true
+			return err
+		}
+	}
+	if j.ended {
+		return ErrEndOfIter
+	}
+	if !j.dec.More() {
+		j.ended = true
+		if _, err := j.dec.Token(); err != nil {
+			return err
+		}
+		return ErrEndOfIter
+	}
+	if err := j.dec.Decode(v); err != nil {
+		j.ended = true
+		return err
+	}
+	return nil
+}
+
+// Next decodes the next element of the array.
+func (j *JSONArrayIterator) Next() (any, error) {
+	var v any
+	if err := j.next(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NextRaw provides the next element of the array without decoding it.
+func (j *JSONArrayIterator) NextRaw() (json.RawMessage, error) {
+	var v json.RawMessage
+	if err := j.next(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Len is not known ahead of reaching the end of the array.
+func (j *JSONArrayIterator) Len() (int, bool) {
+	return 0, false
+}
+
+// Context returns the fields of the parent objects of the array that precede
+// it, nested by their path. The context is populated once the first element
+// has been read. A copy is returned on each call and can therefore be modified
+// freely, e.g. when added to each message consumed from the array.
+func (j *JSONArrayIterator) Context() map[string]any {
+	if j.context == nil {
+		return nil
+	}
+	return IClone(j.context).(map[string]any)
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONArrayIterator(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		path     []string
+		elements []any
+		context  map[string]any
+		err      string
+	}{
+		"root array": {
+			input:    `[{"id":1},"foo",[true,null]]`,
+			elements: []any{map[string]any{"id": json.Number("1")}, "foo", []any{true, nil}},
+			context:  map[string]any{},
+		},
+		"empty array": {
+			input:    `[]`,
+			elements: nil,
+			context:  map[string]any{},
+		},
+		"nested array with context": {
+			input: `{"source":"foo","data":{"page":2,"items":[1,2],"next":"ignored"},"after":true}`,
+			path:  []string{"data", "items"},
+			elements: []any{
+				json.Number("1"), json.Number("2"),
+			},
+			context: map[string]any{
+				"source": "foo",
+				"data":   map[string]any{"page": json.Number("2")},
+			},
+		},
+		"path not found": {
+			input: `{"source":"foo","data":{"page":2}}`,
+			path:  []string{"data", "items"},
+			err:   "path data.items was not found",
+		},
+		"not an array": {
+			input: `{"items":{"foo":"bar"}}`,
+			path:  []string{"items"},
+			err:   "expected array at path items, found object",
+		},
+		"not an object": {
+			input: `[1,2]`,
+			path:  []string{"items"},
+			err:   "expected object at root, found array",
+		},
+		"truncated": {
+			input:    `{"items":[1,2`,
+			path:     []string{"items"},
+			elements: []any{json.Number("1"), json.Number("2")},
+			err:      "unexpected end of JSON input",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			iter := NewJSONArrayIterator(strings.NewReader(test.input), test.path)
+
+			var elements []any
+			var err error
+			for {
+				var v any
+				if v, err = iter.Next(); err != nil {
+					break
+				}
+				elements = append(elements, v)
+			}
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+			} else {
+				require.ErrorIs(t, err, ErrEndOfIter)
+				assert.Equal(t, test.context, iter.Context())
+			}
+			assert.Equal(t, test.elements, elements)
+
+			_, err = iter.Next()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestJSONArrayIteratorRaw(t *testing.T) {
+	iter := NewJSONArrayIterator(strings.NewReader(`{"items":[ {"a": 1} , "b" ]}`), []string{"items"})
+
+	v, err := iter.NextRaw()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, string(v))
+
+	v, err = iter.NextRaw()
+	require.NoError(t, err)
+	assert.Equal(t, `"b"`, string(v))
+
+	_, err = iter.NextRaw()
+	assert.ErrorIs(t, err, ErrEndOfIter)
+}
+
+func TestJSONArrayIteratorContextCopied(t *testing.T) {
+	iter := NewJSONArrayIterator(strings.NewReader(`{"meta":{"id":"foo"},"items":[1,2]}`), []string{"items"})
+
+	_, err := iter.Next()
+	require.NoError(t, err)
+
+	ctx := iter.Context()
+	ctx["meta"].(map[string]any)["id"] = "bar"
+
+	_, err = iter.Next()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"meta": map[string]any{"id": "foo"}}, iter.Context())
+}
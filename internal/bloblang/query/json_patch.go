@@ -0,0 +1,362 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Implementations of RFC 6902 (JSON Patch) and RFC 7396 (JSON Merge Patch)
+// that operate directly on the generic value types of Bloblang.
+
+func jsonPointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("pointer %q must begin with a forward slash", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		t = strings.ReplaceAll(t, "~1", "/")
+		tokens[i] = strings.ReplaceAll(t, "~0", "~")
+	}
+	return tokens, nil
+}
+
+func jsonPointerEscape(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+func jsonPatchIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || (len(token) > 1 && token[0] == '0') || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > length || (!allowEnd && i == length) {
+		return 0, fmt.Errorf("array index %v out of bounds", i)
+	}
+	return i, nil
+}
+
+func jsonPatchGet(node any, tokens []string) (any, error) {
+	for _, token := range tokens {
+		switch t := node.(type) {
+		case map[string]any:
+			v, exists := t[token]
+			if !exists {
+				return nil, fmt.Errorf("key %q does not exist", token)
+			}
+			node = v
+		case []any:
+			i, err := jsonPatchIndex(token, len(t), false)
+			if err != nil {
+				return nil, err
+			}
+			node = t[i]
+		default:
+			return nil, fmt.Errorf("cannot reference %q of value type %v", token, ITypeOf(node))
+		}
+	}
+	return node, nil
+}
+
+// jsonPatchMutate walks a value to the container of the final token of a
+// pointer and calls fn with it, returning the value with the modified
+// container in its place.
+func jsonPatchMutate(node any, tokens []string, fn func(container any, token string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return fn(node, tokens[0])
+	}
+	switch t := node.(type) {
+	case map[string]any:
+		child, exists := t[tokens[0]]
+		if !exists {
+			return nil, fmt.Errorf("key %q does not exist", tokens[0])
+		}
+		newChild, err := jsonPatchMutate(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[tokens[0]] = newChild
+		return t, nil
+	case []any:
+		i, err := jsonPatchIndex(tokens[0], len(t), false)
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := jsonPatchMutate(t[i], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[i] = newChild
+		return t, nil
+	}
+	return nil, fmt.Errorf("cannot reference %q of value type %v", tokens[0], ITypeOf(node))
+}
+
+func jsonPatchAdd(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return jsonPatchMutate(doc, tokens, func(container any, token string) (any, error) {
+		switch t := container.(type) {
+		case map[string]any:
+			t[token] = value
+			return t, nil
+		case []any:
+			i, err := jsonPatchIndex(token, len(t), true)
+			if err != nil {
+				return nil, err
+			}
+			t = append(t, nil)
+			copy(t[i+1:], t[i:])
+			t[i] = value
+			return t, nil
+		}
+		return nil, fmt.Errorf("cannot add %q to value type %v", token, ITypeOf(container))
+	})
+}
+
+func jsonPatchRemove(doc any, tokens []string) (any, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the root of a document")
+	}
+	return jsonPatchMutate(doc, tokens, func(container any, token string) (any, error) {
+		switch t := container.(type) {
+		case map[string]any:
+			if _, exists := t[token]; !exists {
+				return nil, fmt.Errorf("key %q does not exist", token)
+			}
+			delete(t, token)
+			return t, nil
+		case []any:
+			i, err := jsonPatchIndex(token, len(t), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(t[:i], t[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %q from value type %v", token, ITypeOf(container))
+	})
+}
+
+func jsonPatchReplace(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return jsonPatchMutate(doc, tokens, func(container any, token string) (any, error) {
+		switch t := container.(type) {
+		case map[string]any:
+			if _, exists := t[token]; !exists {
+				return nil, fmt.Errorf("key %q does not exist", token)
+			}
+			t[token] = value
+			return t, nil
+		case []any:
+			i, err := jsonPatchIndex(token, len(t), false)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = value
+			return t, nil
+		}
+		return nil, fmt.Errorf("cannot replace %q of value type %v", token, ITypeOf(container))
+	})
+}
+
+func jsonPatchOpField(op map[string]any, field string) (string, error) {
+	v, exists := op[field]
+	if !exists {
+		return "", fmt.Errorf("missing field `%v`", field)
+	}
+	s, err := IGetString(v)
+	if err != nil {
+		return "", fmt.Errorf("field `%v`: %w", field, err)
+	}
+	return s, nil
+}
+
+func jsonPatchApplyOp(doc any, op map[string]any) (any, error) {
+	opType, err := jsonPatchOpField(op, "op")
+	if err != nil {
+		return nil, err
+	}
+	path, err := jsonPatchOpField(op, "path")
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := jsonPointerTokens(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opType {
+	case "add", "replace", "test":
+		value, exists := op["value"]
+		if !exists {
+			return nil, errors.New("missing field `value`")
+		}
+		switch opType {
+		case "add":
+			return jsonPatchAdd(doc, tokens, IClone(value))
+		case "replace":
+			return jsonPatchReplace(doc, tokens, IClone(value))
+		}
+		current, err := jsonPatchGet(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !ICompare(current, value) {
+			return nil, fmt.Errorf("test failed, value at %q does not match", path)
+		}
+		return doc, nil
+	case "remove":
+		return jsonPatchRemove(doc, tokens)
+	case "move", "copy":
+		from, err := jsonPatchOpField(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		fromTokens, err := jsonPointerTokens(from)
+		if err != nil {
+			return nil, err
+		}
+		value, err := jsonPatchGet(doc, fromTokens)
+		if err != nil {
+			return nil, err
+		}
+		if opType == "copy" {
+			return jsonPatchAdd(doc, tokens, IClone(value))
+		}
+		if from == path {
+			return doc, nil
+		}
+		if strings.HasPrefix(path, from+"/") {
+			return nil, fmt.Errorf("cannot move %q into one of its children", from)
+		}
+		if doc, err = jsonPatchRemove(doc, fromTokens); err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(doc, tokens, value)
+	}
+	return nil, fmt.Errorf("unrecognised operation %q", opType)
+}
+
+// JSONPatch applies a list of RFC 6902 JSON Patch operations to a document,
+// returning the resulting document. The provided document is not mutated.
+func JSONPatch(doc any, ops []any) (any, error) {
+	doc = IClone(doc)
+	for i, opV := range ops {
+		op, ok := opV.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("operation %v: %w", i, NewTypeError(opV, ValueObject))
+		}
+		var err error
+		if doc, err = jsonPatchApplyOp(doc, op); err != nil {
+			return nil, fmt.Errorf("operation %v: %w", i, err)
+		}
+	}
+	return doc, nil
+}
+
+// JSONMergePatch applies an RFC 7396 JSON Merge Patch document to a document,
+// returning the resulting document. The provided document is not mutated.
+func JSONMergePatch(doc, patch any) any {
+	return jsonMergePatch(IClone(doc), patch)
+}
+
+func jsonMergePatch(doc, patch any) any {
+	patchObj, isObj := patch.(map[string]any)
+	if !isObj {
+		return IClone(patch)
+	}
+	docObj, isObj := doc.(map[string]any)
+	if !isObj {
+		docObj = map[string]any{}
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(docObj, k)
+			continue
+		}
+		docObj[k] = jsonMergePatch(docObj[k], v)
+	}
+	return docObj
+}
+
+// JSONDiff returns a list of RFC 6902 JSON Patch operations that transform a
+// document into another. Objects are compared key by key and arrays index by
+// index, with elements added or removed from the end of an array when their
+// lengths differ.
+func JSONDiff(from, to any) []any {
+	ops := []any{}
+	jsonDiff("", from, to, &ops)
+	return ops
+}
+
+func jsonDiff(path string, from, to any, ops *[]any) {
+	switch f := from.(type) {
+	case map[string]any:
+		t, isObj := to.(map[string]any)
+		if !isObj {
+			break
+		}
+		keys := make([]string, 0, len(f))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childPath := path + "/" + jsonPointerEscape(k)
+			if tv, exists := t[k]; exists {
+				jsonDiff(childPath, f[k], tv, ops)
+			} else {
+				*ops = append(*ops, map[string]any{"op": "remove", "path": childPath})
+			}
+		}
+		keys = keys[:0]
+		for k := range t {
+			if _, exists := f[k]; !exists {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			*ops = append(*ops, map[string]any{
+				"op": "add", "path": path + "/" + jsonPointerEscape(k), "value": IClone(t[k]),
+			})
+		}
+		return
+	case []any:
+		t, isArray := to.([]any)
+		if !isArray {
+			break
+		}
+		common := len(f)
+		if len(t) < common {
+			common = len(t)
+		}
+		for i := 0; i < common; i++ {
+			jsonDiff(path+"/"+strconv.Itoa(i), f[i], t[i], ops)
+		}
+		for i := len(f) - 1; i >= common; i-- {
+			*ops = append(*ops, map[string]any{"op": "remove", "path": path + "/" + strconv.Itoa(i)})
+		}
+		for i := common; i < len(t); i++ {
+			*ops = append(*ops, map[string]any{
+				"op": "add", "path": path + "/" + strconv.Itoa(i), "value": IClone(t[i]),
+			})
+		}
+		return
+	}
+	if ITypeOf(from) != ITypeOf(to) || !ICompare(from, to) {
+		*ops = append(*ops, map[string]any{"op": "replace", "path": path, "value": IClone(to)})
+	}
+}
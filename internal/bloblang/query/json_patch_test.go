@@ -0,0 +1,190 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonValue(t testing.TB, s string) any {
+	t.Helper()
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		ops  string
+		res  string
+		err  string
+	}{
+		{
+			name: "add object member",
+			doc:  `{"foo":"bar"}`,
+			ops:  `[{"op":"add","path":"/baz","value":"qux"}]`,
+			res:  `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name: "add array element",
+			doc:  `{"foo":["bar","baz"]}`,
+			ops:  `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			res:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name: "remove array element",
+			doc:  `{"foo":["bar","qux","baz"]}`,
+			ops:  `[{"op":"remove","path":"/foo/1"}]`,
+			res:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name: "move value",
+			doc:  `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			ops:  `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			res:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name: "move array element",
+			doc:  `{"foo":["all","grass","cows","eat"]}`,
+			ops:  `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			res:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name: "copy and test",
+			doc:  `{"a":{"b":[1,2]}}`,
+			ops:  `[{"op":"copy","from":"/a/b","path":"/c"},{"op":"test","path":"/c/1","value":2}]`,
+			res:  `{"a":{"b":[1,2]},"c":[1,2]}`,
+		},
+		{
+			name: "escaped pointer",
+			doc:  `{"a/b":{"m~n":1}}`,
+			ops:  `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`,
+			res:  `{"a/b":{"m~n":2}}`,
+		},
+		{
+			name: "replace root",
+			doc:  `{"a":1}`,
+			ops:  `[{"op":"replace","path":"","value":[1]}]`,
+			res:  `[1]`,
+		},
+		{
+			name: "test failure",
+			doc:  `{"baz":"qux"}`,
+			ops:  `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:  `operation 0: test failed, value at "/baz" does not match`,
+		},
+		{
+			name: "add to nonexistent target",
+			doc:  `{"foo":"bar"}`,
+			ops:  `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:  `operation 0: key "baz" does not exist`,
+		},
+		{
+			name: "index out of bounds",
+			doc:  `{"foo":[1]}`,
+			ops:  `[{"op":"add","path":"/foo/2","value":2}]`,
+			err:  `operation 0: array index 2 out of bounds`,
+		},
+		{
+			name: "move into child",
+			doc:  `{"foo":{"bar":1}}`,
+			ops:  `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			err:  `operation 0: cannot move "/foo" into one of its children`,
+		},
+		{
+			name: "unknown operation",
+			doc:  `{}`,
+			ops:  `[{"op":"nope","path":"/a"}]`,
+			err:  `operation 0: unrecognised operation "nope"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			doc := jsonValue(t, test.doc)
+			res, err := JSONPatch(doc, jsonValue(t, test.ops).([]any))
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, jsonValue(t, test.res), res)
+			assert.Equal(t, jsonValue(t, test.doc), doc, "input document was mutated")
+		})
+	}
+}
+
+func TestJSONMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, res string
+	}{
+		{doc: `{"a":"b"}`, patch: `{"a":"c"}`, res: `{"a":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"b":"c"}`, res: `{"a":"b","b":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"a":null}`, res: `{}`},
+		{doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, res: `{"b":"c"}`},
+		{doc: `{"a":["b"]}`, patch: `{"a":"c"}`, res: `{"a":"c"}`},
+		{doc: `{"a":"c"}`, patch: `{"a":["b"]}`, res: `{"a":["b"]}`},
+		{doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, res: `{"a":{"b":"d"}}`},
+		{doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, res: `{"a":[1]}`},
+		{doc: `["a","b"]`, patch: `["c","d"]`, res: `["c","d"]`},
+		{doc: `{"a":"b"}`, patch: `["c"]`, res: `["c"]`},
+		{doc: `{"e":null}`, patch: `{"a":1}`, res: `{"a":1,"e":null}`},
+		{doc: `[1,2]`, patch: `{"a":"b","c":null}`, res: `{"a":"b"}`},
+		{doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, res: `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		doc := jsonValue(t, test.doc)
+		res := JSONMergePatch(doc, jsonValue(t, test.patch))
+		assert.Equal(t, jsonValue(t, test.res), res, "%v + %v", test.doc, test.patch)
+		assert.Equal(t, jsonValue(t, test.doc), doc, "input document was mutated")
+	}
+}
+
+func TestJSONDiff(t *testing.T) {
+	tests := []struct {
+		from, to, ops string
+	}{
+		{from: `{"a":1}`, to: `{"a":1}`, ops: `[]`},
+		{from: `{"a":1}`, to: `{"a":1.0}`, ops: `[]`},
+		{from: `"foo"`, to: `"bar"`, ops: `[{"op":"replace","path":"","value":"bar"}]`},
+		{from: `{"a":1}`, to: `{"a":"1"}`, ops: `[{"op":"replace","path":"/a","value":"1"}]`},
+		{
+			from: `{"a":{"b":1,"c":2},"d/e":[1,2,3]}`,
+			to:   `{"a":{"b":1,"x":2},"d/e":[1,5],"f":null}`,
+			ops: `[
+				{"op":"remove","path":"/a/c"},
+				{"op":"add","path":"/a/x","value":2},
+				{"op":"replace","path":"/d~1e/1","value":5},
+				{"op":"remove","path":"/d~1e/2"},
+				{"op":"add","path":"/f","value":null}
+			]`,
+		},
+		{
+			from: `[1]`,
+			to:   `[1,{"a":2},3]`,
+			ops:  `[{"op":"add","path":"/1","value":{"a":2}},{"op":"add","path":"/2","value":3}]`,
+		},
+		{
+			from: `[1,2,3]`,
+			to:   `[]`,
+			ops:  `[{"op":"remove","path":"/2"},{"op":"remove","path":"/1"},{"op":"remove","path":"/0"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		from, to := jsonValue(t, test.from), jsonValue(t, test.to)
+
+		ops := JSONDiff(from, to)
+		assert.Equal(t, jsonValue(t, test.ops), ops, "%v -> %v", test.from, test.to)
+
+		res, err := JSONPatch(from, ops)
+		require.NoError(t, err)
+		assert.True(t, ICompare(to, res), "%v -> %v", test.from, test.to)
+	}
+}
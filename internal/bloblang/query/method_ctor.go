@@ -16,22 +16,67 @@ func registerSimpleMethod(spec MethodSpec, ctor simpleMethodConstructor) struct{
 		if err != nil {
 			return nil, err
 		}
-		return ClosureFunction("method "+spec.Name, func(ctx FunctionContext) (any, error) {
-			v, err := target.Exec(ctx)
-			if err != nil {
-				return nil, err
+		if lit, isLit := target.(*Literal); isLit && args.static() {
+			// Simple methods are a function of only their target and arguments,
+			// and so when both are static the result can be computed ahead of
+			// time. Errors are left for execution in order to preserve their
+			// context.
+			if v, err := fn(lit.Value, FunctionContext{}); err == nil {
+				return NewLiteralFunction("method "+spec.Name, v), nil
 			}
-			res, err := fn(v, ctx)
-			if err != nil {
-				return nil, ErrFrom(err, target)
-			}
-			return res, nil
-		}, target.QueryTargets), nil
+		}
+		return newSimpleMethodChain(spec.Name, target, fn), nil
 	})
 }
 
 type simpleMethod func(v any, ctx FunctionContext) (any, error)
 
+// simpleMethodChain executes a sequence of simple methods on the result of a
+// target function. Consecutive simple methods are fused into a single chain so
+// that intermediate results are passed directly from one method to the next
+// rather than through nested function executions.
+type simpleMethodChain struct {
+	target Function
+	steps  []simpleMethodStep
+}
+
+type simpleMethodStep struct {
+	name string
+	fn   simpleMethod
+	from Function
+}
+
+func newSimpleMethodChain(name string, target Function, fn simpleMethod) *simpleMethodChain {
+	step := simpleMethodStep{name: name, fn: fn, from: target}
+	if c, isChain := target.(*simpleMethodChain); isChain {
+		steps := make([]simpleMethodStep, 0, len(c.steps)+1)
+		steps = append(steps, c.steps...)
+		return &simpleMethodChain{target: c.target, steps: append(steps, step)}
+	}
+	return &simpleMethodChain{target: target, steps: []simpleMethodStep{step}}
+}
+
+func (c *simpleMethodChain) Annotation() string {
+	return "method " + c.steps[len(c.steps)-1].name
+}
+
+func (c *simpleMethodChain) Exec(ctx FunctionContext) (any, error) {
+	v, err := c.target.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range c.steps {
+		if v, err = step.fn(v, ctx); err != nil {
+			return nil, ErrFrom(err, step.from)
+		}
+	}
+	return v, nil
+}
+
+func (c *simpleMethodChain) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
+	return c.target.QueryTargets(ctx)
+}
+
 func stringMethod(fn func(v string) (any, error)) simpleMethod {
 	return func(v any, ctx FunctionContext) (any, error) {
 		s, err := IGetString(v)
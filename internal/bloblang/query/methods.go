@@ -114,10 +114,36 @@ func boolMethod(target Function, args *ParsedParams) (Function, error) {
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"decimal", "",
+	).InCategory(
+		MethodCategoryCoercion,
+		"Attempt to parse a value into an arbitrary-precision decimal. Arithmetic between decimals, or between a decimal and a number, is exact and preserves the scale of its operands, and decimals are serialized as JSON numbers without being converted into floating point. Division results are rounded to 16 decimal places.",
+		NewExampleSpec("",
+			`root.total = this.price.decimal() + this.fee.decimal()`,
+			`{"price":0.1,"fee":0.2}`,
+			`{"total":0.3}`,
+		),
+		NewExampleSpec("Strings are parsed exactly, which allows values that exceed the precision of a floating point number to be processed.",
+			`root.id = this.id.decimal() + 1`,
+			`{"id":"123456789012345678901234567890"}`,
+			`{"id":123456789012345678901234567891}`,
+		),
+	),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			return IToDecimal(v)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerMethod(
 	NewMethodSpec(
 		"catch",
-		"If the result of a target query fails (due to incorrect types, failed parsing, etc) the argument is returned instead.",
+		"If the result of a target query fails (due to incorrect types, failed parsing, etc) the argument is returned instead. When the argument is a query the error is provided as its context, which is the value given to [`throw`](/docs/guides/bloblang/functions#throw) when a structured value was thrown, otherwise the error message as a string.",
 		NewExampleSpec("",
 			`root.doc.id = this.thing.id.string().catch(uuid_v4())`,
 		),
@@ -145,7 +171,7 @@ func catchMethod(fn Function, args *ParsedParams) (Function, error) {
 	return ClosureFunction("method catch", func(ctx FunctionContext) (any, error) {
 		res, err := fn.Exec(ctx)
 		if err != nil {
-			return catchFn.Exec(ctx.WithValue(err.Error()))
+			return catchFn.Exec(ctx.WithValue(ErrorValue(err)))
 		}
 		return res, err
 	}, aggregateTargetPaths(fn, catchFn)), nil
@@ -255,7 +281,7 @@ func (g *getMethod) Exec(ctx FunctionContext) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	return gabs.Wrap(v).S(g.path...).Data(), nil
+	return walkPath(v, g.path), nil
 }
 
 func (g *getMethod) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
@@ -469,7 +495,7 @@ var _ = registerSimpleMethod(
 		"type", "",
 	).InCategory(
 		MethodCategoryCoercion,
-		"Returns the type of a value as a string, providing one of the following values: `string`, `bytes`, `number`, `bool`, `timestamp`, `array`, `object` or `null`.",
+		"Returns the type of a value as a string, providing one of the following values: `string`, `bytes`, `number`, `decimal`, `bool`, `timestamp`, `array`, `object` or `null`.",
 		NewExampleSpec("",
 			`root.bar_type = this.bar.type()
 root.foo_type = this.foo.type()`,
@@ -1059,6 +1059,8 @@ var _ = registerSimpleMethod(
 		"parse_json", "",
 	).Param(
 		ParamBool("use_number", "An optional flag that when set makes parsing numbers as json.Number instead of the default float64.").Optional(),
+	).Param(
+		ParamBool("use_decimal", "An optional flag that when set parses all numbers as arbitrary-precision decimals, which preserves their exact value and allows them to be used in exact arithmetic.").Optional(),
 	).InCategory(
 		MethodCategoryParsing,
 		"Attempts to parse a string as a JSON document and returns the result.",
@@ -1072,12 +1074,22 @@ var _ = registerSimpleMethod(
 			`{"doc":"{\"foo\":\"11380878173205700000000000000000000000000000000\"}"}`,
 			`{"doc":{"foo":"11380878173205700000000000000000000000000000000"}}`,
 		),
+		NewExampleSpec("",
+			`let doc = this.doc.parse_json(use_decimal: true)
+root.total = $doc.price + $doc.fee`,
+			`{"doc":"{\"price\":0.1,\"fee\":0.2}"}`,
+			`{"total":0.3}`,
+		),
 	),
 	func(args *ParsedParams) (simpleMethod, error) {
 		useNumber, err := args.FieldOptionalBool("use_number")
 		if err != nil {
 			return nil, err
 		}
+		useDecimal, err := args.FieldOptionalBool("use_decimal")
+		if err != nil {
+			return nil, err
+		}
 		return func(v any, ctx FunctionContext) (any, error) {
 			var jsonBytes []byte
 			switch t := v.(type) {
@@ -1090,12 +1102,15 @@ var _ = registerSimpleMethod(
 			}
 			var jObj any
 			decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
-			if useNumber != nil && *useNumber {
+			if (useNumber != nil && *useNumber) || (useDecimal != nil && *useDecimal) {
 				decoder.UseNumber()
 			}
 			if err := decoder.Decode(&jObj); err != nil {
 				return nil, fmt.Errorf("failed to parse value as JSON: %w", err)
 			}
+			if useDecimal != nil && *useDecimal {
+				return numbersToDecimals(jObj)
+			}
 			return jObj, nil
 		}, nil
 	},
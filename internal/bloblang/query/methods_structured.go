@@ -1086,6 +1086,79 @@ func assigner(destination, source any) any {
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"json_patch", "Applies a list of [JSON Patch (RFC 6902)](https://datatracker.ietf.org/doc/html/rfc6902) operations to a value and returns the result. Operations are applied in order, and if any operation fails, including a failed `test` operation, an error is returned.",
+	).InCategory(
+		MethodCategoryObjectAndArray, "",
+		NewExampleSpec(``,
+			`root = this.doc.json_patch(this.patch)`,
+			`{"doc":{"name":"foo","tags":["a"]},"patch":[{"op":"replace","path":"/name","value":"bar"},{"op":"add","path":"/tags/-","value":"b"},{"op":"move","from":"/name","path":"/title"}]}`,
+			`{"tags":["a","b"],"title":"bar"}`,
+		),
+		NewExampleSpec(`Patches that were produced with the `+"[`json_diff`](#json_diff)"+` method can be applied to reproduce a document.`,
+			`root = this.before.json_patch(this.before.json_diff(this.after)) == this.after`,
+			`{"before":{"id":1,"items":[1,2,3]},"after":{"id":1,"items":[1,4],"status":"done"}}`,
+			`true`,
+		),
+	).Param(ParamArray("ops", "An array of JSON Patch operations to apply.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		ops, err := args.FieldArray("ops")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			return JSONPatch(v, ops)
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"json_merge_patch", "Applies a [JSON Merge Patch (RFC 7396)](https://datatracker.ietf.org/doc/html/rfc7396) document to a value and returns the result. Fields of the patch are recursively assigned to the value, and fields of the patch that are `null` are removed from the value.",
+	).InCategory(
+		MethodCategoryObjectAndArray, "",
+		NewExampleSpec(``,
+			`root = this.doc.json_merge_patch(this.patch)`,
+			`{"doc":{"name":"foo","meta":{"a":1,"b":2}},"patch":{"meta":{"b":null,"c":3},"tags":["new"]}}`,
+			`{"meta":{"a":1,"c":3},"name":"foo","tags":["new"]}`,
+		),
+	).Param(ParamAny("doc", "The merge patch document to apply.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		patch, err := args.Field("doc")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			return JSONMergePatch(v, patch), nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"json_diff", "Compares a value with another and returns a list of [JSON Patch (RFC 6902)](https://datatracker.ietf.org/doc/html/rfc6902) operations that transform the value into the other. Objects are compared field by field and arrays index by index, where elements are added to or removed from the end of an array when the lengths of the arrays differ. If the values are equal an empty array is returned.",
+	).InCategory(
+		MethodCategoryObjectAndArray, "",
+		NewExampleSpec(``,
+			`root = this.before.json_diff(this.after)`,
+			`{"before":{"id":1,"name":"foo","tags":["a","b"]},"after":{"id":1,"name":"bar","tags":["a"],"status":"new"}}`,
+			`[{"op":"replace","path":"/name","value":"bar"},{"op":"remove","path":"/tags/1"},{"op":"add","path":"/status","value":"new"}]`,
+		),
+	).Param(ParamAny("other", "The value to compare against.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		other, err := args.Field("other")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			return JSONDiff(v, other), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"not_empty", "",
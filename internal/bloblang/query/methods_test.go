@@ -2107,3 +2107,52 @@ func TestMethodNoArgsTargets(t *testing.T) {
 		assert.Contains(t, targets, exp, "method: %v", k)
 	}
 }
+
+func TestSimpleMethodFolding(t *testing.T) {
+	method := func(fn Function, name string, args ...any) Function {
+		t.Helper()
+		m, err := InitMethodHelper(name, fn, args...)
+		require.NoError(t, err)
+		return m
+	}
+
+	folded := method(method(NewLiteralFunction("", " Foo-Bar "), "trim"), "replace_all", "-", "_")
+	lit, isLit := folded.(*Literal)
+	require.True(t, isLit, "expected literal, got %T", folded)
+	assert.Equal(t, "Foo_Bar", lit.Value)
+
+	failed := method(NewLiteralFunction("", "nope"), "parse_json")
+	_, isLit = failed.(*Literal)
+	require.False(t, isLit)
+	_, err := failed.Exec(FunctionContext{})
+	require.EqualError(t, err, `string literal: failed to parse value as JSON: invalid character 'o' in literal null (expecting 'u')`)
+
+	dynamic := method(NewLiteralFunction("", "foo"), "replace_all", NewFieldFunction("from"), "x")
+	_, isLit = dynamic.(*Literal)
+	require.False(t, isLit)
+}
+
+func TestSimpleMethodChains(t *testing.T) {
+	method := func(fn Function, name string, args ...any) Function {
+		t.Helper()
+		m, err := InitMethodHelper(name, fn, args...)
+		require.NoError(t, err)
+		return m
+	}
+
+	chain := method(method(method(NewFieldFunction("foo"), "trim"), "uppercase"), "parse_json")
+	c, isChain := chain.(*simpleMethodChain)
+	require.True(t, isChain, "expected chain, got %T", chain)
+	assert.Len(t, c.steps, 3)
+	assert.Equal(t, "method parse_json", chain.Annotation())
+
+	res, err := chain.Exec(FunctionContext{}.WithValue(map[string]any{"foo": ` {"a":"b"} `}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"A": "B"}, res)
+
+	_, err = chain.Exec(FunctionContext{}.WithValue(map[string]any{"foo": " nah "}))
+	require.EqualError(t, err, `method uppercase: failed to parse value as JSON: invalid character 'N' looking for beginning of value`)
+
+	_, err = chain.Exec(FunctionContext{}.WithValue(map[string]any{"foo": 5}))
+	require.EqualError(t, err, "expected string value, got number from field `this.foo` (5)")
+}
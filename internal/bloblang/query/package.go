@@ -52,6 +52,9 @@ type FunctionContext struct {
 	NewMeta  MetaMsg
 	NewValue *any
 
+	// Optionally records the steps taken during execution.
+	Tracer *Tracer
+
 	valueFn    func() *any
 	value      *any
 	nextValue  *any
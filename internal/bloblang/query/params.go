@@ -434,6 +434,22 @@ func (p *ParsedParams) dynamic() []Function {
 	return fns
 }
 
+// static returns true if none of the arguments are functions that depend on
+// the context of an execution.
+func (p *ParsedParams) static() bool {
+	if p == nil {
+		return true
+	}
+	for _, v := range p.values {
+		if fn, isFn := v.(Function); isFn {
+			if _, isLit := fn.(*Literal); !isLit {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ResolveDynamic attempts to execute all dynamic arguments with a given context
 // and populate a new parsed parameters set with the values, ready to be used in
 // a function or method.
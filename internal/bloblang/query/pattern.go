@@ -0,0 +1,195 @@
+package query
+
+// Pattern is a structural pattern that values can be matched against within a
+// match expression, where parts of a matched value can be captured under names.
+type Pattern interface {
+	// Match returns true if a value matches the pattern, in which case any
+	// captured values are written to the provided map.
+	Match(v any, captures map[string]any) bool
+
+	// Captures returns the names of all values captured by the pattern.
+	Captures() []string
+}
+
+//------------------------------------------------------------------------------
+
+type literalPattern struct {
+	value any
+}
+
+// NewLiteralPattern creates a pattern that matches values equal to a literal.
+func NewLiteralPattern(value any) Pattern {
+	return literalPattern{value: value}
+}
+
+func (l literalPattern) Match(v any, _ map[string]any) bool {
+	return ICompare(v, l.value)
+}
+
+func (l literalPattern) Captures() []string {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type wildcardPattern struct{}
+
+// NewWildcardPattern creates a pattern that matches any value.
+func NewWildcardPattern() Pattern {
+	return wildcardPattern{}
+}
+
+func (wildcardPattern) Match(any, map[string]any) bool {
+	return true
+}
+
+func (wildcardPattern) Captures() []string {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type capturePattern struct {
+	name string
+}
+
+// NewCapturePattern creates a pattern that matches any value and captures it
+// under a name.
+func NewCapturePattern(name string) Pattern {
+	return capturePattern{name: name}
+}
+
+func (c capturePattern) Match(v any, captures map[string]any) bool {
+	captures[c.name] = v
+	return true
+}
+
+func (c capturePattern) Captures() []string {
+	return []string{c.name}
+}
+
+//------------------------------------------------------------------------------
+
+type typePattern struct {
+	valueType ValueType
+	inner     Pattern
+}
+
+// NewTypePattern creates a pattern that matches values of a given type that
+// also match an inner pattern.
+func NewTypePattern(t ValueType, inner Pattern) Pattern {
+	return typePattern{valueType: t, inner: inner}
+}
+
+func (t typePattern) Match(v any, captures map[string]any) bool {
+	return ITypeOf(v) == t.valueType && t.inner.Match(v, captures)
+}
+
+func (t typePattern) Captures() []string {
+	return t.inner.Captures()
+}
+
+//------------------------------------------------------------------------------
+
+// ObjectPatternField is a key of an object pattern and the pattern that its
+// value must match.
+type ObjectPatternField struct {
+	Key     string
+	Pattern Pattern
+}
+
+type objectPattern struct {
+	fields []ObjectPatternField
+	rest   Pattern
+}
+
+// NewObjectPattern creates a pattern that matches objects containing each of
+// the provided fields, where the value of each field matches its pattern.
+// Objects may contain other fields, and when a rest pattern is provided it is
+// matched against an object of the remaining fields.
+func NewObjectPattern(fields []ObjectPatternField, rest Pattern) Pattern {
+	return objectPattern{fields: fields, rest: rest}
+}
+
+func (o objectPattern) Match(v any, captures map[string]any) bool {
+	obj, isObj := v.(map[string]any)
+	if !isObj {
+		return false
+	}
+	for _, f := range o.fields {
+		fv, exists := obj[f.Key]
+		if !exists || !f.Pattern.Match(fv, captures) {
+			return false
+		}
+	}
+	if o.rest == nil {
+		return true
+	}
+	rest := make(map[string]any, len(obj))
+	for k, v := range obj {
+		rest[k] = v
+	}
+	for _, f := range o.fields {
+		delete(rest, f.Key)
+	}
+	return o.rest.Match(rest, captures)
+}
+
+func (o objectPattern) Captures() []string {
+	var names []string
+	for _, f := range o.fields {
+		names = append(names, f.Pattern.Captures()...)
+	}
+	if o.rest != nil {
+		names = append(names, o.rest.Captures()...)
+	}
+	return names
+}
+
+//------------------------------------------------------------------------------
+
+type arrayPattern struct {
+	elements []Pattern
+	rest     Pattern
+}
+
+// NewArrayPattern creates a pattern that matches arrays where each element
+// matches the pattern at the same index. When a rest pattern is provided the
+// array may contain more elements than patterns, and the rest pattern is
+// matched against an array of those remaining elements. Otherwise, the array
+// must be of the same length as the list of patterns.
+func NewArrayPattern(elements []Pattern, rest Pattern) Pattern {
+	return arrayPattern{elements: elements, rest: rest}
+}
+
+func (a arrayPattern) Match(v any, captures map[string]any) bool {
+	arr, isArr := v.([]any)
+	if !isArr {
+		return false
+	}
+	if len(arr) < len(a.elements) || (a.rest == nil && len(arr) != len(a.elements)) {
+		return false
+	}
+	for i, e := range a.elements {
+		if !e.Match(arr[i], captures) {
+			return false
+		}
+	}
+	if a.rest == nil {
+		return true
+	}
+	rest := make([]any, len(arr)-len(a.elements))
+	copy(rest, arr[len(a.elements):])
+	return a.rest.Match(rest, captures)
+}
+
+func (a arrayPattern) Captures() []string {
+	var names []string
+	for _, e := range a.elements {
+		names = append(names, e.Captures()...)
+	}
+	if a.rest != nil {
+		names = append(names, a.rest.Captures()...)
+	}
+	return names
+}
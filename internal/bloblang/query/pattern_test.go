@@ -0,0 +1,87 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternMatch(t *testing.T) {
+	tests := map[string]struct {
+		pattern  Pattern
+		value    any
+		matched  bool
+		captures map[string]any
+	}{
+		"literal match": {
+			pattern: NewLiteralPattern(int64(5)),
+			value:   5.0,
+			matched: true,
+		},
+		"literal mismatch": {
+			pattern: NewLiteralPattern("foo"),
+			value:   "bar",
+		},
+		"type capture": {
+			pattern:  NewTypePattern(ValueString, NewCapturePattern("s")),
+			value:    "foo",
+			matched:  true,
+			captures: map[string]any{"s": "foo"},
+		},
+		"type mismatch": {
+			pattern: NewTypePattern(ValueNumber, NewCapturePattern("n")),
+			value:   "foo",
+		},
+		"object subset": {
+			pattern: NewObjectPattern([]ObjectPatternField{
+				{Key: "type", Pattern: NewLiteralPattern("order")},
+				{Key: "items", Pattern: NewCapturePattern("items")},
+			}, nil),
+			value:    map[string]any{"type": "order", "items": []any{"a"}, "id": "foo"},
+			matched:  true,
+			captures: map[string]any{"items": []any{"a"}},
+		},
+		"object missing key": {
+			pattern: NewObjectPattern([]ObjectPatternField{
+				{Key: "items", Pattern: NewWildcardPattern()},
+			}, nil),
+			value: map[string]any{"type": "order"},
+		},
+		"object rest": {
+			pattern: NewObjectPattern([]ObjectPatternField{
+				{Key: "id", Pattern: NewWildcardPattern()},
+			}, NewCapturePattern("rest")),
+			value:    map[string]any{"id": "foo", "a": "b"},
+			matched:  true,
+			captures: map[string]any{"rest": map[string]any{"a": "b"}},
+		},
+		"array exact length": {
+			pattern: NewArrayPattern([]Pattern{NewCapturePattern("first")}, nil),
+			value:   []any{"a", "b"},
+		},
+		"array rest": {
+			pattern:  NewArrayPattern([]Pattern{NewCapturePattern("first")}, NewCapturePattern("rest")),
+			value:    []any{"a", "b", "c"},
+			matched:  true,
+			captures: map[string]any{"first": "a", "rest": []any{"b", "c"}},
+		},
+		"array too short": {
+			pattern: NewArrayPattern([]Pattern{NewWildcardPattern(), NewWildcardPattern()}, NewWildcardPattern()),
+			value:   []any{"a"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			captures := map[string]any{}
+			assert.Equal(t, test.matched, test.pattern.Match(test.value, captures))
+			if test.matched {
+				if test.captures == nil {
+					test.captures = map[string]any{}
+				}
+				assert.Equal(t, test.captures, captures)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package query
+
+// TraceEventKind describes the type of step that a trace event represents.
+type TraceEventKind string
+
+// TraceEventKinds.
+const (
+	TraceAssignment TraceEventKind = "assignment"
+	TraceMethod     TraceEventKind = "method"
+	TraceMatchCase  TraceEventKind = "match_case"
+	TraceIfBranch   TraceEventKind = "if_branch"
+)
+
+// TraceEvent describes an individual step taken during the execution of a
+// mapping, along with the value it resulted in.
+type TraceEvent struct {
+	Kind TraceEventKind
+
+	// Description is a human readable summary of the step, such as the target
+	// of an assignment, the name of a method or the pattern of a match case.
+	Description string
+
+	// Input is a tailing clip of the mapping that the step was parsed from,
+	// which can be used in order to determine its line and column.
+	Input []rune
+
+	// Value is a snapshot of the value that the step resulted in, and Err is
+	// any error that the step returned instead.
+	Value any
+	Err   error
+}
+
+// Tracer records the steps taken during the execution of a mapping. A tracer
+// is not safe for concurrent use and should therefore only be shared by the
+// executions of a single mapping invocation.
+type Tracer struct {
+	events []TraceEvent
+}
+
+// NewTracer creates an empty tracer.
+func NewTracer() *Tracer {
+	return &Tracer{}
+}
+
+// Record a trace event. The value of the event is cloned so that later
+// mutations of the mapped document are not reflected in the trace.
+func (t *Tracer) Record(e TraceEvent) {
+	e.Value = IClone(e.Value)
+	t.events = append(t.events, e)
+}
+
+// Events returns the trace events recorded so far in the order that they
+// occurred.
+func (t *Tracer) Events() []TraceEvent {
+	return t.events
+}
+
+//------------------------------------------------------------------------------
+
+// NewTracedFunction wraps a function such that each execution with a tracer
+// present within the function context records a trace event containing its
+// result.
+func NewTracedFunction(kind TraceEventKind, description string, input []rune, fn Function) Function {
+	return &tracedFunction{
+		kind:        kind,
+		description: description,
+		input:       input,
+		fn:          fn,
+	}
+}
+
+type tracedFunction struct {
+	kind        TraceEventKind
+	description string
+	input       []rune
+	fn          Function
+}
+
+func (t *tracedFunction) Annotation() string {
+	return t.fn.Annotation()
+}
+
+func (t *tracedFunction) Exec(ctx FunctionContext) (any, error) {
+	v, err := t.fn.Exec(ctx)
+	if ctx.Tracer != nil {
+		ctx.Tracer.Record(TraceEvent{
+			Kind:        t.kind,
+			Description: t.description,
+			Input:       t.input,
+			Value:       v,
+			Err:         err,
+		})
+	}
+	return v, err
+}
+
+func (t *tracedFunction) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
+	return t.fn.QueryTargets(ctx)
+}
@@ -33,6 +33,7 @@ var (
 	ValueString    ValueType = "string"
 	ValueBytes     ValueType = "bytes"
 	ValueNumber    ValueType = "number"
+	ValueDecimal   ValueType = "decimal"
 	ValueBool      ValueType = "bool"
 	ValueTimestamp ValueType = "timestamp"
 	ValueArray     ValueType = "array"
@@ -58,6 +59,8 @@ func ITypeOf(i any) ValueType {
 		return ValueBytes
 	case int, int64, uint64, float64, json.Number:
 		return ValueNumber
+	case Decimal:
+		return ValueDecimal
 	case bool:
 		return ValueBool
 	case time.Time:
@@ -103,6 +106,8 @@ func IGetNumber(v any) (float64, error) {
 		return t, nil
 	case json.Number:
 		return t.Float64()
+	case Decimal:
+		return t.InexactFloat64(), nil
 	}
 	return 0, NewTypeError(v, ValueNumber)
 }
@@ -124,6 +129,8 @@ func IGetFloat32(v any) (float32, error) {
 	case json.Number:
 		v, e := t.Float64()
 		return float32(v), e
+	case Decimal:
+		return float32(t.InexactFloat64()), nil
 	}
 	return 0, NewTypeError(v, ValueNumber)
 }
@@ -149,6 +156,8 @@ func IGetInt(v any) (int64, error) {
 			return int64(f), nil
 		}
 		return 0, err
+	case Decimal:
+		return t.IntPart(), nil
 	}
 	return 0, NewTypeError(v, ValueNumber)
 }
@@ -168,6 +177,8 @@ func IGetBool(v any) (bool, error) {
 		return t != 0, nil
 	case json.Number:
 		return t.String() != "0", nil
+	case Decimal:
+		return !t.IsZero(), nil
 	}
 	return false, NewTypeError(v, ValueBool)
 }
@@ -226,6 +237,9 @@ func IGetTimestamp(v any) (time.Time, error) {
 		} else {
 			return time.Time{}, fmt.Errorf("failed to parse value '%v' as number", v)
 		}
+	case Decimal:
+		secs := t.Truncate(0)
+		return time.Unix(secs.IntPart(), t.Sub(secs).Shift(9).IntPart()), nil
 	case []byte:
 		return time.Parse(time.RFC3339Nano, string(t))
 	case string:
@@ -265,11 +279,11 @@ func restrictForComparison(v any) any {
 }
 
 // ISanitize takes a boxed value of any type and attempts to convert it into one
-// of the following types: string, []byte, int64, uint64, float64, bool,
-// []interface{}, map[string]interface{}, Delete, Nothing.
+// of the following types: string, []byte, int64, uint64, float64, Decimal,
+// bool, []interface{}, map[string]interface{}, Delete, Nothing.
 func ISanitize(i any) any {
 	switch t := i.(type) {
-	case string, []byte, int64, uint64, float64, bool, []any, map[string]any, Delete, Nothing:
+	case string, []byte, int64, uint64, float64, Decimal, bool, []any, map[string]any, Delete, Nothing:
 		return i
 	case json.RawMessage:
 		return []byte(t)
@@ -308,6 +322,8 @@ func IToBytes(i any) []byte {
 		return t
 	case json.Number:
 		return []byte(t.String())
+	case Decimal:
+		return []byte(t.String())
 	case int64:
 		return strconv.AppendInt(nil, t, 10)
 	case uint64:
@@ -344,6 +360,8 @@ func IToString(i any) string {
 		return strconv.FormatFloat(t, 'g', -1, 64)
 	case json.Number:
 		return t.String()
+	case Decimal:
+		return t.String()
 	case bool:
 		if t {
 			return "true"
@@ -372,6 +390,8 @@ func IToNumber(v any) (float64, error) {
 		return t, nil
 	case json.Number:
 		return t.Float64()
+	case Decimal:
+		return t.InexactFloat64(), nil
 	case []byte:
 		return strconv.ParseFloat(string(t), 64)
 	case string:
@@ -421,6 +441,8 @@ func IToInt(v any) (int64, error) {
 		return int64(t), nil
 	case json.Number:
 		return t.Int64()
+	case Decimal:
+		return decimalToInt(t)
 	case []byte:
 		return strconv.ParseInt(string(t), 0, 64)
 	case string:
@@ -487,6 +509,18 @@ func IToUint(v any) (uint64, error) {
 			return 0, errors.New("signed integer value is negative and cannot be cast as an unsigned integer")
 		}
 		return uint64(i), nil
+	case Decimal:
+		if t.IsNegative() {
+			return 0, errors.New("decimal value is negative and cannot be cast as an unsigned integer")
+		}
+		if !t.IsInteger() {
+			return 0, errors.New("decimal value contains decimals and therefore cannot be cast as an unsigned integer, if you intend to round the value then call `.round_decimal()` explicitly before this cast")
+		}
+		bi := t.BigInt()
+		if !bi.IsUint64() {
+			return 0, errors.New("decimal value is too large to be cast as an unsigned integer")
+		}
+		return bi.Uint64(), nil
 	case []byte:
 		return strconv.ParseUint(string(t), 0, 64)
 	case string:
@@ -524,6 +558,8 @@ func IToBool(v any) (bool, error) {
 		return t != 0, nil
 	case json.Number:
 		return t.String() != "0", nil
+	case Decimal:
+		return !t.IsZero(), nil
 	case []byte:
 		if v, err := strconv.ParseBool(string(t)); err == nil {
 			return v, nil
@@ -566,6 +602,9 @@ func ICompare(left, right any) bool {
 	if left == nil && right == nil {
 		return true
 	}
+	if lhs, rhs, ok := decimalPair(left, right); ok {
+		return lhs.Equal(rhs.Decimal)
+	}
 	switch lhs := restrictForComparison(left).(type) {
 	case string:
 		rhs, err := IGetString(right)
@@ -0,0 +1,72 @@
+package tap
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/component/output/processors"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+)
+
+// streamIdentifier is implemented by managers that are scoped to a stream.
+type streamIdentifier interface {
+	StreamID() string
+}
+
+func pointIdentity(nm bundle.NewManagement) (stream, label string) {
+	if s, ok := nm.(streamIdentifier); ok {
+		stream = s.StreamID()
+	}
+	label = nm.Label()
+	if label == "" {
+		label = "root." + query.SliceToDotPath(nm.Path()...)
+	}
+	return
+}
+
+// TappedBundle modifies a provided bundle environment so that inputs,
+// processors and outputs are wrapped by components that emit the messages they
+// observe to any taps attached to them via the provided registry.
+func TappedBundle(b *bundle.Environment, r *Registry) *bundle.Environment {
+	tappedEnv := b.Clone()
+
+	for _, spec := range b.InputDocs() {
+		_ = tappedEnv.InputAdd(func(conf input.Config, nm bundle.NewManagement) (input.Streamed, error) {
+			i, err := b.InputInit(conf, nm)
+			if err != nil {
+				return nil, err
+			}
+			p, deregister := r.register(pointIdentity(nm))
+			return tapInput(p, deregister, i), nil
+		}, spec)
+	}
+
+	for _, spec := range b.ProcessorDocs() {
+		_ = tappedEnv.ProcessorAdd(func(conf processor.Config, nm bundle.NewManagement) (processor.V1, error) {
+			i, err := b.ProcessorInit(conf, nm)
+			if err != nil {
+				return nil, err
+			}
+			p, deregister := r.register(pointIdentity(nm))
+			return tapProcessor(p, deregister, i), nil
+		}, spec)
+	}
+
+	for _, spec := range b.OutputDocs() {
+		_ = tappedEnv.OutputAdd(func(conf output.Config, nm bundle.NewManagement, pcf ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+			pcf = processors.AppendFromConfig(conf, nm, pcf...)
+			conf.Processors = nil
+
+			o, err := b.OutputInit(conf, nm)
+			if err != nil {
+				return nil, err
+			}
+
+			p, deregister := r.register(pointIdentity(nm))
+			return output.WrapWithPipelines(tapOutput(p, deregister, o), pcf...)
+		}, spec)
+	}
+
+	return tappedEnv
+}
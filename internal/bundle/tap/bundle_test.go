@@ -0,0 +1,190 @@
+package tap_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/bundle/tap"
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/message"
+
+	_ "github.com/benthosdev/benthos/v4/public/components/pure"
+)
+
+func TestBundleInputTap(t *testing.T) {
+	reg := tap.NewRegistry()
+	tenv := tap.TappedBundle(bundle.GlobalEnvironment, reg)
+
+	opts := tap.NewOptions()
+	opts.RateLimit = 0
+	fooTap, err := reg.Attach("", "foo", opts)
+	require.NoError(t, err)
+	defer fooTap.Close()
+
+	inConfig := input.NewConfig()
+	inConfig.Label = "foo"
+	inConfig.Type = "generate"
+	inConfig.Generate.Count = 10
+	inConfig.Generate.Interval = "1us"
+	inConfig.Generate.Mapping = `root.id = uuid_v4()`
+
+	mgr, err := manager.New(
+		manager.NewResourceConfig(),
+		manager.OptSetEnvironment(tenv),
+	)
+	require.NoError(t, err)
+
+	in, err := mgr.NewInput(inConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, reg.Labels(""))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second)
+	defer done()
+	for i := 0; i < 10; i++ {
+		select {
+		case tran := <-in.TransactionChan():
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	in.TriggerStopConsuming()
+	require.NoError(t, in.WaitForClose(ctx))
+	assert.Empty(t, reg.Labels(""))
+
+	for i := 0; i < 10; i++ {
+		select {
+		case e := <-fooTap.Events():
+			assert.Equal(t, "foo", e.Label)
+			assert.Equal(t, tap.ComponentInput, e.Component)
+			assert.Contains(t, e.Content, `{"id":"`)
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestBundleProcessorTapFilterAndRate(t *testing.T) {
+	reg := tap.NewRegistry()
+	tenv := tap.TappedBundle(bundle.GlobalEnvironment, reg)
+
+	opts, _, err := tap.OptionsFromRequest(httptest.NewRequest("GET", "/tap/foo?rate=3&filter=this.n%20%25%202%20%3D%3D%200", nil), bloblang.GlobalEnvironment())
+	require.NoError(t, err)
+
+	fooTap, err := reg.Attach("", "foo", opts)
+	require.NoError(t, err)
+	defer fooTap.Close()
+
+	procConfig := processor.NewConfig()
+	procConfig.Label = "foo"
+	procConfig.Type = "bloblang"
+	procConfig.Bloblang = `root = this
+root.bar = if this.n == 4 { throw("nope") }`
+
+	mgr, err := manager.New(
+		manager.NewResourceConfig(),
+		manager.OptSetEnvironment(tenv),
+	)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(procConfig)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+			[]byte(fmt.Sprintf(`{"n":%v}`, i)),
+		}))
+		require.NoError(t, err)
+	}
+
+	var events []tap.Event
+	for len(fooTap.Events()) > 0 {
+		events = append(events, <-fooTap.Events())
+	}
+	require.Len(t, events, 3)
+	assert.Equal(t, `{"n":0}`, events[0].Content)
+	assert.Equal(t, `{"n":2}`, events[1].Content)
+	assert.Equal(t, `{"n":4}`, events[2].Content)
+	assert.Contains(t, events[2].Error, "nope")
+	assert.Equal(t, uint64(5), fooTap.Dropped())
+
+	require.NoError(t, proc.Close(context.Background()))
+}
+
+func TestRegistryServe(t *testing.T) {
+	reg := tap.NewRegistry()
+	tenv := tap.TappedBundle(bundle.GlobalEnvironment, reg)
+
+	procConfig := processor.NewConfig()
+	procConfig.Label = "foo"
+	procConfig.Type = "bloblang"
+	procConfig.Bloblang = `root = content().uppercase()
+meta bar = "baz"`
+
+	mgr, err := manager.New(
+		manager.NewResourceConfig(),
+		manager.OptSetEnvironment(tenv),
+	)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(procConfig)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reg.Serve(w, r, "", "foo")
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "?count=2")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			_, _ = proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+				[]byte(fmt.Sprintf("hello world %v", i)),
+			}))
+		}
+	}()
+
+	scanner := bufio.NewScanner(res.Body)
+	var contents []string
+	for scanner.Scan() {
+		var e tap.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		assert.Equal(t, map[string]any{"bar": "baz"}, e.Meta)
+		contents = append(contents, e.Content)
+	}
+	assert.Equal(t, []string{"HELLO WORLD 0", "HELLO WORLD 1"}, contents)
+}
+
+func TestRegistryServeBloblEnvironment(t *testing.T) {
+	reg := tap.NewRegistry()
+	reg.SetBloblEnvironment(bloblang.GlobalEnvironment().WithoutFunctions("content"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reg.Serve(w, r, "", "foo")
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "?count=1&filter=" + url.QueryEscape(`content() == "foo"`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,133 @@
+package tap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+)
+
+// EndpointDescription describes the parameters supported by tap endpoints.
+const EndpointDescription = "Attach a live tap to a component by its label and stream the messages it observes as JSON events, either over a WebSocket connection or as newline delimited JSON over a regular HTTP connection." +
+	" The query parameters `sample` (between 0 and 1), `rate` (maximum events per second), `filter` (a Bloblang query) and `count` (the number of events after which the tap closes) are supported."
+
+// OptionsFromRequest parses tap options from the query parameters of a
+// request, returning them along with the maximum number of events to emit, which
+// is zero for no limit. Filters are parsed with the provided bloblang
+// environment.
+func OptionsFromRequest(r *http.Request, env *bloblang.Environment) (opts Options, count int, err error) {
+	opts = NewOptions()
+	q := r.URL.Query()
+	if v := q.Get("sample"); v != "" {
+		if opts.SampleRate, err = strconv.ParseFloat(v, 64); err != nil {
+			return opts, 0, fmt.Errorf("failed to parse sample: %w", err)
+		}
+	}
+	if v := q.Get("rate"); v != "" {
+		if opts.RateLimit, err = strconv.Atoi(v); err != nil {
+			return opts, 0, fmt.Errorf("failed to parse rate: %w", err)
+		}
+	}
+	if v := q.Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil {
+			return opts, 0, fmt.Errorf("failed to parse count: %w", err)
+		}
+	}
+	if v := q.Get("filter"); v != "" {
+		if opts.Filter, err = env.NewMapping(v); err != nil {
+			return opts, 0, fmt.Errorf("failed to parse filter: %w", err)
+		}
+	}
+	return
+}
+
+// The default origin check of the upgrader is kept so that WebSocket
+// connections from other sites are rejected.
+var upgrader = websocket.Upgrader{}
+
+// Serve attaches a tap to the component of a stream identified by a label and
+// writes events observed by the tap to the response until either the client
+// disconnects or the requested count of events is reached.
+func (r *Registry) Serve(w http.ResponseWriter, req *http.Request, stream, label string) {
+	opts, count, err := OptionsFromRequest(req, r.bloblEnvironment())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	t, err := r.Attach(stream, label, opts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer t.Close()
+
+	if websocket.IsWebSocketUpgrade(req) {
+		serveWebSocket(w, req, t, count)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	enc := json.NewEncoder(w)
+	for sent := 0; count <= 0 || sent < count; sent++ {
+		select {
+		case e, open := <-t.Events():
+			if !open {
+				return
+			}
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+func serveWebSocket(w http.ResponseWriter, req *http.Request, t *Tap, count int) {
+	ws, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	// Consume (and ignore) incoming frames in order to detect the client
+	// closing the connection.
+	clientClosed := make(chan struct{})
+	go func() {
+		defer close(clientClosed)
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for sent := 0; count <= 0 || sent < count; sent++ {
+		select {
+		case e, open := <-t.Events():
+			if !open {
+				return
+			}
+			if err := ws.WriteJSON(e); err != nil {
+				return
+			}
+		case <-clientClosed:
+			return
+		}
+	}
+	_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
@@ -0,0 +1,71 @@
+package tap
+
+import (
+	"context"
+
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+type tappedInput struct {
+	p          *point
+	deregister func()
+	wrapped    input.Streamed
+	tChan      chan message.Transaction
+	shutSig    *shutdown.Signaller
+}
+
+func tapInput(p *point, deregister func(), i input.Streamed) input.Streamed {
+	t := &tappedInput{
+		p:          p,
+		deregister: deregister,
+		wrapped:    i,
+		tChan:      make(chan message.Transaction),
+		shutSig:    shutdown.NewSignaller(),
+	}
+	go t.loop()
+	return t
+}
+
+func (t *tappedInput) loop() {
+	defer close(t.tChan)
+	readChan := t.wrapped.TransactionChan()
+	for {
+		tran, open := <-readChan
+		if !open {
+			return
+		}
+		t.p.observe(ComponentInput, tran.Payload)
+		select {
+		case t.tChan <- tran:
+		case <-t.shutSig.CloseNowChan():
+			// Stop flushing if we fully timed out
+			return
+		}
+	}
+}
+
+func (t *tappedInput) TransactionChan() <-chan message.Transaction {
+	return t.tChan
+}
+
+func (t *tappedInput) Connected() bool {
+	return t.wrapped.Connected()
+}
+
+func (t *tappedInput) TriggerStopConsuming() {
+	t.wrapped.TriggerStopConsuming()
+}
+
+func (t *tappedInput) TriggerCloseNow() {
+	t.wrapped.TriggerCloseNow()
+	t.shutSig.CloseNow()
+}
+
+func (t *tappedInput) WaitForClose(ctx context.Context) error {
+	err := t.wrapped.WaitForClose(ctx)
+	t.shutSig.CloseNow()
+	t.deregister()
+	return err
+}
@@ -0,0 +1,64 @@
+package tap
+
+import (
+	"context"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+type tappedOutput struct {
+	p          *point
+	deregister func()
+	wrapped    output.Streamed
+	tChan      chan message.Transaction
+	shutSig    *shutdown.Signaller
+}
+
+func tapOutput(p *point, deregister func(), o output.Streamed) output.Streamed {
+	return &tappedOutput{
+		p:          p,
+		deregister: deregister,
+		wrapped:    o,
+		tChan:      make(chan message.Transaction),
+		shutSig:    shutdown.NewSignaller(),
+	}
+}
+
+func (t *tappedOutput) loop(inChan <-chan message.Transaction) {
+	defer close(t.tChan)
+	for {
+		tran, open := <-inChan
+		if !open {
+			return
+		}
+		t.p.observe(ComponentOutput, tran.Payload)
+		select {
+		case t.tChan <- tran:
+		case <-t.shutSig.CloseNowChan():
+			// Stop flushing if we fully timed out
+			return
+		}
+	}
+}
+
+func (t *tappedOutput) Consume(inChan <-chan message.Transaction) error {
+	go t.loop(inChan)
+	return t.wrapped.Consume(t.tChan)
+}
+
+func (t *tappedOutput) Connected() bool {
+	return t.wrapped.Connected()
+}
+
+func (t *tappedOutput) TriggerCloseNow() {
+	t.wrapped.TriggerCloseNow()
+}
+
+func (t *tappedOutput) WaitForClose(ctx context.Context) error {
+	err := t.wrapped.WaitForClose(ctx)
+	t.shutSig.CloseNow()
+	t.deregister()
+	return err
+}
@@ -0,0 +1,35 @@
+package tap
+
+import (
+	"context"
+
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type tappedProcessor struct {
+	p          *point
+	deregister func()
+	wrapped    iprocessor.V1
+}
+
+func tapProcessor(p *point, deregister func(), proc iprocessor.V1) iprocessor.V1 {
+	return &tappedProcessor{
+		p:          p,
+		deregister: deregister,
+		wrapped:    proc,
+	}
+}
+
+func (t *tappedProcessor) ProcessBatch(ctx context.Context, m message.Batch) ([]message.Batch, error) {
+	outMsgs, res := t.wrapped.ProcessBatch(ctx, m)
+	for _, outMsg := range outMsgs {
+		t.p.observe(ComponentProcessor, outMsg)
+	}
+	return outMsgs, res
+}
+
+func (t *tappedProcessor) Close(ctx context.Context) error {
+	t.deregister()
+	return t.wrapped.Close(ctx)
+}
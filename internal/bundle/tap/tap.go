@@ -0,0 +1,366 @@
+// Package tap provides a mechanism for attaching live taps to the components of
+// running streams, which emit a sample of the messages flowing through them.
+package tap
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// ComponentType describes the type of component that a tap event originated
+// from.
+type ComponentType string
+
+// Various component types.
+var (
+	ComponentInput     ComponentType = "input"
+	ComponentProcessor ComponentType = "processor"
+	ComponentOutput    ComponentType = "output"
+)
+
+// Event represents a single message observed by a tap.
+type Event struct {
+	Stream    string         `json:"stream,omitempty"`
+	Label     string         `json:"label"`
+	Component ComponentType  `json:"component"`
+	Time      time.Time      `json:"time"`
+	Content   string         `json:"content"`
+	Meta      map[string]any `json:"metadata,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// Options describe which messages observed by a tap are emitted.
+type Options struct {
+	// An optional Bloblang query that must resolve to true for a message to be
+	// emitted.
+	Filter *mapping.Executor
+
+	// The proportion of messages, between 0 and 1, to sample. Messages are
+	// sampled before the filter is applied.
+	SampleRate float64
+
+	// The maximum number of events to emit per second, or zero for no limit.
+	RateLimit int
+
+	// The number of events that can be buffered before events are dropped.
+	BufferSize int
+}
+
+// NewOptions returns tap options with default values.
+func NewOptions() Options {
+	return Options{
+		SampleRate: 1,
+		RateLimit:  10,
+		BufferSize: 100,
+	}
+}
+
+// ErrInvalidSampleRate is returned when attempting to attach a tap with a
+// sample rate that is out of bounds.
+var ErrInvalidSampleRate = errors.New("sample rate must be greater than 0 and at most 1")
+
+//------------------------------------------------------------------------------
+
+// Tap is an attachment to a component that emits events for the messages it
+// observes. Emitting events never blocks the component, and events that cannot
+// be emitted due to the rate limit or a full buffer are dropped.
+type Tap struct {
+	opts    Options
+	r       *Registry
+	p       *point
+	events  chan Event
+	dropped uint64
+
+	mut         sync.Mutex
+	closed      bool
+	windowStart time.Time
+	windowCount int
+}
+
+// Events returns a channel of events observed by the tap, which is closed once
+// the tap is closed.
+func (t *Tap) Events() <-chan Event {
+	return t.events
+}
+
+// Dropped returns the number of events that were dropped due to either the
+// rate limit or a full buffer. Messages are checked against the rate limit
+// before the filter, and therefore this includes messages that may not have
+// matched the filter.
+func (t *Tap) Dropped() uint64 {
+	return atomic.LoadUint64(&t.dropped)
+}
+
+// Close detaches the tap from its component.
+func (t *Tap) Close() {
+	t.r.detach(t)
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if !t.closed {
+		t.closed = true
+		close(t.events)
+	}
+}
+
+// rateLimited returns whether the rate limit of the current window has already
+// been reached.
+func (t *Tap) rateLimited(now time.Time) bool {
+	if t.opts.RateLimit <= 0 {
+		return false
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return now.Sub(t.windowStart) < time.Second && t.windowCount >= t.opts.RateLimit
+}
+
+func (t *Tap) observe(c ComponentType, index int, batch message.Batch) {
+	part := batch.Get(index)
+	if t.opts.SampleRate < 1 && rand.Float64() >= t.opts.SampleRate {
+		return
+	}
+	// Messages beyond the rate limit are dropped before the filter is executed
+	// as they would be dropped regardless of the result.
+	if t.rateLimited(time.Now()) {
+		atomic.AddUint64(&t.dropped, 1)
+		return
+	}
+	if t.opts.Filter != nil {
+		if ok, err := t.opts.Filter.QueryPart(index, batch); err != nil || !ok {
+			return
+		}
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if t.closed {
+		return
+	}
+
+	now := time.Now()
+	if t.opts.RateLimit > 0 {
+		if now.Sub(t.windowStart) >= time.Second {
+			t.windowStart, t.windowCount = now, 0
+		}
+		if t.windowCount >= t.opts.RateLimit {
+			atomic.AddUint64(&t.dropped, 1)
+			return
+		}
+		t.windowCount++
+	}
+
+	e := Event{
+		Stream:    t.p.stream,
+		Label:     t.p.label,
+		Component: c,
+		Time:      now,
+		Content:   string(part.AsBytes()),
+	}
+	_ = part.MetaIterMut(func(k string, v any) error {
+		if e.Meta == nil {
+			e.Meta = map[string]any{}
+		}
+		e.Meta[k] = message.CopyJSON(v)
+		return nil
+	})
+	if err := part.ErrorGet(); err != nil {
+		e.Error = err.Error()
+	}
+
+	select {
+	case t.events <- e:
+	default:
+		atomic.AddUint64(&t.dropped, 1)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// point is a tappable component identified by a stream and label.
+type point struct {
+	stream string
+	label  string
+	active int32
+
+	mut  sync.RWMutex
+	taps []*Tap
+}
+
+func (p *point) add(t *Tap) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	p.taps = append(p.taps, t)
+	atomic.StoreInt32(&p.active, int32(len(p.taps)))
+}
+
+// remove a tap from the point, returning the number of taps that remain.
+func (p *point) remove(t *Tap) int {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	for i, e := range p.taps {
+		if e == t {
+			p.taps = append(p.taps[:i:i], p.taps[i+1:]...)
+			break
+		}
+	}
+	atomic.StoreInt32(&p.active, int32(len(p.taps)))
+	return len(p.taps)
+}
+
+// observe a batch of messages, this is a noop unless taps are attached.
+func (p *point) observe(c ComponentType, batch message.Batch) {
+	if atomic.LoadInt32(&p.active) == 0 {
+		return
+	}
+
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+
+	for _, t := range p.taps {
+		for i := 0; i < batch.Len(); i++ {
+			t.observe(c, i, batch)
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type pointKey struct {
+	stream string
+	label  string
+}
+
+// Registry tracks the tappable components of streams and the taps attached to
+// them. Taps can be attached to components that do not yet exist, in which case
+// events are emitted once a component with a matching label is created. Points
+// are removed once they have neither running components nor attached taps,
+// which happens when a stream is removed and its taps are closed.
+type Registry struct {
+	mut      sync.Mutex
+	points   map[pointKey]*point
+	known    map[pointKey]int
+	bloblEnv *bloblang.Environment
+}
+
+// NewRegistry creates an empty tap registry, where tap filters are parsed with
+// the global bloblang environment until SetBloblEnvironment is called.
+func NewRegistry() *Registry {
+	return &Registry{
+		points:   map[pointKey]*point{},
+		known:    map[pointKey]int{},
+		bloblEnv: bloblang.GlobalEnvironment(),
+	}
+}
+
+// SetBloblEnvironment sets the bloblang environment used for parsing the
+// filters of taps attached via HTTP, which should be the environment of the
+// manager that the tapped components belong to.
+func (r *Registry) SetBloblEnvironment(env *bloblang.Environment) {
+	r.mut.Lock()
+	r.bloblEnv = env
+	r.mut.Unlock()
+}
+
+func (r *Registry) bloblEnvironment() *bloblang.Environment {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.bloblEnv
+}
+
+func (r *Registry) point(stream, label string) *point {
+	k := pointKey{stream: stream, label: label}
+	p, exists := r.points[k]
+	if !exists {
+		p = &point{stream: stream, label: label}
+		r.points[k] = p
+	}
+	return p
+}
+
+// register a component and return its point, along with a func to be called
+// once the component is closed.
+func (r *Registry) register(stream, label string) (p *point, deregister func()) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	k := pointKey{stream: stream, label: label}
+	r.known[k]++
+	var once sync.Once
+	return r.point(stream, label), func() {
+		once.Do(func() {
+			r.mut.Lock()
+			defer r.mut.Unlock()
+			if r.known[k]--; r.known[k] <= 0 {
+				delete(r.known, k)
+				if p := r.points[k]; p != nil && atomic.LoadInt32(&p.active) == 0 {
+					delete(r.points, k)
+				}
+			}
+		})
+	}
+}
+
+// Attach a new tap to a component of a stream identified by its label, the
+// stream should be empty when not running in streams mode.
+func (r *Registry) Attach(stream, label string, opts Options) (*Tap, error) {
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		return nil, ErrInvalidSampleRate
+	}
+	if opts.BufferSize < 0 {
+		opts.BufferSize = 0
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	p := r.point(stream, label)
+	t := &Tap{
+		opts:   opts,
+		r:      r,
+		p:      p,
+		events: make(chan Event, opts.BufferSize),
+	}
+	p.add(t)
+	return t, nil
+}
+
+// detach a tap from its point, removing the point when it no longer has taps
+// or running components.
+func (r *Registry) detach(t *Tap) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if t.p.remove(t) > 0 {
+		return
+	}
+	k := pointKey{stream: t.p.stream, label: t.p.label}
+	if _, known := r.known[k]; !known && r.points[k] == t.p {
+		delete(r.points, k)
+	}
+}
+
+// Labels returns the sorted labels of components currently running within a
+// stream.
+func (r *Registry) Labels(stream string) []string {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	var labels []string
+	for k := range r.known {
+		if k.stream == stream {
+			labels = append(labels, k.label)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -0,0 +1,43 @@
+package tap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryPrunesPoints(t *testing.T) {
+	r := NewRegistry()
+
+	_, deregisterFoo := r.register("a", "foo")
+	_, deregisterBar := r.register("a", "bar")
+
+	tp, err := r.Attach("a", "foo", NewOptions())
+	require.NoError(t, err)
+
+	early, err := r.Attach("b", "baz", NewOptions())
+	require.NoError(t, err)
+
+	assert.Len(t, r.points, 3)
+	assert.Equal(t, []string{"bar", "foo"}, r.Labels("a"))
+
+	// Removing the components of the stream prunes points without taps.
+	deregisterFoo()
+	deregisterBar()
+	assert.Len(t, r.points, 2)
+	assert.Empty(t, r.Labels("a"))
+
+	tp.Close()
+	assert.Len(t, r.points, 1)
+
+	// Taps attached before a component exists keep their point until closed.
+	p, deregisterBaz := r.register("b", "baz")
+	assert.Same(t, early.p, p)
+
+	early.Close()
+	assert.Len(t, r.points, 1)
+
+	deregisterBaz()
+	assert.Empty(t, r.points)
+}
@@ -56,6 +56,10 @@ Find out more about Bloblang at: https://benthos.dev/docs/guides/bloblang/about`
 				Aliases: []string{"f"},
 				Usage:   "execute a mapping from a file.",
 			},
+			&cli.BoolFlag{
+				Name:  "trace",
+				Usage: "print a trace of the assignments, method calls, match cases and if branches executed for each document to stderr.",
+			},
 			&cli.IntFlag{
 				Name:  "max-token-length",
 				Usage: "Set the buffer size for document lines.",
@@ -64,6 +68,20 @@ Find out more about Bloblang at: https://benthos.dev/docs/guides/bloblang/about`
 		},
 		Action: run,
 		Subcommands: []*cli.Command{
+			{
+				Name:        "repl",
+				Usage:       "EXPERIMENTAL: Run an interactive Bloblang shell",
+				Description: "Run an interactive shell for executing Bloblang mapping statements and queries against an input document, with tab completion of functions and methods. Type :help within the shell for a list of commands.",
+				Action:      runREPL,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "input-file",
+						Value:   "",
+						Aliases: []string{"i"},
+						Usage:   "an optional path to an input file to load as the initial input document.",
+					},
+				},
+			},
 			{
 				Name:        "server",
 				Usage:       "EXPERIMENTAL: Run a web server that hosts a Bloblang app",
@@ -123,7 +141,7 @@ func newExecCache() *execCache {
 	}
 }
 
-func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) (string, error) {
+func (e *execCache) executeMapping(exec *mapping.Executor, tracer *query.Tracer, rawInput, prettyOutput bool, input []byte) (string, error) {
 	e.msg.Get(0).SetBytes(input)
 
 	var valuePtr *any
@@ -160,6 +178,7 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 		MsgBatch: e.msg,
 		NewMeta:  e.msg.Get(0),
 		NewValue: &result,
+		Tracer:   tracer,
 	}.WithValueFunc(lazyValue), mapping.AssignmentContext{
 		Vars:  e.vars,
 		Meta:  e.msg.Get(0),
@@ -210,6 +229,12 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 	return resultStr, nil
 }
 
+type execResult struct {
+	trace  []traceStep
+	result string
+	err    error
+}
+
 func run(c *cli.Context) error {
 	t := c.Int("threads")
 	if t < 1 {
@@ -218,6 +243,7 @@ func run(c *cli.Context) error {
 	raw := c.Bool("raw")
 	pretty := c.Bool("pretty")
 	file := c.String("file")
+	trace := c.Bool("trace")
 	m := c.Args().First()
 
 	execCache := newExecCache()
@@ -236,6 +262,9 @@ func run(c *cli.Context) error {
 	}
 
 	bEnv := bloblang.NewEnvironment().WithImporterRelativeToFile(file)
+	if trace {
+		bEnv = bEnv.WithTracing()
+	}
 	exec, err := bEnv.NewMapping(m)
 	if err != nil {
 		if perr, ok := err.(*parser.Error); ok {
@@ -265,7 +294,7 @@ func run(c *cli.Context) error {
 
 	wg := sync.WaitGroup{}
 	wg.Add(t)
-	resultsChan := make(chan string)
+	resultsChan := make(chan execResult)
 	go func() {
 		wg.Wait()
 		close(resultsChan)
@@ -281,18 +310,30 @@ func run(c *cli.Context) error {
 					return
 				}
 
-				resultStr, err := execCache.executeMapping(exec, raw, pretty, input)
-				if err != nil {
-					fmt.Fprintln(os.Stderr, red(fmt.Sprintf("failed to execute map: %v", err)))
-					continue
+				var tracer *query.Tracer
+				if trace {
+					tracer = query.NewTracer()
 				}
-				resultsChan <- resultStr
+
+				var res execResult
+				res.result, res.err = execCache.executeMapping(exec, tracer, raw, pretty, input)
+				if tracer != nil {
+					res.trace = traceSteps(m, tracer)
+				}
+				resultsChan <- res
 			}
 		}()
 	}
 
 	for res := range resultsChan {
-		fmt.Println(res)
+		for _, step := range res.trace {
+			fmt.Fprintln(os.Stderr, step.String())
+		}
+		if res.err != nil {
+			fmt.Fprintln(os.Stderr, red(fmt.Sprintf("failed to execute map: %v", res.err)))
+			continue
+		}
+		fmt.Println(res.result)
 	}
 	os.Exit(0)
 	return nil
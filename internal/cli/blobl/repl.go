@@ -0,0 +1,507 @@
+package blobl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+var replKeywords = []string{
+	"root", "this", "if", "else", "match", "let", "map", "import", "from", "meta",
+}
+
+var replCommands = []struct {
+	name  string
+	usage string
+}{
+	{"help", "show this message"},
+	{"load", "<file> load the input document from a file"},
+	{"input", "[document] show the input document, or replace it"},
+	{"meta", "[key [value]] list input metadata, delete a key, or set a key to a value"},
+	{"doc", "<name> show the documentation of a function or method"},
+	{"mapping", "show the statements of the mapping"},
+	{"undo", "remove the last statement from the mapping"},
+	{"reset", "remove all statements from the mapping"},
+	{"quit", "exit the REPL"},
+}
+
+type repl struct {
+	env *bloblang.Environment
+	out io.Writer
+
+	input      *message.Part
+	statements []string
+
+	// pending contains the lines of a statement that spans multiple lines and
+	// has not yet been completed.
+	pending string
+
+	functions []string
+	methods   []string
+}
+
+func newREPL(env *bloblang.Environment, out io.Writer) *repl {
+	r := &repl{
+		env:   env,
+		out:   out,
+		input: message.NewPart([]byte(`{}`)),
+	}
+	env.WalkFunctions(func(name string, spec query.FunctionSpec) {
+		if spec.Status != query.StatusHidden {
+			r.functions = append(r.functions, name)
+		}
+	})
+	env.WalkMethods(func(name string, spec query.MethodSpec) {
+		if spec.Status != query.StatusHidden {
+			r.methods = append(r.methods, name)
+		}
+	})
+	sort.Strings(r.functions)
+	sort.Strings(r.methods)
+	return r
+}
+
+func (r *repl) prompt() string {
+	if r.pending != "" {
+		return "... "
+	}
+	return "> "
+}
+
+func (r *repl) printErr(format string, args ...any) {
+	fmt.Fprintln(r.out, red(fmt.Sprintf(format, args...)))
+}
+
+// handleLine processes a line of input, which is either a command prefixed
+// with a colon, a query to evaluate, or one or more statements to add to the
+// mapping. Returns false if the REPL should exit.
+func (r *repl) handleLine(line string) bool {
+	if r.pending == "" {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			return true
+		}
+		if strings.HasPrefix(trimmed, ":") {
+			return r.handleCommand(trimmed[1:])
+		}
+		r.evaluate(line)
+		return true
+	}
+
+	if strings.TrimSpace(line) == "" {
+		// An empty line abandons an incomplete statement, showing the reason
+		// that it could not be parsed.
+		text := r.pending
+		r.pending = ""
+		if err := r.addStatement(text, false); err != nil {
+			r.printErr("%v", err)
+		}
+		return true
+	}
+	r.evaluate(r.pending + "\n" + line)
+	return true
+}
+
+// evaluate attempts to parse text as a query, in which case the result of the
+// query is printed, or otherwise as mapping statements that are added to the
+// mapping.
+func (r *repl) evaluate(text string) {
+	r.pending = ""
+
+	queryMapping := strings.Join(append(r.statements[:len(r.statements):len(r.statements)], "root = "+text), "\n")
+	if exec, err := r.env.NewMapping(queryMapping); err == nil {
+		result, _, err := r.execute(exec)
+		if err != nil {
+			r.printErr("failed to execute query: %v", err)
+			return
+		}
+		fmt.Fprintln(r.out, formatREPLValue(result))
+		return
+	}
+
+	if err := r.addStatement(text, true); err != nil {
+		r.printErr("%v", err)
+	}
+}
+
+func (r *repl) addStatement(text string, allowIncomplete bool) error {
+	statements := append(r.statements[:len(r.statements):len(r.statements)], text)
+	exec, err := r.env.NewMapping(strings.Join(statements, "\n"))
+	if err != nil {
+		var perr *parser.Error
+		if !errors.As(err, &perr) {
+			return err
+		}
+		if allowIncomplete && len(perr.Input) == 0 {
+			r.pending = text
+			return nil
+		}
+		return fmt.Errorf("failed to parse statement: %v", perr.ErrorAtChar([]rune(text)))
+	}
+
+	result, meta, err := r.execute(exec)
+	if err != nil {
+		return fmt.Errorf("failed to execute mapping: %w", err)
+	}
+
+	r.statements = statements
+	fmt.Fprintln(r.out, formatREPLValue(result))
+	_ = meta.MetaIterMut(func(k string, v any) error {
+		if existing, exists := r.input.MetaGetMut(k); !exists || !query.ICompare(existing, v) {
+			fmt.Fprintf(r.out, "meta %v = %v\n", k, formatREPLValue(v))
+		}
+		return nil
+	})
+	return nil
+}
+
+// execute runs a mapping against a copy of the input document, returning the
+// resulting value and message.
+func (r *repl) execute(exec *mapping.Executor) (any, *message.Part, error) {
+	part := r.input.DeepCopy()
+	msg := message.Batch{part}
+
+	var valuePtr *any
+	var parseErr error
+	lazyValue := func() *any {
+		if valuePtr == nil && parseErr == nil {
+			if jObj, err := part.AsStructured(); err == nil {
+				valuePtr = &jObj
+			} else {
+				parseErr = fmt.Errorf("parse as json: %w", err)
+			}
+		}
+		return valuePtr
+	}
+
+	vars := map[string]any{}
+	var result any = query.Nothing(nil)
+	err := exec.ExecOnto(query.FunctionContext{
+		Maps:     exec.Maps(),
+		Vars:     vars,
+		MsgBatch: msg,
+		NewMeta:  part,
+		NewValue: &result,
+	}.WithValueFunc(lazyValue), mapping.AssignmentContext{
+		Vars:  vars,
+		Meta:  part,
+		Value: &result,
+	})
+	if err != nil {
+		var ctxErr query.ErrNoContext
+		if parseErr != nil && errors.As(err, &ctxErr) {
+			err = fmt.Errorf("unable to reference input document as structured: %w", parseErr)
+		}
+		return nil, nil, err
+	}
+
+	if _, isNothing := result.(query.Nothing); isNothing {
+		if v := lazyValue(); v != nil {
+			result = *v
+		} else {
+			result = part.AsBytes()
+		}
+	}
+	return result, part, nil
+}
+
+func formatREPLValue(v any) string {
+	switch t := v.(type) {
+	case []byte:
+		return string(t)
+	case query.Delete:
+		return "<deleted>"
+	case query.Nothing:
+		return "<nothing>"
+	}
+	return gabs.Wrap(v).StringIndent("", "  ")
+}
+
+func (r *repl) handleCommand(cmd string) bool {
+	name, arg := cmd, ""
+	if i := strings.IndexAny(cmd, " \t"); i >= 0 {
+		name, arg = cmd[:i], strings.TrimSpace(cmd[i+1:])
+	}
+
+	switch name {
+	case "quit", "exit", "q":
+		return false
+	case "help", "h":
+		for _, c := range replCommands {
+			fmt.Fprintf(r.out, "  :%-8v %v\n", c.name, c.usage)
+		}
+	case "load":
+		if arg == "" {
+			r.printErr("usage: :load <file>")
+			break
+		}
+		inputBytes, err := ifs.ReadFile(ifs.OS(), arg)
+		if err != nil {
+			r.printErr("failed to read input file: %v", err)
+			break
+		}
+		r.input.SetBytes(inputBytes)
+		r.printInput()
+	case "input":
+		if arg != "" {
+			r.input.SetBytes([]byte(arg))
+		}
+		r.printInput()
+	case "meta":
+		r.handleMeta(arg)
+	case "doc":
+		if arg == "" {
+			r.printErr("usage: :doc <name>")
+			break
+		}
+		if docs := r.docs(arg); docs != "" {
+			fmt.Fprint(r.out, docs)
+		} else {
+			r.printErr("no function or method named '%v'", arg)
+		}
+	case "mapping":
+		for _, s := range r.statements {
+			fmt.Fprintln(r.out, s)
+		}
+	case "undo":
+		if len(r.statements) > 0 {
+			r.statements = r.statements[:len(r.statements)-1]
+		}
+	case "reset":
+		r.statements = nil
+	default:
+		r.printErr("unknown command ':%v', try :help", name)
+	}
+	return true
+}
+
+func (r *repl) printInput() {
+	if v, err := r.input.AsStructured(); err == nil {
+		fmt.Fprintln(r.out, formatREPLValue(v))
+	} else {
+		fmt.Fprintln(r.out, string(r.input.AsBytes()))
+	}
+}
+
+func (r *repl) handleMeta(arg string) {
+	if arg == "" {
+		var keys []string
+		_ = r.input.MetaIterMut(func(k string, _ any) error {
+			keys = append(keys, k)
+			return nil
+		})
+		sort.Strings(keys)
+		for _, k := range keys {
+			v, _ := r.input.MetaGetMut(k)
+			fmt.Fprintf(r.out, "%v: %v\n", k, formatREPLValue(v))
+		}
+		return
+	}
+
+	key, value := arg, ""
+	if i := strings.IndexAny(arg, " \t"); i >= 0 {
+		key, value = arg[:i], strings.TrimSpace(arg[i+1:])
+	}
+	if value == "" {
+		r.input.MetaDelete(key)
+		return
+	}
+	r.input.MetaSetMut(key, value)
+}
+
+func replSignature(name string, params query.Params) string {
+	var args []string
+	for _, p := range params.Definitions {
+		arg := fmt.Sprintf("%v: %v", p.Name, p.ValueType)
+		if p.IsOptional || p.DefaultValue != nil {
+			arg = fmt.Sprintf("%v?: %v", p.Name, p.ValueType)
+		}
+		args = append(args, arg)
+	}
+	if params.Variadic {
+		args = append(args, "...")
+	}
+	return fmt.Sprintf("%v(%v)", name, strings.Join(args, ", "))
+}
+
+func replDocs(b *strings.Builder, signature string, status query.Status, description string, params query.Params, examples []query.ExampleSpec) {
+	b.WriteString(signature)
+	if status != query.StatusStable && status != "" {
+		fmt.Fprintf(b, " [%v]", status)
+	}
+	b.WriteString("\n\n")
+	if description = strings.TrimSpace(description); description != "" {
+		b.WriteString(description)
+		b.WriteString("\n\n")
+	}
+	for _, p := range params.Definitions {
+		fmt.Fprintf(b, "  %v <%v> %v\n", p.Name, p.ValueType, strings.TrimSpace(p.Description))
+	}
+	if len(params.Definitions) > 0 {
+		b.WriteString("\n")
+	}
+	for _, e := range examples {
+		b.WriteString(e.Mapping)
+		b.WriteString("\n")
+		for _, res := range e.Results {
+			fmt.Fprintf(b, "  # in:  %v\n  # out: %v\n", res[0], res[1])
+		}
+		b.WriteString("\n")
+	}
+}
+
+// docs returns the documentation of any function and method with a name.
+func (r *repl) docs(name string) string {
+	var b strings.Builder
+	r.env.WalkFunctions(func(n string, spec query.FunctionSpec) {
+		if n == name {
+			replDocs(&b, "function "+replSignature(n, spec.Params), spec.Status, spec.Description, spec.Params, spec.Examples)
+		}
+	})
+	r.env.WalkMethods(func(n string, spec query.MethodSpec) {
+		if n == name {
+			replDocs(&b, "method ."+replSignature(n, spec.Params), spec.Status, spec.Description, spec.Params, spec.Examples)
+		}
+	})
+	return b.String()
+}
+
+func isREPLIdentChar(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// completions returns the candidates for completing the identifier that ends
+// at a position of a line, along with the start of that identifier.
+func (r *repl) completions(line string, pos int) (candidates []string, start int) {
+	start = pos
+	for start > 0 && isREPLIdentChar(line[start-1]) {
+		start--
+	}
+	prefix, before := line[start:pos], line[:start]
+
+	var pool []string
+	switch {
+	case r.pending == "" && strings.TrimSpace(before) == ":":
+		for _, c := range replCommands {
+			pool = append(pool, c.name)
+		}
+	case r.pending == "" && strings.HasPrefix(strings.TrimSpace(before), ":doc"):
+		pool = append(append(pool, r.functions...), r.methods...)
+	case r.pending == "" && strings.HasPrefix(strings.TrimSpace(before), ":"):
+	case strings.HasSuffix(before, "."):
+		pool = r.methods
+	default:
+		pool = append(append(pool, r.functions...), replKeywords...)
+	}
+
+	seen := map[string]struct{}{}
+	for _, c := range pool {
+		if _, exists := seen[c]; exists || !strings.HasPrefix(c, prefix) {
+			continue
+		}
+		seen[c] = struct{}{}
+		candidates = append(candidates, c)
+	}
+	sort.Strings(candidates)
+	return
+}
+
+// complete is an auto complete callback for a terminal, where a tab extends the
+// identifier before the cursor to the longest common prefix of all candidates,
+// listing the candidates when there are several.
+func (r *repl) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	candidates, start := r.completions(line, pos)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(candidates) > 1 && common == line[start:pos] {
+		fmt.Fprintln(r.out, strings.Join(candidates, "  "))
+	}
+	return line[:start] + common + line[pos:], start + len(common), true
+}
+
+func runREPL(c *cli.Context) error {
+	r := newREPL(bloblang.NewEnvironment(), os.Stdout)
+	if inputFile := c.String("input-file"); inputFile != "" {
+		inputBytes, err := ifs.ReadFile(ifs.OS(), inputFile)
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %w", err)
+		}
+		r.input.SetBytes(inputBytes)
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdinFd) {
+		// When the input is not a terminal each line is processed without any
+		// prompts, which allows sessions to be scripted.
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if !r.handleLine(scanner.Text()) {
+				return nil
+			}
+		}
+		if r.pending != "" {
+			r.handleLine("")
+		}
+		return scanner.Err()
+	}
+
+	oldState, err := term.MakeRaw(stdinFd)
+	if err != nil {
+		return fmt.Errorf("failed to configure terminal: %w", err)
+	}
+	defer func() {
+		_ = term.Restore(stdinFd, oldState)
+	}()
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, r.prompt())
+	if width, height, err := term.GetSize(stdinFd); err == nil {
+		_ = t.SetSize(width, height)
+	}
+	t.AutoCompleteCallback = r.complete
+	r.out = t
+
+	fmt.Fprintln(t, "Bloblang REPL, type :help for a list of commands.")
+	for {
+		line, err := t.ReadLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if !r.handleLine(line) {
+			return nil
+		}
+		t.SetPrompt(r.prompt())
+	}
+}
@@ -0,0 +1,86 @@
+package blobl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+)
+
+func TestREPLSession(t *testing.T) {
+	var out bytes.Buffer
+	r := newREPL(bloblang.NewEnvironment(), &out)
+
+	for _, line := range []string{
+		`:input {"name":"foo","tags":["a","b"]}`,
+		`root.title = this.name.uppercase()`,
+		`root.tags = match this.tags {`,
+		`  [first, ...rest] => rest`,
+		`}`,
+		`root.nope = this.name.`,
+		``,
+		`root.title + "!"`,
+	} {
+		require.True(t, r.handleLine(line), line)
+	}
+	assert.False(t, r.handleLine(":quit"))
+
+	assert.Equal(t, []string{
+		`root.title = this.name.uppercase()`,
+		"root.tags = match this.tags {\n  [first, ...rest] => rest\n}",
+	}, r.statements)
+
+	output := out.String()
+	assert.Contains(t, output, `"title": "FOO"`)
+	assert.Contains(t, output, "failed to parse statement")
+	assert.True(t, strings.HasSuffix(output, "\"FOO!\"\n"), output)
+
+	out.Reset()
+	require.True(t, r.handleLine(":undo"))
+	require.True(t, r.handleLine(":mapping"))
+	assert.Equal(t, "root.title = this.name.uppercase()\n", out.String())
+}
+
+func TestREPLMeta(t *testing.T) {
+	var out bytes.Buffer
+	r := newREPL(bloblang.NewEnvironment(), &out)
+
+	require.True(t, r.handleLine(":meta topic events"))
+	require.True(t, r.handleLine(`meta("topic")`))
+	assert.Equal(t, "\"events\"\n", out.String())
+
+	out.Reset()
+	require.True(t, r.handleLine(":meta topic"))
+	require.True(t, r.handleLine(":meta"))
+	assert.Equal(t, "", out.String())
+}
+
+func TestREPLCompletion(t *testing.T) {
+	r := newREPL(bloblang.NewEnvironment(), &bytes.Buffer{})
+
+	line, pos, ok := r.complete("this.foo.uppe", 13, '\t')
+	require.True(t, ok)
+	assert.Equal(t, "this.foo.uppercase", line)
+	assert.Equal(t, 18, pos)
+
+	line, _, ok = r.complete("root = uuid_v", 13, '\t')
+	require.True(t, ok)
+	assert.Equal(t, "root = uuid_v4", line)
+
+	line, _, ok = r.complete(":lo", 3, '\t')
+	require.True(t, ok)
+	assert.Equal(t, ":load", line)
+
+	candidates, _ := r.completions("this.map_", 9)
+	assert.Equal(t, []string{"map_each", "map_each_key"}, candidates)
+
+	_, _, ok = r.complete("this.foo", 8, 'x')
+	assert.False(t, ok)
+
+	assert.Contains(t, r.docs("map_each"), "method .map_each(query: query expression)")
+	assert.Empty(t, r.docs("does_not_exist"))
+}
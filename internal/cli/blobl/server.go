@@ -22,6 +22,7 @@ import (
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 
 	_ "embed"
@@ -151,6 +152,7 @@ func runServer(c *cli.Context) error {
 		req := struct {
 			Mapping string `json:"mapping"`
 			Input   string `json:"input"`
+			Trace   bool   `json:"trace"`
 		}{}
 		dec := json.NewDecoder(r.Body)
 		if err := dec.Decode(&req); err != nil {
@@ -161,9 +163,10 @@ func runServer(c *cli.Context) error {
 		fSync.update(req.Input, req.Mapping)
 
 		res := struct {
-			ParseError   string `json:"parse_error"`
-			MappingError string `json:"mapping_error"`
-			Result       string `json:"result"`
+			ParseError   string      `json:"parse_error"`
+			MappingError string      `json:"mapping_error"`
+			Result       string      `json:"result"`
+			Trace        []traceStep `json:"trace,omitempty"`
 		}{}
 		defer func() {
 			resBytes, err := json.Marshal(res)
@@ -174,7 +177,12 @@ func runServer(c *cli.Context) error {
 			_, _ = w.Write(resBytes)
 		}()
 
-		exec, err := bloblang.GlobalEnvironment().NewMapping(req.Mapping)
+		env := bloblang.GlobalEnvironment()
+		if req.Trace {
+			env = env.WithTracing()
+		}
+
+		exec, err := env.NewMapping(req.Mapping)
 		if err != nil {
 			if perr, ok := err.(*parser.Error); ok {
 				res.ParseError = fmt.Sprintf("failed to parse mapping: %v\n", perr.ErrorAtPositionStructured("", []rune(req.Mapping)))
@@ -184,7 +192,15 @@ func runServer(c *cli.Context) error {
 			return
 		}
 
-		output, err := execCache.executeMapping(exec, false, true, []byte(req.Input))
+		var tracer *query.Tracer
+		if req.Trace {
+			tracer = query.NewTracer()
+		}
+
+		output, err := execCache.executeMapping(exec, tracer, false, true, []byte(req.Input))
+		if tracer != nil {
+			res.Trace = traceSteps(req.Mapping, tracer)
+		}
 		if err != nil {
 			res.MappingError = err.Error()
 		} else {
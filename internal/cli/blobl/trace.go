@@ -0,0 +1,91 @@
+package blobl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// traceStep is a step of a traced mapping execution in a form that can be
+// printed or serialized for the blobl server app.
+type traceStep struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Value       string `json:"value"`
+	Error       string `json:"error,omitempty"`
+}
+
+func traceValueString(v any) string {
+	switch t := v.(type) {
+	case query.Nothing:
+		return "nothing"
+	case query.Delete:
+		return "deleted"
+	case []byte:
+		v = string(t)
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return query.IToString(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// traceSteps converts the events of a tracer into steps, resolving the line
+// and column of each event within the mapping. Events that originate from
+// outside of the mapping, such as from imported files, are given a line and
+// column of zero.
+func traceSteps(blobl string, tracer *query.Tracer) []traceStep {
+	input := []rune(blobl)
+
+	steps := make([]traceStep, 0, len(tracer.Events()))
+	for _, e := range tracer.Events() {
+		step := traceStep{
+			Kind:        string(e.Kind),
+			Description: e.Description,
+		}
+		if e.Err != nil {
+			step.Error = e.Err.Error()
+		} else {
+			step.Value = traceValueString(e.Value)
+		}
+		if len(e.Input) <= len(input) && string(input[len(input)-len(e.Input):]) == string(e.Input) {
+			step.Line, step.Column = mapping.LineAndColOf(input, e.Input)
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func (s traceStep) String() string {
+	var b strings.Builder
+	if s.Line > 0 {
+		fmt.Fprintf(&b, "%v:%v ", s.Line, s.Column)
+	}
+	sep := " -> "
+	switch query.TraceEventKind(s.Kind) {
+	case query.TraceAssignment:
+		b.WriteString(s.Description)
+		sep = " = "
+	case query.TraceMethod:
+		b.WriteString("." + s.Description)
+	case query.TraceMatchCase:
+		b.WriteString("match case " + s.Description)
+	case query.TraceIfBranch:
+		b.WriteString(s.Description)
+	}
+	if s.Error != "" {
+		b.WriteString(" failed: " + s.Error)
+	} else {
+		b.WriteString(sep + s.Value)
+	}
+	return b.String()
+}
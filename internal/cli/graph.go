@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/config/graph"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+func graphCliCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "graph",
+		Usage: "Export the component topology of a config as a graph",
+		Description: `
+Walks a config, including its resources, and prints the topology of its
+components as a graph. If stream config paths are provided then the graph
+contains each stream, as would be loaded in streams mode, where inproc outputs
+are connected to the inproc inputs of other streams.
+
+  benthos -c ./config.yaml graph | dot -Tsvg > ./config.svg
+  benthos graph --format mermaid ./streams/*.yaml
+  benthos -r ./resources.yaml graph --format json ./streams`[1:],
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "dot",
+				Usage: "The format of the graph. Options are dot, mermaid or json.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			g, err := buildGraph(c)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Graph error: %v\n", err)
+				os.Exit(1)
+			}
+
+			var outBytes []byte
+			switch c.String("format") {
+			case "dot":
+				outBytes = g.DOT()
+			case "mermaid":
+				outBytes = g.Mermaid()
+			case "json":
+				if outBytes, err = g.JSON(); err != nil {
+					fmt.Fprintf(os.Stderr, "Graph error: %v\n", err)
+					os.Exit(1)
+				}
+				outBytes = append(outBytes, '\n')
+			default:
+				fmt.Fprintf(os.Stderr, "Graph format not recognised: %v\n", c.String("format"))
+				os.Exit(1)
+			}
+			_, _ = os.Stdout.Write(outBytes)
+			return nil
+		},
+	}
+}
+
+func buildGraph(c *cli.Context) (*graph.Graph, error) {
+	streamsMode := c.Args().Len() > 0
+	_, _, confReader := readConfig(
+		c.String("config"), streamsMode,
+		c.StringSlice("resources"), c.Args().Slice(),
+		c.StringSlice("set"), c.StringSlice("profile"),
+	)
+
+	conf := config.New()
+	if _, err := confReader.Read(&conf); err != nil {
+		return nil, err
+	}
+
+	b := graph.NewBuilder(bundle.GlobalEnvironment)
+
+	var rootNode yaml.Node
+	if err := rootNode.Encode(conf); err != nil {
+		return nil, err
+	}
+	if err := b.AddResources(&rootNode); err != nil {
+		return nil, err
+	}
+
+	if !streamsMode {
+		if err := b.AddStream("main", &rootNode); err != nil {
+			return nil, err
+		}
+		return b.Build(), nil
+	}
+
+	streamConfs := map[string]stream.Config{}
+	if _, err := confReader.ReadStreams(streamConfs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(streamConfs))
+	for id := range streamConfs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var streamNode yaml.Node
+		if err := streamNode.Encode(streamConfs[id]); err != nil {
+			return nil, err
+		}
+		if err := b.AddStream(id, &streamNode); err != nil {
+			return nil, fmt.Errorf("stream '%v': %w", id, err)
+		}
+	}
+	return b.Build(), nil
+}
@@ -0,0 +1,149 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+var bloblangKeywords = []string{
+	"root", "this", "if", "else", "match", "let", "map", "import", "from",
+}
+
+func isIdentChar(b byte) bool {
+	return b != '-' && isWordChar(b)
+}
+
+// identAt returns the Bloblang identifier of a line that surrounds a byte
+// column along with the start and end indexes of the identifier.
+func identAt(line string, col int) (ident string, start, end int) {
+	if col > len(line) {
+		col = len(line)
+	}
+	start, end = col, col
+	for start > 0 && isIdentChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdentChar(line[end]) {
+		end++
+	}
+	return line[start:end], start, end
+}
+
+// inBloblang returns true if a cursor is positioned within a Bloblang mapping,
+// either within a mapping file or within a config field that accepts a mapping
+// or interpolation functions.
+func (s *Server) inBloblang(doc *document, cur cursor, line string, col int) bool {
+	if doc.isBloblang() {
+		return true
+	}
+	if cur.onKey {
+		return false
+	}
+	target, ok := resolvePath(s.env, s.spec, cur.path)
+	if !ok || target.value == nil {
+		return false
+	}
+	if target.value.Bloblang {
+		return true
+	}
+	if target.value.Interpolated {
+		before := line[:col]
+		if i := strings.LastIndex(before, "${!"); i >= 0 {
+			return !strings.Contains(before[i:], "}")
+		}
+	}
+	return false
+}
+
+func paramsSignature(name string, params query.Params) string {
+	var args []string
+	for _, p := range params.Definitions {
+		arg := fmt.Sprintf("%v: %v", p.Name, p.ValueType)
+		if p.IsOptional || p.DefaultValue != nil {
+			arg = fmt.Sprintf("%v?: %v", p.Name, p.ValueType)
+		}
+		args = append(args, arg)
+	}
+	if params.Variadic {
+		args = append(args, "...")
+	}
+	return fmt.Sprintf("%v(%v)", name, strings.Join(args, ", "))
+}
+
+func bloblangDocs(signature, description string) *markupContent {
+	return &markupContent{
+		Kind:  "markdown",
+		Value: fmt.Sprintf("```coffee\n%v\n```\n\n%v", signature, strings.TrimSpace(description)),
+	}
+}
+
+func (s *Server) bloblangCompletion(line string, col int) []completionItem {
+	ident, start, _ := identAt(line, col)
+	ident = ident[:col-start]
+
+	var items []completionItem
+	if start > 0 && line[start-1] == '.' {
+		s.blobl.WalkMethods(func(name string, spec query.MethodSpec) {
+			if !strings.HasPrefix(name, ident) {
+				return
+			}
+			sig := paramsSignature(name, spec.Params)
+			items = append(items, completionItem{
+				Label:         name,
+				Kind:          completionKindMethod,
+				Detail:        sig,
+				Documentation: bloblangDocs(sig, spec.Description),
+				Deprecated:    spec.Status == query.StatusDeprecated,
+			})
+		})
+	} else {
+		s.blobl.WalkFunctions(func(name string, spec query.FunctionSpec) {
+			if !strings.HasPrefix(name, ident) {
+				return
+			}
+			sig := paramsSignature(name, spec.Params)
+			items = append(items, completionItem{
+				Label:         name,
+				Kind:          completionKindFunction,
+				Detail:        sig,
+				Documentation: bloblangDocs(sig, spec.Description),
+				Deprecated:    spec.Status == query.StatusDeprecated,
+			})
+		})
+		for _, k := range bloblangKeywords {
+			if strings.HasPrefix(k, ident) {
+				items = append(items, completionItem{Label: k, Kind: completionKindKeyword})
+			}
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+	return items
+}
+
+func (s *Server) bloblangHover(line string, col int) *markupContent {
+	ident, start, end := identAt(line, col)
+	if ident == "" || end >= len(line) || line[end] != '(' {
+		return nil
+	}
+
+	var content *markupContent
+	if start > 0 && line[start-1] == '.' {
+		s.blobl.WalkMethods(func(name string, spec query.MethodSpec) {
+			if name == ident {
+				content = bloblangDocs(paramsSignature(name, spec.Params), spec.Description)
+			}
+		})
+	} else {
+		s.blobl.WalkFunctions(func(name string, spec query.FunctionSpec) {
+			if name == ident {
+				content = bloblangDocs(paramsSignature(name, spec.Params), spec.Description)
+			}
+		})
+	}
+	return content
+}
@@ -0,0 +1,38 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+)
+
+// CliCommand is a cli.Command definition for running a language server.
+func CliCommand(version string) *cli.Command {
+	return &cli.Command{
+		Name:  "lsp",
+		Usage: "Run a language server for Benthos configs and Bloblang mappings",
+		Description: `
+Runs a server implementing the Language Server Protocol over stdio, which
+provides editors with completion of component and field names, hover
+documentation, go-to-definition of resources and Bloblang maps and imports,
+and diagnostics from linting configs and parsing Bloblang mappings.
+
+Configs are expected to have a .yaml or .yml extension and Bloblang mapping
+files a .blobl extension. Consult the documentation of your editor for how to
+register a language server, the command to run is:
+
+  benthos lsp`[1:],
+		Action: func(c *cli.Context) error {
+			s := NewServer(version, bundle.GlobalEnvironment, bloblang.GlobalEnvironment(), os.Stdin, os.Stdout)
+			if err := s.Serve(); err != nil {
+				fmt.Fprintf(os.Stderr, "Language server error: %v\n", err)
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+// resourceRefKeys are the keys of fields that reference resources by label.
+var resourceRefKeys = map[string]struct{}{
+	"resource":   {},
+	"cache":      {},
+	"rate_limit": {},
+}
+
+func (s *Server) componentDocs(cType docs.Type) []docs.ComponentSpec {
+	switch cType {
+	case docs.TypeBuffer:
+		return s.env.BufferDocs()
+	case docs.TypeCache:
+		return s.env.CacheDocs()
+	case docs.TypeInput:
+		return s.env.InputDocs()
+	case docs.TypeMetrics:
+		return s.env.MetricsDocs()
+	case docs.TypeOutput:
+		return s.env.OutputDocs()
+	case docs.TypeProcessor:
+		return s.env.ProcessorDocs()
+	case docs.TypeRateLimit:
+		return s.env.RateLimitDocs()
+	case docs.TypeTracer:
+		return s.env.TracersDocs()
+	}
+	return nil
+}
+
+func fieldDetail(f docs.FieldSpec) string {
+	switch f.Kind {
+	case docs.KindArray:
+		return fmt.Sprintf("array of %v", f.Type)
+	case docs.Kind2DArray:
+		return fmt.Sprintf("two-dimensional array of %v", f.Type)
+	case docs.KindMap:
+		return fmt.Sprintf("map of %v", f.Type)
+	}
+	return string(f.Type)
+}
+
+func markdown(value string) *markupContent {
+	return &markupContent{Kind: "markdown", Value: value}
+}
+
+func (s *Server) completion(doc *document, pos position) []completionItem {
+	line := doc.line(pos.Line)
+	col := byteColumn(line, pos.Character)
+
+	cur := cursorAt(doc.lines, pos.Line, col)
+	if s.inBloblang(doc, cur, line, col) {
+		return s.bloblangCompletion(line, col)
+	}
+
+	items := []completionItem{}
+	if cur.onKey {
+		path := cur.path
+		if cur.existingKey {
+			path = path[:len(path)-1]
+		}
+		target, ok := resolvePath(s.env, s.spec, path)
+		if !ok {
+			return items
+		}
+		for _, item := range s.keyCompletion(target.level, cur.existingKey) {
+			if strings.HasPrefix(item.Label, cur.prefix) {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	if cur.valueCol < 0 || len(cur.path) == 0 {
+		return items
+	}
+	target, ok := resolvePath(s.env, s.spec, cur.path)
+	if !ok {
+		return items
+	}
+	for _, item := range s.valueCompletion(cur.path[len(cur.path)-1], target) {
+		if strings.HasPrefix(item.Label, cur.prefix) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (s *Server) keyCompletion(level schemaLevel, existingKey bool) []completionItem {
+	var items []completionItem
+	insertText := func(name string) string {
+		if existingKey {
+			return name
+		}
+		return name + ": "
+	}
+
+	fieldItem := func(f docs.FieldSpec) completionItem {
+		return completionItem{
+			Label:         f.Name,
+			Kind:          completionKindField,
+			Detail:        fieldDetail(f),
+			Documentation: markdown(f.Description),
+			InsertText:    insertText(f.Name),
+			Deprecated:    f.IsDeprecated,
+		}
+	}
+
+	if level.cType != "" {
+		for _, spec := range s.componentDocs(level.cType) {
+			items = append(items, completionItem{
+				Label:         spec.Name,
+				Kind:          completionKindModule,
+				Detail:        string(level.cType),
+				Documentation: markdown(spec.Summary),
+				InsertText:    insertText(spec.Name),
+				Deprecated:    spec.Status == docs.StatusDeprecated,
+			})
+		}
+		for name, f := range docs.ReservedFieldsByType(level.cType) {
+			if name == "type" || name == "plugin" {
+				continue
+			}
+			items = append(items, fieldItem(f))
+		}
+	} else {
+		for _, f := range level.fields {
+			items = append(items, fieldItem(f))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+	return items
+}
+
+func (s *Server) valueCompletion(key string, target schemaTarget) []completionItem {
+	var items []completionItem
+
+	_, isRef := resourceRefKeys[key]
+	if isRef && (target.value == nil || target.value.Type == docs.FieldTypeString) {
+		seen := map[string]struct{}{}
+		for _, doc := range s.documents {
+			for _, label := range labelsOf(doc) {
+				if _, exists := seen[label.name]; exists {
+					continue
+				}
+				seen[label.name] = struct{}{}
+				items = append(items, completionItem{Label: label.name, Kind: completionKindValue})
+			}
+		}
+	}
+
+	if target.value == nil {
+		return items
+	}
+	f := *target.value
+	for _, opt := range f.AnnotatedOptions {
+		items = append(items, completionItem{
+			Label:         opt[0],
+			Kind:          completionKindValue,
+			Documentation: markdown(opt[1]),
+		})
+	}
+	for _, opt := range f.Options {
+		items = append(items, completionItem{Label: opt, Kind: completionKindValue})
+	}
+	if f.Type == docs.FieldTypeBool && f.Kind == docs.KindScalar {
+		items = append(items,
+			completionItem{Label: "true", Kind: completionKindValue},
+			completionItem{Label: "false", Kind: completionKindValue},
+		)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+	return items
+}
@@ -0,0 +1,258 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+// Configs are frequently invalid YAML whilst being edited, and therefore the
+// position of a cursor within a config is determined from the indentation of
+// the lines above it rather than a parsed YAML tree.
+
+var yamlKeyRegexp = regexp.MustCompile(`^(\s*(?:-\s+)*)([^\s:#'"{}\[\],&*!|>%@` + "`" + `-][^\s:#{}\[\],]*|-[^\s:#{}\[\],]+|"[^"]*"|'[^']*')\s*:(\s|$)`)
+
+type yamlLine struct {
+	indent   int
+	key      string
+	keyStart int
+	keyEnd   int
+	valueCol int
+}
+
+// parseYAMLLine attempts to parse a line as a mapping key, returning false if
+// the line does not contain a key.
+func parseYAMLLine(line string) (yamlLine, bool) {
+	m := yamlKeyRegexp.FindStringSubmatchIndex(line)
+	if m == nil {
+		return yamlLine{}, false
+	}
+	key := line[m[4]:m[5]]
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') {
+		key = key[1 : len(key)-1]
+	}
+	valueCol := m[1]
+	for valueCol < len(line) && line[valueCol] == ' ' {
+		valueCol++
+	}
+	return yamlLine{
+		indent:   m[3],
+		key:      key,
+		keyStart: m[4],
+		keyEnd:   m[5],
+		valueCol: valueCol,
+	}, true
+}
+
+func isIgnoredLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// ancestorKeys walks upwards from a line and returns the keys of each parent
+// mapping of a key at the given indentation.
+func ancestorKeys(lines []string, lineIndex, indent int) []string {
+	var keys []string
+	for i := lineIndex - 1; i >= 0 && indent > 0; i-- {
+		line := lines[i]
+		if strings.HasPrefix(line, "---") {
+			break
+		}
+		if isIgnoredLine(line) {
+			continue
+		}
+		l, ok := parseYAMLLine(line)
+		if !ok || l.indent >= indent {
+			continue
+		}
+		keys = append(keys, l.key)
+		indent = l.indent
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return keys
+}
+
+// inBlockScalar returns true if a line at the given indentation is within the
+// content of a block scalar, such as a multiple line mapping.
+func inBlockScalar(lines []string, lineIndex, indent int) bool {
+	for i := lineIndex - 1; i >= 0; i-- {
+		line := lines[i]
+		if strings.HasPrefix(line, "---") {
+			return false
+		}
+		if isIgnoredLine(line) {
+			continue
+		}
+		l, ok := parseYAMLLine(line)
+		if !ok || l.indent >= indent {
+			continue
+		}
+		value := line[l.valueCol:]
+		return strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">")
+	}
+	return false
+}
+
+var newKeyRegexp = regexp.MustCompile(`^(\s*(?:-\s+)*)([\w-]*)$`)
+
+// cursor describes the position of a cursor within a config.
+type cursor struct {
+	// The keys leading to the cursor. When the cursor is within a value then
+	// the last key is the key of that value.
+	path []string
+
+	// Whether the cursor is on a key, or where a new key could be written.
+	onKey bool
+
+	// Whether the key under the cursor already exists, as opposed to being
+	// written.
+	existingKey bool
+
+	// The partial word that precedes the cursor.
+	prefix string
+
+	// The byte column within the line that the value of the cursor begins, or
+	// -1 if the value does not begin on the line of the cursor.
+	valueCol int
+}
+
+func cursorAt(lines []string, lineIndex, col int) cursor {
+	if lineIndex >= len(lines) {
+		return cursor{valueCol: -1}
+	}
+	line := lines[lineIndex]
+	if col > len(line) {
+		col = len(line)
+	}
+	before := line[:col]
+
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if isIgnoredLine(line) {
+		indent = col
+	}
+	if inBlockScalar(lines, lineIndex, indent) {
+		return cursor{
+			path:     ancestorKeys(lines, lineIndex, indent),
+			valueCol: -1,
+		}
+	}
+
+	if l, ok := parseYAMLLine(line); ok {
+		path := append(ancestorKeys(lines, lineIndex, l.indent), l.key)
+		if col >= l.keyStart && col <= l.keyEnd {
+			return cursor{
+				path:        path,
+				onKey:       true,
+				existingKey: true,
+				prefix:      before[l.keyStart:],
+				valueCol:    -1,
+			}
+		}
+		if col >= l.valueCol {
+			return cursor{
+				path:     path,
+				prefix:   strings.TrimSpace(before[l.valueCol:]),
+				valueCol: l.valueCol,
+			}
+		}
+	}
+
+	if m := newKeyRegexp.FindStringSubmatch(before); m != nil {
+		return cursor{
+			path:     ancestorKeys(lines, lineIndex, len(m[1])),
+			onKey:    true,
+			prefix:   m[2],
+			valueCol: -1,
+		}
+	}
+
+	// Otherwise the cursor is within a multiple line value, which belongs to the
+	// closest parent key.
+	return cursor{
+		path:     ancestorKeys(lines, lineIndex, indent),
+		valueCol: -1,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// schemaLevel describes the keys that are expected within a mapping of a
+// config.
+type schemaLevel struct {
+	// The fields of an object.
+	fields docs.FieldSpecs
+
+	// When set the keys are components of this type.
+	cType docs.Type
+
+	// When set the keys are arbitrary, and each value matches this field.
+	mapValue *docs.FieldSpec
+}
+
+func levelOf(f docs.FieldSpec) schemaLevel {
+	if f.Kind == docs.KindMap {
+		elem := f
+		elem.Kind = docs.KindScalar
+		return schemaLevel{mapValue: &elem}
+	}
+	if coreType, isCore := f.Type.IsCoreComponent(); isCore {
+		return schemaLevel{cType: coreType}
+	}
+	return schemaLevel{fields: f.Children}
+}
+
+// schemaTarget is the outcome of resolving a key path against the schema of a
+// config.
+type schemaTarget struct {
+	// The schema of the keys within the value of the target.
+	level schemaLevel
+
+	// The field the target refers to, which is nil for components.
+	field *docs.FieldSpec
+
+	// The component the target refers to, which is nil for fields.
+	component *docs.ComponentSpec
+
+	// The spec of the value of the target, for components this is the config
+	// of the component.
+	value *docs.FieldSpec
+}
+
+func (l schemaLevel) lookup(prov docs.Provider, key string) (schemaTarget, bool) {
+	if l.mapValue != nil {
+		return schemaTarget{level: levelOf(*l.mapValue), field: l.mapValue, value: l.mapValue}, true
+	}
+	if l.cType != "" {
+		if f, exists := docs.ReservedFieldsByType(l.cType)[key]; exists {
+			return schemaTarget{level: levelOf(f), field: &f, value: &f}, true
+		}
+		if spec, exists := prov.GetDocs(key, l.cType); exists {
+			conf := spec.Config
+			return schemaTarget{level: levelOf(conf), component: &spec, value: &conf}, true
+		}
+		return schemaTarget{}, false
+	}
+	for _, f := range l.fields {
+		if f.Name == key {
+			f := f
+			return schemaTarget{level: levelOf(f), field: &f, value: &f}, true
+		}
+	}
+	return schemaTarget{}, false
+}
+
+// resolvePath resolves a path of keys against the schema of a config, returning
+// false if any key of the path is not recognised.
+func resolvePath(prov docs.Provider, root docs.FieldSpecs, path []string) (schemaTarget, bool) {
+	target := schemaTarget{level: schemaLevel{fields: root}}
+	for _, key := range path {
+		var ok bool
+		if target, ok = target.level.lookup(prov, key); !ok {
+			return target, false
+		}
+	}
+	return target, true
+}
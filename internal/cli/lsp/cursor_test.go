@@ -0,0 +1,92 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorAt(t *testing.T) {
+	lines := strings.Split(`input:
+  label: foo
+  generate:
+    mapping: |
+      root = this
+    ty
+pipeline:
+  processors:
+    - label: bar
+      branch:
+        request_map: 'root = this'
+        processors:
+          - cache:
+              resource: baz
+          - `, "\n")
+
+	tests := []struct {
+		name       string
+		line, col  int
+		path       []string
+		onKey      bool
+		existing   bool
+		prefix     string
+		hasValueAt bool
+	}{
+		{
+			name: "existing root key",
+			line: 0, col: 2,
+			path: []string{"input"}, onKey: true, existing: true, prefix: "in",
+		},
+		{
+			name: "value of label",
+			line: 1, col: 12,
+			path: []string{"input", "label"}, prefix: "foo", hasValueAt: true,
+		},
+		{
+			name: "within block scalar",
+			line: 4, col: 8,
+			path: []string{"input", "generate", "mapping"},
+		},
+		{
+			name: "new key",
+			line: 5, col: 6,
+			path: []string{"input", "generate"}, onKey: true, prefix: "ty",
+		},
+		{
+			name: "nested key within sibling of list item",
+			line: 9, col: 8,
+			path: []string{"pipeline", "processors", "branch"}, onKey: true, existing: true, prefix: "br",
+		},
+		{
+			name: "deeply nested value",
+			line: 13, col: 27,
+			path: []string{"pipeline", "processors", "branch", "processors", "cache", "resource"}, prefix: "baz", hasValueAt: true,
+		},
+		{
+			name: "new list item",
+			line: 14, col: 12,
+			path: []string{"pipeline", "processors", "branch", "processors"}, onKey: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			cur := cursorAt(lines, test.line, test.col)
+			assert.Equal(t, test.path, cur.path)
+			assert.Equal(t, test.onKey, cur.onKey)
+			assert.Equal(t, test.existing, cur.existingKey)
+			assert.Equal(t, test.prefix, cur.prefix)
+			assert.Equal(t, test.hasValueAt, cur.valueCol >= 0)
+		})
+	}
+}
+
+func TestCharacterColumns(t *testing.T) {
+	line := `a: "🙂 b"`
+	assert.Equal(t, 4, byteColumn(line, 4))
+	assert.Equal(t, 9, byteColumn(line, 7))
+	assert.Equal(t, 7, characterColumn(line, 9))
+	assert.Equal(t, len(line), byteColumn(line, 100))
+}
@@ -0,0 +1,187 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var labelRegexp = regexp.MustCompile(`^(\s*(?:-\s+)*label:\s*)(["']?)([^"'\s#]+)["']?\s*(?:#.*)?$`)
+
+type labelLocation struct {
+	name  string
+	line  int
+	start int
+	end   int
+}
+
+// labelsOf returns the component labels declared within a config document.
+func labelsOf(doc *document) []labelLocation {
+	if doc.isBloblang() {
+		return nil
+	}
+	var labels []labelLocation
+	for i, line := range doc.lines {
+		m := labelRegexp.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		labels = append(labels, labelLocation{
+			name:  line[m[6]:m[7]],
+			line:  i,
+			start: m[6],
+			end:   m[7],
+		})
+	}
+	return labels
+}
+
+var (
+	importRegexp = regexp.MustCompile(`(?:import|from)\s+"([^"]+)"`)
+	applyRegexp  = regexp.MustCompile(`apply\(\s*"([^"]+)"\s*\)`)
+	mapRegexp    = regexp.MustCompile(`(?:^|\s)map\s+([\w]+)\s*\{`)
+)
+
+func (s *Server) definition(doc *document, pos position) []location {
+	line := doc.line(pos.Line)
+	col := byteColumn(line, pos.Character)
+
+	// Bloblang imports resolve to the imported file.
+	for _, m := range importRegexp.FindAllStringSubmatchIndex(line, -1) {
+		if col < m[2] || col > m[3] {
+			continue
+		}
+		if path := s.resolveImport(doc, line[m[2]:m[3]]); path != "" {
+			return []location{{URI: pathToURI(path)}}
+		}
+		return []location{}
+	}
+
+	// Bloblang applications of named maps resolve to the map definition, which
+	// may be within the document or a file that it imports.
+	for _, m := range applyRegexp.FindAllStringSubmatchIndex(line, -1) {
+		if col < m[2] || col > m[3] {
+			continue
+		}
+		return s.mapDefinitions(doc, line[m[2]:m[3]])
+	}
+
+	if doc.isBloblang() {
+		return []location{}
+	}
+
+	// References to resources resolve to components with a matching label.
+	cur := cursorAt(doc.lines, pos.Line, col)
+	if cur.onKey || len(cur.path) == 0 {
+		return []location{}
+	}
+	key := cur.path[len(cur.path)-1]
+	if _, isRef := resourceRefKeys[key]; !isRef && key != "branch_resources" {
+		return []location{}
+	}
+	word, _, _ := wordAt(line, col)
+	if word == "" {
+		return []location{}
+	}
+	return s.labelDefinitions(doc, word)
+}
+
+// sortedDocuments returns the open documents with a given document first.
+func (s *Server) sortedDocuments(first *document) []*document {
+	docs := []*document{first}
+	var others []*document
+	for _, d := range s.documents {
+		if d != first {
+			others = append(others, d)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].uri < others[j].uri
+	})
+	return append(docs, others...)
+}
+
+func (s *Server) labelDefinitions(doc *document, label string) []location {
+	locs := []location{}
+	for _, d := range s.sortedDocuments(doc) {
+		for _, l := range labelsOf(d) {
+			if l.name != label {
+				continue
+			}
+			text := d.line(l.line)
+			locs = append(locs, location{
+				URI: d.uri,
+				Range: lspRange{
+					Start: position{Line: l.line, Character: characterColumn(text, l.start)},
+					End:   position{Line: l.line, Character: characterColumn(text, l.end)},
+				},
+			})
+		}
+	}
+	return locs
+}
+
+// resolveImport returns the path of an imported Bloblang file, which is
+// relative to the importing file, or otherwise relative to the working
+// directory.
+func (s *Server) resolveImport(doc *document, importPath string) string {
+	candidates := []string{importPath}
+	if !filepath.IsAbs(importPath) && doc.path != "" {
+		candidates = append([]string{filepath.Join(filepath.Dir(doc.path), importPath)}, candidates...)
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
+
+func mapLocations(uri string, lines []string, name string) []location {
+	var locs []location
+	for i, line := range lines {
+		for _, m := range mapRegexp.FindAllStringSubmatchIndex(line, -1) {
+			if line[m[2]:m[3]] != name {
+				continue
+			}
+			locs = append(locs, location{
+				URI: uri,
+				Range: lspRange{
+					Start: position{Line: i, Character: characterColumn(line, m[2])},
+					End:   position{Line: i, Character: characterColumn(line, m[3])},
+				},
+			})
+		}
+	}
+	return locs
+}
+
+func (s *Server) mapDefinitions(doc *document, name string) []location {
+	locs := mapLocations(doc.uri, doc.lines, name)
+	if len(locs) > 0 {
+		return locs
+	}
+
+	for _, m := range importRegexp.FindAllStringSubmatch(doc.text, -1) {
+		path := s.resolveImport(doc, m[1])
+		if path == "" {
+			continue
+		}
+		uri := pathToURI(path)
+		if d, exists := s.documents[uri]; exists {
+			locs = append(locs, mapLocations(uri, d.lines, name)...)
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		locs = append(locs, mapLocations(uri, strings.Split(string(b), "\n"), name)...)
+	}
+	if locs == nil {
+		locs = []location{}
+	}
+	return locs
+}
@@ -0,0 +1,97 @@
+package lsp
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+var yamlErrLineRegexp = regexp.MustCompile(`line (\d+):`)
+
+func (s *Server) diagnostics(doc *document) []diagnostic {
+	if doc.isBloblang() {
+		return s.bloblangDiagnostics(doc)
+	}
+	return s.configDiagnostics(doc)
+}
+
+// lineDiagnostic creates a diagnostic that spans a line from a column onwards,
+// where both the line and column are one-indexed.
+func lineDiagnostic(doc *document, line, col, severity int, msg string) diagnostic {
+	if line < 1 {
+		line = 1
+	}
+	if col < 1 {
+		col = 1
+	}
+	text := doc.line(line - 1)
+	startCol := col - 1
+	if startCol > len(text) {
+		startCol = len(text)
+	}
+	return diagnostic{
+		Range: lspRange{
+			Start: position{Line: line - 1, Character: characterColumn(text, startCol)},
+			End:   position{Line: line - 1, Character: characterColumn(text, len(text))},
+		},
+		Severity: severity,
+		Source:   "benthos",
+		Message:  msg,
+	}
+}
+
+func (s *Server) configDiagnostics(doc *document) []diagnostic {
+	diags := []diagnostic{}
+	if strings.HasPrefix(doc.text, "# BENTHOS LINT DISABLE") {
+		return diags
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(doc.text), &node); err != nil {
+		line := 1
+		if m := yamlErrLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return append(diags, lineDiagnostic(doc, line, 1, severityError, err.Error()))
+	}
+
+	lintCtx := docs.NewLintContext()
+	lintCtx.DocsProvider = s.env
+	for _, l := range s.spec.LintYAML(lintCtx, &node) {
+		severity := severityError
+		if l.Level == docs.LintWarning {
+			severity = severityWarning
+		}
+		diags = append(diags, lineDiagnostic(doc, l.Line, l.Column, severity, l.What))
+	}
+	return diags
+}
+
+func (s *Server) bloblangDiagnostics(doc *document) []diagnostic {
+	diags := []diagnostic{}
+
+	env := s.blobl.Deactivated()
+	if doc.path != "" {
+		env = env.WithImporterRelativeToFile(doc.path)
+	}
+	if _, err := env.NewMapping(doc.text); err != nil {
+		line, col := 1, 1
+		var perr *parser.Error
+		if errors.As(err, &perr) {
+			line, col = parser.LineAndColOf([]rune(doc.text), perr.Input)
+			// The column is counted in runes, convert it to a byte column.
+			lineRunes := []rune(doc.line(line - 1))
+			if col-1 < len(lineRunes) {
+				col = len(string(lineRunes[:col-1])) + 1
+			}
+		}
+		diags = append(diags, lineDiagnostic(doc, line, col, severityError, err.Error()))
+	}
+	return diags
+}
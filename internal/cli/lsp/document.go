@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// document is a text document opened by the client, the contents of which are
+// synchronised with each change.
+type document struct {
+	uri     string
+	path    string
+	version int
+	text    string
+	lines   []string
+}
+
+func newDocument(uri, text string, version int) *document {
+	d := &document{
+		uri:     uri,
+		path:    uriToPath(uri),
+		version: version,
+	}
+	d.setText(text)
+	return d
+}
+
+func (d *document) setText(text string) {
+	d.text = text
+	d.lines = strings.Split(text, "\n")
+	for i, l := range d.lines {
+		d.lines[i] = strings.TrimSuffix(l, "\r")
+	}
+}
+
+// isBloblang returns true if the document is a Bloblang mapping file rather
+// than a config.
+func (d *document) isBloblang() bool {
+	return filepath.Ext(d.path) == ".blobl" || filepath.Ext(d.uri) == ".blobl"
+}
+
+func (d *document) line(i int) string {
+	if i < 0 || i >= len(d.lines) {
+		return ""
+	}
+	return d.lines[i]
+}
+
+// applyChange applies a content change event, where an event without a range
+// replaces the full contents of the document.
+func (d *document) applyChange(change textDocumentContentChangeEvent) {
+	if change.Range == nil {
+		d.setText(change.Text)
+		return
+	}
+	start := d.offsetOf(change.Range.Start)
+	end := d.offsetOf(change.Range.End)
+	if end < start {
+		start, end = end, start
+	}
+	d.setText(d.text[:start] + change.Text + d.text[end:])
+}
+
+// offsetOf returns the byte offset within the text of the document of a
+// position.
+func (d *document) offsetOf(p position) int {
+	offset := 0
+	for i := 0; i < p.Line && i < len(d.lines); i++ {
+		offset += len(d.lines[i]) + 1
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset += byteColumn(d.lines[p.Line], p.Character)
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	return offset
+}
+
+// byteColumn converts a character position of a line, which the protocol
+// expresses in UTF-16 code units, into a byte index of the line.
+func byteColumn(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// characterColumn converts a byte index of a line into a character position
+// expressed in UTF-16 code units.
+func characterColumn(line string, byteCol int) int {
+	if byteCol > len(line) {
+		byteCol = len(line)
+	}
+	units := 0
+	for i := 0; i < byteCol; {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		units += len(utf16.Encode([]rune{r}))
+		i += size
+	}
+	return units
+}
+
+func isWordChar(b byte) bool {
+	return b == '_' || b == '-' ||
+		(b >= 'a' && b <= 'z') ||
+		(b >= 'A' && b <= 'Z') ||
+		(b >= '0' && b <= '9')
+}
+
+// wordAt returns the word of a line that surrounds a byte column along with
+// the start and end indexes of the word.
+func wordAt(line string, col int) (word string, start, end int) {
+	if col > len(line) {
+		col = len(line)
+	}
+	start, end = col, col
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordChar(line[end]) {
+		end++
+	}
+	return line[start:end], start, end
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,99 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+func fieldMarkdown(f docs.FieldSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%v** `%v`\n\n", f.Name, fieldDetail(f))
+	if f.IsDeprecated {
+		b.WriteString("_This field is deprecated._\n\n")
+	}
+	if f.Description != "" {
+		b.WriteString(strings.TrimSpace(f.Description))
+		b.WriteString("\n\n")
+	}
+	if f.Interpolated {
+		b.WriteString("This field supports [interpolation functions](https://www.benthos.dev/docs/configuration/interpolation#bloblang-queries).\n\n")
+	}
+	if f.Default != nil {
+		if dBytes, err := json.Marshal(*f.Default); err == nil {
+			fmt.Fprintf(&b, "Default: `%s`\n\n", dBytes)
+		}
+	}
+	if len(f.Options) > 0 {
+		fmt.Fprintf(&b, "Options: `%v`\n\n", strings.Join(f.Options, "`, `"))
+	}
+	for _, opt := range f.AnnotatedOptions {
+		fmt.Fprintf(&b, "- `%v`: %v\n", opt[0], opt[1])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func componentMarkdown(spec docs.ComponentSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%v** `%v`", spec.Name, spec.Type)
+	if spec.Status != "" && spec.Status != docs.StatusStable {
+		fmt.Fprintf(&b, " _%v_", spec.Status)
+	}
+	b.WriteString("\n\n")
+	if spec.Summary != "" {
+		b.WriteString(strings.TrimSpace(spec.Summary))
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "[Documentation](https://www.benthos.dev/docs/components/%vs/%v)", spec.Type, spec.Name)
+	return b.String()
+}
+
+func (s *Server) hover(doc *document, pos position) *hover {
+	line := doc.line(pos.Line)
+	col := byteColumn(line, pos.Character)
+
+	cur := cursorAt(doc.lines, pos.Line, col)
+	if s.inBloblang(doc, cur, line, col) {
+		content := s.bloblangHover(line, col)
+		if content == nil {
+			return nil
+		}
+		_, start, end := identAt(line, col)
+		return &hover{
+			Contents: *content,
+			Range: &lspRange{
+				Start: position{Line: pos.Line, Character: characterColumn(line, start)},
+				End:   position{Line: pos.Line, Character: characterColumn(line, end)},
+			},
+		}
+	}
+
+	if !cur.existingKey {
+		return nil
+	}
+	target, ok := resolvePath(s.env, s.spec, cur.path)
+	if !ok {
+		return nil
+	}
+
+	var content string
+	switch {
+	case target.component != nil:
+		content = componentMarkdown(*target.component)
+	case target.field != nil:
+		content = fieldMarkdown(*target.field)
+	default:
+		return nil
+	}
+
+	l, _ := parseYAMLLine(line)
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: content},
+		Range: &lspRange{
+			Start: position{Line: pos.Line, Character: characterColumn(line, l.keyStart)},
+			End:   position{Line: pos.Line, Character: characterColumn(line, l.keyEnd)},
+		},
+	}
+}
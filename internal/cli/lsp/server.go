@@ -0,0 +1,202 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+// Server implements the Language Server Protocol for Benthos configs and
+// Bloblang mapping files.
+type Server struct {
+	version string
+	env     *bundle.Environment
+	blobl   *bloblang.Environment
+	spec    docs.FieldSpecs
+
+	conn      *conn
+	documents map[string]*document
+	shutdown  bool
+}
+
+// NewServer creates a language server that communicates over the provided
+// reader and writer, with completion and documentation sourced from the
+// components of a bundle environment.
+func NewServer(version string, env *bundle.Environment, blobl *bloblang.Environment, r io.Reader, w io.Writer) *Server {
+	return &Server{
+		version:   version,
+		env:       env,
+		blobl:     blobl,
+		spec:      config.Spec(),
+		conn:      newConn(r, w),
+		documents: map[string]*document{},
+	}
+}
+
+// Serve reads and handles messages until the client requests an exit or the
+// reader is closed.
+func (s *Server) Serve() error {
+	for {
+		req, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var rErr *rpcError
+			if errors.As(err, &rErr) {
+				if err := s.conn.replyError(nil, rErr); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit requested without a prior shutdown request")
+			}
+			return nil
+		}
+
+		result, rErr := s.handle(req)
+		if req.isNotification() {
+			continue
+		}
+		if rErr != nil {
+			err = s.conn.replyError(req.ID, rErr)
+		} else {
+			err = s.conn.reply(req.ID, result)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func decodeParams(req *rpcRequest, v any) *rpcError {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) handle(req *rpcRequest) (any, *rpcError) {
+	if s.shutdown && req.Method != "shutdown" {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: 1,
+				CompletionProvider: completionOptions{
+					TriggerCharacters: []string{".", ":", " "},
+				},
+				HoverProvider:      true,
+				DefinitionProvider: true,
+			},
+			ServerInfo: serverInfo{Name: "benthos", Version: s.version},
+		}, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		doc := newDocument(params.TextDocument.URI, params.TextDocument.Text, params.TextDocument.Version)
+		s.documents[doc.uri] = doc
+		return nil, s.publishDiagnostics(doc)
+
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		doc, exists := s.documents[params.TextDocument.URI]
+		if !exists {
+			return nil, nil
+		}
+		for _, change := range params.ContentChanges {
+			doc.applyChange(change)
+		}
+		return nil, s.publishDiagnostics(doc)
+
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if err := decodeParams(req, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		if err := s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []diagnostic{},
+		}); err != nil {
+			return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		return nil, nil
+
+	case "textDocument/completion":
+		doc, pos, err := s.positionParams(req)
+		if err != nil {
+			return nil, err
+		}
+		return completionList{Items: s.completion(doc, pos)}, nil
+
+	case "textDocument/hover":
+		doc, pos, err := s.positionParams(req)
+		if err != nil {
+			return nil, err
+		}
+		if h := s.hover(doc, pos); h != nil {
+			return h, nil
+		}
+		return nil, nil
+
+	case "textDocument/definition":
+		doc, pos, err := s.positionParams(req)
+		if err != nil {
+			return nil, err
+		}
+		return s.definition(doc, pos), nil
+	}
+
+	if req.isNotification() {
+		// Unsupported notifications such as $/cancelRequest can be ignored.
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %v", req.Method)}
+}
+
+func (s *Server) positionParams(req *rpcRequest) (*document, position, *rpcError) {
+	var params textDocumentPositionParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, position{}, err
+	}
+	doc, exists := s.documents[params.TextDocument.URI]
+	if !exists {
+		return nil, position{}, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("document not open: %v", params.TextDocument.URI)}
+	}
+	return doc, params.Position, nil
+}
+
+func (s *Server) publishDiagnostics(doc *document) *rpcError {
+	if err := s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         doc.uri,
+		Diagnostics: s.diagnostics(doc),
+	}); err != nil {
+		return &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/cli/lsp"
+
+	_ "github.com/benthosdev/benthos/v4/public/components/pure"
+)
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type location struct {
+	URI   string `json:"uri"`
+	Range struct {
+		Start position `json:"start"`
+	} `json:"range"`
+}
+
+type diagnostic struct {
+	Range struct {
+		Start position `json:"start"`
+	} `json:"range"`
+	Message string `json:"message"`
+}
+
+type completionList struct {
+	Items []struct {
+		Label string `json:"label"`
+	} `json:"items"`
+}
+
+type hover struct {
+	Contents struct {
+		Value string `json:"value"`
+	} `json:"contents"`
+}
+
+type testClient struct {
+	t      testing.TB
+	w      io.Writer
+	nextID int
+	msgs   chan map[string]json.RawMessage
+	done   chan error
+}
+
+func newTestClient(t testing.TB) *testClient {
+	t.Helper()
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	s := lsp.NewServer("test", bundle.GlobalEnvironment, bloblang.GlobalEnvironment(), serverR, serverW)
+	c := &testClient{
+		t:    t,
+		w:    clientW,
+		msgs: make(chan map[string]json.RawMessage, 100),
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		c.done <- s.Serve()
+		_ = serverW.Close()
+	}()
+	go func() {
+		defer close(c.msgs)
+		r := textproto.NewReader(bufio.NewReader(clientR))
+		for {
+			header, err := r.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			length, _ := strconv.Atoi(header.Get("Content-Length"))
+			body := make([]byte, length)
+			if _, err := io.ReadFull(r.R, body); err != nil {
+				return
+			}
+			var msg map[string]json.RawMessage
+			if err := json.Unmarshal(body, &msg); err != nil {
+				return
+			}
+			c.msgs <- msg
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = clientW.Close()
+	})
+	return c
+}
+
+func (c *testClient) write(msg map[string]any) {
+	c.t.Helper()
+
+	msg["jsonrpc"] = "2.0"
+	body, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(c.t, err)
+}
+
+func (c *testClient) next() map[string]json.RawMessage {
+	c.t.Helper()
+	select {
+	case msg, open := <-c.msgs:
+		require.True(c.t, open)
+		return msg
+	case <-time.After(time.Second * 5):
+		c.t.Fatal("timed out waiting for message")
+	}
+	return nil
+}
+
+func (c *testClient) request(method string, params, result any) {
+	c.t.Helper()
+
+	c.nextID++
+	c.write(map[string]any{
+		"id":     c.nextID,
+		"method": method,
+		"params": params,
+	})
+
+	msg := c.next()
+	require.NotContains(c.t, msg, "error", string(msg["error"]))
+	require.NoError(c.t, json.Unmarshal(msg["result"], result))
+}
+
+func (c *testClient) open(uri, text string) []diagnostic {
+	c.t.Helper()
+
+	c.write(map[string]any{
+		"method": "textDocument/didOpen",
+		"params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "text": text, "version": 1},
+		},
+	})
+
+	msg := c.next()
+	require.Equal(c.t, `"textDocument/publishDiagnostics"`, string(msg["method"]))
+
+	var params struct {
+		URI         string       `json:"uri"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+	require.NoError(c.t, json.Unmarshal(msg["params"], &params))
+	require.Equal(c.t, uri, params.URI)
+	return params.Diagnostics
+}
+
+func completionLabels(list completionList) []string {
+	var labels []string
+	for _, item := range list.Items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func posParams(uri string, line, char int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     position{Line: line, Character: char},
+	}
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func TestServerConfig(t *testing.T) {
+	c := newTestClient(t)
+
+	var initRes struct {
+		Capabilities struct {
+			HoverProvider bool `json:"hoverProvider"`
+		} `json:"capabilities"`
+		ServerInfo struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	c.request("initialize", map[string]any{}, &initRes)
+	assert.True(t, initRes.Capabilities.HoverProvider)
+	assert.Equal(t, "benthos", initRes.ServerInfo.Name)
+
+	uri := "file:///tmp/config.yaml"
+	diags := c.open(uri, `input:
+  generate:
+    mapping: 'root = this.'
+    nope: true
+  
+
+pipeline:
+  processors:
+    - cache:
+        resource: foo
+        operator: get
+        key: bar
+
+cache_resources:
+  - label: foo
+    memory: {}
+`)
+	require.Len(t, diags, 2)
+	assert.Equal(t, 2, diags[0].Range.Start.Line)
+	assert.Contains(t, diags[0].Message, "expected")
+	assert.Equal(t, 3, diags[1].Range.Start.Line)
+	assert.Contains(t, diags[1].Message, "field nope not recognised")
+
+	var list completionList
+	c.request("textDocument/completion", posParams(uri, 4, 2), &list)
+	assert.Contains(t, completionLabels(list), "generate")
+	assert.NotContains(t, completionLabels(list), "mapping")
+
+	c.request("textDocument/completion", posParams(uri, 3, 4), &list)
+	assert.Contains(t, completionLabels(list), "interval")
+	assert.Contains(t, completionLabels(list), "mapping")
+
+	c.request("textDocument/completion", posParams(uri, 10, 18), &list)
+	assert.Contains(t, completionLabels(list), "get")
+	assert.Contains(t, completionLabels(list), "set")
+
+	c.request("textDocument/completion", posParams(uri, 2, 26), &list)
+	assert.Contains(t, completionLabels(list), "uppercase")
+
+	var h hover
+	c.request("textDocument/hover", posParams(uri, 1, 4), &h)
+	assert.Contains(t, h.Contents.Value, "**generate** `input`")
+	assert.Contains(t, h.Contents.Value, "/docs/components/inputs/generate")
+
+	c.request("textDocument/hover", posParams(uri, 10, 10), &h)
+	assert.Contains(t, h.Contents.Value, "**operator**")
+	assert.Contains(t, h.Contents.Value, "`get`")
+
+	var locs []location
+	c.request("textDocument/definition", posParams(uri, 9, 19), &locs)
+	require.Len(t, locs, 1)
+	assert.Equal(t, uri, locs[0].URI)
+	assert.Equal(t, 14, locs[0].Range.Start.Line)
+	assert.Equal(t, 11, locs[0].Range.Start.Character)
+
+	var shutdownRes any
+	c.request("shutdown", nil, &shutdownRes)
+	c.write(map[string]any{"method": "exit"})
+	select {
+	case err := <-c.done:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for exit")
+	}
+}
+
+func TestServerBloblang(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "common.blobl"), []byte(`
+map upper {
+  root = this.uppercase()
+}
+`), 0o644))
+
+	c := newTestClient(t)
+
+	uri := pathToURI(filepath.Join(tmpDir, "main.blobl"))
+	diags := c.open(uri, `import "./common.blobl"
+
+map local {
+  root.foo = this
+}
+
+root.a = this.a.apply("upper")
+root.b = this.b.apply("local")
+root.c = this.c.upp
+root.d = this.d.not_a_method()
+`)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 9, diags[0].Range.Start.Line)
+
+	var list completionList
+	c.request("textDocument/completion", posParams(uri, 8, 19), &list)
+	assert.Equal(t, []string{"uppercase"}, completionLabels(list))
+
+	var h hover
+	c.request("textDocument/hover", posParams(uri, 6, 16), &h)
+	assert.Contains(t, h.Contents.Value, "apply(mapping: string)")
+
+	var locs []location
+	c.request("textDocument/definition", posParams(uri, 7, 25), &locs)
+	require.Len(t, locs, 1)
+	assert.Equal(t, uri, locs[0].URI)
+	assert.Equal(t, 2, locs[0].Range.Start.Line)
+
+	c.request("textDocument/definition", posParams(uri, 6, 25), &locs)
+	require.Len(t, locs, 1)
+	assert.Equal(t, pathToURI(filepath.Join(tmpDir, "common.blobl")), locs[0].URI)
+	assert.Equal(t, 1, locs[0].Range.Start.Line)
+
+	c.request("textDocument/definition", posParams(uri, 0, 12), &locs)
+	require.Len(t, locs, 1)
+	assert.Equal(t, pathToURI(filepath.Join(tmpDir, "common.blobl")), locs[0].URI)
+}
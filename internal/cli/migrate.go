@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	ifilepath "github.com/benthosdev/benthos/v4/internal/filepath"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+)
+
+func migrateCliCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "Rewrite configs that use deprecated components",
+		Description: `
+Rewrites the provided config files, replacing deprecated components with their
+successors wherever an automatic migration is possible. Comments within the
+configs are preserved, and components that cannot be migrated are reported
+along with the reason why.
+
+Environment variables and other interpolations are left intact. By default the
+migrated configs are printed to stdout, use the --write flag in order to
+rewrite the files in place instead.
+
+  benthos migrate ./config.yaml
+  benthos migrate -w ./configs/*.yaml`[1:],
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "write",
+				Aliases: []string{"w"},
+				Value:   false,
+				Usage:   "Write migrated configs back to their files rather than stdout.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			paths := c.Args().Slice()
+			if len(paths) == 0 && c.String("config") != "" {
+				paths = []string{c.String("config")}
+			}
+			if len(paths) == 0 {
+				fmt.Fprintln(os.Stderr, "Migrate error: at least one config path must be provided")
+				os.Exit(1)
+			}
+
+			targets, err := ifilepath.Globs(ifs.OS(), paths)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Migrate error: failed to resolve paths: %v\n", err)
+				os.Exit(1)
+			}
+
+			var failed bool
+			for i, target := range targets {
+				outBytes, res, err := migrateFile(target)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%v: %v\n", target, err)
+					failed = true
+					continue
+				}
+				for _, r := range res {
+					if r.Err != nil {
+						fmt.Fprintf(os.Stderr, "%v: line %v: unable to migrate %v %v: %v\n", target, r.Line, r.ComponentType, r.From, r.Err)
+						failed = true
+					} else {
+						fmt.Fprintf(os.Stderr, "%v: line %v: migrated %v %v to %v\n", target, r.Line, r.ComponentType, r.From, r.To)
+					}
+				}
+
+				if c.Bool("write") {
+					if len(res) == 0 {
+						continue
+					}
+					if err := os.WriteFile(target, outBytes, 0o644); err != nil {
+						fmt.Fprintf(os.Stderr, "%v: failed to write migrated config: %v\n", target, err)
+						failed = true
+					}
+					continue
+				}
+				if i > 0 {
+					_, _ = os.Stdout.WriteString("---\n")
+				}
+				_, _ = os.Stdout.Write(outBytes)
+			}
+			if failed {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+}
+
+func migrateFile(path string) ([]byte, []docs.MigratedComponent, error) {
+	rawBytes, err := ifs.ReadFile(ifs.OS(), path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(rawBytes, &node); err != nil {
+		return nil, nil, err
+	}
+
+	var res []docs.MigratedComponent
+	if err := config.Spec().MigrateYAML(&node, bundle.GlobalEnvironment, &res); err != nil {
+		return nil, nil, err
+	}
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		// Retain comments attached to the document itself.
+		root := node.Content[0]
+		if root.HeadComment == "" {
+			root.HeadComment = node.HeadComment
+		}
+		if root.FootComment == "" {
+			root.FootComment = node.FootComment
+		}
+		node = *root
+	}
+
+	outBytes, err := config.MarshalYAML(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return outBytes, res, nil
+}
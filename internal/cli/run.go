@@ -11,6 +11,7 @@ import (
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
 	"github.com/benthosdev/benthos/v4/internal/cli/blobl"
+	"github.com/benthosdev/benthos/v4/internal/cli/lsp"
 	"github.com/benthosdev/benthos/v4/internal/cli/studio"
 	clitemplate "github.com/benthosdev/benthos/v4/internal/cli/template"
 	"github.com/benthosdev/benthos/v4/internal/cli/test"
@@ -18,6 +19,8 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/filepath"
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
+	"github.com/benthosdev/benthos/v4/internal/stream/cluster"
 	"github.com/benthosdev/benthos/v4/internal/template"
 )
 
@@ -142,6 +145,10 @@ func Run() {
 			Aliases: []string{"s"},
 			Usage:   "set a field (identified by a dot path) in the main configuration file, e.g. `\"metrics.type=prometheus\"`",
 		},
+		&cli.StringSliceFlag{
+			Name:  "profile",
+			Usage: "select a profile defined within the `profiles` field of config files, where the overlay of each profile is merged on top of the config",
+		},
 		&cli.StringFlag{
 			Name:    "config",
 			Aliases: []string{"c"},
@@ -170,6 +177,7 @@ func Run() {
 			Usage:   "EXPERIMENTAL: watch config files for changes and automatically apply them",
 		},
 	}
+	flags = append(flags, secretsFlags()...)
 	if len(customFlags) > 0 {
 		flags = append(flags, customFlags...)
 	}
@@ -205,6 +213,11 @@ Either run Benthos as a stream processor or choose a command:
 				}
 			}
 
+			if err := initSecretProviders(c); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to initialise secret providers: %v\n", err)
+				os.Exit(1)
+			}
+
 			templatesPaths, err := filepath.Globs(ifs.OS(), c.StringSlice("templates"))
 			if err != nil {
 				fmt.Printf("Failed to resolve template glob pattern: %v\n", err)
@@ -238,6 +251,7 @@ Either run Benthos as a stream processor or choose a command:
 				c.String("config"),
 				c.StringSlice("resources"),
 				c.StringSlice("set"),
+				c.StringSlice("profile"),
 				c.String("log.level"),
 				!c.Bool("chilled"),
 				c.Bool("watcher"),
@@ -245,6 +259,10 @@ Either run Benthos as a stream processor or choose a command:
 				false,
 				false,
 				nil,
+				"",
+				false,
+				false,
+				nil,
 			); code != 0 {
 				os.Exit(code)
 			}
@@ -257,11 +275,12 @@ Either run Benthos as a stream processor or choose a command:
 				Description: `
 This simple command is useful for sanity checking a config if it isn't
 behaving as expected, as it shows you a normalised version after environment
-variables have been resolved:
+variables, included fragments and selected profiles have been resolved:
 
   benthos -c ./config.yaml echo | less`[1:],
 				Action: func(c *cli.Context) error {
-					_, _, confReader := readConfig(c.String("config"), false, c.StringSlice("resources"), nil, c.StringSlice("set"))
+					// Secrets that cannot be resolved are echoed as references.
+					_, _, confReader := readConfig(c.String("config"), false, c.StringSlice("resources"), nil, c.StringSlice("set"), c.StringSlice("profile"), config.OptLenientSecrets())
 					conf := config.New()
 					if _, err := confReader.Read(&conf); err != nil {
 						fmt.Fprintf(os.Stderr, "Configuration file read error: %v\n", err)
@@ -278,7 +297,7 @@ variables have been resolved:
 					if err == nil {
 						var configYAML []byte
 						if configYAML, err = config.MarshalYAML(node); err == nil {
-							fmt.Println(string(configYAML))
+							fmt.Println(string(secrets.RedactBytes(configYAML)))
 						}
 					}
 					if err != nil {
@@ -289,6 +308,9 @@ variables have been resolved:
 				},
 			},
 			lintCliCommand(),
+			secretsCliCommand(),
+			graphCliCommand(),
+			migrateCliCommand(),
 			{
 				Name:  "streams",
 				Usage: "Run Benthos in streams mode",
@@ -319,12 +341,63 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 						Value: true,
 						Usage: "Whether HTTP endpoints registered by stream configs should be prefixed with the stream ID",
 					},
+					&cli.StringFlag{
+						Name:  "store",
+						Value: "",
+						Usage: "Persist the configs of streams created via the API along with their version history, in the form `dir:./path`, `sqlite:./file.db` or `cache:resource_name`",
+					},
+					&cli.BoolFlag{
+						Name:  "restore",
+						Value: false,
+						Usage: "Restore streams from the stream store at startup, streams loaded from config files take precedence",
+					},
+					&cli.BoolFlag{
+						Name:  "api-file-secrets",
+						Value: false,
+						Usage: "Allow stream configs submitted via the HTTP API to reference secrets of the file provider",
+					},
+					&cli.StringFlag{
+						Name:  "cluster-cache",
+						Value: "",
+						Usage: "Run as a node of a cluster that shares streams across multiple instances, where the state of the cluster is held within the named cache resource, which must support atomic operations and key iteration",
+					},
+					&cli.StringFlag{
+						Name:  "cluster-node-id",
+						Value: "",
+						Usage: "A unique identifier of this node within the cluster, defaults to the hostname",
+					},
+					&cli.StringFlag{
+						Name:  "cluster-address",
+						Value: "",
+						Usage: "The base URL at which other nodes of the cluster can reach the HTTP server of this node, defaults to the hostname and the port of the http.address field",
+					},
+					&cli.StringFlag{
+						Name:  "cluster-secret",
+						Value: "",
+						Usage: "A secret shared by all nodes of the cluster used to authenticate requests forwarded between nodes, which is required when running as a cluster",
+					},
+					&cli.IntFlag{
+						Name:  "cluster-replicas",
+						Value: 1,
+						Usage: "The number of nodes of the cluster that each stream is assigned to",
+					},
 				},
 				Action: func(c *cli.Context) error {
+					var clusterConf *cluster.Config
+					if cacheName := c.String("cluster-cache"); cacheName != "" {
+						cConf := cluster.NewConfig()
+						cConf.Cache = cacheName
+						cConf.NodeID = c.String("cluster-node-id")
+						cConf.Address = c.String("cluster-address")
+						cConf.Secret = c.String("cluster-secret")
+						cConf.Replicas = c.Int("cluster-replicas")
+						clusterConf = &cConf
+					}
 					os.Exit(cmdService(
 						c.String("config"),
 						c.StringSlice("resources"),
 						c.StringSlice("set"),
+						c.StringSlice("profile"),
 						c.String("log.level"),
 						!c.Bool("chilled"),
 						c.Bool("watcher"),
@@ -332,6 +405,10 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 						c.Bool("prefix-stream-endpoints"),
 						true,
 						c.Args().Slice(),
+						c.String("store"),
+						c.Bool("restore"),
+						c.Bool("api-file-secrets"),
+						clusterConf,
 					))
 					return nil
 				},
@@ -341,6 +418,7 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 			test.CliCommand(testSuffix),
 			clitemplate.CliCommand(),
 			blobl.CliCommand(),
+			lsp.CliCommand(Version),
 			studio.CliCommand(Version, DateBuilt),
 		},
 	}
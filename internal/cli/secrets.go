@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
+)
+
+func secretsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "secrets-dir",
+			Value: "",
+			Usage: "a directory from which relative paths of the `file` secret provider are resolved, e.g. a mounted Kubernetes secret",
+		},
+		&cli.StringFlag{
+			Name:  "secrets-file",
+			Value: "",
+			Usage: "a file of secrets encrypted with a master key, which can be referenced with the `encrypted` secret provider",
+		},
+		&cli.StringFlag{
+			Name:  "master-key-file",
+			Value: "",
+			Usage: "a file containing the master key of the encrypted secrets file, if omitted the key is read from the environment variable " + secrets.MasterKeyEnv,
+		},
+	}
+}
+
+func readMasterKey(c *cli.Context) ([]byte, error) {
+	if keyFile := c.String("master-key-file"); keyFile != "" {
+		return ifs.ReadFile(ifs.OS(), keyFile)
+	}
+	if key := os.Getenv(secrets.MasterKeyEnv); key != "" {
+		return []byte(key), nil
+	}
+	return nil, secrets.ErrNoMasterKey
+}
+
+func initSecretProviders(c *cli.Context) error {
+	if dir := c.String("secrets-dir"); dir != "" {
+		if err := secrets.Register("file", secrets.NewFileProvider(dir)); err != nil {
+			return err
+		}
+	}
+	if secretsFile := c.String("secrets-file"); secretsFile != "" {
+		var masterKey []byte
+		if keyFile := c.String("master-key-file"); keyFile != "" {
+			var err error
+			if masterKey, err = ifs.ReadFile(ifs.OS(), keyFile); err != nil {
+				return fmt.Errorf("failed to read master key file: %w", err)
+			}
+		}
+		if err := secrets.Register("encrypted", secrets.NewEncryptedFileProvider(secretsFile, masterKey)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func secretsCliCommand() *cli.Command {
+	cryptAction := func(fn func(masterKey, data []byte) ([]byte, error)) func(c *cli.Context) error {
+		return func(c *cli.Context) error {
+			masterKey, err := readMasterKey(c)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read master key: %v\n", err)
+				os.Exit(1)
+			}
+			inBytes, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read stdin: %v\n", err)
+				os.Exit(1)
+			}
+			outBytes, err := fn(masterKey, inBytes)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			_, _ = os.Stdout.Write(outBytes)
+			return nil
+		}
+	}
+
+	return &cli.Command{
+		Name:  "secrets",
+		Usage: "Manage files of secrets encrypted with a master key",
+		Description: `
+Generate master keys and encrypt or decrypt files of secrets, which can then be
+referenced within configs with the encrypted secret provider. The master key is
+read from the file specified with --master-key-file, or from the environment
+variable BENTHOS_MASTER_KEY.
+
+  benthos secrets keygen > ./master.key
+  benthos --master-key-file ./master.key secrets encrypt < ./secrets.yaml > ./secrets.enc
+  benthos --master-key-file ./master.key secrets decrypt < ./secrets.enc
+
+For more information check out the docs at:
+https://benthos.dev/docs/configuration/secrets`[1:],
+		Subcommands: []*cli.Command{
+			{
+				Name:  "keygen",
+				Usage: "Generate a random master key",
+				Action: func(c *cli.Context) error {
+					key, err := secrets.NewMasterKey()
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Failed to generate master key: %v\n", err)
+						os.Exit(1)
+					}
+					fmt.Println(key)
+					return nil
+				},
+			},
+			{
+				Name:   "encrypt",
+				Usage:  "Encrypt a YAML or JSON document of secrets read from stdin",
+				Action: cryptAction(secrets.Encrypt),
+			},
+			{
+				Name:   "decrypt",
+				Usage:  "Decrypt a document of secrets read from stdin",
+				Action: cryptAction(secrets.Decrypt),
+			},
+		},
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime/pprof"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -21,6 +23,8 @@ import (
 
 	"github.com/benthosdev/benthos/v4/internal/api"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/bundle/tap"
+	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
@@ -28,7 +32,9 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 	"github.com/benthosdev/benthos/v4/internal/stream"
+	"github.com/benthosdev/benthos/v4/internal/stream/cluster"
 	strmmgr "github.com/benthosdev/benthos/v4/internal/stream/manager"
 )
 
@@ -40,7 +46,7 @@ type stoppable interface {
 
 //------------------------------------------------------------------------------
 
-func readConfig(path string, streamsMode bool, resourcesPaths, streamsPaths, overrides []string) (mainPath string, inferred bool, conf *config.Reader) {
+func readConfig(path string, streamsMode bool, resourcesPaths, streamsPaths, overrides, profiles []string, extraOpts ...config.OptFunc) (mainPath string, inferred bool, conf *config.Reader) {
 	if path == "" {
 		// Iterate default config paths
 		for _, dpath := range []string{
@@ -57,23 +63,53 @@ func readConfig(path string, streamsMode bool, resourcesPaths, streamsPaths, ove
 	}
 	opts := []config.OptFunc{
 		config.OptAddOverrides(overrides...),
+		config.OptSetProfiles(profiles...),
 		config.OptTestSuffix(testSuffix),
 	}
 	if streamsMode {
 		opts = append(opts, config.OptSetStreamPaths(streamsPaths...))
 	}
+	opts = append(opts, extraOpts...)
 	return path, inferred, config.NewReader(path, resourcesPaths, opts...)
 }
 
 //------------------------------------------------------------------------------
 
 func initStreamsMode(
-	strict, watching, enableAPI bool,
+	strict, watching, enableAPI, apiFileSecrets bool,
+	storeURI string, restore bool,
+	clusterConf *cluster.Config,
 	confReader *config.Reader,
 	mgr *manager.Type,
+	taps *tap.Registry,
 ) stoppable {
 	logger := mgr.Logger()
-	streamMgr := strmmgr.New(mgr, strmmgr.OptAPIEnabled(enableAPI))
+	if clusterConf != nil {
+		if storeURI != "" || restore {
+			logger.Errorln("A stream store cannot be used in cluster mode, as streams are persisted within the cluster cache")
+			os.Exit(1)
+		}
+		return initClusterMode(strict, watching, enableAPI, apiFileSecrets, *clusterConf, confReader, mgr, taps)
+	}
+	streamMgrOpts := []func(*strmmgr.Type){
+		strmmgr.OptAPIEnabled(enableAPI),
+		strmmgr.OptPausableStreams(enableAPI),
+		strmmgr.OptAPIFileSecrets(apiFileSecrets),
+		strmmgr.OptTapRegistry(taps),
+		strmmgr.OptSwappablePipelines(watching),
+	}
+	if storeURI != "" {
+		store, err := strmmgr.NewStoreFromURI(mgr, storeURI)
+		if err != nil {
+			logger.Errorf("Failed to create stream store: %v\n", err)
+			os.Exit(1)
+		}
+		streamMgrOpts = append(streamMgrOpts, strmmgr.OptStore(store))
+	} else if restore {
+		logger.Errorln("Restoring streams requires a stream store to be specified with --store")
+		os.Exit(1)
+	}
+	streamMgr := strmmgr.New(mgr, streamMgrOpts...)
 
 	streamConfs := map[string]stream.Config{}
 	lints, err := confReader.ReadStreams(streamConfs)
@@ -100,6 +136,15 @@ func initStreamsMode(
 			os.Exit(1)
 		}
 	}
+	if restore {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		err := streamMgr.Restore(ctx)
+		done()
+		if err != nil {
+			logger.Errorf("Failed to restore streams: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	logger.Infoln("Launching benthos in streams mode, use CTRL+C to close")
 
 	if err := confReader.SubscribeStreamChanges(func(id string, newStreamConf *stream.Config) error {
@@ -135,6 +180,11 @@ type swappableStopper struct {
 	stopped bool
 	current stoppable
 	mut     sync.Mutex
+
+	// Used in order to restart the stream when it is resumed after being
+	// drained.
+	restart  func() (stoppable, error)
+	draining uint32
 }
 
 func (s *swappableStopper) Stop(ctx context.Context) error {
@@ -149,7 +199,12 @@ func (s *swappableStopper) Stop(ctx context.Context) error {
 	return s.current.Stop(ctx)
 }
 
-func (s *swappableStopper) Replace(fn func() (stoppable, error)) error {
+// Replace stops the active stream and replaces it with a new stream created
+// with fn. The active stream is stopped first so that the two never run at the
+// same time, which would otherwise result in duplicate consumption and
+// conflicting resources such as bound ports. When fn fails the stream is
+// restored with fallback.
+func (s *swappableStopper) Replace(fn, fallback func() (stoppable, error)) error {
 	s.mut.Lock()
 	defer s.mut.Unlock()
 
@@ -162,18 +217,133 @@ func (s *swappableStopper) Replace(fn func() (stoppable, error)) error {
 	defer done()
 
 	if err := s.current.Stop(ctx); err != nil {
-		return fmt.Errorf("failed to stop active stream: %w", err)
+		return fmt.Errorf("failed to stop previous stream: %w", err)
 	}
+	atomic.StoreUint32(&s.draining, 0)
 
 	newStoppable, err := fn()
+	if err == nil {
+		s.current = newStoppable
+		return nil
+	}
+
+	prevStoppable, fErr := fallback()
+	if fErr != nil {
+		return fmt.Errorf("failed to init updated stream: %w, and failed to restore previous stream: %v", err, fErr)
+	}
+	s.current = prevStoppable
+	return fmt.Errorf("failed to init updated stream, previous stream restored: %w", err)
+}
+
+// Update attempts to apply a new config to the active stream without
+// restarting it, returning stream.ErrUpdateRequiresRestart if this is not
+// possible.
+func (s *swappableStopper) Update(conf stream.Config) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.stopped {
+		return nil
+	}
+
+	updater, ok := s.current.(interface {
+		Update(ctx context.Context, conf stream.Config) error
+	})
+	if !ok {
+		return stream.ErrUpdateRequiresRestart
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	return updater.Update(ctx, conf)
+}
+
+// Draining reports whether the active stream is being closed as a result of a
+// drain, in which case the service should continue running.
+func (s *swappableStopper) Draining() bool {
+	return atomic.LoadUint32(&s.draining) == 1
+}
+
+func (s *swappableStopper) controller() (stream.Controller, error) {
+	if s.stopped {
+		return nil, component.ErrTypeClosed
+	}
+	c, ok := s.current.(stream.Controller)
+	if !ok {
+		return nil, errors.New("stream does not support control actions")
+	}
+	return c, nil
+}
+
+// Pause the consumption of messages by the active stream.
+func (s *swappableStopper) Pause() error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	c, err := s.controller()
 	if err != nil {
-		return fmt.Errorf("failed to init updated stream: %w", err)
+		return err
 	}
+	return c.Pause()
+}
 
+// Resume the active stream, or restart it if it has been drained.
+func (s *swappableStopper) Resume(ctx context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	c, err := s.controller()
+	if err != nil {
+		return err
+	}
+	if err = c.Resume(ctx); !errors.Is(err, stream.ErrDrained) {
+		return err
+	}
+
+	// A drain that timed out leaves the stream shutting down, which must be
+	// finished before a new stream is started so that the two never overlap.
+	if err := s.current.Stop(ctx); err != nil {
+		return fmt.Errorf("failed to close drained stream: %w", err)
+	}
+
+	newStoppable, err := s.restart()
+	if err != nil {
+		return fmt.Errorf("failed to restart drained stream: %w", err)
+	}
 	s.current = newStoppable
+	atomic.StoreUint32(&s.draining, 0)
 	return nil
 }
 
+// Drain the active stream, blocking until all buffered and in-flight messages
+// are delivered. The lock is not held whilst waiting, so that the state of the
+// stream can be queried and other actions taken during a drain.
+func (s *swappableStopper) Drain(ctx context.Context) error {
+	s.mut.Lock()
+	c, err := s.controller()
+	if err != nil {
+		s.mut.Unlock()
+		return err
+	}
+	atomic.StoreUint32(&s.draining, 1)
+	s.mut.Unlock()
+
+	return c.Drain(ctx)
+}
+
+// State returns the state of the active stream.
+func (s *swappableStopper) State() stream.State {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	c, err := s.controller()
+	if err != nil {
+		return stream.StateClosed
+	}
+	return c.State()
+}
+
 func initNormalMode(
 	conf config.Type,
 	strict, watching bool,
@@ -182,16 +352,27 @@ func initNormalMode(
 ) (newStream stoppable, stoppedChan chan struct{}) {
 	logger := mgr.Logger()
 
+	var stoppableStream swappableStopper
+
 	stoppedChan = make(chan struct{})
 	streamInit := func() (stoppable, error) {
-		return stream.New(conf.Config, mgr, stream.OptOnClose(func() {
-			if !watching {
-				close(stoppedChan)
-			}
-		}))
+		opts := []func(*stream.Type){
+			stream.OptOnClose(func() {
+				if !watching && !stoppableStream.Draining() {
+					close(stoppedChan)
+				}
+			}),
+		}
+		if watching {
+			opts = append(opts, stream.OptSwappablePipeline())
+		}
+		if conf.HTTP.Enabled {
+			// Pausing is only possible via the API.
+			opts = append(opts, stream.OptPausable())
+		}
+		return stream.New(conf.Config, mgr, opts...)
 	}
-
-	var stoppableStream swappableStopper
+	stoppableStream.restart = streamInit
 
 	var err error
 	if stoppableStream.current, err = streamInit(); err != nil {
@@ -200,10 +381,33 @@ func initNormalMode(
 	}
 	logger.Infoln("Launching a benthos instance, use CTRL+C to close")
 
+	for _, action := range []string{"pause", "resume", "drain"} {
+		action := action
+		mgr.RegisterEndpoint("/"+action, stream.ControlDescriptions[action], func(w http.ResponseWriter, r *http.Request) {
+			stream.ServeControl(w, r, &stoppableStream, action)
+		})
+	}
+
 	if err := confReader.SubscribeConfigChanges(func(newStreamConf stream.Config) error {
+		// Attempt to swap the processing pipeline in place first, which keeps
+		// the input and output connected.
+		err := stoppableStream.Update(newStreamConf)
+		if err == nil {
+			conf.Config = newStreamConf
+			logger.Infoln("Applied config changes to the running pipeline without a restart")
+			return nil
+		}
+		if !errors.Is(err, stream.ErrUpdateRequiresRestart) {
+			return err
+		}
+
+		prevConfig := conf.Config
 		return stoppableStream.Replace(func() (stoppable, error) {
 			conf.Config = newStreamConf
 			return streamInit()
+		}, func() (stoppable, error) {
+			conf.Config = prevConfig
+			return streamInit()
 		})
 	}); err != nil {
 		logger.Errorf("Failed to create config file watcher: %v", err)
@@ -221,16 +425,108 @@ func initNormalMode(
 	return
 }
 
+func initClusterMode(
+	strict, watching, enableAPI, apiFileSecrets bool,
+	clusterConf cluster.Config,
+	confReader *config.Reader,
+	mgr *manager.Type,
+	taps *tap.Registry,
+) stoppable {
+	logger := mgr.Logger()
+	streamMgr := strmmgr.New(
+		mgr,
+		strmmgr.OptAPIEnabled(false),
+		strmmgr.OptPausableStreams(enableAPI),
+		strmmgr.OptAPIFileSecrets(apiFileSecrets),
+		strmmgr.OptTapRegistry(taps),
+		strmmgr.OptSwappablePipelines(true),
+	)
+
+	node, err := cluster.New(mgr, streamMgr, clusterConf, cluster.OptAPIEnabled(enableAPI))
+	if err != nil {
+		logger.Errorf("Failed to join cluster: %v\n", err)
+		os.Exit(1)
+	}
+
+	streamConfs := map[string]stream.Config{}
+	lints, err := confReader.ReadStreams(streamConfs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Stream configuration file read error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, lint := range lints {
+		if strict {
+			logger.With("lint", lint).Errorln("Config lint error")
+		} else {
+			logger.With("lint", lint).Warnln("Config lint error")
+		}
+	}
+	if strict && len(lints) > 0 {
+		logger.Errorln("Shutting down due to stream linter errors, to prevent shutdown run Benthos with --chilled")
+		os.Exit(1)
+	}
+
+	// Streams from config files are added to the registry of the cluster,
+	// replacing any existing streams of the same id.
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	for id, conf := range streamConfs {
+		if err := node.Put(ctx, id, conf); err != nil {
+			logger.Errorf("Failed to add stream (%v) to cluster: %v\n", id, err)
+			os.Exit(1)
+		}
+	}
+	done()
+	logger.Infof("Launching benthos in streams mode as cluster node '%v', use CTRL+C to close", clusterConf.NodeID)
+
+	if err := confReader.SubscribeStreamChanges(func(id string, newStreamConf *stream.Config) error {
+		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		defer done()
+
+		if newStreamConf != nil {
+			return node.Put(ctx, id, *newStreamConf)
+		}
+		return node.Remove(ctx, id)
+	}); err != nil {
+		logger.Errorf("Failed to create stream config watcher: %v", err)
+		os.Exit(1)
+	}
+
+	if watching {
+		if err := confReader.BeginFileWatching(mgr, strict); err != nil {
+			logger.Errorf("Failed to create stream config watcher: %v", err)
+			os.Exit(1)
+		}
+	}
+	return node
+}
+
+// clusterAddress returns the default address at which other nodes of a
+// cluster can reach this node, which is derived from the hostname of the
+// machine and the port of the HTTP server.
+func clusterAddress(httpAddress, hostname string) string {
+	_, port, err := net.SplitHostPort(httpAddress)
+	if err != nil {
+		return "http://" + hostname
+	}
+	return "http://" + net.JoinHostPort(hostname, port)
+}
+
 func cmdService(
 	confPath string,
 	resourcesPaths []string,
 	confOverrides []string,
+	profiles []string,
 	overrideLogLevel string,
 	strict, watching, enableStreamsAPI, namespaceStreamEndpoints bool,
 	streamsMode bool,
 	streamsPaths []string,
+	streamsStore string,
+	streamsRestore bool,
+	streamsAPIFileSecrets bool,
+	clusterConf *cluster.Config,
 ) int {
-	mainPath, inferredMainPath, confReader := readConfig(confPath, streamsMode, resourcesPaths, streamsPaths, confOverrides)
+	mainPath, inferredMainPath, confReader := readConfig(confPath, streamsMode, resourcesPaths, streamsPaths, confOverrides, profiles)
 	conf := config.New()
 
 	lints, err := confReader.Read(&conf)
@@ -266,15 +562,15 @@ func cmdService(
 			}
 		}
 		if err == nil {
-			logger, err = log.NewV2(writer, conf.Logger)
+			logger, err = log.NewV2(secrets.RedactWriter(writer), conf.Logger)
 		}
 	} else {
 		// Note: Only log to Stderr if our output is stdout, brokers aren't counted
 		// here as this is only a special circumstance for very basic use cases.
 		if !streamsMode && conf.Output.Type == "stdout" {
-			logger, err = log.NewV2(os.Stderr, conf.Logger)
+			logger, err = log.NewV2(secrets.RedactWriter(os.Stderr), conf.Logger)
 		} else {
-			logger, err = log.NewV2(os.Stdout, conf.Logger)
+			logger, err = log.NewV2(secrets.RedactWriter(os.Stdout), conf.Logger)
 		}
 	}
 	if err != nil {
@@ -348,8 +644,19 @@ func cmdService(
 	if err != nil {
 		logger.Warnf("Failed to generate sanitised config: %v\n", err)
 	}
+
+	// Components are only wrapped for tapping when taps are explicitly enabled.
+	env := bundle.GlobalEnvironment
+	var taps *tap.Registry
+	var apiOpts []api.OptFunc
+	if conf.HTTP.Taps.Enabled {
+		taps = tap.NewRegistry()
+		env = tap.TappedBundle(env, taps)
+		apiOpts = append(apiOpts, api.OptWithTaps(taps))
+	}
+
 	var httpServer *api.Type
-	if httpServer, err = api.New(Version, DateBuilt, conf.HTTP, sanitNode, logger, stats); err != nil {
+	if httpServer, err = api.New(Version, DateBuilt, conf.HTTP, sanitNode, logger, stats, apiOpts...); err != nil {
 		logger.Errorf("Failed to initialise API: %v\n", err)
 		return 1
 	}
@@ -363,18 +670,32 @@ func cmdService(
 		manager.OptSetMetrics(stats),
 		manager.OptSetTracer(trac),
 		manager.OptSetStreamsMode(streamsMode),
+		manager.OptSetEnvironment(env),
 	)
 	if err != nil {
 		logger.Errorf("Failed to create resource: %v\n", err)
 		return 1
 	}
+	if taps != nil {
+		taps.SetBloblEnvironment(manager.BloblEnvironment())
+	}
 
 	var stoppableStream stoppable
 	var dataStreamClosedChan chan struct{}
 
+	if clusterConf != nil {
+		hostname, _ := os.Hostname()
+		if clusterConf.NodeID == "" {
+			clusterConf.NodeID = hostname
+		}
+		if clusterConf.Address == "" {
+			clusterConf.Address = clusterAddress(conf.HTTP.Address, hostname)
+		}
+	}
+
 	// Create data streams.
 	if streamsMode {
-		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, confReader, manager)
+		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, streamsAPIFileSecrets, streamsStore, streamsRestore, clusterConf, confReader, manager, taps)
 	} else {
 		stoppableStream, dataStreamClosedChan = initNormalMode(conf, strict, watching, confReader, manager)
 	}
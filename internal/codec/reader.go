@@ -15,10 +15,12 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/Jeffail/gabs/v2"
 	"github.com/klauspost/compress/gzip"
 
 	goavro "github.com/linkedin/goavro/v2"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/public/service"
@@ -36,6 +38,8 @@ var ReaderDocs = docs.FieldString(
 	"csv:x", "Consume structured rows as values separated by a custom delimiter, the first row must be a header row. The custom delimiter must be a single character, e.g. the codec `\"csv:\\t\"` would consume a tab delimited file.",
 	"delim:x", "Consume the file in segments divided by a custom delimiter.",
 	"gzip", "Decompress a gzip file, this codec should precede another codec, e.g. `gzip/all-bytes`, `gzip/tar`, `gzip/csv`, etc.",
+	"json_array", "Consume the file as a JSON array and read each element as a message. Elements are decoded one at a time, and therefore large arrays are never held in memory as a whole.",
+	"json_array:x", "Consume each element of a JSON array found at a dot path of object keys as a message, e.g. the codec `json_array:data.items` consumes the elements of the array at `data.items`. The fields of parent objects that precede the array are added to each message as a metadata field called `json_array_context`.",
 	"lines", "Consume the file in segments divided by linebreaks.",
 	"multipart", "Consumes the output of another codec and batches messages together. A batch ends when an empty message is consumed. For example, the codec `lines/multipart` could be used to consume multipart messages where an empty line indicates the end of each batch.",
 	"regex:(?m)^\\d\\d:\\d\\d:\\d\\d", "Consume the file in segments divided by regular expression.",
@@ -227,6 +231,10 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 		}, true, nil
 	case "tar":
 		return newTarReader, true, nil
+	case "json_array":
+		return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newJSONArrayReader(r, nil, fn)
+		}, true, nil
 	}
 
 	if strings.HasPrefix(codec, "avro-ocf:") {
@@ -268,6 +276,16 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 			return newCSVReader(r, fn, &byRune)
 		}, true, nil
 	}
+	if strings.HasPrefix(codec, "json_array:") {
+		by := strings.TrimPrefix(codec, "json_array:")
+		if by == "" {
+			return nil, false, errors.New("json_array codec requires a non-empty path")
+		}
+		path := gabs.DotPathToSlice(by)
+		return func(_ string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newJSONArrayReader(r, path, fn)
+		}, true, nil
+	}
 	if strings.HasPrefix(codec, "chunker:") {
 		chunkSize, err := strconv.ParseInt(strings.TrimPrefix(codec, "chunker:"), 10, 64)
 		if err != nil {
@@ -661,6 +679,81 @@ func (a *csvReader) Close(ctx context.Context) error {
 
 //------------------------------------------------------------------------------
 
+type jsonArrayReader struct {
+	iter      *query.JSONArrayIterator
+	withCtx   bool
+	r         io.ReadCloser
+	sourceAck ReaderAckFn
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newJSONArrayReader(r io.ReadCloser, path []string, ackFn ReaderAckFn) (Reader, error) {
+	return &jsonArrayReader{
+		iter:      query.NewJSONArrayIterator(r, path),
+		withCtx:   len(path) > 0,
+		r:         r,
+		sourceAck: ackOnce(ackFn),
+	}, nil
+}
+
+func (a *jsonArrayReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+func (a *jsonArrayReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
+	element, err := a.iter.NextRaw()
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if err != nil {
+		if errors.Is(err, query.ErrEndOfIter) {
+			a.finished = true
+			err = io.EOF
+		} else {
+			_ = a.sourceAck(ctx, err)
+		}
+		return nil, nil, err
+	}
+
+	a.pending++
+
+	part := message.NewPart(element)
+	if a.withCtx {
+		part.MetaSetMut("json_array_context", a.iter.Context())
+	}
+	return []*message.Part{part}, a.ack, nil
+}
+
+func (a *jsonArrayReader) Close(ctx context.Context) error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	return a.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
 type customDelimReader struct {
 	buf       *bufio.Scanner
 	r         io.ReadCloser
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -771,3 +772,40 @@ func TestRegexpSplitReader(t *testing.T) {
 	data = []byte("")
 	testReaderSuite(t, "regex:split", "", data)
 }
+
+func TestJSONArrayReader(t *testing.T) {
+	data := []byte(`[{"id":1}, "foo", [1,2]]`)
+	testReaderSuite(t, "json_array", "", data, `{"id":1}`, `"foo"`, `[1,2]`)
+
+	data = []byte(`[]`)
+	testReaderSuite(t, "json_array", "", data)
+
+	data = []byte(`{"source":"foo","data":{"items":[{"id":1},{"id":2}]}}`)
+	testReaderSuite(t, "json_array:data.items", "", data, `{"id":1}`, `{"id":2}`)
+}
+
+func TestJSONArrayReaderContext(t *testing.T) {
+	ctor, err := GetReader("json_array:data.items", NewReaderConfig())
+	require.NoError(t, err)
+
+	data := []byte(`{"source":"foo","data":{"page":2,"items":[{"id":1}]}}`)
+	r, err := ctor("", noopCloser{bytes.NewReader(data), false}, func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	p, _, err := r.Next(context.Background())
+	require.NoError(t, err)
+	require.Len(t, p, 1)
+
+	v, exists := p[0].MetaGetMut("json_array_context")
+	require.True(t, exists)
+	assert.Equal(t, map[string]any{
+		"source": "foo",
+		"data":   map[string]any{"page": json.Number("2")},
+	}, v)
+
+	_, _, err = r.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+	require.NoError(t, r.Close(context.Background()))
+}
@@ -31,6 +31,20 @@ type metricsCache struct {
 	mDelError   metrics.StatCounter
 	mDelSuccess metrics.StatCounter
 	mDelLatency metrics.StatTimer
+
+	mGetMultiError   metrics.StatCounter
+	mGetMultiSuccess metrics.StatCounter
+	mGetMultiLatency metrics.StatTimer
+
+	mIncrError   metrics.StatCounter
+	mIncrSuccess metrics.StatCounter
+	mIncrLatency metrics.StatTimer
+
+	mCASNotFound metrics.StatCounter
+	mCASMismatch metrics.StatCounter
+	mCASError    metrics.StatCounter
+	mCASSuccess  metrics.StatCounter
+	mCASLatency  metrics.StatTimer
 }
 
 // MetricsForCache wraps a cache with a struct that adds standard metrics over
@@ -60,6 +74,20 @@ func MetricsForCache(c V1, stats metrics.Type) V1 {
 		mDelError:   cacheError.With("delete"),
 		mDelSuccess: cacheSuccess.With("delete"),
 		mDelLatency: cacheLatency.With("delete"),
+
+		mGetMultiError:   cacheError.With("get_multi"),
+		mGetMultiSuccess: cacheSuccess.With("get_multi"),
+		mGetMultiLatency: cacheLatency.With("get_multi"),
+
+		mIncrError:   cacheError.With("incr"),
+		mIncrSuccess: cacheSuccess.With("incr"),
+		mIncrLatency: cacheLatency.With("incr"),
+
+		mCASNotFound: stats.GetCounterVec("cache_not_found", "operation").With("compare_and_swap"),
+		mCASMismatch: stats.GetCounterVec("cache_mismatch", "operation").With("compare_and_swap"),
+		mCASError:    cacheError.With("compare_and_swap"),
+		mCASSuccess:  cacheSuccess.With("compare_and_swap"),
+		mCASLatency:  cacheLatency.With("compare_and_swap"),
 	}
 }
 
@@ -131,6 +159,53 @@ func (a *metricsCache) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+func (a *metricsCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	started := time.Now()
+	res, err := a.c.GetMulti(ctx, keys...)
+	a.mGetMultiLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mGetMultiError.Incr(1)
+	} else {
+		a.mGetMultiSuccess.Incr(1)
+	}
+	return res, err
+}
+
+func (a *metricsCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	started := time.Now()
+	v, err := a.c.Incr(ctx, key, delta, ttl)
+	a.mIncrLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mIncrError.Incr(1)
+	} else {
+		a.mIncrSuccess.Incr(1)
+	}
+	return v, err
+}
+
+func (a *metricsCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	started := time.Now()
+	err := a.c.CompareAndSwap(ctx, key, old, value, ttl)
+	a.mCASLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		switch {
+		case errors.Is(err, component.ErrKeyNotFound):
+			a.mCASNotFound.Incr(1)
+		case errors.Is(err, component.ErrKeyValueMismatch):
+			a.mCASMismatch.Incr(1)
+		default:
+			a.mCASError.Incr(1)
+		}
+	} else {
+		a.mCASSuccess.Incr(1)
+	}
+	return err
+}
+
+func (a *metricsCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	return a.c.IterKeys(ctx, prefix, fn)
+}
+
 func (a *metricsCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
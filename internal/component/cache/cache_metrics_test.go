@@ -76,6 +76,31 @@ func (c *closableCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (c *closableCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	res := map[string][]byte{}
+	for _, k := range keys {
+		if i, ok := c.m[k]; ok {
+			res[k] = i.b
+		}
+	}
+	return res, nil
+}
+
+func (c *closableCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	return 0, component.ErrCacheOpNotSupported
+}
+
+func (c *closableCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	return component.ErrCacheOpNotSupported
+}
+
+func (c *closableCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	return component.ErrCacheOpNotSupported
+}
+
 func (c *closableCache) Close(ctx context.Context) error {
 	c.closed = true
 	return nil
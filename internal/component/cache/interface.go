@@ -33,6 +33,31 @@ type V1 interface {
 	// Delete attempts to remove a key. Returns an error if a failure occurs.
 	Delete(ctx context.Context, key string) error
 
+	// GetMulti attempts to locate and return the cached values of multiple
+	// keys. Keys that do not exist are omitted from the result, and an error
+	// is returned only if the command fails.
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+
+	// Incr atomically increments the integer value of a key by a delta, and
+	// returns the resulting value. A key that does not exist is treated as
+	// zero. Returns an error if the existing value is not an integer, if the
+	// command fails, or component.ErrCacheOpNotSupported if the cache is unable
+	// to perform this operation atomically.
+	Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+
+	// CompareAndSwap attempts to set the value of a key only if its current
+	// value matches old. Returns component.ErrKeyNotFound if the key does not
+	// exist, component.ErrKeyValueMismatch if the current value differs,
+	// component.ErrCacheOpNotSupported if the cache is unable to perform this
+	// operation atomically, or another error if the command fails.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error
+
+	// IterKeys calls fn for each key in the cache that begins with prefix,
+	// stopping at the first error returned by fn. The order in which keys are
+	// visited is undefined. Returns component.ErrCacheOpNotSupported if the
+	// cache is unable to enumerate its keys.
+	IterKeys(ctx context.Context, prefix string, fn func(key string) error) error
+
 	// Close the component, blocks until either the underlying resources are
 	// cleaned up or the context is cancelled. Returns an error if the context
 	// is cancelled.
@@ -52,14 +52,16 @@ var (
 
 // Manager errors.
 var (
-	ErrInputNotFound     = errors.New("input not found")
-	ErrCacheNotFound     = errors.New("cache not found")
-	ErrProcessorNotFound = errors.New("processor not found")
-	ErrRateLimitNotFound = errors.New("rate limit not found")
-	ErrOutputNotFound    = errors.New("output not found")
-	ErrKeyAlreadyExists  = errors.New("key already exists")
-	ErrKeyNotFound       = errors.New("key does not exist")
-	ErrPipeNotFound      = errors.New("pipe was not found")
+	ErrInputNotFound       = errors.New("input not found")
+	ErrCacheNotFound       = errors.New("cache not found")
+	ErrProcessorNotFound   = errors.New("processor not found")
+	ErrRateLimitNotFound   = errors.New("rate limit not found")
+	ErrOutputNotFound      = errors.New("output not found")
+	ErrKeyAlreadyExists    = errors.New("key already exists")
+	ErrKeyNotFound         = errors.New("key does not exist")
+	ErrKeyValueMismatch    = errors.New("key value does not match")
+	ErrCacheOpNotSupported = errors.New("operation not supported by cache")
+	ErrPipeNotFound        = errors.New("pipe was not found")
 )
 
 //------------------------------------------------------------------------------
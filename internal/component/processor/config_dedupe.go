@@ -2,9 +2,11 @@ package processor
 
 // DedupeConfig contains configuration fields for the Dedupe processor.
 type DedupeConfig struct {
-	Cache          string `json:"cache" yaml:"cache"`
-	Key            string `json:"key" yaml:"key"`
-	DropOnCacheErr bool   `json:"drop_on_err" yaml:"drop_on_err"`
+	Cache          string                  `json:"cache" yaml:"cache"`
+	Key            string                  `json:"key" yaml:"key"`
+	DropOnCacheErr bool                    `json:"drop_on_err" yaml:"drop_on_err"`
+	Scope          string                  `json:"scope" yaml:"scope"`
+	BloomFilter    DedupeBloomFilterConfig `json:"bloom_filter" yaml:"bloom_filter"`
 }
 
 // NewDedupeConfig returns a DedupeConfig with default values.
@@ -13,5 +15,31 @@ func NewDedupeConfig() DedupeConfig {
 		Cache:          "",
 		Key:            "",
 		DropOnCacheErr: true,
+		Scope:          "global",
+		BloomFilter:    NewDedupeBloomFilterConfig(),
+	}
+}
+
+// DedupeBloomFilterConfig contains configuration fields for the probabilistic
+// backend of the Dedupe processor.
+type DedupeBloomFilterConfig struct {
+	Enabled           bool    `json:"enabled" yaml:"enabled"`
+	Capacity          int     `json:"capacity" yaml:"capacity"`
+	FalsePositiveRate float64 `json:"false_positive_rate" yaml:"false_positive_rate"`
+	RotateInterval    string  `json:"rotate_interval" yaml:"rotate_interval"`
+	SnapshotPath      string  `json:"snapshot_path" yaml:"snapshot_path"`
+	SnapshotInterval  string  `json:"snapshot_interval" yaml:"snapshot_interval"`
+}
+
+// NewDedupeBloomFilterConfig returns a DedupeBloomFilterConfig with default
+// values.
+func NewDedupeBloomFilterConfig() DedupeBloomFilterConfig {
+	return DedupeBloomFilterConfig{
+		Enabled:           false,
+		Capacity:          1000000,
+		FalsePositiveRate: 0.001,
+		RotateInterval:    "",
+		SnapshotPath:      "",
+		SnapshotInterval:  "1m",
 	}
 }
@@ -1,6 +1,12 @@
 package processor
 
 import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/tracing"
 )
@@ -24,3 +30,135 @@ func MarkErr(part *message.Part, span *tracing.Span, err error) {
 		)
 	}
 }
+
+//------------------------------------------------------------------------------
+
+// ErrProcessor is an error that a processor attached to a message, annotated
+// with details of the processor so that it can be inspected from mappings.
+type ErrProcessor struct {
+	name  string
+	label string
+	path  string
+	err   error
+}
+
+// NewErrProcessor wraps an error with the type, label and config path of the
+// processor that produced it.
+func NewErrProcessor(name, label, path string, err error) *ErrProcessor {
+	return &ErrProcessor{name: name, label: label, path: path, err: err}
+}
+
+// Error returns the message of the underlying error.
+func (e *ErrProcessor) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrProcessor) Unwrap() error {
+	return e.err
+}
+
+// ErrorSourceName returns the type of the processor that produced the error.
+func (e *ErrProcessor) ErrorSourceName() string {
+	return e.name
+}
+
+// ErrorSourceLabel returns the label of the processor that produced the error.
+func (e *ErrProcessor) ErrorSourceLabel() string {
+	return e.label
+}
+
+// ErrorSourcePath returns the config path of the processor that produced the
+// error.
+func (e *ErrProcessor) ErrorSourcePath() string {
+	return e.path
+}
+
+// ErrorKind returns a short classification of the underlying error.
+func (e *ErrProcessor) ErrorKind() string {
+	return ErrorKind(e.err)
+}
+
+// ErrorKind classifies an error as one of `thrown`, `type`, `timeout`,
+// `canceled`, `not_found` or `other`.
+func ErrorKind(err error) string {
+	var tErr *query.ErrThrown
+	var typeErr *query.TypeError
+	var mismatchErr *query.TypeMismatch
+	switch {
+	case errors.As(err, &tErr):
+		return "thrown"
+	case errors.As(err, &typeErr), errors.As(err, &mismatchErr):
+		return "type"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, component.ErrTimeout):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, component.ErrKeyNotFound):
+		return "not_found"
+	}
+	return "other"
+}
+
+type errSource struct {
+	name  string
+	label string
+	path  string
+}
+
+func newErrSource(name string, mgr component.Observability) errSource {
+	s := errSource{name: name}
+	if l, ok := mgr.(interface{ Label() string }); ok {
+		s.label = l.Label()
+	}
+	if p, ok := mgr.(interface{ Path() []string }); ok && len(p.Path()) > 0 {
+		s.path = "root." + query.SliceToDotPath(p.Path()...)
+	}
+	return s
+}
+
+// errSet is a set of the errors attached to message parts.
+type errSet map[error]struct{}
+
+// partErrs returns the set of errors attached to message parts, which is used
+// in order to distinguish errors that existed prior to processing from those
+// added by a processor. Errors of types that cannot be compared are omitted.
+func partErrs(parts message.Batch) errSet {
+	var s errSet
+	for _, p := range parts {
+		err := p.ErrorGet()
+		if err == nil || !reflect.TypeOf(err).Comparable() {
+			continue
+		}
+		if s == nil {
+			s = errSet{}
+		}
+		s[err] = struct{}{}
+	}
+	return s
+}
+
+func (s errSet) contains(err error) bool {
+	if !reflect.TypeOf(err).Comparable() {
+		return false
+	}
+	_, exists := s[err]
+	return exists
+}
+
+// annotate wraps the errors of message parts that were added by the processor,
+// where prior is the set of errors the parts had before being processed.
+// Errors already attributed to a processor, such as those of nested
+// processors, are left unchanged.
+func (s errSource) annotate(prior errSet, parts message.Batch) {
+	for _, p := range parts {
+		err := p.ErrorGet()
+		if err == nil || prior.contains(err) {
+			continue
+		}
+		var pErr *ErrProcessor
+		if !errors.As(err, &pErr) {
+			p.ErrorSet(NewErrProcessor(s.name, s.label, s.path, err))
+		}
+	}
+}
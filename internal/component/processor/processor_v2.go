@@ -44,6 +44,7 @@ type v2ToV1Processor struct {
 	typeStr string
 	p       V2
 	mgr     component.Observability
+	source  errSource
 
 	mReceived      metrics.StatCounter
 	mBatchReceived metrics.StatCounter
@@ -57,6 +58,7 @@ type v2ToV1Processor struct {
 func NewV2ToV1Processor(typeStr string, p V2, mgr component.Observability) V1 {
 	return &v2ToV1Processor{
 		typeStr: typeStr, p: p, mgr: mgr,
+		source: newErrSource(typeStr, mgr),
 
 		mReceived:      mgr.Metrics().GetCounter("processor_received"),
 		mBatchReceived: mgr.Metrics().GetCounter("processor_batch_received"),
@@ -77,6 +79,7 @@ func (a *v2ToV1Processor) ProcessBatch(ctx context.Context, msg message.Batch) (
 	_ = msg.Iter(func(i int, part *message.Part) error {
 		_, span := tracing.WithChildSpan(a.mgr.Tracer(), a.typeStr, part)
 
+		prior := partErrs(message.Batch{part})
 		nextParts, err := a.p.Process(ctx, part)
 		if err != nil {
 			a.mError.Incr(1)
@@ -86,6 +89,7 @@ func (a *v2ToV1Processor) ProcessBatch(ctx context.Context, msg message.Batch) (
 		}
 
 		span.Finish()
+		a.source.annotate(prior, nextParts)
 		if len(nextParts) > 0 {
 			newParts = append(newParts, nextParts...)
 		}
@@ -113,6 +117,7 @@ type v2BatchedToV1Processor struct {
 	typeStr string
 	p       V2Batched
 	mgr     component.Observability
+	source  errSource
 
 	mReceived      metrics.StatCounter
 	mBatchReceived metrics.StatCounter
@@ -127,6 +132,7 @@ type v2BatchedToV1Processor struct {
 func NewV2BatchedToV1Processor(typeStr string, p V2Batched, mgr component.Observability) V1 {
 	return &v2BatchedToV1Processor{
 		typeStr: typeStr, p: p, mgr: mgr,
+		source: newErrSource(typeStr, mgr),
 
 		mReceived:      mgr.Metrics().GetCounter("processor_received"),
 		mBatchReceived: mgr.Metrics().GetCounter("processor_batch_received"),
@@ -144,6 +150,7 @@ func (a *v2BatchedToV1Processor) ProcessBatch(ctx context.Context, msg message.B
 	tStarted := time.Now()
 	_, spans := tracing.WithChildSpans(a.mgr.Tracer(), a.typeStr, msg)
 
+	prior := partErrs(msg)
 	outputBatches, err := a.p.ProcessBatch(ctx, spans, msg)
 	if err != nil {
 		a.mError.Incr(1)
@@ -165,6 +172,7 @@ func (a *v2BatchedToV1Processor) ProcessBatch(ctx context.Context, msg message.B
 	}
 
 	for _, m := range outputBatches {
+		a.source.annotate(prior, m)
 		a.mSent.Incr(int64(m.Len()))
 	}
 	a.mBatchSent.Incr(int64(len(outputBatches)))
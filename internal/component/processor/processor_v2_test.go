@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/tracing"
@@ -224,3 +225,99 @@ func TestBatchProcessorAirGapOneToMany(t *testing.T) {
 	assert.Equal(t, 1, msgs[1].Len())
 	assert.Equal(t, "changed 3", string(msgs[1].Get(0).AsBytes()))
 }
+
+type labelledObs struct {
+	component.Observability
+}
+
+func (labelledObs) Label() string  { return "my_proc" }
+func (labelledObs) Path() []string { return []string{"pipeline", "processors", "0"} }
+
+func TestProcessorAirGapErrorSource(t *testing.T) {
+	tCtx := context.Background()
+
+	agrp := NewV2ToV1Processor("foo", &fnProcessor{
+		fn: func(c context.Context, m *message.Part) ([]*message.Part, error) {
+			if string(m.AsBytes()) == "timeout" {
+				return nil, context.DeadlineExceeded
+			}
+			return nil, &query.ErrThrown{Value: map[string]any{"code": 429}}
+		},
+	}, labelledObs{component.NoopObservability()})
+
+	msgs, res := agrp.ProcessBatch(tCtx, message.QuickBatch([][]byte{[]byte("thrown"), []byte("timeout")}))
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	require.Equal(t, 2, msgs[0].Len())
+
+	var pErr *ErrProcessor
+	require.True(t, errors.As(msgs[0].Get(0).ErrorGet(), &pErr))
+	assert.Equal(t, "foo", pErr.ErrorSourceName())
+	assert.Equal(t, "my_proc", pErr.ErrorSourceLabel())
+	assert.Equal(t, "root.pipeline.processors.0", pErr.ErrorSourcePath())
+	assert.Equal(t, "thrown", pErr.ErrorKind())
+	assert.Equal(t, `{"code":429}`, pErr.Error())
+
+	require.True(t, errors.As(msgs[0].Get(1).ErrorGet(), &pErr))
+	assert.Equal(t, "timeout", pErr.ErrorKind())
+
+	// Errors already attributed to a processor are left unchanged.
+	agrp = NewV2ToV1Processor("bar", &fnProcessor{
+		fn: func(c context.Context, m *message.Part) ([]*message.Part, error) {
+			return []*message.Part{m}, nil
+		},
+	}, component.NoopObservability())
+
+	msgs, res = agrp.ProcessBatch(tCtx, msgs[0])
+	require.Nil(t, res)
+	require.True(t, errors.As(msgs[0].Get(0).ErrorGet(), &pErr))
+	assert.Equal(t, "foo", pErr.ErrorSourceName())
+}
+
+func TestProcessorAirGapErrorSourcePriorErrors(t *testing.T) {
+	tCtx := context.Background()
+
+	// Errors set before a processor is reached, such as by an input, are not
+	// attributed to processors that pass them through.
+	priorErr := errors.New("prior")
+	part := message.NewPart([]byte("hello"))
+	part.ErrorSet(priorErr)
+
+	agrp := NewV2ToV1Processor("foo", &fnProcessor{
+		fn: func(c context.Context, m *message.Part) ([]*message.Part, error) {
+			return []*message.Part{m.ShallowCopy()}, nil
+		},
+	}, labelledObs{component.NoopObservability()})
+
+	msgs, res := agrp.ProcessBatch(tCtx, message.Batch{part})
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, priorErr, msgs[0].Get(0).ErrorGet())
+
+	batchedProc := NewV2BatchedToV1Processor("bar", &fnBatchProcessor{
+		fn: func(c context.Context, b message.Batch) ([]message.Batch, error) {
+			return []message.Batch{b}, nil
+		},
+	}, labelledObs{component.NoopObservability()})
+
+	msgs, res = batchedProc.ProcessBatch(tCtx, msgs[0])
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, priorErr, msgs[0].Get(0).ErrorGet())
+
+	// Replacing the error of a part attributes the new error.
+	agrp = NewV2ToV1Processor("baz", &fnProcessor{
+		fn: func(c context.Context, m *message.Part) ([]*message.Part, error) {
+			return nil, errors.New("replaced")
+		},
+	}, labelledObs{component.NoopObservability()})
+
+	msgs, res = agrp.ProcessBatch(tCtx, msgs[0])
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+
+	var pErr *ErrProcessor
+	require.True(t, errors.As(msgs[0].Get(0).ErrorGet(), &pErr))
+	assert.Equal(t, "baz", pErr.ErrorSourceName())
+	assert.Equal(t, "replaced", pErr.Error())
+}
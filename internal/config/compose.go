@@ -0,0 +1,442 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	ifilepath "github.com/benthosdev/benthos/v4/internal/filepath"
+)
+
+const (
+	includeField  = "include"
+	profilesField = "profiles"
+	anchorsField  = "anchors"
+
+	// A temporary field prepended to config files in order to define anchors
+	// that originate from other files.
+	headerField = "__benthos_anchors__"
+)
+
+// Matches the aliases of a YAML document, the characters of an anchor name are
+// limited to those supported by the YAML parser.
+var aliasRegex = regexp.MustCompile(`\*([0-9A-Za-z_-]+)`)
+
+type namedAnchor struct {
+	name string
+	node *yaml.Node
+}
+
+// composer reads config files and resolves any fragments that they include.
+type composer struct {
+	r    *Reader
+	root string
+	spec docs.FieldSpecs
+}
+
+// readComposed reads a config file and resolves its composition, where the
+// file may include other config fragments with the `include` field, which are
+// deep merged in the order they are listed with the contents of the file
+// itself merged last, and may define overlays under the `profiles` field that
+// are deep merged on top when selected with the profiles of the reader.
+//
+// Anchors defined within included fragments can be referenced by aliases
+// within the files that include them, and within fragments listed after them.
+// Anchors that are not part of the config itself can be defined under the
+// `anchors` field, which is removed from the result.
+//
+// The raw bytes of the root file are returned along with the composed node.
+func (r *Reader) readComposed(path string, spec docs.FieldSpecs) (confBytes []byte, rawNode *yaml.Node, lints []docs.Lint, err error) {
+	c := &composer{r: r, root: path, spec: spec}
+
+	var root *yaml.Node
+	if confBytes, root, _, lints, err = c.compose(path, nil, nil); err != nil {
+		return
+	}
+
+	profiles := takeField(root, profilesField)
+	if profiles != nil && profiles.Kind != yaml.MappingNode {
+		err = fmt.Errorf("field %v: expected object value, got %v", profilesField, nodeKindStr(profiles))
+		return
+	}
+	for _, p := range r.profiles {
+		overlay := mappingValue(profiles, p)
+		if overlay == nil {
+			if profiles != nil {
+				lints = append(lints, docs.NewLintError(profiles.Line, docs.LintUnknown, fmt.Sprintf("profile %v is not defined", p)))
+			}
+			continue
+		}
+		root = mergeNodes(spec, root, overlay)
+	}
+
+	// Aliases are resolved as the anchors they reference might originate from
+	// other files, and would otherwise be lost when re-encoding the config.
+	rawNode = &yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{copyResolved(root)},
+	}
+	return
+}
+
+func (c *composer) compose(path string, chain []string, anchors []namedAnchor) (confBytes []byte, node *yaml.Node, defined []namedAnchor, lints []docs.Lint, err error) {
+	for _, p := range chain {
+		if p == path {
+			err = fmt.Errorf("include cycle detected: %v -> %v", strings.Join(chain, " -> "), path)
+			return
+		}
+	}
+	if len(chain) > 0 {
+		c.r.includedBy[path] = c.root
+	}
+
+	var modTime time.Time
+	if confBytes, lints, modTime, err = readFileEnvSwap(c.r.fs, path, c.r.lenientSecrets); err != nil {
+		return
+	}
+	c.r.modTimeLastRead[path] = modTime
+
+	// Includes are extracted before anchors from other files are known, so
+	// aliases are temporarily satisfied with placeholders.
+	hasAliases := aliasRegex.Match(confBytes)
+	var own *yaml.Node
+	if own, err = parseWithAnchors(confBytes, nil, hasAliases); err != nil {
+		return
+	}
+
+	var includes []string
+	if includes, err = includePaths(c.r, path, takeField(own, includeField)); err != nil {
+		return
+	}
+
+	var merged *yaml.Node
+	chain = append(chain, path)
+	for _, incPath := range includes {
+		var incNode *yaml.Node
+		var incAnchors []namedAnchor
+		var incLints []docs.Lint
+		if _, incNode, incAnchors, incLints, err = c.compose(incPath, chain, anchors); err != nil {
+			err = fmt.Errorf("%v: %w", incPath, err)
+			return
+		}
+		for _, l := range incLints {
+			lints = append(lints, docs.NewLintError(l.Line, l.Type, fmt.Sprintf("%v: %v", incPath, l.What)))
+		}
+		anchors = incAnchors
+		merged = mergeNodes(c.spec, merged, incNode)
+	}
+
+	if hasAliases {
+		if own, err = parseWithAnchors(confBytes, anchors, false); err != nil {
+			return
+		}
+		_ = takeField(own, includeField)
+	}
+
+	// Anchors are collected before removing the anchors field, which exists
+	// only in order to define them.
+	defined = append(append([]namedAnchor{}, anchors...), collectAnchors(own)...)
+	_ = takeField(own, anchorsField)
+	node = mergeNodes(c.spec, merged, own)
+	return
+}
+
+// includePaths resolves the paths listed in an include field, which can either
+// be a single path or a list of paths, and may contain glob patterns. Relative
+// paths are resolved from the directory of the including file.
+func includePaths(r *Reader, from string, incNode *yaml.Node) ([]string, error) {
+	if incNode == nil {
+		return nil, nil
+	}
+
+	var patterns []string
+	switch incNode.Kind {
+	case yaml.ScalarNode:
+		patterns = []string{incNode.Value}
+	case yaml.SequenceNode:
+		if err := incNode.Decode(&patterns); err != nil {
+			return nil, fmt.Errorf("field %v: %w", includeField, err)
+		}
+	default:
+		return nil, fmt.Errorf("field %v: expected string or array value, got %v", includeField, nodeKindStr(incNode))
+	}
+
+	var paths []string
+	for _, p := range patterns {
+		if p == "" {
+			return nil, fmt.Errorf("field %v: include path must not be empty", includeField)
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(from), p)
+		}
+		expanded, err := ifilepath.Globs(r.fs, []string{p})
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %w", includeField, err)
+		}
+		sort.Strings(expanded)
+		for _, e := range expanded {
+			paths = append(paths, filepath.Clean(e))
+		}
+	}
+	return paths, nil
+}
+
+// parseWithAnchors parses a YAML document where anchors defined elsewhere are
+// made available to the aliases of the document. When placeholders is true any
+// alias is satisfied with a null value.
+func parseWithAnchors(confBytes []byte, anchors []namedAnchor, placeholders bool) (*yaml.Node, error) {
+	var header []byte
+	if aliasRegex.Match(confBytes) {
+		var err error
+		if header, err = anchorsHeader(confBytes, anchors, placeholders); err != nil {
+			return nil, err
+		}
+	}
+
+	// The header must follow a leading document marker.
+	var docStart []byte
+	if bytes.HasPrefix(confBytes, []byte("---\n")) {
+		docStart, confBytes = confBytes[:4], confBytes[4:]
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(append(append(append([]byte{}, docStart...), header...), confBytes...), &doc); err != nil {
+		if len(header) > 0 {
+			err = errors.New(strings.ReplaceAll(err.Error(), headerField, "anchors"))
+		}
+		return nil, err
+	}
+
+	var root *yaml.Node
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if root == nil || (root.Kind == yaml.ScalarNode && root.Tag == "!!null") {
+		root = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	if len(header) > 0 {
+		_ = takeField(root, headerField)
+		offsetLines(root, bytes.Count(header, []byte("\n")))
+	}
+	return root, nil
+}
+
+func anchorsHeader(confBytes []byte, anchors []namedAnchor, placeholders bool) ([]byte, error) {
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	if placeholders {
+		for _, m := range aliasRegex.FindAllSubmatch(confBytes, -1) {
+			seq.Content = append(seq.Content, &yaml.Node{
+				Kind:   yaml.ScalarNode,
+				Tag:    "!!null",
+				Value:  "null",
+				Anchor: string(m[1]),
+			})
+		}
+	}
+	for _, a := range anchors {
+		n := copyResolved(a.node)
+		n.Anchor = a.name
+		seq.Content = append(seq.Content, n)
+	}
+	if len(seq.Content) == 0 {
+		return nil, nil
+	}
+	return yaml.Marshal(&yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: headerField},
+			seq,
+		},
+	})
+}
+
+// collectAnchors returns the anchors defined within a node tree in the order
+// that they are defined.
+func collectAnchors(node *yaml.Node) (anchors []namedAnchor) {
+	if node == nil || node.Kind == yaml.AliasNode {
+		return
+	}
+	if node.Anchor != "" {
+		anchors = append(anchors, namedAnchor{name: node.Anchor, node: node})
+	}
+	for _, n := range node.Content {
+		anchors = append(anchors, collectAnchors(n)...)
+	}
+	return
+}
+
+// copyResolved creates a deep copy of a node tree where aliases are replaced
+// with copies of the nodes they reference and anchors are removed.
+func copyResolved(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	n := *node
+	n.Anchor = ""
+	n.Content = make([]*yaml.Node, len(node.Content))
+	for i, c := range node.Content {
+		n.Content[i] = copyResolved(c)
+	}
+	return &n
+}
+
+// offsetLines corrects the line numbers of a node tree parsed from a document
+// with a prepended header.
+func offsetLines(node *yaml.Node, offset int) {
+	if node.Line > offset {
+		node.Line -= offset
+	}
+	for _, n := range node.Content {
+		offsetLines(n, offset)
+	}
+}
+
+// mergeNodes deep merges an overlay node onto a base node, where the fields of
+// objects are merged recursively and all other values of the overlay replace
+// those of the base. The provided field specs are used in order to identify
+// components, where a component of the overlay replaces that of the base
+// entirely when their types differ.
+func mergeNodes(spec docs.FieldSpecs, base, overlay *yaml.Node) *yaml.Node {
+	for base != nil && base.Kind == yaml.AliasNode && base.Alias != nil {
+		base = base.Alias
+	}
+	for overlay != nil && overlay.Kind == yaml.AliasNode && overlay.Alias != nil {
+		overlay = overlay.Alias
+	}
+	if base == nil {
+		return overlay
+	}
+	if overlay == nil {
+		return base
+	}
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+
+	merged := *overlay
+	merged.Content = append([]*yaml.Node{}, base.Content...)
+	for i := 0; i < len(overlay.Content)-1; i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+
+		found := false
+		for j := 0; j < len(merged.Content)-1; j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeField(fieldByName(spec, key.Value), merged.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
+
+// mergeField deep merges the values of a field, which may not have a spec.
+func mergeField(spec *docs.FieldSpec, base, overlay *yaml.Node) *yaml.Node {
+	if spec == nil {
+		return mergeNodes(nil, base, overlay)
+	}
+	if spec.Kind == docs.KindMap {
+		// Each value of a map field is described by the same spec.
+		var valueSpecs docs.FieldSpecs
+		if o := resolveAlias(overlay); o.Kind == yaml.MappingNode {
+			for i := 0; i < len(o.Content)-1; i += 2 {
+				valueSpec := *spec
+				valueSpec.Name = o.Content[i].Value
+				valueSpec.Kind = docs.KindScalar
+				valueSpecs = append(valueSpecs, valueSpec)
+			}
+		}
+		return mergeNodes(valueSpecs, base, overlay)
+	}
+	if spec.Kind != docs.KindScalar {
+		return mergeNodes(nil, base, overlay)
+	}
+
+	coreType, isCore := spec.Type.IsCoreComponent()
+	if !isCore {
+		return mergeNodes(spec.Children, base, overlay)
+	}
+
+	baseName, baseSpec, err := docs.GetInferenceCandidateFromYAML(docs.DeprecatedProvider, coreType, resolveAlias(base))
+	if err != nil {
+		return mergeNodes(nil, base, overlay)
+	}
+	overlayName, _, err := docs.GetInferenceCandidateFromYAML(docs.DeprecatedProvider, coreType, resolveAlias(overlay))
+	if err != nil {
+		return mergeNodes(nil, base, overlay)
+	}
+	if baseName != overlayName {
+		return overlay
+	}
+	configSpec := baseSpec.Config
+	configSpec.Name = baseName
+	return mergeNodes(docs.FieldSpecs{configSpec}, base, overlay)
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func fieldByName(spec docs.FieldSpecs, name string) *docs.FieldSpec {
+	for i := range spec {
+		if spec[i].Name == name {
+			return &spec[i]
+		}
+	}
+	return nil
+}
+
+// takeField removes a field from a mapping node and returns its value, or nil
+// if the field does not exist.
+func takeField(node *yaml.Node, field string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == field {
+			value := node.Content[i+1]
+			node.Content = append(node.Content[:i:i], node.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+func mappingValue(node *yaml.Node, field string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == field {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func nodeKindStr(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	case yaml.ScalarNode:
+		return "value"
+	}
+	return "unknown"
+}
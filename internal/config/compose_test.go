@@ -0,0 +1,259 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeIncludes(t *testing.T) {
+	testFS := &testFS{m: fstest.MapFS{
+		"main.yaml": &fstest.MapFile{
+			Data: []byte(`
+include:
+  - ./base/*.yaml
+  - outputs.yaml
+
+input:
+  label: mainin
+`),
+		},
+		"base/a.yaml": &fstest.MapFile{
+			Data: []byte(`
+input:
+  label: ain
+  generate:
+    mapping: 'root = "a"'
+    interval: 1s
+`),
+		},
+		"base/b.yaml": &fstest.MapFile{
+			Data: []byte(`
+input:
+  generate:
+    interval: 5s
+`),
+		},
+		"outputs.yaml": &fstest.MapFile{
+			Data: []byte(`
+output:
+  label: fooout
+  drop: {}
+`),
+		},
+	}}
+	rdr := newDummyReader("main.yaml", nil, OptUseFS(testFS))
+
+	conf := New()
+	lints, err := rdr.Read(&conf)
+	require.NoError(t, err)
+	require.Empty(t, lints)
+
+	assert.Equal(t, "mainin", conf.Input.Label)
+	assert.Equal(t, "generate", conf.Input.Type)
+	assert.Equal(t, `root = "a"`, conf.Input.Generate.Mapping)
+	assert.Equal(t, "5s", conf.Input.Generate.Interval)
+	assert.Equal(t, "fooout", conf.Output.Label)
+	assert.Equal(t, "drop", conf.Output.Type)
+
+	assert.Equal(t, map[string]string{
+		"base/a.yaml":  "main.yaml",
+		"base/b.yaml":  "main.yaml",
+		"outputs.yaml": "main.yaml",
+	}, rdr.includedBy)
+}
+
+func TestComposeProfiles(t *testing.T) {
+	testFS := &testFS{m: fstest.MapFS{
+		"main.yaml": &fstest.MapFile{
+			Data: []byte(`
+include: profiles.yaml
+
+input:
+  generate:
+    mapping: 'root = "dev"'
+
+output:
+  drop: {}
+
+profiles:
+  prod:
+    input:
+      generate:
+        mapping: 'root = "prod"'
+    output:
+      reject: nope
+`),
+		},
+		"profiles.yaml": &fstest.MapFile{
+			Data: []byte(`
+profiles:
+  prod:
+    input:
+      label: prodin
+  loud:
+    input:
+      generate:
+        mapping: 'root = "LOUD"'
+`),
+		},
+	}}
+
+	for _, test := range []struct {
+		name     string
+		profiles []string
+		label    string
+		mapping  string
+		output   string
+		lints    []string
+	}{
+		{
+			name:    "no profile",
+			mapping: `root = "dev"`,
+			output:  "drop",
+		},
+		{
+			name:     "single profile",
+			profiles: []string{"prod"},
+			label:    "prodin",
+			mapping:  `root = "prod"`,
+			output:   "reject",
+		},
+		{
+			name:     "multiple profiles",
+			profiles: []string{"prod", "loud"},
+			label:    "prodin",
+			mapping:  `root = "LOUD"`,
+			output:   "reject",
+		},
+		{
+			name:     "unknown profile",
+			profiles: []string{"nope"},
+			mapping:  `root = "dev"`,
+			output:   "drop",
+			lints:    []string{"(12,1) profile nope is not defined"},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			rdr := newDummyReader("main.yaml", nil, OptUseFS(testFS), OptSetProfiles(test.profiles...))
+
+			conf := New()
+			lints, err := rdr.Read(&conf)
+			require.NoError(t, err)
+			assert.Equal(t, test.lints, lints)
+
+			assert.Equal(t, test.label, conf.Input.Label)
+			assert.Equal(t, test.mapping, conf.Input.Generate.Mapping)
+			assert.Equal(t, test.output, conf.Output.Type)
+		})
+	}
+}
+
+func TestComposeAnchors(t *testing.T) {
+	testFS := &testFS{m: fstest.MapFS{
+		"main.yaml": &fstest.MapFile{
+			Data: []byte(`include: [ anchors.yaml, output.yaml ]
+input:
+  generate:
+    mapping: *mapping
+pipeline:
+  processors:
+    - *proc
+`),
+		},
+		"anchors.yaml": &fstest.MapFile{
+			Data: []byte(`
+anchors:
+  mapping: &mapping 'root = "from anchors"'
+  label: &label from_anchors
+  proc: &proc
+    mapping: *mapping
+`),
+		},
+		"output.yaml": &fstest.MapFile{
+			Data: []byte(`
+output:
+  label: *label
+  drop: {}
+`),
+		},
+	}}
+	rdr := newDummyReader("main.yaml", nil, OptUseFS(testFS))
+
+	conf := New()
+	lints, err := rdr.Read(&conf)
+	require.NoError(t, err)
+	require.Empty(t, lints)
+
+	assert.Equal(t, `root = "from anchors"`, conf.Input.Generate.Mapping)
+	assert.Equal(t, "from_anchors", conf.Output.Label)
+	require.Len(t, conf.Pipeline.Processors, 1)
+	assert.Equal(t, "mapping", conf.Pipeline.Processors[0].Type)
+}
+
+func TestComposeErrors(t *testing.T) {
+	testFS := &testFS{m: fstest.MapFS{
+		"cycle_a.yaml": &fstest.MapFile{
+			Data: []byte(`include: cycle_b.yaml`),
+		},
+		"cycle_b.yaml": &fstest.MapFile{
+			Data: []byte(`include: cycle_a.yaml`),
+		},
+		"missing.yaml": &fstest.MapFile{
+			Data: []byte(`include: nope.yaml`),
+		},
+		"bad_include.yaml": &fstest.MapFile{
+			Data: []byte(`include: { foo: bar }`),
+		},
+		"bad_alias.yaml": &fstest.MapFile{
+			Data: []byte(`
+input:
+  label: *nope
+`),
+		},
+	}}
+
+	for path, errContains := range map[string]string{
+		"cycle_a.yaml":     "include cycle detected: cycle_a.yaml -> cycle_b.yaml -> cycle_a.yaml",
+		"missing.yaml":     "nope.yaml",
+		"bad_include.yaml": "field include: expected string or array value, got object",
+		"bad_alias.yaml":   "unknown anchor 'nope' referenced",
+	} {
+		rdr := newDummyReader(path, nil, OptUseFS(testFS))
+
+		conf := New()
+		_, err := rdr.Read(&conf)
+		require.Error(t, err, path)
+		assert.Contains(t, err.Error(), errContains, path)
+	}
+}
+
+func TestComposeLintLines(t *testing.T) {
+	testFS := &testFS{m: fstest.MapFS{
+		"main.yaml": &fstest.MapFile{
+			Data: []byte(`include: anchors.yaml
+input:
+  label: *label
+  generate:
+    mapping: 'root = "foo"'
+    nope: true
+`),
+		},
+		"anchors.yaml": &fstest.MapFile{
+			Data: []byte(`
+http:
+  address: &label foo
+`),
+		},
+	}}
+	rdr := newDummyReader("main.yaml", nil, OptUseFS(testFS))
+
+	conf := New()
+	lints, err := rdr.Read(&conf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.yaml(6,1) field nope not recognised"}, lints)
+	assert.Equal(t, "foo", conf.Input.Label)
+}
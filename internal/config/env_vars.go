@@ -2,9 +2,12 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"regexp"
 	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 )
 
 var (
@@ -21,8 +24,14 @@ var (
 // respective environment variable will be read and will replace the pattern. If
 // the environment variable is empty or does not exist then either the default
 // value is used or the field will be left empty.
+//
+// Secret references of the form `${secret:provider:path}`, including escaped
+// references, are left untouched in order to be resolved afterwards.
 func ReplaceEnvVariables(inBytes []byte) []byte {
 	replaced := envRegex.ReplaceAllFunc(inBytes, func(content []byte) []byte {
+		if secrets.IsReference(content) {
+			return content
+		}
 		var value string
 		if len(content) > 3 {
 			if colonIndex := bytes.IndexByte(content, ':'); colonIndex == -1 {
@@ -42,6 +51,35 @@ func ReplaceEnvVariables(inBytes []byte) []byte {
 		}
 		return []byte(value)
 	})
-	replaced = escapedEnvRegex.ReplaceAll(replaced, []byte("$$$1"))
+	replaced = escapedEnvRegex.ReplaceAllFunc(replaced, func(content []byte) []byte {
+		unescaped := append([]byte("$"), content[2:len(content)-1]...)
+		if secrets.IsReference(unescaped) {
+			return content
+		}
+		return unescaped
+	})
 	return replaced
 }
+
+// ReplaceSecretsAndEnvVariables will search a blob of data for environment
+// variable patterns as described in ReplaceEnvVariables and replace them,
+// followed by replacing secret references of the form `${secret:provider:path}`
+// with the resolved values of those secrets. Secrets are resolved last so that
+// their values are never interpolated further.
+func ReplaceSecretsAndEnvVariables(inBytes []byte) ([]byte, error) {
+	return ReplaceSecretsAndEnvVariablesExcept(inBytes)
+}
+
+// ReplaceSecretsAndEnvVariablesExcept is the same as
+// ReplaceSecretsAndEnvVariables, except that references to secrets of any of
+// the excluded providers result in an error.
+func ReplaceSecretsAndEnvVariablesExcept(inBytes []byte, excluded ...string) ([]byte, error) {
+	return ReplaceSecretsAndEnvVariablesFrom(secrets.GlobalRegistry(), inBytes, excluded...)
+}
+
+// ReplaceSecretsAndEnvVariablesFrom is the same as
+// ReplaceSecretsAndEnvVariablesExcept, except that secrets are resolved with
+// the providers of a given registry rather than the global registry.
+func ReplaceSecretsAndEnvVariablesFrom(reg *secrets.Registry, inBytes []byte, excluded ...string) ([]byte, error) {
+	return reg.ReplaceExcept(context.Background(), ReplaceEnvVariables(inBytes), excluded...)
+}
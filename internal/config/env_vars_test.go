@@ -1,8 +1,14 @@
 package config
 
 import (
+	"context"
 	"os"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 )
 
 func TestEnvSwapping(t *testing.T) {
@@ -33,6 +39,8 @@ func TestEnvSwapping(t *testing.T) {
 		"foo ${{BENTHOS_TEST_FOO:bar}} baz":                                        "foo ${BENTHOS_TEST_FOO:bar} baz",
 		"foo ${{BENTHOS_TEST_FOO}} baz":                                            "foo ${BENTHOS_TEST_FOO} baz",
 		"foo ${BENTHOS.TEST.BAR} baz":                                              "foo test\\nbar baz",
+		"foo ${secret:file:/bar} baz":                                              "foo ${secret:file:/bar} baz",
+		"foo ${{secret:file:/bar}} baz":                                            "foo ${{secret:file:/bar}} baz",
 	}
 
 	for in, exp := range tests {
@@ -42,3 +50,25 @@ func TestEnvSwapping(t *testing.T) {
 		}
 	}
 }
+
+func TestSecretsAndEnvSwapping(t *testing.T) {
+	t.Setenv("BENTHOS_TEST_SECRET_ENV", "from env")
+
+	reg := secrets.NewRegistry()
+	require.NoError(t, reg.Register("testenv", secrets.ProviderFunc(func(ctx context.Context, path string) (string, error) {
+		return "p4ss ${BENTHOS_TEST_SECRET_ENV} ${{BENTHOS_TEST_SECRET_ENV}}", nil
+	})))
+
+	out, err := ReplaceSecretsAndEnvVariablesFrom(reg, []byte(`a: ${secret:testenv:foo}
+b: ${BENTHOS_TEST_SECRET_ENV}
+c: ${{secret:testenv:foo}}
+d: ${BENTHOS_TEST_SECRET_NOPE:${secret:testenv:foo}}`))
+	require.NoError(t, err)
+	assert.Equal(t, `a: p4ss ${BENTHOS_TEST_SECRET_ENV} ${{BENTHOS_TEST_SECRET_ENV}}
+b: from env
+c: ${secret:testenv:foo}
+d: p4ss ${BENTHOS_TEST_SECRET_ENV} ${{BENTHOS_TEST_SECRET_ENV}}`, string(out))
+
+	_, err = ReplaceSecretsAndEnvVariablesFrom(reg, []byte(`a: ${secret:testenv:foo}`), "testenv")
+	require.Error(t, err)
+}
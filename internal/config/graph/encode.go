@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (n *Node) title() string {
+	if n.Label != "" {
+		return n.Label + " (" + n.Type + ")"
+	}
+	return n.Type
+}
+
+func (g *Graph) groups() (names []string, nodes map[string][]*Node) {
+	nodes = map[string][]*Node{}
+	for _, n := range g.Nodes {
+		if _, exists := nodes[n.Group]; !exists {
+			names = append(names, n.Group)
+		}
+		nodes[n.Group] = append(nodes[n.Group], n)
+	}
+	return
+}
+
+// JSON encodes the graph as a JSON document.
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+var dotShapes = map[string]string{
+	"input":      "invhouse",
+	"buffer":     "cylinder",
+	"processor":  "box",
+	"output":     "house",
+	"cache":      "cylinder",
+	"rate_limit": "octagon",
+}
+
+// DOT encodes the graph in the Graphviz DOT language, where the components of
+// each stream, and of resources, are grouped into clusters.
+func (g *Graph) DOT() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("digraph benthos {\n")
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [fontname=\"Helvetica\"];\n")
+
+	names, nodes := g.groups()
+	for i, name := range names {
+		fmt.Fprintf(&buf, "  subgraph cluster_%v {\n", i)
+		fmt.Fprintf(&buf, "    label=%v;\n", strconv.Quote(name))
+		for _, n := range nodes[name] {
+			shape, exists := dotShapes[n.Kind]
+			if !exists {
+				shape = "box"
+			}
+			fmt.Fprintf(&buf, "    %v [label=%v, shape=%v, tooltip=%v];\n",
+				strconv.Quote(n.ID), strconv.Quote(n.title()+"\n"+n.Kind), shape, strconv.Quote(n.Path))
+		}
+		buf.WriteString("  }\n")
+	}
+
+	for _, e := range g.Edges {
+		var attrs []string
+		switch e.Kind {
+		case EdgeResource:
+			attrs = append(attrs, "style=dashed")
+		case EdgeInproc:
+			attrs = append(attrs, "style=bold", "color=blue")
+		}
+		if e.Label != "" {
+			attrs = append(attrs, "label="+strconv.Quote(e.Label))
+		}
+		fmt.Fprintf(&buf, "  %v -> %v", strconv.Quote(e.From), strconv.Quote(e.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&buf, " [%v]", strings.Join(attrs, ", "))
+		}
+		buf.WriteString(";\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "\n", " ")
+
+// Mermaid encodes the graph as a Mermaid flowchart, where the components of
+// each stream, and of resources, are grouped into subgraphs.
+func (g *Graph) Mermaid() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("flowchart LR\n")
+
+	// Mermaid identifiers are restricted and so nodes are given short IDs.
+	ids := make(map[string]string, len(g.Nodes))
+	names, nodes := g.groups()
+	for i, name := range names {
+		fmt.Fprintf(&buf, "  subgraph s%v [\"%v\"]\n", i, mermaidEscaper.Replace(name))
+		for _, n := range nodes[name] {
+			id := "n" + strconv.Itoa(len(ids))
+			ids[n.ID] = id
+			fmt.Fprintf(&buf, "    %v[\"%v<br/><i>%v</i>\"]\n", id, mermaidEscaper.Replace(n.title()), n.Kind)
+		}
+		buf.WriteString("  end\n")
+	}
+
+	for _, e := range g.Edges {
+		from, to := ids[e.From], ids[e.To]
+		switch e.Kind {
+		case EdgeResource:
+			fmt.Fprintf(&buf, "  %v -.-> %v\n", from, to)
+		case EdgeInproc:
+			fmt.Fprintf(&buf, "  %v == \"%v\" ==> %v\n", from, mermaidEscaper.Replace(e.Label), to)
+		default:
+			fmt.Fprintf(&buf, "  %v --> %v\n", from, to)
+		}
+	}
+	return buf.Bytes()
+}
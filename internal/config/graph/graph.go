@@ -0,0 +1,476 @@
+// Package graph builds the topology of the components within Benthos configs,
+// including the connections between streams and resources, and encodes it in
+// formats that can be rendered as diagrams.
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+// The kinds of edges within a graph.
+const (
+	// EdgeData is the flow of messages between components.
+	EdgeData = "data"
+	// EdgeResource is a reference to a resource component.
+	EdgeResource = "resource"
+	// EdgeInproc is the flow of messages between an inproc output and the
+	// inproc inputs of the same name.
+	EdgeInproc = "inproc"
+)
+
+// ResourcesGroup is the group of nodes that represent resources.
+const ResourcesGroup = "resources"
+
+// Node is a component within a graph.
+type Node struct {
+	ID    string `json:"id"`
+	Group string `json:"group"`
+	Path  string `json:"path"`
+	Kind  string `json:"kind"`
+	Type  string `json:"type"`
+	Label string `json:"label,omitempty"`
+}
+
+// Edge is a connection between two components within a graph.
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+}
+
+// Graph is the topology of the components within a set of configs.
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+//------------------------------------------------------------------------------
+
+type resourceRef struct {
+	from  string
+	cType docs.Type
+	label string
+}
+
+// Builder walks configs in order to build a graph.
+type Builder struct {
+	prov docs.Provider
+	g    Graph
+
+	edgeSet   map[[2]string]struct{}
+	resources map[docs.Type]map[string]string
+	refs      []resourceRef
+
+	inprocInputs  map[string][]string
+	inprocOutputs map[string][]string
+}
+
+// NewBuilder creates a graph builder that uses a docs provider in order to
+// identify components.
+func NewBuilder(prov docs.Provider) *Builder {
+	return &Builder{
+		prov:          prov,
+		edgeSet:       map[[2]string]struct{}{},
+		resources:     map[docs.Type]map[string]string{},
+		inprocInputs:  map[string][]string{},
+		inprocOutputs: map[string][]string{},
+	}
+}
+
+// AddStream walks the config of a stream (input, buffer, pipeline and output)
+// and adds its components to the graph within a group of the given name.
+func (b *Builder) AddStream(group string, node *yaml.Node) error {
+	fields := mappingFields(node)
+
+	var exits []string
+	if n, exists := fields["input"]; exists {
+		var err error
+		if _, exits, err = b.component(group, "root.input", docs.TypeInput, n); err != nil {
+			return err
+		}
+	}
+
+	if n, exists := fields["buffer"]; exists {
+		if name, _, err := docs.GetInferenceCandidateFromYAML(b.prov, docs.TypeBuffer, n); err == nil && name != "none" {
+			entry, bExits, err := b.component(group, "root.buffer", docs.TypeBuffer, n)
+			if err != nil {
+				return err
+			}
+			b.connect(exits, entry)
+			exits = bExits
+		}
+	}
+
+	if n, exists := mappingFields(fields["pipeline"])["processors"]; exists {
+		entry, pExits, err := b.chain(group, indexedPaths("root.pipeline.processors", n.Content), docs.TypeProcessor, n.Content)
+		if err != nil {
+			return err
+		}
+		if entry != "" {
+			b.connect(exits, entry)
+			exits = pExits
+		}
+	}
+
+	if n, exists := fields["output"]; exists {
+		entry, _, err := b.component(group, "root.output", docs.TypeOutput, n)
+		if err != nil {
+			return err
+		}
+		b.connect(exits, entry)
+	}
+	return nil
+}
+
+var resourceFields = []struct {
+	name  string
+	cType docs.Type
+}{
+	{name: "input_resources", cType: docs.TypeInput},
+	{name: "processor_resources", cType: docs.TypeProcessor},
+	{name: "output_resources", cType: docs.TypeOutput},
+	{name: "cache_resources", cType: docs.TypeCache},
+	{name: "rate_limit_resources", cType: docs.TypeRateLimit},
+}
+
+// AddResources walks the resource fields of a config (input_resources,
+// processor_resources, etc) and adds them to the graph.
+func (b *Builder) AddResources(node *yaml.Node) error {
+	fields := mappingFields(node)
+	for _, rf := range resourceFields {
+		n, exists := fields[rf.name]
+		if !exists {
+			continue
+		}
+		for i, c := range n.Content {
+			path := "root." + rf.name + "." + strconv.Itoa(i)
+			label := scalarField(c, "label")
+			if label == "" {
+				return fmt.Errorf("%v: resource must have a label", path)
+			}
+			entry, _, err := b.component(ResourcesGroup, path, rf.cType, c)
+			if err != nil {
+				return err
+			}
+			if b.resources[rf.cType] == nil {
+				b.resources[rf.cType] = map[string]string{}
+			}
+			b.resources[rf.cType][label] = entry
+		}
+	}
+	return nil
+}
+
+// Build the graph, connecting references to resources and inproc outputs to
+// inputs of the same name.
+func (b *Builder) Build() *Graph {
+	for _, ref := range b.refs {
+		if to, exists := b.resources[ref.cType][ref.label]; exists {
+			b.addEdge(ref.from, to, EdgeResource, "")
+		}
+	}
+
+	names := make([]string, 0, len(b.inprocOutputs))
+	for k := range b.inprocOutputs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, from := range b.inprocOutputs[name] {
+			for _, to := range b.inprocInputs[name] {
+				b.addEdge(from, to, EdgeInproc, name)
+			}
+		}
+	}
+	return &b.g
+}
+
+//------------------------------------------------------------------------------
+
+func (b *Builder) addNode(group, path string, cType docs.Type, name, label string) string {
+	id := group + "/" + path
+	b.g.Nodes = append(b.g.Nodes, &Node{
+		ID:    id,
+		Group: group,
+		Path:  path,
+		Kind:  string(cType),
+		Type:  name,
+		Label: label,
+	})
+	return id
+}
+
+func (b *Builder) addEdge(from, to, kind, label string) {
+	key := [2]string{from, to}
+	if _, exists := b.edgeSet[key]; exists {
+		return
+	}
+	b.edgeSet[key] = struct{}{}
+	b.g.Edges = append(b.g.Edges, &Edge{From: from, To: to, Kind: kind, Label: label})
+}
+
+func (b *Builder) connect(from []string, to string) {
+	for _, f := range from {
+		b.addEdge(f, to, EdgeData, "")
+	}
+}
+
+func indexedPaths(path string, nodes []*yaml.Node) []string {
+	paths := make([]string, len(nodes))
+	for i := range nodes {
+		paths[i] = path + "." + strconv.Itoa(i)
+	}
+	return paths
+}
+
+// chain adds a sequence of components where messages flow from one to the
+// next, returning the entry of the first and the exits of the last.
+func (b *Builder) chain(group string, paths []string, cType docs.Type, nodes []*yaml.Node) (entry string, exits []string, err error) {
+	for i, n := range nodes {
+		cEntry, cExits, err := b.component(group, paths[i], cType, n)
+		if err != nil {
+			return "", nil, err
+		}
+		if i == 0 {
+			entry = cEntry
+		} else {
+			b.connect(exits, cEntry)
+		}
+		exits = cExits
+	}
+	return
+}
+
+// mergingProcessors are processors that run their nested processors on copies
+// of messages and merge the results back into the originals.
+var mergingProcessors = map[string]bool{
+	"branch":   true,
+	"workflow": true,
+}
+
+// component adds a component along with any components nested within it,
+// returning the node that messages flow into and the nodes that messages flow
+// out of.
+func (b *Builder) component(group, path string, cType docs.Type, node *yaml.Node) (entry string, exits []string, err error) {
+	name, spec, err := docs.GetInferenceCandidateFromYAML(b.prov, cType, node)
+	if err != nil {
+		return "", nil, fmt.Errorf("%v: %w", path, err)
+	}
+
+	id := b.addNode(group, path, cType, name, scalarField(node, "label"))
+	entry, exits = id, []string{id}
+
+	confNode := mappingFields(node)[name]
+	b.references(id, cType, name, confNode)
+
+	var nested []nestedChain
+	if confNode != nil {
+		collectFieldChains(spec.Config, confNode, path+"."+name, &nested)
+	}
+
+	var procChain nestedChain
+	if n, exists := mappingFields(node)["processors"]; exists && (cType == docs.TypeInput || cType == docs.TypeOutput) {
+		procChain = nestedChain{cType: docs.TypeProcessor, paths: indexedPaths(path+".processors", n.Content), nodes: n.Content}
+	}
+
+	switch cType {
+	case docs.TypeInput:
+		for _, c := range nested {
+			cEntry, cExits, err := b.chain(group, c.paths, c.cType, c.nodes)
+			if err != nil {
+				return "", nil, err
+			}
+			if c.cType == docs.TypeInput {
+				b.connect(cExits, id)
+			} else if cEntry != "" {
+				b.connect([]string{id}, cEntry)
+			}
+		}
+		if pEntry, pExits, err := b.chain(group, procChain.paths, procChain.cType, procChain.nodes); err != nil {
+			return "", nil, err
+		} else if pEntry != "" {
+			b.connect(exits, pEntry)
+			exits = pExits
+		}
+	case docs.TypeProcessor:
+		var branchExits []string
+		for _, c := range nested {
+			cEntry, cExits, err := b.chain(group, c.paths, c.cType, c.nodes)
+			if err != nil {
+				return "", nil, err
+			}
+			if cEntry == "" {
+				continue
+			}
+			b.connect([]string{id}, cEntry)
+			if c.cType == docs.TypeProcessor {
+				branchExits = append(branchExits, cExits...)
+			}
+		}
+		// Messages only leave through nested processors when they are run in
+		// sequence, processors such as branch and workflow merge the results
+		// of their nested processors back into the original messages.
+		if len(branchExits) > 0 && !mergingProcessors[name] {
+			exits = branchExits
+		}
+	case docs.TypeOutput:
+		for _, c := range nested {
+			cEntry, _, err := b.chain(group, c.paths, c.cType, c.nodes)
+			if err != nil {
+				return "", nil, err
+			}
+			if cEntry != "" {
+				b.connect([]string{id}, cEntry)
+			}
+		}
+		if pEntry, pExits, err := b.chain(group, procChain.paths, procChain.cType, procChain.nodes); err != nil {
+			return "", nil, err
+		} else if pEntry != "" {
+			b.connect(pExits, id)
+			entry = pEntry
+		}
+		exits = nil
+	}
+	return
+}
+
+// references records the resources and inproc connections referenced by the
+// config of a component.
+func (b *Builder) references(id string, cType docs.Type, name string, confNode *yaml.Node) {
+	if confNode == nil {
+		return
+	}
+
+	if confNode.Kind == yaml.ScalarNode {
+		switch name {
+		case "resource":
+			b.refs = append(b.refs, resourceRef{from: id, cType: cType, label: confNode.Value})
+		case "inproc":
+			if cType == docs.TypeInput {
+				b.inprocInputs[confNode.Value] = append(b.inprocInputs[confNode.Value], id)
+			} else if cType == docs.TypeOutput {
+				b.inprocOutputs[confNode.Value] = append(b.inprocOutputs[confNode.Value], id)
+			}
+		}
+		return
+	}
+
+	fields := mappingFields(confNode)
+	if cType == docs.TypeProcessor {
+		switch name {
+		case "cache":
+			b.addRef(id, docs.TypeCache, fields["resource"])
+		case "rate_limit":
+			b.addRef(id, docs.TypeRateLimit, fields["resource"])
+		case "workflow":
+			if n, exists := fields["branch_resources"]; exists {
+				for _, r := range n.Content {
+					b.addRef(id, docs.TypeProcessor, r)
+				}
+			}
+		}
+	}
+	b.addRef(id, docs.TypeCache, fields["cache"])
+	b.addRef(id, docs.TypeRateLimit, fields["rate_limit"])
+}
+
+func (b *Builder) addRef(id string, cType docs.Type, n *yaml.Node) {
+	if n == nil || n.Kind != yaml.ScalarNode || n.Value == "" {
+		return
+	}
+	b.refs = append(b.refs, resourceRef{from: id, cType: cType, label: n.Value})
+}
+
+//------------------------------------------------------------------------------
+
+type nestedChain struct {
+	cType docs.Type
+	paths []string
+	nodes []*yaml.Node
+}
+
+// collectChains walks the fields of a component config and collects the
+// components nested within it, where an array of processors is a chain that
+// messages flow through in sequence and other components are each a chain of
+// their own.
+func collectChains(specs docs.FieldSpecs, node *yaml.Node, path string, chains *[]nestedChain) {
+	fields := mappingFields(node)
+	for _, f := range specs {
+		n, exists := fields[f.Name]
+		if !exists {
+			continue
+		}
+		collectFieldChains(f, n, path+"."+f.Name, chains)
+	}
+}
+
+func collectFieldChains(f docs.FieldSpec, node *yaml.Node, path string, chains *[]nestedChain) {
+	coreType, isCore := f.Type.IsCoreComponent()
+	if !isCore && len(f.Children) == 0 {
+		return
+	}
+
+	// Each call adds a chain of components, or for fields that are not
+	// components walks the children of each node.
+	add := func(paths []string, nodes []*yaml.Node) {
+		if isCore {
+			*chains = append(*chains, nestedChain{cType: coreType, paths: paths, nodes: nodes})
+			return
+		}
+		for i, n := range nodes {
+			collectChains(f.Children, n, paths[i], chains)
+		}
+	}
+
+	switch f.Kind {
+	case docs.Kind2DArray:
+		for i, n := range node.Content {
+			add(indexedPaths(path+"."+strconv.Itoa(i), n.Content), n.Content)
+		}
+	case docs.KindArray:
+		if isCore && coreType == docs.TypeProcessor {
+			add(indexedPaths(path, node.Content), node.Content)
+		} else {
+			for i, n := range node.Content {
+				add([]string{path + "." + strconv.Itoa(i)}, []*yaml.Node{n})
+			}
+		}
+	case docs.KindMap:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			add([]string{path + "." + node.Content[i].Value}, []*yaml.Node{node.Content[i+1]})
+		}
+	default:
+		add([]string{path}, []*yaml.Node{node})
+	}
+}
+
+func mappingFields(node *yaml.Node) map[string]*yaml.Node {
+	fields := map[string]*yaml.Node{}
+	if node == nil {
+		return fields
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return fields
+	}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		fields[node.Content[i].Value] = node.Content[i+1]
+	}
+	return fields
+}
+
+func scalarField(node *yaml.Node, name string) string {
+	if n, exists := mappingFields(node)[name]; exists && n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config/graph"
+
+	_ "github.com/benthosdev/benthos/v4/public/components/pure"
+)
+
+func yamlNode(t testing.TB, str string) *yaml.Node {
+	t.Helper()
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(str), &node))
+	return &node
+}
+
+func edgeStrs(g *graph.Graph) []string {
+	var edges []string
+	for _, e := range g.Edges {
+		str := e.From + " -> " + e.To
+		if e.Kind != graph.EdgeData {
+			str += " (" + e.Kind + ")"
+		}
+		edges = append(edges, str)
+	}
+	return edges
+}
+
+func TestGraphStream(t *testing.T) {
+	b := graph.NewBuilder(bundle.GlobalEnvironment)
+	require.NoError(t, b.AddStream("main", yamlNode(t, `
+input:
+  broker:
+    inputs:
+      - generate:
+          mapping: 'root = 1'
+      - generate:
+          mapping: 'root = 2'
+  processors:
+    - mapping: 'root = this'
+pipeline:
+  processors:
+    - switch:
+        - check: 'this == 1'
+          processors:
+            - log:
+                message: one
+        - processors:
+            - log:
+                message: other
+    - branch:
+        processors:
+          - log:
+              message: branched
+    - mapping: 'root = this'
+output:
+  fallback:
+    - drop: {}
+    - reject: nope
+      processors:
+        - mapping: 'root = this'
+`)))
+
+	g := b.Build()
+	assert.Len(t, g.Nodes, 14)
+	assert.ElementsMatch(t, []string{
+		"main/root.input.broker.inputs.0 -> main/root.input",
+		"main/root.input.broker.inputs.1 -> main/root.input",
+		"main/root.input -> main/root.input.processors.0",
+		"main/root.input.processors.0 -> main/root.pipeline.processors.0",
+		"main/root.pipeline.processors.0 -> main/root.pipeline.processors.0.switch.0.processors.0",
+		"main/root.pipeline.processors.0 -> main/root.pipeline.processors.0.switch.1.processors.0",
+		"main/root.pipeline.processors.0.switch.0.processors.0 -> main/root.pipeline.processors.1",
+		"main/root.pipeline.processors.0.switch.1.processors.0 -> main/root.pipeline.processors.1",
+		"main/root.pipeline.processors.1 -> main/root.pipeline.processors.1.branch.processors.0",
+		"main/root.pipeline.processors.1 -> main/root.pipeline.processors.2",
+		"main/root.pipeline.processors.2 -> main/root.output",
+		"main/root.output -> main/root.output.fallback.0",
+		"main/root.output -> main/root.output.fallback.1.processors.0",
+		"main/root.output.fallback.1.processors.0 -> main/root.output.fallback.1",
+	}, edgeStrs(g))
+}
+
+func TestGraphResourcesAndInproc(t *testing.T) {
+	b := graph.NewBuilder(bundle.GlobalEnvironment)
+	require.NoError(t, b.AddResources(yamlNode(t, `
+processor_resources:
+  - label: foo
+    mapping: 'root = this'
+cache_resources:
+  - label: bar
+    memory: {}
+`)))
+	require.NoError(t, b.AddStream("first", yamlNode(t, `
+input:
+  generate:
+    mapping: 'root = 1'
+pipeline:
+  processors:
+    - resource: foo
+    - workflow:
+        branches:
+          baz:
+            processors:
+              - cache:
+                  resource: bar
+                  operator: get
+                  key: x
+output:
+  inproc: buz
+`)))
+	require.NoError(t, b.AddStream("second", yamlNode(t, `
+input:
+  inproc: buz
+output:
+  drop: {}
+`)))
+
+	g := b.Build()
+	assert.ElementsMatch(t, []string{
+		"first/root.input -> first/root.pipeline.processors.0",
+		"first/root.pipeline.processors.0 -> first/root.pipeline.processors.1",
+		"first/root.pipeline.processors.1 -> first/root.pipeline.processors.1.workflow.branches.baz.processors.0",
+		"first/root.pipeline.processors.1 -> first/root.output",
+		"second/root.input -> second/root.output",
+		"first/root.pipeline.processors.0 -> resources/root.processor_resources.0 (resource)",
+		"first/root.pipeline.processors.1.workflow.branches.baz.processors.0 -> resources/root.cache_resources.0 (resource)",
+		"first/root.output -> second/root.input (inproc)",
+	}, edgeStrs(g))
+
+	assert.Contains(t, string(g.DOT()), `"first/root.output" -> "second/root.input" [style=bold, color=blue, label="buz"];`)
+	assert.Contains(t, string(g.Mermaid()), `subgraph s0 ["resources"]`)
+	assert.Contains(t, string(g.Mermaid()), `n0["foo (mapping)<br/><i>processor</i>"]`)
+
+	jBytes, err := g.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(jBytes), `"kind": "inproc"`)
+}
+
+func TestGraphErrors(t *testing.T) {
+	b := graph.NewBuilder(bundle.GlobalEnvironment)
+	require.EqualError(t, b.AddResources(yamlNode(t, `
+processor_resources:
+  - mapping: 'root = this'
+`)), "root.processor_resources.0: resource must have a label")
+
+	require.Error(t, b.AddStream("main", yamlNode(t, `
+input:
+  not_a_real_input: {}
+`)))
+}
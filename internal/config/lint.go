@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/fs"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 )
 
 // LintOptions specifies the linters that will be enabled.
@@ -20,22 +22,19 @@ type LintOptions struct {
 }
 
 // ReadFileLinted will attempt to read a configuration file path into a
-// structure. Returns an array of lint messages or an error.
+// structure, resolving any fragments that it includes. Returns an array of lint
+// messages or an error.
 func ReadFileLinted(fs ifs.FS, path string, opts LintOptions, config *Type) ([]docs.Lint, error) {
-	configBytes, lints, _, err := ReadFileEnvSwap(fs, path)
+	configBytes, rawNode, lints, err := NewReader(path, nil, OptUseFS(fs), OptLenientSecrets()).readComposed(path, Spec())
 	if err != nil {
 		return nil, err
 	}
 
-	if err := yaml.Unmarshal(configBytes, config); err != nil {
+	if err := rawNode.Decode(config); err != nil {
 		return nil, err
 	}
 
-	newLints, err := LintBytes(opts, configBytes)
-	if err != nil {
-		return nil, err
-	}
-	lints = append(lints, newLints...)
+	lints = append(lints, lintNode(opts, configBytes, rawNode)...)
 	return lints, nil
 }
 
@@ -50,21 +49,36 @@ func LintBytes(opts LintOptions, rawBytes []byte) ([]docs.Lint, error) {
 	if err := yaml.Unmarshal(rawBytes, &rawNode); err != nil {
 		return nil, err
 	}
+	return lintNode(opts, rawBytes, &rawNode), nil
+}
+
+func lintNode(opts LintOptions, rawBytes []byte, rawNode *yaml.Node) []docs.Lint {
+	if bytes.HasPrefix(rawBytes, []byte("# BENTHOS LINT DISABLE")) {
+		return nil
+	}
 
 	lintCtx := docs.NewLintContext()
 	lintCtx.RejectDeprecated = opts.RejectDeprecated
 	lintCtx.RequireLabels = opts.RequireLabels
 
-	return Spec().LintYAML(lintCtx, &rawNode), nil
+	return Spec().LintYAML(lintCtx, rawNode)
 }
 
-// ReadFileEnvSwap reads a file and replaces any environment variable
+// ReadFileEnvSwap reads a file and replaces any secret and environment variable
 // interpolations before returning the contents. Linting errors are returned if
 // the file has an unexpected higher level format, such as invalid utf-8
 // encoding.
 //
 // An modTime timestamp is returned if the modtime of the file is available.
 func ReadFileEnvSwap(store ifs.FS, path string) (configBytes []byte, lints []docs.Lint, modTime time.Time, err error) {
+	return readFileEnvSwap(store, path, false)
+}
+
+// readFileEnvSwap is the same as ReadFileEnvSwap, but when lenientSecrets is
+// true secret references that cannot be resolved are left in place and reported
+// as linting warnings rather than errors, similar to how environment variables
+// that are not set do not prevent a config from being read.
+func readFileEnvSwap(store ifs.FS, path string, lenientSecrets bool) (configBytes []byte, lints []docs.Lint, modTime time.Time, err error) {
 	var configFile fs.File
 	if configFile, err = store.Open(path); err != nil {
 		return
@@ -85,6 +99,15 @@ func ReadFileEnvSwap(store ifs.FS, path string) (configBytes []byte, lints []doc
 		))
 	}
 
-	configBytes = ReplaceEnvVariables(configBytes)
+	if !lenientSecrets {
+		configBytes, err = ReplaceSecretsAndEnvVariables(configBytes)
+		return
+	}
+
+	var secretErrs []secrets.ReferenceError
+	configBytes, secretErrs = secrets.ReplaceResolvable(context.Background(), ReplaceEnvVariables(configBytes))
+	for _, e := range secretErrs {
+		lints = append(lints, docs.NewLintWarning(e.Line, docs.LintFailedRead, e.Error()))
+	}
 	return
 }
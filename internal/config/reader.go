@@ -48,12 +48,20 @@ type Reader struct {
 	resourcePaths []string
 	streamsPaths  []string
 	overrides     []string
+	profiles      []string
+
+	// Tracks the config files that included fragments were last read by.
+	includedBy map[string]string
 
 	modTimeLastRead map[string]time.Time
 
 	// Controls whether the main config should include input, output, etc.
 	streamsMode bool
 
+	// Controls whether secrets that cannot be resolved are reported as lints
+	// rather than errors.
+	lenientSecrets bool
+
 	// Tracks the details of the config file when we last read it.
 	configFileInfo resourceFileInfo
 
@@ -85,6 +93,7 @@ func NewReader(mainPath string, resourcePaths []string, opts ...OptFunc) *Reader
 		fs:                 ifs.OS(),
 		mainPath:           mainPath,
 		resourcePaths:      resourcePaths,
+		includedBy:         map[string]string{},
 		modTimeLastRead:    map[string]time.Time{},
 		streamFileInfo:     map[string]streamFileInfo{},
 		resourceFileInfo:   map[string]resourceFileInfo{},
@@ -121,6 +130,15 @@ func OptAddOverrides(overrides ...string) OptFunc {
 	}
 }
 
+// OptSetProfiles selects one or more profiles, where the overlays defined for
+// each profile within the `profiles` field of a config file are merged on top
+// of the config in the order they are listed.
+func OptSetProfiles(profiles ...string) OptFunc {
+	return func(r *Reader) {
+		r.profiles = append(r.profiles, profiles...)
+	}
+}
+
 // OptSetStreamPaths marks this config reader as operating in streams mode, and
 // adds a list of paths to obtain individual stream configs from.
 func OptSetStreamPaths(streamsPaths ...string) OptFunc {
@@ -130,6 +148,15 @@ func OptSetStreamPaths(streamsPaths ...string) OptFunc {
 	}
 }
 
+// OptLenientSecrets configures the reader to leave secret references that
+// cannot be resolved in place and report them as linting warnings rather than
+// failing, which is useful for reading configs without running them.
+func OptLenientSecrets() OptFunc {
+	return func(r *Reader) {
+		r.lenientSecrets = true
+	}
+}
+
 // OptUseFS sets the ifs.FS implementation for the reader to use. By default the
 // OS filesystem is used, and when overridden it is no longer possible to use
 // BeginFileWatching.
@@ -251,36 +278,31 @@ func (r *Reader) readMain(conf *Type) (lints []string, err error) {
 		return
 	}
 
-	var rawNode yaml.Node
+	confSpec := Spec()
+	if r.streamsMode {
+		// Spec is limited to just non-stream fields when in streams mode (no
+		// input, output, etc)
+		confSpec = SpecWithoutStream()
+	}
+
+	rawNode := &yaml.Node{}
 	var confBytes []byte
 	if r.mainPath != "" {
 		var dLints []docs.Lint
-		var modTime time.Time
-		if confBytes, dLints, modTime, err = ReadFileEnvSwap(r.fs, r.mainPath); err != nil {
+		if confBytes, rawNode, dLints, err = r.readComposed(r.mainPath, confSpec); err != nil {
 			return
 		}
 		for _, l := range dLints {
 			lints = append(lints, l.Error())
 		}
-		r.modTimeLastRead[r.mainPath] = modTime
-		if err = yaml.Unmarshal(confBytes, &rawNode); err != nil {
-			return
-		}
-	}
-
-	confSpec := Spec()
-	if r.streamsMode {
-		// Spec is limited to just non-stream fields when in streams mode (no
-		// input, output, etc)
-		confSpec = SpecWithoutStream()
 	}
-	if err = applyOverrides(confSpec, &rawNode, r.overrides...); err != nil {
+	if err = applyOverrides(confSpec, rawNode, r.overrides...); err != nil {
 		return
 	}
 
 	if !bytes.HasPrefix(confBytes, []byte("# BENTHOS LINT DISABLE")) {
 		lintFilePrefix := r.mainPath
-		for _, lint := range confSpec.LintYAML(docs.NewLintContext(), &rawNode) {
+		for _, lint := range confSpec.LintYAML(docs.NewLintContext(), rawNode) {
 			lints = append(lints, fmt.Sprintf("%v%v", lintFilePrefix, lint.Error()))
 		}
 	}
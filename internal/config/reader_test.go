@@ -91,3 +91,33 @@ processor_resources:
 	assert.Equal(t, "c", conf.ResourceProcessors[2].Label)
 	assert.Equal(t, "d", conf.ResourceProcessors[3].Label)
 }
+
+func TestReaderLenientSecrets(t *testing.T) {
+	testFS := &testFS{m: fstest.MapFS{
+		"main.yaml": &fstest.MapFile{
+			Data: []byte(`
+input:
+  label: fooin
+  inproc: ${secret:nope:foo}
+
+output:
+  label: fooout
+  inproc: bar
+`),
+		},
+	}}
+
+	conf := New()
+	_, err := newDummyReader("main.yaml", nil, OptUseFS(testFS)).Read(&conf)
+	require.Error(t, err)
+
+	conf = New()
+	lints, err := newDummyReader("main.yaml", nil, OptUseFS(testFS), OptLenientSecrets()).Read(&conf)
+	require.NoError(t, err)
+	require.Len(t, lints, 1)
+	assert.Contains(t, lints[0], "(4,1)")
+	assert.Contains(t, lints[0], "secret provider 'nope' was not recognised")
+
+	assert.Equal(t, "${secret:nope:foo}", string(conf.Input.Inproc))
+	assert.Equal(t, "bar", string(conf.Output.Inproc))
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"reflect"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -204,26 +205,21 @@ func (r *Reader) readResource(path string, conf *manager.ResourceConfig) (lints
 		}
 	}()
 
+	allowTest := append(docs.FieldSpecs{
+		tdocs.ConfigSpec(),
+	}, manager.Spec()...)
+
 	var confBytes []byte
+	var rawNode *yaml.Node
 	var dLints []docs.Lint
-	var modTime time.Time
-	if confBytes, dLints, modTime, err = ReadFileEnvSwap(r.fs, path); err != nil {
+	if confBytes, rawNode, dLints, err = r.readComposed(path, allowTest); err != nil {
 		return
 	}
 	for _, l := range dLints {
 		lints = append(lints, l.Error())
 	}
-	r.modTimeLastRead[path] = modTime
-
-	var rawNode yaml.Node
-	if err = yaml.Unmarshal(confBytes, &rawNode); err != nil {
-		return
-	}
 	if !bytes.HasPrefix(confBytes, []byte("# BENTHOS LINT DISABLE")) {
-		allowTest := append(docs.FieldSpecs{
-			tdocs.ConfigSpec(),
-		}, manager.Spec()...)
-		for _, lint := range allowTest.LintYAML(docs.NewLintContext(), &rawNode) {
+		for _, lint := range allowTest.LintYAML(docs.NewLintContext(), rawNode) {
 			lints = append(lints, fmt.Sprintf("%v%v", path, lint.Error()))
 		}
 	}
@@ -287,6 +283,9 @@ func (r *Reader) applyResourceChanges(path string, mgr bundle.NewManagement, cur
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
+	// Resources with a config that hasn't changed are left untouched, which
+	// avoids reconnecting them and losing state such as the contents of caches.
+	//
 	// WARNING: The order here is actually kind of important, we want to start
 	// with components that could be dependencies of other components. This is
 	// a "best attempt", so not all edge cases need to be accounted for.
@@ -297,6 +296,9 @@ func (r *Reader) applyResourceChanges(path string, mgr bundle.NewManagement, cur
 	}
 	for k, v := range currentInfo.rateLimits {
 		delete(unaccounted, k)
+		if prev, exists := prevInfo.rateLimits[k]; exists && reflect.DeepEqual(prev, v) {
+			continue
+		}
 		if err := mgr.StoreRateLimit(ctx, k, *v); err != nil {
 			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
 			return fmt.Errorf("resource %v: %w", k, err)
@@ -313,6 +315,9 @@ func (r *Reader) applyResourceChanges(path string, mgr bundle.NewManagement, cur
 	}
 	for k, v := range currentInfo.caches {
 		delete(unaccounted, k)
+		if prev, exists := prevInfo.caches[k]; exists && reflect.DeepEqual(prev, v) {
+			continue
+		}
 		if err := mgr.StoreCache(ctx, k, *v); err != nil {
 			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
 			return fmt.Errorf("resource %v: %w", k, err)
@@ -329,6 +334,9 @@ func (r *Reader) applyResourceChanges(path string, mgr bundle.NewManagement, cur
 	}
 	for k, v := range currentInfo.processors {
 		delete(unaccounted, k)
+		if prev, exists := prevInfo.processors[k]; exists && reflect.DeepEqual(prev, v) {
+			continue
+		}
 		if err := mgr.StoreProcessor(ctx, k, *v); err != nil {
 			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
 			return fmt.Errorf("resource %v: %w", k, err)
@@ -345,6 +353,9 @@ func (r *Reader) applyResourceChanges(path string, mgr bundle.NewManagement, cur
 	}
 	for k, v := range currentInfo.inputs {
 		delete(unaccounted, k)
+		if prev, exists := prevInfo.inputs[k]; exists && reflect.DeepEqual(prev, v) {
+			continue
+		}
 		if err := mgr.StoreInput(ctx, k, *v); err != nil {
 			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
 			return fmt.Errorf("resource %v: %w", k, err)
@@ -361,6 +372,9 @@ func (r *Reader) applyResourceChanges(path string, mgr bundle.NewManagement, cur
 	}
 	for k, v := range currentInfo.outputs {
 		delete(unaccounted, k)
+		if prev, exists := prevInfo.outputs[k]; exists && reflect.DeepEqual(prev, v) {
+			continue
+		}
 		if err := mgr.StoreOutput(ctx, k, *v); err != nil {
 			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
 			return fmt.Errorf("resource %v: %w", k, err)
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/message"
@@ -238,3 +239,64 @@ processor_resources:
 	testProc("barproc", "hello world", "hello world and a replaced bar")
 	testProc("bazproc", "hello world", "hello world and a new baz")
 }
+
+func TestReaderResourceUnchangedLeftUntouched(t *testing.T) {
+	confDir := t.TempDir()
+
+	resPath := filepath.Join(confDir, "res.yaml")
+	require.NoError(t, os.WriteFile(resPath, []byte(`
+cache_resources:
+  - label: foocache
+    memory: {}
+`), 0o644))
+
+	rdr := NewReader("", []string{resPath})
+
+	conf := New()
+	lints, err := rdr.Read(&conf)
+	require.NoError(t, err)
+	require.Empty(t, lints)
+
+	testMgr, err := manager.New(conf.ResourceConfig)
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, testMgr.AccessCache(tCtx, "foocache", func(c cache.V1) {
+		require.NoError(t, c.Set(tCtx, "foo", []byte("bar"), nil))
+	}))
+
+	// Adding a resource to the file does not recreate the unchanged cache.
+	require.NoError(t, os.WriteFile(resPath, []byte(`
+cache_resources:
+  - label: foocache
+    memory: {}
+  - label: barcache
+    memory: {}
+`), 0o644))
+	require.NoError(t, rdr.TriggerResourceUpdate(testMgr, true, resPath))
+
+	require.True(t, testMgr.ProbeCache("barcache"))
+	require.NoError(t, testMgr.AccessCache(tCtx, "foocache", func(c cache.V1) {
+		v, err := c.Get(tCtx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "bar", string(v))
+	}))
+
+	// Changing the cache recreates it.
+	require.NoError(t, os.WriteFile(resPath, []byte(`
+cache_resources:
+  - label: foocache
+    memory:
+      default_ttl: 1h
+  - label: barcache
+    memory: {}
+`), 0o644))
+	require.NoError(t, rdr.TriggerResourceUpdate(testMgr, true, resPath))
+
+	require.NoError(t, testMgr.AccessCache(tCtx, "foocache", func(c cache.V1) {
+		_, err := c.Get(tCtx, "foo")
+		assert.Error(t, err)
+	}))
+}
@@ -7,7 +7,6 @@ import (
 	"io/fs"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -45,27 +44,21 @@ func inferStreamID(dir, path string) (string, error) {
 func (r *Reader) readStreamFileConfig(path string) (conf stream.Config, lints []string, err error) {
 	conf = stream.NewConfig()
 
+	confSpec := stream.Spec()
+	confSpec = append(confSpec, tdocs.ConfigSpec())
+
 	var confBytes []byte
+	var rawNode *yaml.Node
 	var dLints []docs.Lint
-	var modTime time.Time
-	if confBytes, dLints, modTime, err = ReadFileEnvSwap(r.fs, path); err != nil {
+	if confBytes, rawNode, dLints, err = r.readComposed(path, confSpec); err != nil {
 		return
 	}
 	for _, l := range dLints {
 		lints = append(lints, l.Error())
 	}
-	r.modTimeLastRead[path] = modTime
-
-	var rawNode yaml.Node
-	if err = yaml.Unmarshal(confBytes, &rawNode); err != nil {
-		return
-	}
-
-	confSpec := stream.Spec()
-	confSpec = append(confSpec, tdocs.ConfigSpec())
 
 	if !bytes.HasPrefix(confBytes, []byte("# BENTHOS LINT DISABLE")) {
-		for _, lint := range confSpec.LintYAML(docs.NewLintContext(), &rawNode) {
+		for _, lint := range confSpec.LintYAML(docs.NewLintContext(), rawNode) {
 			lints = append(lints, fmt.Sprintf("%v%v", path, lint.Error()))
 		}
 	}
@@ -125,7 +125,12 @@ func (r *Reader) BeginFileWatching(mgr bundle.NewManagement, strict bool) error
 		if err := addNotWatching(resourcePaths); err != nil {
 			return err
 		}
-		return nil
+
+		var includePaths []string
+		for p := range r.includedBy {
+			includePaths = append(includePaths, p)
+		}
+		return addNotWatching(includePaths)
 	}
 
 	if err := refreshFiles(); err != nil {
@@ -171,13 +176,20 @@ func (r *Reader) BeginFileWatching(mgr bundle.NewManagement, strict bool) error
 					if time.Since(change.at) < r.changeDelayPeriod {
 						continue
 					}
+					// Changes to an included fragment are applied by
+					// re-reading the file that included it.
+					target := nameClean
+					if root, exists := r.includedBy[nameClean]; exists {
+						target = root
+					}
+
 					var succeeded bool
-					if nameClean == r.mainPath {
+					if target == r.mainPath {
 						succeeded = !ShouldReread(r.TriggerMainUpdate(mgr, strict))
-					} else if _, exists := r.streamFileInfo[nameClean]; exists {
-						succeeded = !ShouldReread(r.TriggerStreamUpdate(mgr, strict, nameClean))
+					} else if _, exists := r.streamFileInfo[target]; exists {
+						succeeded = !ShouldReread(r.TriggerStreamUpdate(mgr, strict, target))
 					} else {
-						succeeded = !ShouldReread(r.TriggerResourceUpdate(mgr, strict, nameClean))
+						succeeded = !ShouldReread(r.TriggerResourceUpdate(mgr, strict, target))
 					}
 					if succeeded {
 						delete(collapsedChanges, nameClean)
@@ -62,6 +62,59 @@ output:
 	assert.Equal(t, "drop", updatedConf.Output.Type)
 }
 
+func TestReaderIncludeWatching(t *testing.T) {
+	confDir := t.TempDir()
+
+	confFilePath := filepath.Join(confDir, "main.yaml")
+	require.NoError(t, os.WriteFile(confFilePath, []byte(`
+include: output.yaml
+input:
+  generate:
+    mapping: 'root = "foo"'
+`), 0o644))
+
+	outFilePath := filepath.Join(confDir, "output.yaml")
+	require.NoError(t, os.WriteFile(outFilePath, []byte(`
+output:
+  drop: {}
+`), 0o644))
+
+	rdr := newDummyReader(confFilePath, nil)
+
+	conf := New()
+	lints, err := rdr.Read(&conf)
+	require.NoError(t, err)
+	require.Empty(t, lints)
+	assert.Equal(t, "drop", conf.Output.Type)
+
+	changeChan := make(chan struct{})
+	var updatedConf stream.Config
+	require.NoError(t, rdr.SubscribeConfigChanges(func(conf stream.Config) error {
+		updatedConf = conf
+		close(changeChan)
+		return nil
+	}))
+
+	testMgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+	require.NoError(t, rdr.BeginFileWatching(testMgr, true))
+
+	// Modifying the included file triggers an update of the main config
+	require.NoError(t, os.WriteFile(outFilePath, []byte(`
+output:
+  reject: nope
+`), 0o644))
+
+	select {
+	case <-changeChan:
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "Expected a config change to be triggered")
+	}
+
+	assert.Equal(t, "generate", updatedConf.Input.Type)
+	assert.Equal(t, "reject", updatedConf.Output.Type)
+}
+
 func TestReaderFileWatchingSymlinkReplace(t *testing.T) {
 	dummyConfig := []byte(`
 input:
@@ -103,4 +103,13 @@ type ComponentSpec struct {
 
 	// Version is the Benthos version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// Migration is an optional Bloblang mapping that rewrites the config of
+	// this component into the config of a successor, which is used by the
+	// migrate subcommand. The mapping is provided the config of the component
+	// as the context `this` and must assign to `root` an object with a single
+	// key, the name of the successor, and the config of the successor as the
+	// value. When a config cannot be migrated the mapping should either delete
+	// the root or throw an error explaining why.
+	Migration string `json:"migration,omitempty"`
 }
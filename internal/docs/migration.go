@@ -0,0 +1,154 @@
+package docs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// ErrNoMigration is returned when the migration mapping of a component deletes
+// the root, indicating that the config of the component cannot be migrated.
+var ErrNoMigration = errors.New("no migration is possible for this config")
+
+// migrate executes the migration mapping of a component against its config,
+// returning the name of the successor component along with a node of its
+// config.
+func (c ComponentSpec) migrate(conf *yaml.Node) (string, *yaml.Node, error) {
+	m, err := bloblang.NewEnvironment().OnlyPure().Parse(c.Migration)
+	if err != nil {
+		return "", nil, fmt.Errorf("migration mapping failed to parse: %w", err)
+	}
+
+	var value any = map[string]any{}
+	if conf != nil {
+		if err := conf.Decode(&value); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var res any
+	if err := m.Overlay(value, &res); err != nil {
+		if errors.Is(err, bloblang.ErrRootDeleted) {
+			return "", nil, ErrNoMigration
+		}
+		return "", nil, err
+	}
+
+	obj, ok := res.(map[string]any)
+	if !ok || len(obj) != 1 {
+		return "", nil, errors.New("migration mapping must result in an object with a single key")
+	}
+
+	var name string
+	var newValue any
+	for k, v := range obj {
+		name, newValue = k, v
+	}
+	newConf, err := transplantNode(conf, newValue)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, newConf, nil
+}
+
+// transplantNode creates a YAML node from a value, reusing the nodes (and
+// therefore comments) of an original node wherever the value matches.
+func transplantNode(original *yaml.Node, value any) (*yaml.Node, error) {
+	if original != nil {
+		var originalValue any
+		if err := original.Decode(&originalValue); err == nil && reflect.DeepEqual(originalValue, value) {
+			return original, nil
+		}
+	}
+
+	obj, isObj := value.(map[string]any)
+	if !isObj || original == nil || original.Kind != yaml.MappingNode {
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return nil, err
+		}
+		if original != nil {
+			node.HeadComment = original.HeadComment
+			node.LineComment = original.LineComment
+			node.FootComment = original.FootComment
+		}
+		return &node, nil
+	}
+
+	node := &yaml.Node{
+		Kind:        yaml.MappingNode,
+		Tag:         "!!map",
+		Style:       original.Style,
+		HeadComment: original.HeadComment,
+		LineComment: original.LineComment,
+		FootComment: original.FootComment,
+	}
+
+	originalKeys := map[string]struct{}{}
+	for i := 0; i < len(original.Content)-1; i += 2 {
+		originalKeys[original.Content[i].Value] = struct{}{}
+	}
+
+	var newKeys []string
+	for k := range obj {
+		if _, exists := originalKeys[k]; !exists {
+			newKeys = append(newKeys, k)
+		}
+	}
+	sort.Strings(newKeys)
+
+	// Keys that exist within the original node retain their order. Keys that
+	// were removed but whose value has been moved unchanged to a new key are
+	// treated as a rename, and retain both their position and comments. The
+	// remaining new keys are appended in alphabetical order.
+	renamed := map[string]struct{}{}
+	for i := 0; i < len(original.Content)-1; i += 2 {
+		kNode, vNode := original.Content[i], original.Content[i+1]
+		if v, exists := obj[kNode.Value]; exists {
+			newVNode, err := transplantNode(vNode, v)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, kNode, newVNode)
+			continue
+		}
+
+		var originalValue any
+		if err := vNode.Decode(&originalValue); err != nil {
+			continue
+		}
+		for _, k := range newKeys {
+			if _, done := renamed[k]; done {
+				continue
+			}
+			if reflect.DeepEqual(originalValue, obj[k]) {
+				renamed[k] = struct{}{}
+				newKNode := *kNode
+				newKNode.Value = k
+				node.Content = append(node.Content, &newKNode, vNode)
+				break
+			}
+		}
+	}
+
+	for _, k := range newKeys {
+		if _, done := renamed[k]; done {
+			continue
+		}
+		vNode, err := transplantNode(nil, obj[k])
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!str",
+			Value: k,
+		}, vNode)
+	}
+	return node, nil
+}
@@ -0,0 +1,142 @@
+package docs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+func TestYAMLComponentMigration(t *testing.T) {
+	prov := docs.NewMappedDocsProvider()
+	prov.RegisterDocs(docs.ComponentSpec{
+		Name: "oldthing",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("woof", "").Optional(),
+			docs.FieldString("quack", "").Optional(),
+			docs.FieldProcessor("children", "").Array().Optional(),
+		),
+		Migration: `
+let conf = this.without("woof").assign(if this.woof != null {
+  { "bark": this.woof, "meow": "added" }
+} else {
+  {}
+})
+root = match {
+  this.quack == "nope" => deleted(),
+  this.quack == "throw" => throw("quack must not be throw"),
+  _ => { "newthing": $conf },
+}
+`,
+	})
+	prov.RegisterDocs(docs.ComponentSpec{
+		Name: "newthing",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("bark", "").Optional(),
+			docs.FieldString("meow", "").Optional(),
+			docs.FieldString("quack", "").Optional(),
+			docs.FieldProcessor("children", "").Array().Optional(),
+		),
+	})
+
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		res    []docs.MigratedComponent
+	}{
+		{
+			name: "rename with comments",
+			input: `
+# head comment
+processors:
+  - label: foo
+    # component comment
+    oldthing:
+      woof: hello # woof comment
+      # quack comment
+      quack: world
+`,
+			output: `# head comment
+processors:
+    - label: foo
+      # component comment
+      newthing:
+        bark: hello # woof comment
+        # quack comment
+        quack: world
+        meow: added
+`,
+			res: []docs.MigratedComponent{
+				{Line: 4, ComponentType: docs.TypeProcessor, From: "oldthing", To: "newthing"},
+			},
+		},
+		{
+			name: "nested components",
+			input: `
+processors:
+  - newthing:
+      children:
+        - oldthing:
+            quack: nope
+        - oldthing:
+            quack: throw
+        - oldthing:
+            quack: yep
+`,
+			output: `processors:
+    - newthing:
+        children:
+            - oldthing:
+                quack: nope
+            - oldthing:
+                quack: throw
+            - newthing:
+                quack: yep
+`,
+			res: []docs.MigratedComponent{
+				{Line: 5, ComponentType: docs.TypeProcessor, From: "oldthing", Err: docs.ErrNoMigration},
+				{Line: 7, ComponentType: docs.TypeProcessor, From: "oldthing"},
+				{Line: 9, ComponentType: docs.TypeProcessor, From: "oldthing", To: "newthing"},
+			},
+		},
+	}
+
+	spec := docs.FieldSpecs{
+		docs.FieldProcessor("processors", "").Array(),
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(test.input), &node))
+
+			var res []docs.MigratedComponent
+			require.NoError(t, spec.MigrateYAML(&node, prov, &res))
+
+			require.Len(t, res, len(test.res))
+			for i, exp := range test.res {
+				assert.Equal(t, exp.Line, res[i].Line, i)
+				assert.Equal(t, exp.ComponentType, res[i].ComponentType, i)
+				assert.Equal(t, exp.From, res[i].From, i)
+				assert.Equal(t, exp.To, res[i].To, i)
+				if exp.To == "" {
+					assert.Error(t, res[i].Err, i)
+				}
+				if exp.Err != nil {
+					assert.ErrorIs(t, res[i].Err, exp.Err, i)
+				}
+			}
+
+			b, err := yaml.Marshal(&node)
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(b))
+		})
+	}
+}
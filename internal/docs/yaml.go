@@ -1031,6 +1031,158 @@ func (f FieldSpecs) WalkYAML(node *yaml.Node, prov Provider, fn ComponentWalkYAM
 
 //------------------------------------------------------------------------------
 
+// MigratedComponent describes the outcome of migrating a component that
+// declares a migration mapping.
+type MigratedComponent struct {
+	Line          int
+	ComponentType Type
+	From          string
+	To            string
+	Err           error
+}
+
+func migrateComponentsYAML(cType Type, node *yaml.Node, prov Provider, res *[]MigratedComponent) error {
+	node = unwrapDocumentNode(node)
+
+	name, spec, err := GetInferenceCandidateFromYAML(prov, cType, node)
+	if err != nil {
+		return err
+	}
+
+	if spec.Migration != "" {
+		keyIndex := -1
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			if node.Content[i].Value == name {
+				keyIndex = i
+				break
+			}
+		}
+
+		var confNode *yaml.Node
+		if keyIndex >= 0 {
+			confNode = node.Content[keyIndex+1]
+		}
+
+		newName, newConf, err := spec.migrate(confNode)
+		switch {
+		case err != nil:
+			*res = append(*res, MigratedComponent{
+				Line:          node.Line,
+				ComponentType: cType,
+				From:          name,
+				Err:           err,
+			})
+		case newName == name && newConf == confNode:
+		default:
+			newSpec, exists := prov.GetDocs(newName, cType)
+			if !exists {
+				return fmt.Errorf("line %v: migration of %v %v resulted in unknown component %v", node.Line, cType, name, newName)
+			}
+			if keyIndex >= 0 {
+				node.Content[keyIndex].Value = newName
+				node.Content[keyIndex+1] = newConf
+			} else {
+				node.Content = append(node.Content, &yaml.Node{
+					Kind:  yaml.ScalarNode,
+					Tag:   "!!str",
+					Value: newName,
+				}, newConf)
+			}
+			for i := 0; i < len(node.Content)-1; i += 2 {
+				if node.Content[i].Value == "type" {
+					node.Content[i+1].Value = newName
+				}
+			}
+			*res = append(*res, MigratedComponent{
+				Line:          node.Line,
+				ComponentType: cType,
+				From:          name,
+				To:            newName,
+			})
+			name, spec = newName, newSpec
+		}
+	}
+
+	reservedFields := ReservedFieldsByType(cType)
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == name {
+			if err := spec.Config.migrateYAML(node.Content[i+1], prov, res); err != nil {
+				return err
+			}
+			continue
+		}
+		if node.Content[i].Value == "type" || node.Content[i].Value == "label" {
+			continue
+		}
+		if spec, exists := reservedFields[node.Content[i].Value]; exists {
+			if err := spec.migrateYAML(node.Content[i+1], prov, res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f FieldSpec) migrateYAML(node *yaml.Node, prov Provider, res *[]MigratedComponent) error {
+	node = unwrapDocumentNode(node)
+
+	var nodes []*yaml.Node
+	switch f.Kind {
+	case Kind2DArray:
+		for i := 0; i < len(node.Content); i++ {
+			nodes = append(nodes, node.Content[i].Content...)
+		}
+	case KindArray:
+		nodes = node.Content
+	case KindMap:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			nodes = append(nodes, node.Content[i+1])
+		}
+	default:
+		nodes = []*yaml.Node{node}
+	}
+
+	for _, n := range nodes {
+		if coreType, isCore := f.Type.IsCoreComponent(); isCore {
+			if err := migrateComponentsYAML(coreType, n, prov, res); err != nil {
+				return err
+			}
+		} else if len(f.Children) > 0 {
+			if err := f.Children.MigrateYAML(n, prov, res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MigrateYAML walks each node of a YAML tree and for any components within the
+// config that declare a migration mapping the config of the component is
+// rewritten in place. Comments of the original config are retained wherever
+// possible. The outcome of each attempted migration is appended to res,
+// including those that were not possible.
+func (f FieldSpecs) MigrateYAML(node *yaml.Node, prov Provider, res *[]MigratedComponent) error {
+	node = unwrapDocumentNode(node)
+
+	nodeKeys := map[string]*yaml.Node{}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		nodeKeys[node.Content[i].Value] = node.Content[i+1]
+	}
+
+	for _, field := range f {
+		value, exists := nodeKeys[field.Name]
+		if !exists {
+			continue
+		}
+		if err := field.migrateYAML(value, prov, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
 func unwrapDocumentNode(node *yaml.Node) *yaml.Node {
 	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
 		return node.Content[0]
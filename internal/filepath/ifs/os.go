@@ -48,6 +48,64 @@ func WriteFile(f fs.FS, name string, data []byte, perm fs.FileMode) error {
 	return err
 }
 
+// WriteFileAtomic writes data produced by fn to a temporary file alongside the
+// named file, which is synced to disk and then renamed over the named file once
+// fn returns without error. This prevents a crash mid-write from leaving a
+// partially written file behind. When the file system does not support renames
+// the data is written to the named file directly.
+func WriteFileAtomic(f fs.FS, name string, perm fs.FileMode, fn func(w io.Writer) error) error {
+	renamer, canRename := f.(interface {
+		Rename(oldname, newname string) error
+	})
+
+	target := name
+	if canRename {
+		target = name + ".tmp"
+	}
+
+	var h fs.File
+	var err error
+	if ef, ok := f.(FS); ok {
+		h, err = ef.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	} else {
+		h, err = os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	}
+	if err != nil {
+		return err
+	}
+
+	w, isw := h.(io.Writer)
+	if !isw {
+		_ = h.Close()
+		return errors.New("failed to open a writable file")
+	}
+	if err = fn(w); err == nil {
+		if s, ok := h.(interface{ Sync() error }); ok {
+			err = s.Sync()
+		}
+	}
+	if err1 := h.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	if err != nil {
+		if canRename {
+			_ = remove(f, target)
+		}
+		return err
+	}
+	if canRename {
+		return renamer.Rename(target, name)
+	}
+	return nil
+}
+
+func remove(f fs.FS, name string) error {
+	if ef, ok := f.(FS); ok {
+		return ef.Remove(name)
+	}
+	return os.Remove(name)
+}
+
 // FileWrite attempts to write to an fs.File provided it supports io.Writer.
 func FileWrite(file fs.File, data []byte) (int, error) {
 	writer, isw := file.(io.Writer)
@@ -90,6 +148,10 @@ func (o *osPT) Remove(name string) error {
 	return os.Remove(name)
 }
 
+func (o *osPT) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
+}
+
 func (o *osPT) MkdirAll(path string, perm fs.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
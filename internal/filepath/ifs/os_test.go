@@ -2,10 +2,13 @@ package ifs
 
 import (
 	"errors"
+	"io"
 	"io/fs"
+	"path/filepath"
 	"testing"
 	"testing/fstest"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,3 +37,28 @@ func TestOSAccess(t *testing.T) {
 
 	require.True(t, IsOS(fs))
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo.txt")
+
+	require.NoError(t, WriteFileAtomic(OS(), path, 0o644, func(w io.Writer) error {
+		_, err := w.Write([]byte("first"))
+		return err
+	}))
+
+	data, err := ReadFile(OS(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+
+	require.EqualError(t, WriteFileAtomic(OS(), path, 0o644, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return errors.New("nope")
+	}), "nope")
+
+	data, err = ReadFile(OS(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+
+	_, err = OS().Stat(path + ".tmp")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
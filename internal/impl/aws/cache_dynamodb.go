@@ -397,7 +397,7 @@ func (d *dynamodbCache) putItemInput(key string, value []byte, ttl *time.Duratio
 	if ttl == nil {
 		ttl = d.ttl
 	}
-	if ttl != nil && d.ttlKey != nil {
+	if ttl != nil && *ttl != 0 && d.ttlKey != nil {
 		input.Item[*d.ttlKey] = &dynamodb.AttributeValue{
 			N: aws.String(strconv.FormatInt(time.Now().Add(*ttl).Unix(), 10)),
 		}
@@ -1,8 +1,10 @@
 package dgraph
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,7 +23,9 @@ func ristrettoCacheConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Stable().
 		Summary(`Stores key/value pairs in a map held in the memory-bound [Ristretto cache](https://github.com/dgraph-io/ristretto).`).
-		Description(`This cache is more efficient and appropriate for high-volume use cases than the standard memory cache. However, the add command is non-atomic, and therefore this cache is not suitable for deduplication.`).
+		Description(`This cache is more efficient and appropriate for high-volume use cases than the standard memory cache. However, the add command is non-atomic, and therefore this cache is not suitable for deduplication.
+
+The incr and compare-and-swap operations are only atomic with respect to each other, and listing keys is not supported.`).
 		Field(service.NewDurationField("default_ttl").
 			Description("A default TTL to set for items, calculated from the moment the item is cached. Set to an empty string or zero duration to disable TTLs.").
 			Default("").
@@ -69,6 +73,9 @@ type ristrettoCache struct {
 
 	retriesEnabled bool
 	boffPool       sync.Pool
+
+	// Serialises read-modify-write operations such as Incr and CompareAndSwap.
+	rmwMut sync.Mutex
 }
 
 func newRistrettoCache(defaultTTL time.Duration, retriesEnabled bool, backOff *backoff.ExponentialBackOff) (*ristrettoCache, error) {
@@ -150,6 +157,63 @@ func (r *ristrettoCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (r *ristrettoCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		if v, ok := r.cache.Get(k); ok {
+			res[k] = v.([]byte)
+		}
+	}
+	return res, nil
+}
+
+// setAndWait performs a set and blocks until it is reflected in subsequent
+// reads, which is necessary for read-modify-write operations.
+func (r *ristrettoCache) setAndWait(key string, value []byte, ttl *time.Duration) error {
+	t := r.defaultTTL
+	if ttl != nil {
+		t = *ttl
+	}
+	if !r.cache.SetWithTTL(key, value, 1, t) {
+		return errors.New("set operation was dropped")
+	}
+	r.cache.Wait()
+	return nil
+}
+
+func (r *ristrettoCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	r.rmwMut.Lock()
+	defer r.rmwMut.Unlock()
+
+	var v int64
+	if res, ok := r.cache.Get(key); ok {
+		var err error
+		if v, err = strconv.ParseInt(string(res.([]byte)), 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	v += delta
+
+	if err := r.setAndWait(key, []byte(strconv.FormatInt(v, 10)), ttl); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+func (r *ristrettoCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	r.rmwMut.Lock()
+	defer r.rmwMut.Unlock()
+
+	res, ok := r.cache.Get(key)
+	if !ok {
+		return service.ErrKeyNotFound
+	}
+	if !bytes.Equal(res.([]byte), old) {
+		return service.ErrKeyValueMismatch
+	}
+	return r.setAndWait(key, value, ttl)
+}
+
 func (r *ristrettoCache) Close(ctx context.Context) error {
 	r.cache.Close()
 	return nil
@@ -1,11 +1,15 @@
 package io
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
@@ -16,7 +20,7 @@ func fileCacheConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Stable().
 		Summary(`Stores each item in a directory as a file, where an item ID is the path relative to the configured directory.`).
-		Description(`This type currently offers no form of item expiry or garbage collection, and is intended to be used for development and debugging purposes only.`).
+		Description(`This type currently offers no form of item expiry or garbage collection, and is intended to be used for development and debugging purposes only. The incr and compare-and-swap operations are only atomic within a single process.`).
 		Field(service.NewStringField("directory").
 			Description("The directory within which to store items."))
 
@@ -55,6 +59,9 @@ func newFileCache(dir string, mgr *service.Resources) *fileCache {
 type fileCache struct {
 	mgr *service.Resources
 	dir string
+
+	// Serialises read-modify-write operations such as Incr and CompareAndSwap.
+	rmwMut sync.Mutex
 }
 
 func (f *fileCache) Get(_ context.Context, key string) ([]byte, error) {
@@ -88,6 +95,75 @@ func (f *fileCache) Delete(_ context.Context, key string) error {
 	return f.mgr.FS().Remove(filepath.Join(f.dir, key))
 }
 
+func (f *fileCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		b, err := f.Get(ctx, k)
+		if err != nil {
+			if errors.Is(err, service.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		res[k] = b
+	}
+	return res, nil
+}
+
+func (f *fileCache) Incr(ctx context.Context, key string, delta int64, _ *time.Duration) (int64, error) {
+	f.rmwMut.Lock()
+	defer f.rmwMut.Unlock()
+
+	var v int64
+	b, err := f.Get(ctx, key)
+	if err == nil {
+		if v, err = strconv.ParseInt(string(b), 10, 64); err != nil {
+			return 0, err
+		}
+	} else if !errors.Is(err, service.ErrKeyNotFound) {
+		return 0, err
+	}
+	v += delta
+
+	if err := f.Set(ctx, key, []byte(strconv.FormatInt(v, 10)), nil); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+func (f *fileCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, _ *time.Duration) error {
+	f.rmwMut.Lock()
+	defer f.rmwMut.Unlock()
+
+	b, err := f.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(b, old) {
+		return service.ErrKeyValueMismatch
+	}
+	return f.Set(ctx, key, value, nil)
+}
+
+func (f *fileCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	return fs.WalkDir(f.mgr.FS(), f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return ctx.Err()
+		}
+		key, err := filepath.Rel(f.dir, path)
+		if err != nil {
+			return err
+		}
+		if key = filepath.ToSlash(key); !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		return fn(key)
+	})
+}
+
 func (f *fileCache) Close(context.Context) error {
 	return nil
 }
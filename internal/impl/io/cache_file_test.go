@@ -48,3 +48,38 @@ func TestFileCache(t *testing.T) {
 	_, err = c.Get(tCtx, "foo")
 	assert.Equal(t, service.ErrKeyNotFound, err)
 }
+
+func TestFileCacheAtomicOps(t *testing.T) {
+	dir := t.TempDir()
+
+	tCtx := context.Background()
+	c := newFileCache(dir, service.MockResources())
+
+	v, err := c.Incr(tCtx, "counter", 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	v, err = c.Incr(tCtx, "counter", 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), v)
+
+	require.NoError(t, c.Set(tCtx, "foo", []byte("bar"), nil))
+
+	assert.Equal(t, service.ErrKeyNotFound, c.CompareAndSwap(tCtx, "nope", []byte("bar"), []byte("baz"), nil))
+	assert.Equal(t, service.ErrKeyValueMismatch, c.CompareAndSwap(tCtx, "foo", []byte("nope"), []byte("baz"), nil))
+	require.NoError(t, c.CompareAndSwap(tCtx, "foo", []byte("bar"), []byte("baz"), nil))
+
+	res, err := c.GetMulti(tCtx, "counter", "foo", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"counter": []byte("5"),
+		"foo":     []byte("baz"),
+	}, res)
+
+	var keys []string
+	require.NoError(t, c.IterKeys(tCtx, "f", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.Equal(t, []string{"foo"}, keys)
+}
@@ -112,6 +112,7 @@ Unfortunately this error message will appear for a wide range of connection prob
 		Categories: []string{
 			"Services",
 		},
+		Migration: kafkaInputMigration,
 	})
 	if err != nil {
 		panic(err)
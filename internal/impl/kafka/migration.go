@@ -0,0 +1,85 @@
+package kafka
+
+// Bloblang mappings that migrate the configs of the sarama based kafka
+// components to the franz-go based components, applied by the migrate
+// subcommand. Configs that use features without an equivalent are rejected.
+
+const saslMigrationMapping = `
+map sasl {
+  let mechanism = this.mechanism | "none"
+  root = match {
+    $mechanism == "none" => deleted(),
+    [ "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512" ].contains($mechanism) => [{
+      "mechanism": $mechanism,
+      "username": this.user | "",
+      "password": this.password | "",
+    }],
+    $mechanism == "OAUTHBEARER" && (this.token_cache | "") == "" => [{
+      "mechanism": $mechanism,
+      "token": this.access_token | "",
+    }],
+    $mechanism == "OAUTHBEARER" => throw("sasl token caches are not supported by kafka_franz"),
+    _ => throw("sasl mechanism %v is not supported by kafka_franz".format($mechanism)),
+  }
+}
+`
+
+const kafkaInputMigration = saslMigrationMapping + `
+let unsupported = [
+  if (this.consumer_group | "") == "" { "an empty consumer_group" },
+  if (this.topics | []).any(t -> t.contains(":")) { "explicit topic partitions" },
+  if (this.client_id | "benthos") != "benthos" { "client_id" },
+  if (this.rack_id | "") != "" { "rack_id" },
+  if (this.fetch_buffer_cap | 256) != 256 { "fetch_buffer_cap" },
+  if (this.max_processing_period | "100ms") != "100ms" { "max_processing_period" },
+  if (this.extract_tracing_map | "") != "" { "extract_tracing_map" },
+  if (this.group.session_timeout | "10s") != "10s" || (this.group.heartbeat_interval | "3s") != "3s" || (this.group.rebalance_timeout | "60s") != "60s" { "group" },
+  if (this.batching.count | 0) != 0 || (this.batching.byte_size | 0) != 0 || (this.batching.period | "") != "" || (this.batching.check | "") != "" || (this.batching.processors | []).length() > 0 { "batching" },
+]
+root = if $unsupported.length() > 0 {
+  throw("kafka_franz does not support %v".format($unsupported.join(", ")))
+}
+
+root.kafka_franz.seed_brokers = this.addresses | []
+root.kafka_franz.topics = this.topics | []
+root.kafka_franz.consumer_group = this.consumer_group
+root.kafka_franz.checkpoint_limit = this.checkpoint_limit | 1024
+root.kafka_franz.commit_period = this.commit_period | "1s"
+root.kafka_franz.start_from_oldest = this.start_from_oldest | true
+root.kafka_franz.multi_header = this.multi_header | false
+root.kafka_franz.tls = this.tls | deleted()
+root.kafka_franz.sasl = (this.sasl | {}).apply("sasl")
+`
+
+const kafkaOutputMigration = saslMigrationMapping + `
+let keyed = (this.key | "") != ""
+let partitioner_name = this.partitioner | "fnv1a_hash"
+let unsupported = [
+  if (this.client_id | "benthos") != "benthos" { "client_id" },
+  if (this.rack_id | "") != "" { "rack_id" },
+  if (this.static_headers | {}).length() > 0 { "static_headers" },
+  if (this.metadata.exclude_prefixes | []).length() > 0 { "metadata.exclude_prefixes" },
+  if (this.inject_tracing_map | "") != "" { "inject_tracing_map" },
+]
+root = if $unsupported.length() > 0 {
+  throw("kafka_franz does not support %v".format($unsupported.join(", ")))
+}
+
+root.kafka_franz.seed_brokers = this.addresses | []
+root.kafka_franz.topic = this.topic
+root.kafka_franz.key = if $keyed { this.key } else { deleted() }
+root.kafka_franz.partitioner = match {
+  [ "murmur2_hash", "round_robin" ].contains($partitioner_name) => $partitioner_name,
+  [ "fnv1a_hash", "random" ].contains($partitioner_name) && !$keyed => deleted(),
+  [ "fnv1a_hash", "random" ].contains($partitioner_name) => throw("the %v partitioner is not supported by kafka_franz for keyed messages".format($partitioner_name)),
+  _ => throw("the %v partitioner is not supported by kafka_franz".format($partitioner_name)),
+}
+root.kafka_franz.metadata.include_patterns = [ ".*" ]
+root.kafka_franz.max_in_flight = this.max_in_flight | 64
+root.kafka_franz.timeout = this.timeout | "5s"
+root.kafka_franz.batching = this.batching | deleted()
+root.kafka_franz.max_message_bytes = "%vB".format(this.max_msg_bytes | 1000000)
+root.kafka_franz.compression = this.compression | "none"
+root.kafka_franz.tls = this.tls | deleted()
+root.kafka_franz.sasl = (this.sasl | {}).apply("sasl")
+`
@@ -0,0 +1,123 @@
+package kafka_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+func TestKafkaMigration(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		output string
+		errStr string
+	}{
+		{
+			name: "input with sasl",
+			input: `
+input:
+  kafka:
+    addresses: [ localhost:9092 ] # brokers
+    topics: [ foo ]
+    consumer_group: bar
+    sasl:
+      mechanism: SCRAM-SHA-256
+      user: ${USER}
+      password: ${PASS}
+`,
+			output: `input:
+  kafka_franz:
+    seed_brokers: ['localhost:9092'] # brokers
+    topics: [foo]
+    consumer_group: bar
+    sasl:
+      - mechanism: SCRAM-SHA-256
+        password: ${PASS}
+        username: ${USER}
+    checkpoint_limit: 1024
+    commit_period: 1s
+    multi_header: false
+    start_from_oldest: true
+`,
+		},
+		{
+			name: "input with explicit partitions",
+			input: `
+input:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topics: [ foo:0 ]
+    consumer_group: bar
+    rack_id: baz
+`,
+			errStr: "kafka_franz does not support explicit topic partitions, rack_id",
+		},
+		{
+			name: "output with key",
+			input: `
+output:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topic: foo
+    key: ${! meta("key") }
+    partitioner: murmur2_hash
+    max_msg_bytes: 2000
+`,
+			output: `output:
+  kafka_franz:
+    seed_brokers: ['localhost:9092']
+    topic: foo
+    key: ${! meta("key") }
+    partitioner: murmur2_hash
+    compression: none
+    max_in_flight: 64
+    max_message_bytes: 2000B
+    metadata:
+      include_patterns:
+        - .*
+    timeout: 5s
+`,
+		},
+		{
+			name: "output with fnv1a key",
+			input: `
+output:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topic: foo
+    key: bar
+`,
+			errStr: "the fnv1a_hash partitioner is not supported by kafka_franz for keyed messages",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(test.input), &node))
+
+			var res []docs.MigratedComponent
+			require.NoError(t, config.Spec().MigrateYAML(&node, bundle.GlobalEnvironment, &res))
+			require.Len(t, res, 1)
+
+			if test.errStr != "" {
+				require.Error(t, res[0].Err)
+				assert.Contains(t, res[0].Err.Error(), test.errStr)
+				return
+			}
+			require.NoError(t, res[0].Err)
+
+			b, err := config.MarshalYAML(*node.Content[0])
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(b))
+		})
+	}
+}
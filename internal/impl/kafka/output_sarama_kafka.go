@@ -82,6 +82,7 @@ Unfortunately this error message will appear for a wide range of connection prob
 		Categories: []string{
 			"Services",
 		},
+		Migration: kafkaOutputMigration,
 	})
 	if err != nil {
 		panic(err)
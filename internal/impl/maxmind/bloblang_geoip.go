@@ -9,6 +9,7 @@ import (
 	"github.com/oschwald/geoip2-golang"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/impl/pure"
 	"github.com/benthosdev/benthos/v4/public/bloblang"
 )
 
@@ -17,7 +18,7 @@ func registerMaxmindMethodSpec(name, entity string, fn func(*geoip2.Reader, net.
 		bloblang.NewPluginSpec().
 			Experimental().
 			Category(query.MethodCategoryGeoIP).
-			Description(fmt.Sprintf("Looks up an IP address against a [MaxMind database file](https://www.maxmind.com/en/home) and, if found, returns an object describing the %v associated with it.", entity)).
+			Description(fmt.Sprintf("Looks up an IP address against a [MaxMind database file](https://www.maxmind.com/en/home) and, if found, returns an object describing the %v associated with it. The IP address can either be a string or an object produced by the [`parse_ip`](#parse_ip) method.", entity)).
 			Param(bloblang.NewStringParam("path").Description("A path to an mmdb (maxmind) file.")),
 		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
 			path, err := args.GetString("path")
@@ -28,12 +29,12 @@ func registerMaxmindMethodSpec(name, entity string, fn func(*geoip2.Reader, net.
 			if err != nil {
 				return nil, err
 			}
-			return bloblang.StringMethod(func(s string) (any, error) {
-				ip := net.ParseIP(s)
-				if ip == nil {
-					return nil, fmt.Errorf("value %v does not appear to be a valid v4 or v6 IP address", s)
+			return func(v any) (any, error) {
+				addr, err := pure.IPFromValue(v)
+				if err != nil {
+					return nil, err
 				}
-				v, err := fn(db, ip)
+				v, err = fn(db, net.IP(addr.AsSlice()))
 				if err != nil {
 					return nil, err
 				}
@@ -46,7 +47,7 @@ func registerMaxmindMethodSpec(name, entity string, fn func(*geoip2.Reader, net.
 				var gV any
 				err = dec.Decode(&gV)
 				return gV, err
-			}), nil
+			}, nil
 		}); err != nil {
 		panic(err)
 	}
@@ -50,6 +50,11 @@ func TestGeoIPCity(t *testing.T) {
 			input: `root = "89.95.192.0".geoip_domain("./testdata/GeoIP2-Domain-Test.mmdb").Domain`,
 			exp:   "bbox.fr",
 		},
+		{
+			name:  "geoip city from parsed ip",
+			input: `root = "::ffff:81.2.69.192".parse_ip().geoip_city("./testdata/GeoIP2-City-Test.mmdb").City.Names.en`,
+			exp:   "London",
+		},
 		{
 			name:  "geoip ISP",
 			input: `root = "12.87.120.0".geoip_isp("./testdata/GeoIP2-ISP-Test.mmdb").ISP`,
@@ -0,0 +1,333 @@
+package pure
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// IPFromValue attempts to extract an IP address from a Bloblang value, which
+// can either be a string containing a v4 or v6 address, or an object produced
+// by the parse_ip method.
+func IPFromValue(v any) (netip.Addr, error) {
+	if obj, isObj := v.(map[string]any); isObj {
+		addr, exists := obj["address"]
+		if !exists {
+			return netip.Addr{}, errors.New("object does not contain an IP address field `address`")
+		}
+		v = addr
+	}
+	s, err := query.IGetString(v)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("value %v does not appear to be a valid v4 or v6 IP address", s)
+	}
+	return ip, nil
+}
+
+func ipMethod(fn func(ip netip.Addr) (any, error)) bloblang.Method {
+	return func(v any) (any, error) {
+		ip, err := IPFromValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return fn(ip)
+	}
+}
+
+func ipVersion(ip netip.Addr) int64 {
+	if ip.Is4() {
+		return 4
+	}
+	return 6
+}
+
+func ipToBigInt(ip netip.Addr) *big.Int {
+	b := ip.AsSlice()
+	return new(big.Int).SetBytes(b)
+}
+
+func ipFromBigInt(i *big.Int, is4 bool) (netip.Addr, error) {
+	size := 16
+	if is4 {
+		size = 4
+	}
+	if i.Sign() < 0 || i.BitLen() > size*8 {
+		return netip.Addr{}, errors.New("resulting address is out of range")
+	}
+	b := make([]byte, size)
+	i.FillBytes(b)
+	ip, _ := netip.AddrFromSlice(b)
+	return ip, nil
+}
+
+func ipReverseDNS(ip netip.Addr) string {
+	var b strings.Builder
+	if ip.Is4() {
+		octets := ip.As4()
+		for i := len(octets) - 1; i >= 0; i-- {
+			b.WriteString(strconv.Itoa(int(octets[i])))
+			b.WriteByte('.')
+		}
+		b.WriteString("in-addr.arpa")
+		return b.String()
+	}
+	octets := ip.As16()
+	nibbles := hex.EncodeToString(octets[:])
+	for i := len(nibbles) - 1; i >= 0; i-- {
+		b.WriteByte(nibbles[i])
+		b.WriteByte('.')
+	}
+	b.WriteString("ip6.arpa")
+	return b.String()
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_ip",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNetwork).
+			Version("4.12.0").
+			Description("Parses a v4 or v6 IP address and returns an object containing the normalized address, its version and its classification. The resulting object can be used in place of an IP address string with any other network method, as well as with the GeoIP methods.").
+			Example("", `root.ip = this.ip.parse_ip()`,
+				[2]string{
+					`{"ip":"2001:0DB8:0000::0001"}`,
+					`{"ip":{"address":"2001:db8::1","is_global_unicast":true,"is_link_local":false,"is_loopback":false,"is_multicast":false,"is_private":false,"is_unspecified":false,"version":6}}`,
+				},
+			).
+			Example("", `root.internal = this.ip.parse_ip().is_private || this.ip.parse_ip().is_loopback`,
+				[2]string{
+					`{"ip":"10.1.2.3"}`,
+					`{"internal":true}`,
+				},
+				[2]string{
+					`{"ip":"8.8.8.8"}`,
+					`{"internal":false}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return ipMethod(func(ip netip.Addr) (any, error) {
+				return map[string]any{
+					"address":           ip.String(),
+					"version":           ipVersion(ip),
+					"is_loopback":       ip.IsLoopback(),
+					"is_private":        ip.IsPrivate(),
+					"is_multicast":      ip.IsMulticast(),
+					"is_link_local":     ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast(),
+					"is_unspecified":    ip.IsUnspecified(),
+					"is_global_unicast": ip.IsGlobalUnicast(),
+				}, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_in_cidr",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNetwork).
+			Version("4.12.0").
+			Description("Checks whether an IP address is contained within a network described in CIDR notation. IPv4 addresses mapped into IPv6 are compared as IPv4 addresses.").
+			Param(bloblang.NewStringParam("cidr").Description("The network to check against in CIDR notation.")).
+			Example("", `root.is_internal = this.ip.ip_in_cidr("10.0.0.0/8")`,
+				[2]string{
+					`{"ip":"10.24.0.1"}`,
+					`{"is_internal":true}`,
+				},
+				[2]string{
+					`{"ip":"192.168.0.1"}`,
+					`{"is_internal":false}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			cidrStr, err := args.GetString("cidr")
+			if err != nil {
+				return nil, err
+			}
+			prefix, err := netip.ParsePrefix(cidrStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse cidr: %w", err)
+			}
+			prefix = prefix.Masked()
+			return ipMethod(func(ip netip.Addr) (any, error) {
+				ip = ip.WithZone("")
+				if prefix.Addr().Is4() {
+					ip = ip.Unmap()
+				}
+				return prefix.Contains(ip), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_to_v4",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNetwork).
+			Version("4.12.0").
+			Description("Converts an IP address into its IPv4 representation, which unmaps IPv4 addresses that are mapped into IPv6. An error is returned if the address cannot be represented as IPv4.").
+			Example("", `root.ip = this.ip.ip_to_v4()`,
+				[2]string{
+					`{"ip":"::ffff:192.0.2.1"}`,
+					`{"ip":"192.0.2.1"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return ipMethod(func(ip netip.Addr) (any, error) {
+				ip = ip.Unmap()
+				if !ip.Is4() {
+					return nil, fmt.Errorf("address %v cannot be represented as IPv4", ip)
+				}
+				return ip.String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_to_v6",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNetwork).
+			Version("4.12.0").
+			Description("Converts an IP address into its IPv6 representation, where IPv4 addresses are mapped into IPv6.").
+			Example("", `root.ip = this.ip.ip_to_v6()`,
+				[2]string{
+					`{"ip":"192.0.2.1"}`,
+					`{"ip":"::ffff:192.0.2.1"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return ipMethod(func(ip netip.Addr) (any, error) {
+				return netip.AddrFrom16(ip.As16()).WithZone(ip.Zone()).String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_reverse_dns",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNetwork).
+			Version("4.12.0").
+			Description("Formats an IP address as the domain name used for reverse DNS lookups, within the `in-addr.arpa` domain for IPv4 addresses and the `ip6.arpa` domain for IPv6 addresses.").
+			Example("", `root.ptr = this.ip.ip_reverse_dns()`,
+				[2]string{
+					`{"ip":"192.0.2.1"}`,
+					`{"ptr":"1.2.0.192.in-addr.arpa"}`,
+				},
+				[2]string{
+					`{"ip":"2001:db8::1"}`,
+					`{"ptr":"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return ipMethod(func(ip netip.Addr) (any, error) {
+				return ipReverseDNS(ip.Unmap()), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ip_add",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNetwork).
+			Version("4.12.0").
+			Description("Adds an offset to an IP address and returns the resulting address, the offset can be negative. An error is returned if the resulting address overflows the address space.").
+			Param(bloblang.NewInt64Param("offset").Description("The offset to add to the address.")).
+			Example("", `root.next = this.ip.ip_add(1)
+root.prev = this.ip.ip_add(-1)`,
+				[2]string{
+					`{"ip":"10.0.0.255"}`,
+					`{"next":"10.0.1.0","prev":"10.0.0.254"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			offset, err := args.GetInt64("offset")
+			if err != nil {
+				return nil, err
+			}
+			bigOffset := big.NewInt(offset)
+			return ipMethod(func(ip netip.Addr) (any, error) {
+				res, err := ipFromBigInt(ipToBigInt(ip).Add(ipToBigInt(ip), bigOffset), ip.Is4())
+				if err != nil {
+					return nil, err
+				}
+				return res.WithZone(ip.Zone()).String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_cidr",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNetwork).
+			Version("4.12.0").
+			Description("Parses a network described in CIDR notation and returns an object describing the network, including its first and last addresses and the number of addresses it contains. The `size` of networks that exceed the capacity of a 64-bit integer is returned as a decimal.").
+			Example("", `root.net = this.cidr.parse_cidr()`,
+				[2]string{
+					`{"cidr":"192.168.12.34/22"}`,
+					`{"net":{"address":"192.168.12.34","first":"192.168.12.0","last":"192.168.15.255","netmask":"255.255.252.0","network":"192.168.12.0/22","prefix_length":22,"size":1024,"version":4}}`,
+				},
+				[2]string{
+					`{"cidr":"2001:db8::/64"}`,
+					`{"net":{"address":"2001:db8::","first":"2001:db8::","last":"2001:db8::ffff:ffff:ffff:ffff","netmask":"ffff:ffff:ffff:ffff::","network":"2001:db8::/64","prefix_length":64,"size":18446744073709551616,"version":6}}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				prefix, err := netip.ParsePrefix(s)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse cidr: %w", err)
+				}
+				return cidrToMap(prefix)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
+
+func cidrToMap(prefix netip.Prefix) (map[string]any, error) {
+	network := prefix.Masked()
+	is4 := network.Addr().Is4()
+	hostBits := uint(network.Addr().BitLen() - network.Bits())
+
+	size := new(big.Int).Lsh(big.NewInt(1), hostBits)
+	hostMask := new(big.Int).Sub(size, big.NewInt(1))
+
+	first := ipToBigInt(network.Addr())
+	last, err := ipFromBigInt(new(big.Int).Or(first, hostMask), is4)
+	if err != nil {
+		return nil, err
+	}
+
+	allOnes := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(network.Addr().BitLen())), big.NewInt(1))
+	netmask, err := ipFromBigInt(new(big.Int).Xor(allOnes, hostMask), is4)
+	if err != nil {
+		return nil, err
+	}
+
+	var sizeV any
+	if size.IsInt64() {
+		sizeV = size.Int64()
+	} else {
+		sizeV = query.NewDecimal(decimal.NewFromBigInt(size, 0))
+	}
+
+	return map[string]any{
+		"network":       network.String(),
+		"address":       prefix.Addr().String(),
+		"version":       ipVersion(network.Addr()),
+		"prefix_length": int64(network.Bits()),
+		"netmask":       netmask.String(),
+		"first":         network.Addr().String(),
+		"last":          last.String(),
+		"size":          sizeV,
+	}, nil
+}
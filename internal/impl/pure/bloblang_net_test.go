@@ -0,0 +1,145 @@
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+func TestNetworkMethods(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		args   []any
+		exp    any
+		err    string
+	}{
+		{
+			name:   "parse ip loopback",
+			method: "parse_ip",
+			target: "::1",
+			exp: map[string]any{
+				"address":           "::1",
+				"version":           int64(6),
+				"is_loopback":       true,
+				"is_private":        false,
+				"is_multicast":      false,
+				"is_link_local":     false,
+				"is_unspecified":    false,
+				"is_global_unicast": false,
+			},
+		},
+		{
+			name:   "parse ip invalid",
+			method: "parse_ip",
+			target: "10.0.0.256",
+			err:    "value 10.0.0.256 does not appear to be a valid v4 or v6 IP address",
+		},
+		{
+			name:   "in cidr from parsed object",
+			method: "ip_in_cidr",
+			target: map[string]any{"address": "172.16.4.1"},
+			args:   []any{"172.16.0.0/12"},
+			exp:    true,
+		},
+		{
+			name:   "in cidr mapped v4",
+			method: "ip_in_cidr",
+			target: "::ffff:10.1.1.1",
+			args:   []any{"10.0.0.0/8"},
+			exp:    true,
+		},
+		{
+			name:   "in cidr version mismatch",
+			method: "ip_in_cidr",
+			target: "2001:db8::1",
+			args:   []any{"10.0.0.0/8"},
+			exp:    false,
+		},
+		{
+			name:   "in cidr v6",
+			method: "ip_in_cidr",
+			target: "2001:db8::1",
+			args:   []any{"2001:db8::/32"},
+			exp:    true,
+		},
+		{
+			name:   "to v4 not representable",
+			method: "ip_to_v4",
+			target: "2001:db8::1",
+			err:    "address 2001:db8::1 cannot be represented as IPv4",
+		},
+		{
+			name:   "to v6 already v6",
+			method: "ip_to_v6",
+			target: "2001:DB8::1",
+			exp:    "2001:db8::1",
+		},
+		{
+			name:   "reverse dns mapped v4",
+			method: "ip_reverse_dns",
+			target: "::ffff:10.0.0.1",
+			exp:    "1.0.0.10.in-addr.arpa",
+		},
+		{
+			name:   "add overflow",
+			method: "ip_add",
+			target: "255.255.255.255",
+			args:   []any{int64(1)},
+			err:    "resulting address is out of range",
+		},
+		{
+			name:   "add v6",
+			method: "ip_add",
+			target: "2001:db8::ffff",
+			args:   []any{int64(1)},
+			exp:    "2001:db8::1:0",
+		},
+		{
+			name:   "parse cidr single host",
+			method: "parse_cidr",
+			target: "10.0.0.1/32",
+			exp: map[string]any{
+				"network":       "10.0.0.1/32",
+				"address":       "10.0.0.1",
+				"version":       int64(4),
+				"prefix_length": int64(32),
+				"netmask":       "255.255.255.255",
+				"first":         "10.0.0.1",
+				"last":          "10.0.0.1",
+				"size":          int64(1),
+			},
+		},
+		{
+			name:   "parse cidr invalid",
+			method: "parse_cidr",
+			target: "10.0.0.1/33",
+			err:    "failed to parse cidr",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{
+				Maps:     map[string]query.Function{},
+				Index:    0,
+				MsgBatch: nil,
+			})
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
@@ -0,0 +1,655 @@
+package pure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rickb777/date/period"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+// calendarDuration is an amount of time to add to or subtract from a
+// timestamp, where years, months and days are applied according to the
+// calendar of the timestamp location rather than as fixed durations.
+type calendarDuration struct {
+	months int
+	days   int
+	dur    time.Duration
+}
+
+var calendarDurationUnits = map[string]func(c *calendarDuration, n int64){
+	"years":        func(c *calendarDuration, n int64) { c.months += int(n) * 12 },
+	"months":       func(c *calendarDuration, n int64) { c.months += int(n) },
+	"weeks":        func(c *calendarDuration, n int64) { c.days += int(n) * 7 },
+	"days":         func(c *calendarDuration, n int64) { c.days += int(n) },
+	"hours":        func(c *calendarDuration, n int64) { c.dur += time.Duration(n) * time.Hour },
+	"minutes":      func(c *calendarDuration, n int64) { c.dur += time.Duration(n) * time.Minute },
+	"seconds":      func(c *calendarDuration, n int64) { c.dur += time.Duration(n) * time.Second },
+	"milliseconds": func(c *calendarDuration, n int64) { c.dur += time.Duration(n) * time.Millisecond },
+	"microseconds": func(c *calendarDuration, n int64) { c.dur += time.Duration(n) * time.Microsecond },
+	"nanoseconds":  func(c *calendarDuration, n int64) { c.dur += time.Duration(n) },
+}
+
+func calendarDurationUnitNames() string {
+	names := make([]string, 0, len(calendarDurationUnits))
+	for k := range calendarDurationUnits {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func parseCalendarDuration(v any) (c calendarDuration, err error) {
+	switch t := v.(type) {
+	case string:
+		if c.dur, err = time.ParseDuration(t); err == nil {
+			return
+		}
+		p, pErr := period.Parse(t)
+		if pErr != nil {
+			return c, fmt.Errorf("failed to parse %q as either a duration or an ISO-8601 period", t)
+		}
+		c.months = p.Years()*12 + p.Months()
+		c.days = p.Days()
+		c.dur = time.Duration(p.Hours())*time.Hour +
+			time.Duration(p.Minutes())*time.Minute +
+			time.Duration(float64(p.SecondsFloat())*float64(time.Second))
+		return c, nil
+	case map[string]any:
+		for k, v := range t {
+			fn, exists := calendarDurationUnits[k]
+			if !exists {
+				return c, fmt.Errorf("unrecognised duration field %q, expected one of: %v", k, calendarDurationUnitNames())
+			}
+			n, err := query.IGetInt(v)
+			if err != nil {
+				return c, fmt.Errorf("field %v: %w", k, err)
+			}
+			fn(&c, n)
+		}
+		return c, nil
+	}
+	n, err := query.IGetInt(v)
+	if err != nil {
+		return c, query.NewTypeError(v, query.ValueNumber, query.ValueString, query.ValueObject)
+	}
+	c.dur = time.Duration(n)
+	return c, nil
+}
+
+// addMonths adds a number of months to a timestamp, where the day of the
+// month is clamped to the last day of the resulting month rather than
+// overflowing into the month after.
+func addMonths(t time.Time, months int) time.Time {
+	if months == 0 {
+		return t
+	}
+	y, m, d := t.Date()
+	lastDay := time.Date(y, m+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if d > lastDay {
+		d = lastDay
+	}
+	return time.Date(y, m+time.Month(months), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func (c calendarDuration) addTo(t time.Time, sign int) time.Time {
+	t = addMonths(t, sign*c.months)
+	t = t.AddDate(0, 0, sign*c.days)
+	return t.Add(time.Duration(sign) * c.dur)
+}
+
+func optionalLocation(args *bloblang.ParsedParams) (*time.Location, error) {
+	tzStr, err := args.GetOptionalString("tz")
+	if err != nil || tzStr == nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(*tzStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timezone location name: %w", err)
+	}
+	return loc, nil
+}
+
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+//------------------------------------------------------------------------------
+
+func truncateTimestamp(t time.Time, unit string) (time.Time, error) {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch unit {
+	case "second":
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+	case "minute":
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc), nil
+	case "hour":
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc), nil
+	case "day":
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), nil
+	case "week":
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc), nil
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc), nil
+	case "quarter":
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc), nil
+	case "year":
+		return time.Date(y, 1, 1, 0, 0, 0, 0, loc), nil
+	}
+	return t, fmt.Errorf("unrecognised unit %q, expected one of: second, minute, hour, day, week, month, quarter, year", unit)
+}
+
+// calendarSteps returns the number of whole calendar steps that can be added
+// to a timestamp without passing another, where estimate is a close guess.
+func calendarSteps(from, to time.Time, estimate int, add func(time.Time, int) time.Time) int64 {
+	if to.Before(from) {
+		return -calendarSteps(to, from, -estimate, add)
+	}
+	n := estimate
+	if n < 0 {
+		n = 0
+	}
+	for n > 0 && add(from, n).After(to) {
+		n--
+	}
+	for !add(from, n+1).After(to) {
+		n++
+	}
+	return int64(n)
+}
+
+var fixedDiffUnits = map[string]time.Duration{
+	"nanosecond":  time.Nanosecond,
+	"microsecond": time.Microsecond,
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+}
+
+func diffTimestamps(from, to time.Time, unit string) (int64, error) {
+	if d, exists := fixedDiffUnits[unit]; exists {
+		return int64(to.Sub(from) / d), nil
+	}
+	addDays := func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) }
+	estimateDays := int(to.Sub(from).Hours() / 24)
+	estimateMonths := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	switch unit {
+	case "day":
+		return calendarSteps(from, to, estimateDays, addDays), nil
+	case "week":
+		return calendarSteps(from, to, estimateDays/7, func(t time.Time, n int) time.Time {
+			return addDays(t, n*7)
+		}), nil
+	case "month":
+		return calendarSteps(from, to, estimateMonths, addMonths), nil
+	case "year":
+		return calendarSteps(from, to, estimateMonths/12, func(t time.Time, n int) time.Time {
+			return addMonths(t, n*12)
+		}), nil
+	}
+	return 0, fmt.Errorf("unrecognised unit %q, expected one of: nanosecond, microsecond, millisecond, second, minute, hour, day, week, month, year", unit)
+}
+
+//------------------------------------------------------------------------------
+
+// businessCalendar determines which days are business days, which are Monday
+// to Friday with the exception of holidays.
+type businessCalendar struct {
+	holidays map[string]struct{}
+
+	// The day numbers of holidays that fall on weekdays in ascending order,
+	// which allows holidays within a range of days to be counted with a binary
+	// search.
+	weekdayHolidays []int64
+}
+
+const businessDateLayout = "2006-01-02"
+
+func newBusinessCalendar(args *bloblang.ParsedParams) (businessCalendar, error) {
+	c := businessCalendar{holidays: map[string]struct{}{}}
+	v, err := args.Get("holidays")
+	if err != nil || v == nil {
+		return c, err
+	}
+	holidays, ok := v.([]any)
+	if !ok {
+		return c, query.NewTypeError(v, query.ValueArray)
+	}
+	for i, h := range holidays {
+		if s, isStr := h.(string); isStr {
+			if _, err := time.Parse(businessDateLayout, s); err == nil {
+				c.holidays[s] = struct{}{}
+				continue
+			}
+		}
+		t, err := query.IGetTimestamp(h)
+		if err != nil {
+			return c, fmt.Errorf("holiday %v: %w", i, err)
+		}
+		c.holidays[t.Format(businessDateLayout)] = struct{}{}
+	}
+	for s := range c.holidays {
+		t, _ := time.Parse(businessDateLayout, s)
+		if wd := t.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			c.weekdayHolidays = append(c.weekdayHolidays, dayNumber(t))
+		}
+	}
+	sort.Slice(c.weekdayHolidays, func(i, j int) bool {
+		return c.weekdayHolidays[i] < c.weekdayHolidays[j]
+	})
+	return c, nil
+}
+
+func (c businessCalendar) isBusinessDay(t time.Time) bool {
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	_, isHoliday := c.holidays[t.Format(businessDateLayout)]
+	return !isHoliday
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// dayNumber returns the number of days between 1970-01-01 and the date of a
+// timestamp within its location.
+func dayNumber(t time.Time) int64 {
+	y, m, d := t.Date()
+	return floorDiv(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix(), 86400)
+}
+
+// weekdaysBefore counts the weekdays from an arbitrary Monday up to but not
+// including the given day number, such that the number of weekdays within the
+// days (a, b] is weekdaysBefore(b+1) - weekdaysBefore(a+1). The count is
+// negative for days before that Monday.
+func weekdaysBefore(day int64) int64 {
+	// Day 0 is a Thursday, and therefore day -3 is a Monday.
+	k := day + 3
+	weeks := floorDiv(k, 7)
+	rem := k - weeks*7
+	if rem > 5 {
+		rem = 5
+	}
+	return weeks*5 + rem
+}
+
+// firstDayWithWeekdays returns the earliest day number for which
+// weekdaysBefore(day+1) is at least n.
+func firstDayWithWeekdays(n int64) int64 {
+	weeks := floorDiv(n, 5)
+	rem := n - weeks*5
+	k := weeks*7 + rem
+	if rem == 0 {
+		// The count is reached on the Friday of the previous week.
+		k = weeks*7 - 2
+	}
+	return k - 3 - 1
+}
+
+// holidaysBetween counts the weekday holidays within the days (a, b].
+func (c businessCalendar) holidaysBetween(a, b int64) int64 {
+	lower := sort.Search(len(c.weekdayHolidays), func(i int) bool { return c.weekdayHolidays[i] > a })
+	upper := sort.Search(len(c.weekdayHolidays), func(i int) bool { return c.weekdayHolidays[i] > b })
+	return int64(upper - lower)
+}
+
+// addDays moves a timestamp forward (or backward when n is negative) by n
+// business days. Days are counted arithmetically rather than stepped through,
+// and so the cost depends only on the number of holidays that are passed.
+func (c businessCalendar) addDays(t time.Time, n int64) time.Time {
+	if n == 0 {
+		return t
+	}
+	from := dayNumber(t)
+	day := from
+	if n > 0 {
+		// Find the nth weekday after the current day, then for each holiday
+		// passed along the way move forward by another weekday.
+		for remaining := n; remaining > 0; {
+			next := firstDayWithWeekdays(weekdaysBefore(day+1) + remaining)
+			remaining = c.holidaysBetween(day, next)
+			day = next
+		}
+	} else {
+		// Find the nth weekday before the current day, then for each holiday
+		// passed along the way move back by another weekday.
+		for remaining := -n; remaining > 0; {
+			prev := firstDayWithWeekdays(weekdaysBefore(day) - remaining + 1)
+			remaining = c.holidaysBetween(prev-1, day-1)
+			day = prev
+		}
+	}
+	return t.AddDate(0, 0, int(day-from))
+}
+
+// diffDays counts the business days after the date of one timestamp up to and
+// including the date of another.
+func (c businessCalendar) diffDays(from, to time.Time) int64 {
+	a, b := dayNumber(from), dayNumber(to)
+	if b < a {
+		return -c.diffDays(to, from)
+	}
+	return weekdaysBefore(b+1) - weekdaysBefore(a+1) - c.holidaysBetween(a, b)
+}
+
+//------------------------------------------------------------------------------
+
+func timestampArg(args *bloblang.ParsedParams, name string) (time.Time, error) {
+	v, err := args.Get(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return query.IGetTimestamp(v)
+}
+
+func init() {
+	tzParam := bloblang.NewStringParam("tz").Optional().Description(`An optional timezone in which to perform calendar calculations, which is also the timezone of the result. If omitted then the timezone of the timestamp is used. The argument is a location name corresponding to a file in the IANA Time Zone database, such as "America/New_York".`)
+	holidaysParam := bloblang.NewAnyParam("holidays").Optional().Description("An optional array of dates that are not business days, either as strings of the form `2006-01-02` or as timestamps.")
+
+	tsAddSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Adds an amount of time to a timestamp. The amount can be an integer of nanoseconds, a duration string such as "1h30m", an ISO-8601 period such as "P1M2D", or an object with any of the fields `+"`years`, `months`, `weeks`, `days`, `hours`, `minutes`, `seconds`, `milliseconds`, `microseconds` and `nanoseconds`"+`. Years, months and days are added according to the calendar, therefore adding a day across a daylight saving transition keeps the same time of day, and adding a month to the 31st of a month results in the last day of the following month when it is shorter. Timestamp values can either be a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in RFC 3339 format. The `+"[`ts_parse`](#ts_parse)"+` method can be used in order to parse different timestamp formats.`).
+		Param(bloblang.NewAnyParam("amount").Description("The amount of time to add.")).
+		Param(tzParam).
+		Example("",
+			`root.next_billing = this.created_at.ts_add({"months": 1})
+root.expires_at = this.created_at.ts_add("72h")`,
+			[2]string{
+				`{"created_at":"2024-01-31T10:00:00Z"}`,
+				`{"expires_at":"2024-02-03T10:00:00Z","next_billing":"2024-02-29T10:00:00Z"}`,
+			}).
+		Example("Calendar days are added within the timezone provided, which accounts for daylight saving transitions.",
+			`root.tomorrow = this.now.ts_add({"days": 1}, "America/New_York").ts_format("2006-01-02T15:04:05Z07:00")`,
+			[2]string{
+				`{"now":"2024-03-09T12:00:00-05:00"}`,
+				`{"tomorrow":"2024-03-10T12:00:00-04:00"}`,
+			})
+
+	tsSubSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Subtracts an amount of time from a timestamp. The amount is provided in any of the forms supported by `+"[`ts_add`](#ts_add)"+`.`).
+		Param(bloblang.NewAnyParam("amount").Description("The amount of time to subtract.")).
+		Param(tzParam).
+		Example("",
+			`root.last_quarter = this.created_at.ts_sub("P3M")`,
+			[2]string{
+				`{"created_at":"2024-05-31T00:00:00Z"}`,
+				`{"last_quarter":"2024-02-29T00:00:00Z"}`,
+			})
+
+	tsAddSubCtor := func(sign int) bloblang.MethodConstructorV2 {
+		return func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			amount, err := args.Get("amount")
+			if err != nil {
+				return nil, err
+			}
+			c, err := parseCalendarDuration(amount)
+			if err != nil {
+				return nil, err
+			}
+			loc, err := optionalLocation(args)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+				return c.addTo(inLocation(t, loc), sign), nil
+			}), nil
+		}
+	}
+
+	if err := bloblang.RegisterMethodV2("ts_add", tsAddSpec, tsAddSubCtor(1)); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("ts_sub", tsSubSpec, tsAddSubCtor(-1)); err != nil {
+		panic(err)
+	}
+
+	tsDiffSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Returns the number of whole units of time that have passed from another timestamp to the target timestamp, which is negative when the target is earlier. Days, weeks, months and years are counted according to the calendar, where a month has passed from the 15th of January once the 15th of February is reached.`).
+		Param(bloblang.NewAnyParam("other").Description("The timestamp to measure from.")).
+		Param(bloblang.NewStringParam("unit").Description("The unit to measure in, one of `nanosecond`, `microsecond`, `millisecond`, `second`, `minute`, `hour`, `day`, `week`, `month` or `year`.")).
+		Param(tzParam).
+		Example("",
+			`root.age_days = this.shipped_at.ts_diff(this.ordered_at, "day")
+root.age_months = this.shipped_at.ts_diff(this.ordered_at, "month")`,
+			[2]string{
+				`{"ordered_at":"2024-01-15T09:00:00Z","shipped_at":"2024-03-14T10:00:00Z"}`,
+				`{"age_days":59,"age_months":1}`,
+			})
+
+	tsDiffCtor := func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		unit, err := args.GetString("unit")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := diffTimestamps(time.Time{}, time.Time{}, unit); err != nil {
+			return nil, err
+		}
+		loc, err := optionalLocation(args)
+		if err != nil {
+			return nil, err
+		}
+		other, err := timestampArg(args, "other")
+		if err != nil {
+			return nil, err
+		}
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			t = inLocation(t, loc)
+			return diffTimestamps(other.In(t.Location()), t, unit)
+		}), nil
+	}
+
+	if err := bloblang.RegisterMethodV2("ts_diff", tsDiffSpec, tsDiffCtor); err != nil {
+		panic(err)
+	}
+
+	tsTruncateSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Returns the start of the unit of time that a timestamp falls within, such as the start of its day or month. Weeks start on a Monday.`).
+		Param(bloblang.NewStringParam("unit").Description("The unit to truncate to, one of `second`, `minute`, `hour`, `day`, `week`, `month`, `quarter` or `year`.")).
+		Param(tzParam).
+		Example("",
+			`root.day = this.created_at.ts_truncate("day")
+root.week = this.created_at.ts_truncate("week")
+root.month = this.created_at.ts_truncate("month", "Europe/London")`,
+			[2]string{
+				`{"created_at":"2024-06-13T23:30:00Z"}`,
+				`{"day":"2024-06-13T00:00:00Z","month":"2024-06-01T00:00:00+01:00","week":"2024-06-10T00:00:00Z"}`,
+			})
+
+	tsTruncateCtor := func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		unit, err := args.GetString("unit")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := truncateTimestamp(time.Time{}, unit); err != nil {
+			return nil, err
+		}
+		loc, err := optionalLocation(args)
+		if err != nil {
+			return nil, err
+		}
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			return truncateTimestamp(inLocation(t, loc), unit)
+		}), nil
+	}
+
+	if err := bloblang.RegisterMethodV2("ts_truncate", tsTruncateSpec, tsTruncateCtor); err != nil {
+		panic(err)
+	}
+
+	//--------------------------------------------------------------------------
+
+	tsISOWeekSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Returns the ISO-8601 week number of a timestamp, from 1 to 53. The first week of a year is the week containing its first Thursday, and therefore the first and last few days of a year can belong to a week of a neighbouring year, which is given by `+"[`ts_iso_year`](#ts_iso_year)"+`.`).
+		Example("",
+			`root.partition = "%d/W%02d".format(this.created_at.ts_iso_year(), this.created_at.ts_iso_week())`,
+			[2]string{
+				`{"created_at":"2021-01-02T12:00:00Z"}`,
+				`{"partition":"2020/W53"}`,
+			})
+
+	if err := bloblang.RegisterMethodV2("ts_iso_week", tsISOWeekSpec, func(*bloblang.ParsedParams) (bloblang.Method, error) {
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			_, week := t.ISOWeek()
+			return int64(week), nil
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+
+	tsISOYearSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Returns the year that the ISO-8601 week of a timestamp belongs to, which can differ from its calendar year during the first and last few days of a year.`).
+		Example("",
+			`root.iso_year = this.created_at.ts_iso_year()`,
+			[2]string{
+				`{"created_at":"2024-12-30T12:00:00Z"}`,
+				`{"iso_year":2025}`,
+			})
+
+	if err := bloblang.RegisterMethodV2("ts_iso_year", tsISOYearSpec, func(*bloblang.ParsedParams) (bloblang.Method, error) {
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			year, _ := t.ISOWeek()
+			return int64(year), nil
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+
+	tsDayOfYearSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Returns the day of the year of a timestamp, from 1 to 365, or 366 in leap years.`).
+		Example("",
+			`root.day_of_year = this.created_at.ts_day_of_year()`,
+			[2]string{
+				`{"created_at":"2024-03-01T12:00:00Z"}`,
+				`{"day_of_year":61}`,
+			})
+
+	if err := bloblang.RegisterMethodV2("ts_day_of_year", tsDayOfYearSpec, func(*bloblang.ParsedParams) (bloblang.Method, error) {
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			return int64(t.YearDay()), nil
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+
+	//--------------------------------------------------------------------------
+
+	tsIsBusinessDaySpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Returns whether a timestamp falls on a business day, which is a Monday to Friday that is not a holiday.`).
+		Param(holidaysParam).
+		Example("",
+			`root.business_day = this.created_at.ts_is_business_day(["2024-12-25"])`,
+			[2]string{
+				`{"created_at":"2024-12-25T12:00:00Z"}`,
+				`{"business_day":false}`,
+			})
+
+	if err := bloblang.RegisterMethodV2("ts_is_business_day", tsIsBusinessDaySpec, func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		cal, err := newBusinessCalendar(args)
+		if err != nil {
+			return nil, err
+		}
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			return cal.isBusinessDay(t), nil
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+
+	tsAddBusinessDaysSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Adds a number of business days to a timestamp, skipping weekends and holidays, and keeping the time of day. A negative number of days moves the timestamp backwards.`).
+		Param(bloblang.NewInt64Param("days").Description("The number of business days to add.")).
+		Param(holidaysParam).
+		Example("",
+			`root.due_at = this.created_at.ts_add_business_days(3, ["2024-12-25", "2024-12-26"])`,
+			[2]string{
+				`{"created_at":"2024-12-20T09:00:00Z"}`,
+				`{"due_at":"2024-12-27T09:00:00Z"}`,
+			})
+
+	if err := bloblang.RegisterMethodV2("ts_add_business_days", tsAddBusinessDaysSpec, func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		days, err := args.GetInt64("days")
+		if err != nil {
+			return nil, err
+		}
+		cal, err := newBusinessCalendar(args)
+		if err != nil {
+			return nil, err
+		}
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			return cal.addDays(t, days), nil
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+
+	tsDiffBusinessDaysSpec := bloblang.NewPluginSpec().
+		Beta().
+		Static().
+		Category(query.MethodCategoryTime).
+		Description(`Returns the number of business days from another timestamp to the target timestamp, counting each business day after the date of the other timestamp up to and including the date of the target. The result is negative when the target is earlier.`).
+		Param(bloblang.NewAnyParam("other").Description("The timestamp to count from.")).
+		Param(holidaysParam).
+		Example("",
+			`root.business_days_taken = this.resolved_at.ts_diff_business_days(this.opened_at)`,
+			[2]string{
+				`{"opened_at":"2024-06-07T16:00:00Z","resolved_at":"2024-06-11T10:00:00Z"}`,
+				`{"business_days_taken":2}`,
+			})
+
+	if err := bloblang.RegisterMethodV2("ts_diff_business_days", tsDiffBusinessDaysSpec, func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+		other, err := timestampArg(args, "other")
+		if err != nil {
+			return nil, err
+		}
+		cal, err := newBusinessCalendar(args)
+		if err != nil {
+			return nil, err
+		}
+		return bloblang.TimestampMethod(func(t time.Time) (any, error) {
+			return cal.diffDays(other.In(t.Location()), t), nil
+		}), nil
+	}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,266 @@
+package pure
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestTimestampCalendarMethods(t *testing.T) {
+	tests := []struct {
+		name               string
+		mapping            string
+		input              any
+		output             any
+		parseErrorContains string
+		execErrorContains  string
+	}{
+		{
+			name:    "ts_add nanoseconds",
+			mapping: `root = this.ts_add(1000000000).ts_format()`,
+			input:   "2024-01-01T00:00:00Z",
+			output:  "2024-01-01T00:00:01Z",
+		},
+		{
+			name:    "ts_add duration string",
+			mapping: `root = this.ts_add("1h30m").ts_format()`,
+			input:   "2024-01-01T00:00:00Z",
+			output:  "2024-01-01T01:30:00Z",
+		},
+		{
+			name:    "ts_add iso period",
+			mapping: `root = this.ts_add("P1Y2M3DT4H").ts_format()`,
+			input:   "2024-01-01T00:00:00Z",
+			output:  "2025-03-04T04:00:00Z",
+		},
+		{
+			name:    "ts_add months clamps to end of month",
+			mapping: `root = this.ts_add({"months": 1}).ts_format()`,
+			input:   "2023-01-31T12:00:00Z",
+			output:  "2023-02-28T12:00:00Z",
+		},
+		{
+			name:    "ts_add years from leap day",
+			mapping: `root = this.ts_add({"years": 1}).ts_format()`,
+			input:   "2024-02-29T12:00:00Z",
+			output:  "2025-02-28T12:00:00Z",
+		},
+		{
+			name:    "ts_add days across dst",
+			mapping: `root = this.ts_add({"days": 1}, "Europe/London").ts_format()`,
+			input:   "2024-03-30T12:00:00Z",
+			output:  "2024-03-31T12:00:00+01:00",
+		},
+		{
+			name:    "ts_add hours across dst",
+			mapping: `root = this.ts_add({"hours": 24}, "Europe/London").ts_format()`,
+			input:   "2024-03-30T12:00:00Z",
+			output:  "2024-03-31T13:00:00+01:00",
+		},
+		{
+			name:               "ts_add bad field",
+			mapping:            `root = this.ts_add({"fortnights": 1})`,
+			parseErrorContains: `unrecognised duration field "fortnights"`,
+		},
+		{
+			name:               "ts_add bad string",
+			mapping:            `root = this.ts_add("soon")`,
+			parseErrorContains: `failed to parse "soon" as either a duration or an ISO-8601 period`,
+		},
+		{
+			name:    "ts_sub months",
+			mapping: `root = this.ts_sub({"months": 1, "days": 1}).ts_format()`,
+			input:   "2024-03-31T00:00:00Z",
+			output:  "2024-02-28T00:00:00Z",
+		},
+		{
+			name:    "ts_sub dynamic amount",
+			mapping: `root = "2024-03-31T00:00:00Z".ts_sub(this).ts_format()`,
+			input:   "24h",
+			output:  "2024-03-30T00:00:00Z",
+		},
+		{
+			name:    "ts_diff hours",
+			mapping: `root = this.b.ts_diff(this.a, "hour")`,
+			input:   map[string]any{"a": "2024-01-01T00:00:00Z", "b": "2024-01-02T05:59:00Z"},
+			output:  int64(29),
+		},
+		{
+			name:    "ts_diff days negative",
+			mapping: `root = this.a.ts_diff(this.b, "day")`,
+			input:   map[string]any{"a": "2024-01-01T00:00:00Z", "b": "2024-01-02T05:59:00Z"},
+			output:  int64(-1),
+		},
+		{
+			name:    "ts_diff months",
+			mapping: `root = this.b.ts_diff(this.a, "month")`,
+			input:   map[string]any{"a": "2024-01-31T00:00:00Z", "b": "2024-02-29T00:00:00Z"},
+			output:  int64(1),
+		},
+		{
+			name:    "ts_diff months not yet passed",
+			mapping: `root = this.b.ts_diff(this.a, "month")`,
+			input:   map[string]any{"a": "2024-01-15T12:00:00Z", "b": "2024-02-15T11:59:59Z"},
+			output:  int64(0),
+		},
+		{
+			name:    "ts_diff years",
+			mapping: `root = this.b.ts_diff(this.a, "year")`,
+			input:   map[string]any{"a": "2020-02-29T00:00:00Z", "b": "2023-02-28T00:00:00Z"},
+			output:  int64(3),
+		},
+		{
+			name:               "ts_diff bad unit",
+			mapping:            `root = this.ts_diff("2024-01-01T00:00:00Z", "fortnight")`,
+			parseErrorContains: `unrecognised unit "fortnight"`,
+		},
+		{
+			name:    "ts_truncate week",
+			mapping: `root = this.ts_truncate("week").ts_format()`,
+			input:   "2024-06-16T23:30:00Z",
+			output:  "2024-06-10T00:00:00Z",
+		},
+		{
+			name:    "ts_truncate quarter",
+			mapping: `root = this.ts_truncate("quarter").ts_format()`,
+			input:   "2024-06-16T23:30:00Z",
+			output:  "2024-04-01T00:00:00Z",
+		},
+		{
+			name:    "ts_truncate day in timezone",
+			mapping: `root = this.ts_truncate("day", "America/New_York").ts_format()`,
+			input:   "2024-06-16T02:30:00Z",
+			output:  "2024-06-15T00:00:00-04:00",
+		},
+		{
+			name:               "ts_truncate bad unit",
+			mapping:            `root = this.ts_truncate("decade")`,
+			parseErrorContains: `unrecognised unit "decade"`,
+		},
+		{
+			name:    "ts_iso_week and year",
+			mapping: `root = [this.ts_iso_year(), this.ts_iso_week(), this.ts_day_of_year()]`,
+			input:   "2024-12-31T12:00:00Z",
+			output:  []any{int64(2025), int64(1), int64(366)},
+		},
+		{
+			name:    "ts_is_business_day weekend",
+			mapping: `root = this.ts_is_business_day()`,
+			input:   "2024-06-15T12:00:00Z",
+			output:  false,
+		},
+		{
+			name:    "ts_is_business_day timestamp holiday",
+			mapping: `root = this.ts_is_business_day(["2024-06-14T00:00:00Z".ts_parse("2006-01-02T15:04:05Z07:00")])`,
+			input:   "2024-06-14T12:00:00Z",
+			output:  false,
+		},
+		{
+			name:    "ts_add_business_days over weekend",
+			mapping: `root = this.ts_add_business_days(1).ts_format()`,
+			input:   "2024-06-14T12:00:00Z",
+			output:  "2024-06-17T12:00:00Z",
+		},
+		{
+			name:    "ts_add_business_days backwards",
+			mapping: `root = this.ts_add_business_days(-2, ["2024-06-14"]).ts_format()`,
+			input:   "2024-06-17T12:00:00Z",
+			output:  "2024-06-12T12:00:00Z",
+		},
+		{
+			name:    "ts_diff_business_days",
+			mapping: `root = [this.b.ts_diff_business_days(this.a), this.a.ts_diff_business_days(this.b, ["2024-06-12"])]`,
+			input:   map[string]any{"a": "2024-06-07T16:00:00Z", "b": "2024-06-17T09:00:00Z"},
+			output:  []any{int64(6), int64(-5)},
+		},
+		{
+			name:               "ts_add_business_days bad holidays",
+			mapping:            `root = this.ts_add_business_days(1, "2024-06-14")`,
+			parseErrorContains: `expected array value`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			m, err := bloblang.Parse(test.mapping)
+			if test.parseErrorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErrorContains)
+			} else {
+				require.NoError(t, err)
+				v, err := m.Query(test.input)
+				if test.execErrorContains != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.execErrorContains)
+				} else {
+					require.NoError(t, err)
+					assert.Equal(t, test.output, v)
+				}
+			}
+		})
+	}
+}
+
+func TestBusinessCalendarArithmetic(t *testing.T) {
+	c := businessCalendar{holidays: map[string]struct{}{}}
+	for _, h := range []string{"2024-06-12", "2024-06-14", "2024-06-15", "2024-06-17", "1969-12-31", "2024-12-25"} {
+		c.holidays[h] = struct{}{}
+		ht, err := time.Parse(businessDateLayout, h)
+		require.NoError(t, err)
+		if wd := ht.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			c.weekdayHolidays = append(c.weekdayHolidays, dayNumber(ht))
+		}
+	}
+	sort.Slice(c.weekdayHolidays, func(i, j int) bool { return c.weekdayHolidays[i] < c.weekdayHolidays[j] })
+
+	// Reference implementations that step through each day.
+	stepAdd := func(ts time.Time, n int64) time.Time {
+		step := 1
+		if n < 0 {
+			step, n = -1, -n
+		}
+		for n > 0 {
+			if ts = ts.AddDate(0, 0, step); c.isBusinessDay(ts) {
+				n--
+			}
+		}
+		return ts
+	}
+	stepDiff := func(from, to time.Time) (n int64) {
+		sign := int64(1)
+		if to.Before(from) {
+			from, to, sign = to, from, -1
+		}
+		for ts := from.AddDate(0, 0, 1); !ts.After(to); ts = ts.AddDate(0, 0, 1) {
+			if c.isBusinessDay(ts) {
+				n++
+			}
+		}
+		return sign * n
+	}
+
+	for _, start := range []time.Time{
+		time.Date(2024, 6, 10, 9, 30, 0, 0, time.UTC),
+		time.Date(2024, 6, 15, 9, 30, 0, 0, time.UTC),
+		time.Date(2024, 6, 16, 23, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 2, 12, 0, 0, 0, time.UTC),
+	} {
+		for n := int64(-30); n <= 30; n++ {
+			exp := stepAdd(start, n)
+			assert.Equal(t, exp, c.addDays(start, n), "%v + %v", start, n)
+			assert.Equal(t, stepDiff(start, exp), c.diffDays(start, exp), "%v to %v", start, exp)
+		}
+	}
+
+	// Large counts do not step through each day.
+	start := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	end := c.addDays(start, 1_000_000)
+	assert.Equal(t, int64(1_000_000), c.diffDays(start, end))
+	assert.Equal(t, start, c.addDays(end, -1_000_000))
+}
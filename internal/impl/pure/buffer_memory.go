@@ -2,6 +2,7 @@ package pure
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -44,10 +45,32 @@ This buffer intentionally weakens the delivery guarantees of the pipeline and th
 
 ## Batching
 
-It is possible to batch up messages sent from this buffer using a [batch policy](/docs/configuration/batching#batch-policy).`).
+It is possible to batch up messages sent from this buffer using a [batch policy](/docs/configuration/batching#batch-policy).
+
+## Spilling to Disk
+
+When ` + "`spill.enabled`" + ` is set to ` + "`true`" + ` messages that arrive once the ` + "`limit`" + ` has been reached are written to temporary file segments on local disk rather than applying back pressure. Spilled messages are read back in the order they were received as consumers catch up, and segments are deleted once fully read. Back pressure is only applied once the total size of spilled messages reaches ` + "`spill.limit`" + `.
+
+Spilled messages are not persisted across restarts and therefore this mode does not strengthen the delivery guarantees of the buffer, it only increases its capacity for absorbing bursts of traffic. Structured metadata values are stored as their JSON equivalent.`).
 		Field(service.NewIntField("limit").
 			Description(`The maximum buffer size (in bytes) to allow before applying backpressure upstream.`).
 			Default(524288000)).
+		Field(service.NewObjectField("spill",
+			service.NewBoolField("enabled").
+				Description("Whether to spill messages to disk once the `limit` has been reached rather than applying back pressure.").
+				Default(false),
+			service.NewStringField("directory").
+				Description("A directory within which a temporary directory for spill segments is created. When empty the default directory for temporary files of the OS is used.").
+				Default(""),
+			service.NewIntField("segment_size").
+				Description("The size (in bytes) at which a spill segment is closed and a new segment is started.").
+				Default(67108864),
+			service.NewIntField("limit").
+				Description("The maximum total size (in bytes) of messages to spill to disk before applying back pressure upstream, or zero for no limit.").
+				Default(0),
+		).
+			Description("Optionally spill messages to local disk once the in-memory limit is reached.").
+			Advanced()).
 		Field(service.NewInternalField(bs))
 }
 
@@ -88,7 +111,33 @@ func newMemoryBufferFromConfig(conf *service.ParsedConfig, res *service.Resource
 		}
 	}
 
-	return newMemoryBuffer(limit, batcher), nil
+	buf := newMemoryBuffer(limit, batcher)
+	buf.log = res.Logger()
+
+	spillEnabled, err := conf.FieldBool("spill", "enabled")
+	if err != nil {
+		return nil, err
+	}
+	if spillEnabled {
+		spillDir, err := conf.FieldString("spill", "directory")
+		if err != nil {
+			return nil, err
+		}
+		segmentSize, err := conf.FieldInt("spill", "segment_size")
+		if err != nil {
+			return nil, err
+		}
+		if segmentSize <= 0 {
+			return nil, errors.New("spill segment_size must be greater than zero")
+		}
+		if buf.spillLimit, err = conf.FieldInt("spill", "limit"); err != nil {
+			return nil, err
+		}
+		if buf.spill, err = newSpillStore(spillDir, int64(segmentSize)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
 }
 
 //------------------------------------------------------------------------------
@@ -108,6 +157,10 @@ type memoryBuffer struct {
 	closed     bool
 
 	batcher *service.Batcher
+	log     *service.Logger
+
+	spill      *spillStore
+	spillLimit int
 }
 
 func newMemoryBuffer(capacity int, batcher *service.Batcher) *memoryBuffer {
@@ -177,7 +230,10 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 			return nil, nil, ctx.Err()
 		}
 
-		for len(m.batches) > 0 && !batchReady {
+		for !batchReady {
+			if len(m.batches) == 0 && !m.unspill() {
+				break
+			}
 			outSize += m.batches[0].size
 			for _, msg := range m.batches[0].b {
 				batchReady = m.batcher.Add(msg.Copy())
@@ -188,6 +244,17 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 			m.batches = m.batches[1:]
 		}
 
+		// Spilled batches are read from disk without holding the lock so that
+		// writers and other readers are not blocked on disk I/O.
+		if !batchReady && m.spilledBatches() > 0 {
+			m.cond.L.Unlock()
+			ready := m.prefetchSpilled()
+			m.cond.L.Lock()
+			if ready {
+				continue
+			}
+		}
+
 		if batchReady || m.endOfInput {
 			var err error
 			if outBatch, err = m.batcher.Flush(ctx); err != nil {
@@ -221,6 +288,47 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 	}, nil
 }
 
+// unspill moves the oldest spilled batch into memory if it has been read from
+// disk, returns false otherwise.
+func (m *memoryBuffer) unspill() bool {
+	if m.spill == nil {
+		return false
+	}
+	batch, size := m.spill.pop()
+	if batch == nil {
+		return false
+	}
+	m.batches = append(m.batches, measuredBatch{
+		b:    batch,
+		size: size,
+	})
+	m.bytes += size
+	return true
+}
+
+// prefetchSpilled reads the oldest spilled batch from disk so that it can be
+// moved into memory with unspill, and returns true if a batch is ready. This
+// performs disk I/O and must therefore be called without holding the lock.
+func (m *memoryBuffer) prefetchSpilled() bool {
+	for {
+		ready, err := m.spill.prefetch()
+		if err == nil {
+			return ready
+		}
+		if m.log != nil {
+			m.log.Errorf("Failed to read spilled messages from disk, they will be dropped: %v", err)
+		}
+	}
+}
+
+func (m *memoryBuffer) spilledBatches() int {
+	if m.spill == nil {
+		return 0
+	}
+	count, _ := m.spill.stats()
+	return count
+}
+
 // PushMessage adds a new message to the stack. Returns the backlog in bytes.
 func (m *memoryBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageBatch, aFn service.AckFunc) error {
 	// Deep copy before acknowledging in order to avoid vague ownership
@@ -243,16 +351,51 @@ func (m *memoryBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageB
 	}
 
 	m.cond.L.Lock()
-	defer m.cond.L.Unlock()
+	spilled, err := m.pushBatch(msgBatch, extraBytes)
+	m.cond.L.Unlock()
+	if err != nil || !spilled {
+		return err
+	}
 
+	// Spilled batches are written to disk outside of the buffer lock so that
+	// readers and writers aren't blocked on the I/O. Readers are notified once
+	// the write completes as the batch can only be consumed from then on.
+	err = m.spill.flush()
+
+	m.cond.L.Lock()
+	m.cond.Broadcast()
+	m.cond.L.Unlock()
+	return err
+}
+
+// pushBatch adds a batch to the buffer, blocking until there is capacity for
+// it. Returns true if the batch was queued for spilling to disk, in which case
+// it must be flushed to the spill store once the lock is released. Must be
+// called with the lock held.
+func (m *memoryBuffer) pushBatch(msgBatch service.MessageBatch, extraBytes int) (bool, error) {
 	if m.closed {
-		return component.ErrTypeClosed
+		return false, component.ErrTypeClosed
 	}
 
-	for (m.bytes + extraBytes) > m.cap {
+	for {
+		// Once any batches are spilled all subsequent batches must also be
+		// spilled until the disk is drained in order to preserve ordering.
+		if m.spill != nil {
+			spillCount, spillBytes := m.spill.stats()
+			if spillCount > 0 || (m.bytes+extraBytes) > m.cap {
+				if m.spillLimit <= 0 || (spillBytes+extraBytes) <= m.spillLimit {
+					m.spill.push(msgBatch, extraBytes)
+					return true, nil
+				}
+			} else {
+				break
+			}
+		} else if (m.bytes + extraBytes) <= m.cap {
+			break
+		}
 		m.cond.Wait()
 		if m.closed {
-			return component.ErrTypeClosed
+			return false, component.ErrTypeClosed
 		}
 	}
 
@@ -263,7 +406,7 @@ func (m *memoryBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageB
 	m.bytes += extraBytes
 
 	m.cond.Broadcast()
-	return nil
+	return false, nil
 }
 
 func (m *memoryBuffer) EndOfInput() {
@@ -274,7 +417,7 @@ func (m *memoryBuffer) EndOfInput() {
 		m.endOfInput = true
 		m.cond.Broadcast()
 
-		for m.bytes > 0 && !m.closed {
+		for (m.bytes > 0 || m.spilledBatches() > 0) && !m.closed {
 			m.cond.Wait()
 		}
 		m.closed = true
@@ -284,8 +427,12 @@ func (m *memoryBuffer) EndOfInput() {
 
 func (m *memoryBuffer) Close(ctx context.Context) error {
 	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+
 	m.closed = true
 	m.cond.Broadcast()
-	m.cond.L.Unlock()
+	if m.spill != nil {
+		return m.spill.close()
+	}
 	return nil
 }
@@ -0,0 +1,424 @@
+package pure
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// spillSegment is a single temporary file containing a sequence of serialised
+// batches. Batches are appended by the writer and consumed in order by the
+// reader using a separate file handle.
+type spillSegment struct {
+	path string
+
+	w       *os.File
+	written int64
+
+	rf *os.File
+	r  *bufio.Reader
+
+	pending int
+	bytes   int
+}
+
+func (s *spillSegment) close() {
+	if s.w != nil {
+		_ = s.w.Close()
+		s.w = nil
+	}
+	if s.rf != nil {
+		_ = s.rf.Close()
+		s.rf = nil
+	}
+	_ = os.Remove(s.path)
+}
+
+// spillEntry is a batch that has been accepted by the store but not yet
+// written to a segment.
+type spillEntry struct {
+	batch service.MessageBatch
+	size  int
+}
+
+// spillStore is a FIFO queue of message batches stored within a sequence of
+// temporary file segments. Segments are deleted once all of their batches have
+// been read.
+//
+// Batches are accepted with push, which only queues them, and are written to
+// disk in the order they were pushed by calls to flush. Similarly, batches are
+// read from disk ahead of time by calls to prefetch and then taken with pop.
+// This allows callers to push and pop batches whilst holding a lock and perform
+// the disk I/O once it has been released.
+type spillStore struct {
+	dir         string
+	segmentSize int64
+	nextID      int
+
+	// Serialises reads from the head segment.
+	readMut sync.Mutex
+
+	// Serialises writes to the tail segment so that queued batches are
+	// written in order.
+	writeMut sync.Mutex
+
+	mut      sync.Mutex
+	queue    []spillEntry
+	segments []*spillSegment
+	next     *spillEntry
+	closed   bool
+
+	// The total size of message payloads and the number of batches currently
+	// held by the store, including those queued but not yet written.
+	bytes int
+	count int
+}
+
+func newSpillStore(parentDir string, segmentSize int64) (*spillStore, error) {
+	if parentDir == "" {
+		parentDir = os.TempDir()
+	}
+	dir, err := os.MkdirTemp(parentDir, "benthos-memory-buffer-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	return &spillStore{
+		dir:         dir,
+		segmentSize: segmentSize,
+	}, nil
+}
+
+// stats returns the number of batches and total size of the store.
+func (s *spillStore) stats() (count, bytes int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.count, s.bytes
+}
+
+// writeSegment returns the segment that the next batch should be written to,
+// must be called with writeMut held.
+func (s *spillStore) writeSegment() (*spillSegment, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if len(s.segments) > 0 {
+		if seg := s.segments[len(s.segments)-1]; seg.w != nil && seg.written < s.segmentSize {
+			return seg, nil
+		}
+	}
+
+	// The previous segment is full so we stop writing to it.
+	if len(s.segments) > 0 {
+		if seg := s.segments[len(s.segments)-1]; seg.w != nil {
+			_ = seg.w.Close()
+			seg.w = nil
+		}
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("%08d.seg", s.nextID))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.nextID++
+
+	seg := &spillSegment{path: path, w: f}
+	s.segments = append(s.segments, seg)
+	return seg, nil
+}
+
+// push queues a batch of size bytes at the end of the store, the batch is not
+// written to disk until flush is called.
+func (s *spillStore) push(batch service.MessageBatch, size int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.queue = append(s.queue, spillEntry{batch: batch, size: size})
+	s.count++
+	s.bytes += size
+}
+
+// flush writes all queued batches to disk in the order they were pushed. If a
+// batch could not be written it is dropped and the first such error is
+// returned once the queue is drained.
+func (s *spillStore) flush() error {
+	s.writeMut.Lock()
+	defer s.writeMut.Unlock()
+
+	var firstErr error
+	for {
+		s.mut.Lock()
+		if s.closed || len(s.queue) == 0 {
+			s.mut.Unlock()
+			return firstErr
+		}
+		e := s.queue[0]
+		s.queue[0] = spillEntry{}
+		s.queue = s.queue[1:]
+		s.mut.Unlock()
+
+		if err := s.write(e); err != nil {
+			s.mut.Lock()
+			s.count--
+			s.bytes -= e.size
+			s.mut.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+}
+
+func (s *spillStore) write(e spillEntry) error {
+	data, err := spillSerialiseBatch(e.batch)
+	if err != nil {
+		return err
+	}
+
+	seg, err := s.writeSegment()
+	if err != nil {
+		return err
+	}
+
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(e.size))
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	if _, err := seg.w.Write(append(header[:], data...)); err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	seg.written += int64(len(header) + len(data))
+	seg.pending++
+	seg.bytes += e.size
+	s.mut.Unlock()
+	return nil
+}
+
+// prefetch reads the oldest batch that has been written to disk so that it can
+// be taken with pop, and returns true if a batch is ready to be popped. If the
+// batch could not be read then the remainder of its segment is discarded and
+// an error is returned.
+func (s *spillStore) prefetch() (bool, error) {
+	s.readMut.Lock()
+	defer s.readMut.Unlock()
+
+	s.mut.Lock()
+	if s.closed {
+		s.mut.Unlock()
+		return false, nil
+	}
+	if s.next != nil {
+		s.mut.Unlock()
+		return true, nil
+	}
+	var seg *spillSegment
+	for len(s.segments) > 0 {
+		if seg = s.segments[0]; seg.pending > 0 {
+			break
+		}
+		if seg.w != nil {
+			// Nothing has been written to the tail segment yet.
+			s.mut.Unlock()
+			return false, nil
+		}
+		s.dropHead()
+		seg = nil
+	}
+	s.mut.Unlock()
+	if seg == nil {
+		return false, nil
+	}
+
+	// Only the head segment is read from, and it is only dropped whilst
+	// holding readMut, and therefore it can be read without holding mut.
+	batch, size, err := seg.read()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.closed {
+		return false, nil
+	}
+	if err != nil {
+		s.dropHead()
+		return false, err
+	}
+
+	seg.pending--
+	seg.bytes -= size
+	if seg.pending == 0 && seg.w == nil {
+		s.dropHead()
+	}
+	s.next = &spillEntry{batch: batch, size: size}
+	return true, nil
+}
+
+// pop takes the oldest batch from the store if it has been read from disk by
+// prefetch, otherwise a nil batch is returned.
+func (s *spillStore) pop() (service.MessageBatch, int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.next == nil {
+		return nil, 0
+	}
+	e := s.next
+	s.next = nil
+	s.count--
+	s.bytes -= e.size
+	return e.batch, e.size
+}
+
+func (s *spillStore) dropHead() {
+	seg := s.segments[0]
+	s.count -= seg.pending
+	s.bytes -= seg.bytes
+	seg.close()
+	s.segments[0] = nil
+	s.segments = s.segments[1:]
+}
+
+func (s *spillSegment) read() (service.MessageBatch, int, error) {
+	if s.rf == nil {
+		f, err := os.Open(s.path)
+		if err != nil {
+			return nil, 0, err
+		}
+		s.rf = f
+		s.r = bufio.NewReader(f)
+	}
+
+	var header [8]byte
+	if _, err := io.ReadFull(s.r, header[:]); err != nil {
+		return nil, 0, fmt.Errorf("failed to read spilled batch header: %w", err)
+	}
+	size := int(binary.BigEndian.Uint32(header[:4]))
+
+	data := make([]byte, binary.BigEndian.Uint32(header[4:]))
+	if _, err := io.ReadFull(s.r, data); err != nil {
+		return nil, 0, fmt.Errorf("failed to read spilled batch: %w", err)
+	}
+
+	batch, err := spillDeserialiseBatch(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return batch, size, nil
+}
+
+// close deletes all segments and the spill directory, any queued batches are
+// dropped.
+func (s *spillStore) close() error {
+	s.readMut.Lock()
+	defer s.readMut.Unlock()
+
+	s.writeMut.Lock()
+	defer s.writeMut.Unlock()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, seg := range s.segments {
+		seg.close()
+	}
+	s.segments = nil
+	s.queue = nil
+	s.next = nil
+	s.closed = true
+	s.count, s.bytes = 0, 0
+	return os.RemoveAll(s.dir)
+}
+
+//------------------------------------------------------------------------------
+
+func spillSerialiseBatch(batch service.MessageBatch) ([]byte, error) {
+	var buf bytes.Buffer
+	writeChunk := func(b []byte) {
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(b)))
+		_, _ = buf.Write(l[:])
+		_, _ = buf.Write(b)
+	}
+
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(batch)))
+	_, _ = buf.Write(n[:])
+
+	for i, msg := range batch {
+		mBytes, err := msg.AsBytes()
+		if err != nil {
+			return nil, fmt.Errorf("unable to extract bytes from message %v: %w", i, err)
+		}
+		writeChunk(mBytes)
+
+		meta := map[string]any{}
+		_ = msg.MetaWalkMut(func(k string, v any) error {
+			meta[k] = v
+			return nil
+		})
+		metaBytes, err := json.Marshal(meta)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialise metadata of message %v: %w", i, err)
+		}
+		writeChunk(metaBytes)
+	}
+	return buf.Bytes(), nil
+}
+
+func spillDeserialiseBatch(data []byte) (service.MessageBatch, error) {
+	errUnexpectedEnd := errors.New("spilled batch ended unexpectedly")
+	readChunk := func() ([]byte, error) {
+		if len(data) < 4 {
+			return nil, errUnexpectedEnd
+		}
+		l := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		if len(data) < int(l) {
+			return nil, errUnexpectedEnd
+		}
+		b := data[:l]
+		data = data[l:]
+		return b, nil
+	}
+
+	if len(data) < 4 {
+		return nil, errUnexpectedEnd
+	}
+	n := int(binary.BigEndian.Uint32(data[:4]))
+	data = data[4:]
+
+	batch := make(service.MessageBatch, 0, n)
+	for i := 0; i < n; i++ {
+		mBytes, err := readChunk()
+		if err != nil {
+			return nil, err
+		}
+		metaBytes, err := readChunk()
+		if err != nil {
+			return nil, err
+		}
+
+		msg := service.NewMessage(mBytes)
+
+		dec := json.NewDecoder(bytes.NewReader(metaBytes))
+		dec.UseNumber()
+		var meta map[string]any
+		if err := dec.Decode(&meta); err != nil {
+			return nil, fmt.Errorf("failed to parse metadata of message %v: %w", i, err)
+		}
+		for k, v := range meta {
+			msg.MetaSetMut(k, v)
+		}
+		batch = append(batch, msg)
+	}
+	return batch, nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -476,3 +478,223 @@ batch_policy:
 	msgEqual(t, "hello", m[0])
 	require.NoError(t, ackFunc(ctx, nil))
 }
+
+func TestMemorySpill(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	block := memBufFromConf(t, fmt.Sprintf(`
+limit: 20
+spill:
+  enabled: true
+  directory: %v
+  segment_size: 50
+`, dir))
+
+	n := 50
+	for i := 0; i < n; i++ {
+		msg := service.NewMessage([]byte(fmt.Sprintf("test%02v", i)))
+		msg.MetaSetMut("index", i)
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{msg}, func(ctx context.Context, err error) error { return nil }))
+	}
+
+	spillDirs, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, spillDirs, 1)
+
+	segments, err := os.ReadDir(filepath.Join(dir, spillDirs[0].Name()))
+	require.NoError(t, err)
+	assert.Greater(t, len(segments), 1)
+
+	for i := 0; i < n; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		require.Len(t, m, 1)
+		msgEqual(t, fmt.Sprintf("test%02v", i), m[0])
+
+		v, ok := m[0].MetaGetMut("index")
+		require.True(t, ok)
+		assert.Equal(t, fmt.Sprintf("%v", i), fmt.Sprintf("%v", v))
+
+		if i%7 == 0 {
+			// Nack and read again in order to ensure spilled batches are
+			// retried in order.
+			require.NoError(t, ackFunc(ctx, errors.New("nope")))
+			m, ackFunc, err = block.ReadBatch(ctx)
+			require.NoError(t, err)
+			require.Len(t, m, 1)
+			msgEqual(t, fmt.Sprintf("test%02v", i), m[0])
+		}
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+
+	segments, err = os.ReadDir(filepath.Join(dir, spillDirs[0].Name()))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(segments), 1)
+
+	require.NoError(t, block.Close(ctx))
+
+	spillDirs, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, spillDirs)
+}
+
+func TestMemorySpillLimit(t *testing.T) {
+	ctx := context.Background()
+	block := memBufFromConf(t, fmt.Sprintf(`
+limit: 10
+spill:
+  enabled: true
+  directory: %v
+  limit: 10
+`, t.TempDir()))
+	defer block.Close(ctx)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte(fmt.Sprintf("test%v", i))),
+		}, func(ctx context.Context, err error) error { return nil }))
+	}
+
+	writeErr := make(chan error)
+	go func() {
+		writeErr <- block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte("test4")),
+		}, func(ctx context.Context, err error) error { return nil })
+	}()
+
+	select {
+	case <-writeErr:
+		t.Fatal("expected back pressure")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	for i := 0; i < 5; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		require.Len(t, m, 1)
+		msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+		require.NoError(t, ackFunc(ctx, nil))
+
+		if i == 2 {
+			require.NoError(t, <-writeErr)
+		}
+	}
+}
+
+func TestMemorySpillEndOfInput(t *testing.T) {
+	ctx := context.Background()
+	block := memBufFromConf(t, fmt.Sprintf(`
+limit: 10
+spill:
+  enabled: true
+  directory: %v
+`, t.TempDir()))
+	defer block.Close(ctx)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte(fmt.Sprintf("test%v", i))),
+		}, func(ctx context.Context, err error) error { return nil }))
+	}
+	block.EndOfInput()
+
+	for i := 0; i < 5; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		require.Len(t, m, 1)
+		msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+
+	_, _, err := block.ReadBatch(ctx)
+	assert.Equal(t, service.ErrEndOfBuffer, err)
+}
+
+func TestMemorySpillConcurrent(t *testing.T) {
+	ctx := context.Background()
+	block := memBufFromConf(t, fmt.Sprintf(`
+limit: 50
+spill:
+  enabled: true
+  directory: %v
+  segment_size: 100
+`, t.TempDir()))
+	defer block.Close(ctx)
+
+	writers, n := 5, 100
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				if err := block.WriteBatch(ctx, service.MessageBatch{
+					service.NewMessage([]byte(fmt.Sprintf("%v:%03v", w, i))),
+				}, func(ctx context.Context, err error) error { return nil }); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+
+	// Each writer must have its batches read in the order they were written.
+	next := make([]int, writers)
+	for i := 0; i < writers*n; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		require.Len(t, m, 1)
+
+		mBytes, err := m[0].AsBytes()
+		require.NoError(t, err)
+
+		var w, seq int
+		_, err = fmt.Sscanf(string(mBytes), "%d:%d", &w, &seq)
+		require.NoError(t, err)
+		require.Equal(t, next[w], seq, string(mBytes))
+		next[w]++
+
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+	wg.Wait()
+}
+
+func TestSpillStorePrefetch(t *testing.T) {
+	s, err := newSpillStore(t.TempDir(), 100)
+	require.NoError(t, err)
+	defer s.close()
+
+	s.push(service.MessageBatch{service.NewMessage([]byte("foo"))}, 3)
+	s.push(service.MessageBatch{service.NewMessage([]byte("bar"))}, 3)
+
+	// Nothing can be popped until it has been written and then read from
+	// disk.
+	ready, err := s.prefetch()
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	require.NoError(t, s.flush())
+	batch, _ := s.pop()
+	assert.Nil(t, batch)
+
+	for _, exp := range []string{"foo", "bar"} {
+		ready, err = s.prefetch()
+		require.NoError(t, err)
+		require.True(t, ready)
+
+		// Prefetching again does not read another batch.
+		ready, err = s.prefetch()
+		require.NoError(t, err)
+		require.True(t, ready)
+
+		batch, size := s.pop()
+		require.Len(t, batch, 1)
+		assert.Equal(t, 3, size)
+		msgEqual(t, exp, batch[0])
+	}
+
+	count, bytes := s.stats()
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, bytes)
+}
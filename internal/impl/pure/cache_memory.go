@@ -1,12 +1,22 @@
 package pure
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/OneOfOne/xxhash"
 
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -29,9 +39,17 @@ cache_resources:
         foo: bar
 ` + "```" + `
 
-These values can be overridden during execution, at which point the configured TTL is respected as usual.`).
+These values can be overridden during execution, at which point the configured TTL is respected as usual.
+
+### Bounds
+
+By default the cache is unbounded and will grow until items expire. The fields ` + "`max_items` and `max_bytes`" + ` can be used to limit the size of the cache, at which point items are evicted according to the ` + "`eviction_policy`" + `. The size of an item is calculated as the combined length of its key and value. Limits are divided evenly across ` + "`shards`" + `, and each eviction is counted by the metric ` + "`cache_evictions`" + `.
+
+### Snapshots
+
+When a ` + "`snapshot_path`" + ` is specified the contents of the cache are periodically written to a local file, as well as during a graceful shutdown, and are loaded back into the cache on startup. Items that have expired since the snapshot was written are not restored, and restored items take precedence over ` + "`init_values`" + `.`).
 		Field(service.NewDurationField("default_ttl").
-			Description("The default TTL of each item. After this period an item will be eligible for removal during the next compaction.").
+			Description("The default TTL of each item. After this period an item will be eligible for removal during the next compaction. Items set with an explicit TTL of zero never expire.").
 			Default("5m")).
 		Field(service.NewDurationField("compaction_interval").
 			Description("The period of time to wait before each compaction, at which point expired items are removed. This field can be set to an empty string in order to disable compactions/expiry entirely.").
@@ -47,6 +65,30 @@ These values can be overridden during execution, at which point the configured T
 		Field(service.NewIntField("shards").
 			Description("A number of logical shards to spread keys across, increasing the shards can have a performance benefit when processing a large number of keys.").
 			Default(1).
+			Advanced()).
+		Field(service.NewIntField("max_items").
+			Description("The maximum number of items to hold in the cache before evicting items, where zero is unbounded.").
+			Default(0).
+			Advanced()).
+		Field(service.NewIntField("max_bytes").
+			Description("The maximum number of bytes to hold in the cache before evicting items, where zero is unbounded.").
+			Default(0).
+			Advanced()).
+		Field(service.NewStringAnnotatedEnumField("eviction_policy", map[string]string{
+			"lru": "Evict the least recently used item.",
+			"lfu": "Evict the least frequently used item, where ties are broken by evicting the least recently used.",
+		}).
+			Description("The policy used to select items for eviction once the cache exceeds its bounds.").
+			Default("lru").
+			Advanced()).
+		Field(service.NewStringField("snapshot_path").
+			Description("An optional file path to periodically write the contents of the cache to, from which the cache is restored on startup.").
+			Default("").
+			Example("./cache.snapshot").
+			Advanced()).
+		Field(service.NewDurationField("snapshot_interval").
+			Description("The period of time to wait between snapshots when a `snapshot_path` is specified.").
+			Default("5m").
 			Advanced())
 	return spec
 }
@@ -55,7 +97,7 @@ func init() {
 	err := service.RegisterCache(
 		"memory", memCacheConfig(),
 		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Cache, error) {
-			f, err := newMemCacheFromConfig(conf)
+			f, err := newMemCacheFromConfig(conf, mgr)
 			if err != nil {
 				return nil, err
 			}
@@ -66,7 +108,7 @@ func init() {
 	}
 }
 
-func newMemCacheFromConfig(conf *service.ParsedConfig) (*memoryCache, error) {
+func newMemCacheFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*memoryCache, error) {
 	ttl, err := conf.FieldDuration("default_ttl")
 	if err != nil {
 		return nil, err
@@ -89,7 +131,53 @@ func newMemCacheFromConfig(conf *service.ParsedConfig) (*memoryCache, error) {
 		return nil, err
 	}
 
-	return newMemCache(ttl, compInterval, nShards, initValues), nil
+	maxItems, err := conf.FieldInt("max_items")
+	if err != nil {
+		return nil, err
+	}
+
+	maxBytes, err := conf.FieldInt("max_bytes")
+	if err != nil {
+		return nil, err
+	}
+
+	evictionPolicy, err := conf.FieldString("eviction_policy")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotPath, err := conf.FieldString("snapshot_path")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotInterval, err := conf.FieldDuration("snapshot_interval")
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMemCache(ttl, compInterval, nShards, initValues)
+	m.log = mgr.Logger()
+
+	if snapshotPath != "" {
+		m.fs = interop.UnwrapManagement(mgr).FS()
+		m.snapshotPath = snapshotPath
+		if err := m.loadSnapshot(); err != nil {
+			m.log.Warnf("Failed to fully restore cache snapshot '%v': %v", snapshotPath, err)
+		}
+	}
+
+	mEvictions := mgr.Metrics().NewCounter("cache_evictions")
+	m.setBounds(maxItems, maxBytes, evictionPolicy, func() {
+		mEvictions.Incr(1)
+	})
+
+	if snapshotPath != "" && snapshotInterval > 0 {
+		m.closeChan = make(chan struct{})
+		m.loopDone = make(chan struct{})
+		go m.snapshotLoop(snapshotInterval)
+	}
+	return m, nil
 }
 
 //------------------------------------------------------------------------------
@@ -99,12 +187,23 @@ type item struct {
 	expires time.Time
 }
 
+func itemSize(key string, i item) int {
+	return len(key) + len(i.value)
+}
+
 type shard struct {
 	items map[string]item
 
 	compInterval   time.Duration
 	lastCompaction time.Time
 
+	// Bounds are only enforced when a tracker is set.
+	maxItems int
+	maxBytes int
+	bytes    int
+	tracker  evictionTracker
+	onEvict  func()
+
 	sync.RWMutex
 }
 
@@ -127,12 +226,80 @@ func (s *shard) compaction() {
 	}
 	for k, v := range s.items {
 		if s.isExpired(v) {
-			delete(s.items, k)
+			s.remove(k)
 		}
 	}
 	s.lastCompaction = time.Now()
 }
 
+// get an item from the shard, acquiring the appropriate lock. Bounded shards
+// require a write lock as accesses are recorded for eviction.
+func (s *shard) get(key string) (item, bool) {
+	if s.tracker == nil {
+		s.RLock()
+		i, exists := s.items[key]
+		s.RUnlock()
+		return i, exists && !s.isExpired(i)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	i, exists := s.items[key]
+	if !exists || s.isExpired(i) {
+		return item{}, false
+	}
+	s.tracker.touch(key)
+	return i, true
+}
+
+// put an item into the shard, evicting other items if the shard is bounded and
+// now exceeds its bounds. The item being put is never evicted as a result, and
+// therefore an item that alone exceeds the bounds is kept. The shard must be
+// write locked.
+func (s *shard) put(key string, i item) {
+	if s.tracker != nil {
+		if old, exists := s.items[key]; exists {
+			s.bytes -= itemSize(key, old)
+		}
+		s.bytes += itemSize(key, i)
+		s.tracker.touch(key)
+	}
+	s.items[key] = i
+	s.evict(key)
+}
+
+// remove an item from the shard. The shard must be write locked.
+func (s *shard) remove(key string) {
+	if s.tracker != nil {
+		if old, exists := s.items[key]; exists {
+			s.bytes -= itemSize(key, old)
+			s.tracker.remove(key)
+		}
+	}
+	delete(s.items, key)
+}
+
+func (s *shard) exceedsBounds() bool {
+	return (s.maxItems > 0 && len(s.items) > s.maxItems) ||
+		(s.maxBytes > 0 && s.bytes > s.maxBytes)
+}
+
+func (s *shard) evict(protect string) {
+	if s.tracker == nil {
+		return
+	}
+	for s.exceedsBounds() {
+		key, ok := s.tracker.victim(protect)
+		if !ok {
+			return
+		}
+		s.remove(key)
+		if s.onEvict != nil {
+			s.onEvict()
+		}
+	}
+}
+
 //------------------------------------------------------------------------------
 
 func newMemCache(ttl, compInterval time.Duration, nShards int, initValues map[string]string) *memoryCache {
@@ -171,6 +338,44 @@ func newMemCache(ttl, compInterval time.Duration, nShards int, initValues map[st
 type memoryCache struct {
 	shards     []*shard
 	defaultTTL time.Duration
+
+	log          *service.Logger
+	fs           ifs.FS
+	snapshotPath string
+	closeChan    chan struct{}
+	loopDone     chan struct{}
+}
+
+// setBounds limits the number of items and bytes held by the cache, which are
+// divided evenly across shards, evicting items according to a policy once a
+// shard exceeds its share. A limit of zero is unbounded.
+func (m *memoryCache) setBounds(maxItems, maxBytes int, policy string, onEvict func()) {
+	if maxItems <= 0 && maxBytes <= 0 {
+		return
+	}
+	divideLimit := func(limit int) int {
+		if limit <= 0 {
+			return 0
+		}
+		if perShard := limit / len(m.shards); perShard > 0 {
+			return perShard
+		}
+		return 1
+	}
+	for _, s := range m.shards {
+		s.Lock()
+		s.maxItems = divideLimit(maxItems)
+		s.maxBytes = divideLimit(maxBytes)
+		s.onEvict = onEvict
+		s.tracker = newEvictionTracker(policy)
+		s.bytes = 0
+		for k, v := range s.items {
+			s.bytes += itemSize(k, v)
+			s.tracker.touch(k)
+		}
+		s.evict("")
+		s.Unlock()
+	}
 }
 
 func (m *memoryCache) getShard(key string) *shard {
@@ -183,42 +388,26 @@ func (m *memoryCache) getShard(key string) *shard {
 }
 
 func (m *memoryCache) Get(_ context.Context, key string) ([]byte, error) {
-	shard := m.getShard(key)
-	shard.RLock()
-	k, exists := shard.items[key]
-	shard.RUnlock()
-	if !exists {
-		return nil, service.ErrKeyNotFound
-	}
 	// Simulate compaction by returning ErrKeyNotFound if ttl expired.
-	if shard.isExpired(k) {
+	k, exists := m.getShard(key).get(key)
+	if !exists {
 		return nil, service.ErrKeyNotFound
 	}
 	return k.value, nil
 }
 
 func (m *memoryCache) Set(_ context.Context, key string, value []byte, ttl *time.Duration) error {
-	var expires time.Time
-	if ttl != nil {
-		expires = time.Now().Add(*ttl)
-	} else {
-		expires = time.Now().Add(m.defaultTTL)
-	}
+	expires := m.expiresFor(ttl)
 	shard := m.getShard(key)
 	shard.Lock()
 	shard.compaction()
-	shard.items[key] = item{value: value, expires: expires}
+	shard.put(key, item{value: value, expires: expires})
 	shard.Unlock()
 	return nil
 }
 
 func (m *memoryCache) Add(_ context.Context, key string, value []byte, ttl *time.Duration) error {
-	var expires time.Time
-	if ttl != nil {
-		expires = time.Now().Add(*ttl)
-	} else {
-		expires = time.Now().Add(m.defaultTTL)
-	}
+	expires := m.expiresFor(ttl)
 	shard := m.getShard(key)
 	shard.Lock()
 	if _, exists := shard.items[key]; exists {
@@ -226,7 +415,7 @@ func (m *memoryCache) Add(_ context.Context, key string, value []byte, ttl *time
 		return service.ErrKeyAlreadyExists
 	}
 	shard.compaction()
-	shard.items[key] = item{value: value, expires: expires}
+	shard.put(key, item{value: value, expires: expires})
 	shard.Unlock()
 	return nil
 }
@@ -235,11 +424,210 @@ func (m *memoryCache) Delete(_ context.Context, key string) error {
 	shard := m.getShard(key)
 	shard.Lock()
 	shard.compaction()
-	delete(shard.items, key)
+	shard.remove(key)
 	shard.Unlock()
 	return nil
 }
 
-func (m *memoryCache) Close(context.Context) error {
+// expiresFor returns the expiry time of an item set with a given TTL, where an
+// explicit TTL of zero means the item never expires.
+func (m *memoryCache) expiresFor(ttl *time.Duration) time.Time {
+	if ttl != nil {
+		if *ttl == 0 {
+			return time.Time{}
+		}
+		return time.Now().Add(*ttl)
+	}
+	return time.Now().Add(m.defaultTTL)
+}
+
+func (m *memoryCache) GetMulti(_ context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if k, exists := m.getShard(key).get(key); exists {
+			res[key] = k.value
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryCache) Incr(_ context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	expires := m.expiresFor(ttl)
+	shard := m.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	var v int64
+	if k, exists := shard.items[key]; exists && !shard.isExpired(k) {
+		var err error
+		if v, err = strconv.ParseInt(string(k.value), 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	v += delta
+
+	shard.compaction()
+	shard.put(key, item{value: []byte(strconv.FormatInt(v, 10)), expires: expires})
+	return v, nil
+}
+
+func (m *memoryCache) CompareAndSwap(_ context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	expires := m.expiresFor(ttl)
+	shard := m.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	k, exists := shard.items[key]
+	if !exists || shard.isExpired(k) {
+		return service.ErrKeyNotFound
+	}
+	if !bytes.Equal(k.value, old) {
+		return service.ErrKeyValueMismatch
+	}
+
+	shard.compaction()
+	shard.put(key, item{value: value, expires: expires})
+	return nil
+}
+
+func (m *memoryCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	for _, shard := range m.shards {
+		// Collect the keys first so that fn is free to access the cache.
+		var keys []string
+		shard.RLock()
+		for k, v := range shard.items {
+			if strings.HasPrefix(k, prefix) && !shard.isExpired(v) {
+				keys = append(keys, k)
+			}
+		}
+		shard.RUnlock()
+
+		for _, k := range keys {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryCache) Close(ctx context.Context) error {
+	if m.closeChan != nil {
+		close(m.closeChan)
+		select {
+		case <-m.loopDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if m.snapshotPath != "" {
+		return m.writeSnapshot()
+	}
 	return nil
 }
+
+//------------------------------------------------------------------------------
+
+type memCacheSnapshotItem struct {
+	Key     string `json:"key"`
+	Value   []byte `json:"value"`
+	Expires int64  `json:"expires,omitempty"`
+}
+
+func (m *memoryCache) snapshotLoop(interval time.Duration) {
+	defer close(m.loopDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.writeSnapshot(); err != nil {
+				m.log.Errorf("Failed to write cache snapshot '%v': %v", m.snapshotPath, err)
+			}
+		case <-m.closeChan:
+			return
+		}
+	}
+}
+
+// writeSnapshot writes each unexpired item of the cache to the snapshot file
+// as a line delimited JSON document. Shards are copied individually whilst
+// locked and then written without holding any locks, and therefore the
+// snapshot is not guaranteed to be a consistent view of the entire cache. The
+// snapshot is written to a temporary file that replaces the previous snapshot
+// once complete.
+func (m *memoryCache) writeSnapshot() error {
+	return ifs.WriteFileAtomic(m.fs, m.snapshotPath, 0o644, func(fw io.Writer) error {
+		w := bufio.NewWriter(fw)
+		enc := json.NewEncoder(w)
+
+		var sItems []memCacheSnapshotItem
+		for _, shard := range m.shards {
+			sItems = sItems[:0]
+			shard.RLock()
+			for k, v := range shard.items {
+				if shard.isExpired(v) {
+					continue
+				}
+				sItem := memCacheSnapshotItem{Key: k, Value: v.value}
+				if !v.expires.IsZero() {
+					sItem.Expires = v.expires.UnixNano()
+				}
+				sItems = append(sItems, sItem)
+			}
+			shard.RUnlock()
+
+			for _, sItem := range sItems {
+				if err := enc.Encode(sItem); err != nil {
+					return err
+				}
+			}
+		}
+		return w.Flush()
+	})
+}
+
+// loadSnapshot restores the contents of the cache from a snapshot file, items
+// that are successfully read before any error is encountered are kept. A
+// snapshot that does not exist is not considered an error.
+func (m *memoryCache) loadSnapshot() error {
+	f, err := m.fs.Open(m.snapshotPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var sItem memCacheSnapshotItem
+		if err := dec.Decode(&sItem); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		i := item{value: sItem.Value}
+		if sItem.Expires != 0 {
+			i.expires = time.Unix(0, sItem.Expires)
+		}
+
+		// Expiry follows the same rules as reads, and therefore items are
+		// never expired when compaction is disabled.
+		shard := m.getShard(sItem.Key)
+		if shard.isExpired(i) {
+			continue
+		}
+		shard.Lock()
+		shard.put(sItem.Key, i)
+		shard.Unlock()
+	}
+}
@@ -0,0 +1,165 @@
+package pure
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+// evictionTracker tracks the usage of keys within a memory cache shard in
+// order to select candidates for eviction once the shard exceeds its bounds.
+type evictionTracker interface {
+	// touch records that a key has been accessed or written, adding it to the
+	// tracker if it is not already present.
+	touch(key string)
+
+	// remove a key from the tracker.
+	remove(key string)
+
+	// victim returns the next key to be evicted other than the key protect, or
+	// false if there are no other keys.
+	victim(protect string) (string, bool)
+}
+
+func newEvictionTracker(policy string) evictionTracker {
+	if policy == "lfu" {
+		return newLFUTracker()
+	}
+	return newLRUTracker()
+}
+
+//------------------------------------------------------------------------------
+
+// lruTracker evicts the least recently used key.
+type lruTracker struct {
+	order *list.List
+	elems map[string]*list.Element
+}
+
+func newLRUTracker() *lruTracker {
+	return &lruTracker{
+		order: list.New(),
+		elems: map[string]*list.Element{},
+	}
+}
+
+func (l *lruTracker) touch(key string) {
+	if e, exists := l.elems[key]; exists {
+		l.order.MoveToFront(e)
+		return
+	}
+	l.elems[key] = l.order.PushFront(key)
+}
+
+func (l *lruTracker) remove(key string) {
+	if e, exists := l.elems[key]; exists {
+		l.order.Remove(e)
+		delete(l.elems, key)
+	}
+}
+
+func (l *lruTracker) victim(protect string) (string, bool) {
+	e := l.order.Back()
+	if e != nil && e.Value.(string) == protect {
+		e = e.Prev()
+	}
+	if e == nil {
+		return "", false
+	}
+	return e.Value.(string), true
+}
+
+//------------------------------------------------------------------------------
+
+type lfuEntry struct {
+	key   string
+	freq  uint64
+	seq   uint64
+	index int
+}
+
+// lfuHeap is a min-heap of entries ordered by access frequency, where ties are
+// broken by evicting the least recently used entry.
+type lfuHeap []*lfuEntry
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].freq == h[j].freq {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].freq < h[j].freq
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap) Push(x any) {
+	e := x.(*lfuEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+// lfuTracker evicts the least frequently used key.
+type lfuTracker struct {
+	entries lfuHeap
+	byKey   map[string]*lfuEntry
+	seq     uint64
+}
+
+func newLFUTracker() *lfuTracker {
+	return &lfuTracker{
+		byKey: map[string]*lfuEntry{},
+	}
+}
+
+func (l *lfuTracker) touch(key string) {
+	l.seq++
+	if e, exists := l.byKey[key]; exists {
+		e.freq++
+		e.seq = l.seq
+		heap.Fix(&l.entries, e.index)
+		return
+	}
+	e := &lfuEntry{key: key, freq: 1, seq: l.seq}
+	heap.Push(&l.entries, e)
+	l.byKey[key] = e
+}
+
+func (l *lfuTracker) remove(key string) {
+	if e, exists := l.byKey[key]; exists {
+		heap.Remove(&l.entries, e.index)
+		delete(l.byKey, key)
+	}
+}
+
+func (l *lfuTracker) victim(protect string) (string, bool) {
+	if len(l.entries) == 0 {
+		return "", false
+	}
+	if l.entries[0].key != protect {
+		return l.entries[0].key, true
+	}
+	// The next candidate is always one of the children of the root.
+	switch len(l.entries) {
+	case 1:
+		return "", false
+	case 2:
+		return l.entries[1].key, true
+	}
+	if l.entries.Less(2, 1) {
+		return l.entries[2].key, true
+	}
+	return l.entries[1].key, true
+}
@@ -3,6 +3,8 @@ package pure
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -16,7 +18,7 @@ func TestMemoryCache(t *testing.T) {
 	defConf, err := memCacheConfig().ParseYAML(``, nil)
 	require.NoError(t, err)
 
-	c, err := newMemCacheFromConfig(defConf)
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -80,7 +82,7 @@ compaction_interval: 1ns
 `, nil)
 	require.NoError(t, err)
 
-	c, err := newMemCacheFromConfig(defConf)
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -104,6 +106,36 @@ compaction_interval: 1ns
 	assert.Equal(t, service.ErrKeyNotFound, err)
 }
 
+func TestMemoryCacheZeroTTL(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(`
+default_ttl: 0s
+compaction_interval: 1ns
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	noExpiry := time.Duration(0)
+	require.NoError(t, c.Set(ctx, "foo", []byte("1"), &noExpiry))
+	require.NoError(t, c.Add(ctx, "bar", []byte("2"), &noExpiry))
+
+	<-time.After(time.Millisecond * 50)
+
+	// This should trigger compaction.
+	require.NoError(t, c.Set(ctx, "baz", []byte("3"), nil))
+
+	v, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(v))
+
+	v, err = c.Get(ctx, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(v))
+}
+
 func TestMemoryCacheInitValues(t *testing.T) {
 	defConf, err := memCacheConfig().ParseYAML(`
 default_ttl: 0s
@@ -114,7 +146,7 @@ init_values:
 `, nil)
 	require.NoError(t, err)
 
-	c, err := newMemCacheFromConfig(defConf)
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -153,7 +185,7 @@ compaction_interval: 1ns
 `, nil)
 	require.NoError(t, err)
 
-	c, err := newMemCacheFromConfig(defConf)
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -177,6 +209,229 @@ compaction_interval: 1ns
 
 //------------------------------------------------------------------------------
 
+func TestMemoryCacheAtomicOps(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(`
+shards: 4
+init_values:
+  counter: "10"
+  text: hello
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	v, err := c.Incr(ctx, "counter", 5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), v)
+
+	v, err = c.Incr(ctx, "new_counter", -3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-3), v)
+
+	_, err = c.Incr(ctx, "text", 1, nil)
+	require.Error(t, err)
+
+	assert.Equal(t, service.ErrKeyNotFound, c.CompareAndSwap(ctx, "nope", []byte("a"), []byte("b"), nil))
+	assert.Equal(t, service.ErrKeyValueMismatch, c.CompareAndSwap(ctx, "text", []byte("nope"), []byte("world"), nil))
+	require.NoError(t, c.CompareAndSwap(ctx, "text", []byte("hello"), []byte("world"), nil))
+
+	res, err := c.GetMulti(ctx, "counter", "text", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"counter": []byte("15"),
+		"text":    []byte("world"),
+	}, res)
+
+	var keys []string
+	require.NoError(t, c.IterKeys(ctx, "counter", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{"counter"}, keys)
+
+	keys = nil
+	require.NoError(t, c.IterKeys(ctx, "", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{"counter", "new_counter", "text"}, keys)
+}
+
+func TestMemoryCacheBoundsLRU(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(`
+max_items: 3
+eviction_policy: lru
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, k := range []string{"a", "b", "c"} {
+		require.NoError(t, c.Set(ctx, k, []byte(k), nil))
+	}
+
+	// Access a so that b becomes the least recently used.
+	_, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, "d", []byte("d"), nil))
+
+	_, err = c.Get(ctx, "b")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+	for _, k := range []string{"a", "c", "d"} {
+		_, err = c.Get(ctx, k)
+		assert.NoError(t, err, k)
+	}
+}
+
+func TestMemoryCacheBoundsLFU(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(`
+max_items: 3
+eviction_policy: lfu
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, k := range []string{"a", "b", "c"} {
+		require.NoError(t, c.Set(ctx, k, []byte(k), nil))
+	}
+
+	// Access a and b more frequently than c, where c is the most recent.
+	for i := 0; i < 3; i++ {
+		_, err = c.Get(ctx, "a")
+		require.NoError(t, err)
+		_, err = c.Get(ctx, "b")
+		require.NoError(t, err)
+	}
+	_, err = c.Get(ctx, "c")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, "d", []byte("d"), nil))
+
+	_, err = c.Get(ctx, "c")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+	for _, k := range []string{"a", "b", "d"} {
+		_, err = c.Get(ctx, k)
+		assert.NoError(t, err, k)
+	}
+}
+
+func TestMemoryCacheBoundsBytes(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(`
+max_bytes: 20
+init_values:
+  foo: hello world
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// foo (3 + 11 bytes) plus bar (3 + 5 bytes) exceeds the limit.
+	require.NoError(t, c.Set(ctx, "bar", []byte("hello"), nil))
+
+	_, err = c.Get(ctx, "foo")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+
+	act, err := c.Get(ctx, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(act))
+
+	require.NoError(t, c.Delete(ctx, "bar"))
+	require.NoError(t, c.Set(ctx, "foo", []byte("hello world"), nil))
+
+	act, err = c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(act))
+}
+
+func TestMemoryCacheSnapshot(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	defConf, err := memCacheConfig().ParseYAML(fmt.Sprintf(`
+shards: 3
+snapshot_path: %v
+init_values:
+  foo: init
+`, snapshotPath), nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "foo", []byte("foo value"), nil))
+	require.NoError(t, c.Set(ctx, "bar", []byte("bar value"), nil))
+	shortTTL := time.Millisecond
+	require.NoError(t, c.Set(ctx, "baz", []byte("baz value"), &shortTTL))
+	require.NoError(t, c.Close(ctx))
+
+	// The snapshot is written to a temporary file that replaces the previous
+	// snapshot once complete.
+	_, err = os.Stat(snapshotPath + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	<-time.After(time.Millisecond * 5)
+
+	c, err = newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	act, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo value", string(act))
+
+	act, err = c.Get(ctx, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "bar value", string(act))
+
+	_, err = c.Get(ctx, "baz")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+
+	require.NoError(t, c.Close(ctx))
+}
+
+func TestMemoryCacheSnapshotExpiryDisabled(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	defConf, err := memCacheConfig().ParseYAML(fmt.Sprintf(`
+compaction_interval: ""
+snapshot_path: %v
+`, snapshotPath), nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// Items are stamped with a TTL even though expiry is disabled.
+	shortTTL := time.Millisecond
+	require.NoError(t, c.Set(ctx, "foo", []byte("foo value"), &shortTTL))
+	require.NoError(t, c.Close(ctx))
+
+	<-time.After(time.Millisecond * 5)
+
+	c, err = newMemCacheFromConfig(defConf, service.MockResources())
+	require.NoError(t, err)
+
+	act, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo value", string(act))
+
+	require.NoError(t, c.Close(ctx))
+}
+
 func BenchmarkMemoryShards1(b *testing.B) {
 	defConf, err := memCacheConfig().ParseYAML(`
 default_ttl: 0s
@@ -184,7 +439,7 @@ compaction_interval: ""
 `, nil)
 	require.NoError(b, err)
 
-	c, err := newMemCacheFromConfig(defConf)
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
 	require.NoError(b, err)
 
 	ctx := context.Background()
@@ -210,7 +465,7 @@ shards: 10
 `, nil)
 	require.NoError(b, err)
 
-	c, err := newMemCacheFromConfig(defConf)
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
 	require.NoError(b, err)
 
 	ctx := context.Background()
@@ -236,7 +491,7 @@ shards: 10
 `, nil)
 	require.NoError(b, err)
 
-	c, err := newMemCacheFromConfig(defConf)
+	c, err := newMemCacheFromConfig(defConf, service.MockResources())
 	require.NoError(b, err)
 
 	ctx := context.Background()
@@ -3,6 +3,7 @@ package pure
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/public/service"
@@ -66,6 +67,40 @@ type cacheProvider interface {
 	AccessCache(ctx context.Context, name string, fn func(c service.Cache)) error
 }
 
+type multiGetCache interface {
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+}
+
+type incrCache interface {
+	Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+type casCache interface {
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error
+}
+
+type keyIterCache interface {
+	IterKeys(ctx context.Context, prefix string, fn func(key string) error) error
+}
+
+func getMultiFromCache(ctx context.Context, c service.Cache, keys []string) (map[string][]byte, error) {
+	if mc, ok := c.(multiGetCache); ok {
+		return mc.GetMulti(ctx, keys...)
+	}
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		v, err := c.Get(ctx, k)
+		if err != nil {
+			if err == service.ErrKeyNotFound {
+				continue
+			}
+			return nil, err
+		}
+		res[k] = v
+	}
+	return res, nil
+}
+
 type multilevelCache struct {
 	mgr    cacheProvider
 	log    *service.Logger
@@ -196,6 +231,108 @@ func (l *multilevelCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (l *multilevelCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	remaining := keys
+	for i, name := range l.caches {
+		if len(remaining) == 0 {
+			break
+		}
+		var found map[string][]byte
+		var err error
+		if cerr := l.mgr.AccessCache(ctx, name, func(c service.Cache) {
+			found, err = getMultiFromCache(ctx, c, remaining)
+		}); cerr != nil {
+			return nil, fmt.Errorf("unable to access cache '%v': %v", name, cerr)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		nextRemaining := make([]string, 0, len(remaining)-len(found))
+		for _, k := range remaining {
+			v, exists := found[k]
+			if !exists {
+				nextRemaining = append(nextRemaining, k)
+				continue
+			}
+			l.setUpToLevelPassive(ctx, i, k, v)
+			res[k] = v
+		}
+		remaining = nextRemaining
+	}
+	return res, nil
+}
+
+// Incr is performed atomically against the final level, which is considered
+// the source of truth, and the result is then written to all prior levels.
+func (l *multilevelCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	last := len(l.caches) - 1
+
+	var v int64
+	var err error
+	if cerr := l.mgr.AccessCache(ctx, l.caches[last], func(c service.Cache) {
+		ic, ok := c.(incrCache)
+		if !ok {
+			err = service.ErrCacheOpNotSupported
+			return
+		}
+		v, err = ic.Incr(ctx, key, delta, ttl)
+	}); cerr != nil {
+		return 0, fmt.Errorf("unable to access cache '%v': %v", l.caches[last], cerr)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	l.setUpToLevelPassive(ctx, last, key, []byte(strconv.FormatInt(v, 10)))
+	return v, nil
+}
+
+// CompareAndSwap is performed atomically against the final level, which is
+// considered the source of truth, and on success the new value is then written
+// to all prior levels.
+func (l *multilevelCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	last := len(l.caches) - 1
+
+	var err error
+	if cerr := l.mgr.AccessCache(ctx, l.caches[last], func(c service.Cache) {
+		cc, ok := c.(casCache)
+		if !ok {
+			err = service.ErrCacheOpNotSupported
+			return
+		}
+		err = cc.CompareAndSwap(ctx, key, old, value, ttl)
+	}); cerr != nil {
+		return fmt.Errorf("unable to access cache '%v': %v", l.caches[last], cerr)
+	}
+	if err != nil {
+		return err
+	}
+
+	l.setUpToLevelPassive(ctx, last, key, value)
+	return nil
+}
+
+// IterKeys walks the keys of the final level, which is considered the source
+// of truth.
+func (l *multilevelCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	last := len(l.caches) - 1
+
+	var err error
+	if cerr := l.mgr.AccessCache(ctx, l.caches[last], func(c service.Cache) {
+		kc, ok := c.(keyIterCache)
+		if !ok {
+			err = service.ErrCacheOpNotSupported
+			return
+		}
+		err = kc.IterKeys(ctx, prefix, fn)
+	}); cerr != nil {
+		return fmt.Errorf("unable to access cache '%v': %v", l.caches[last], cerr)
+	}
+	return err
+}
+
 func (l *multilevelCache) Close(ctx context.Context) error {
 	return nil
 }
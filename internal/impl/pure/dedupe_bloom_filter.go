@@ -0,0 +1,344 @@
+package pure
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/OneOfOne/xxhash"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+// bloomFilter is a fixed size Bloom filter using double hashing in order to
+// derive k bit positions from two xxhash digests.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// bloomFilterParams calculates the optimal number of bits (m) and hash
+// functions (k) for a filter expected to hold capacity items with a given
+// false positive rate.
+func bloomFilterParams(capacity int, fpRate float64) (m, k uint64) {
+	n := float64(capacity)
+	mf := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	m = uint64(mf)
+	if m < 64 {
+		m = 64
+	}
+	k = uint64(math.Round(mf / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return
+}
+
+func newBloomFilter(m, k uint64) *bloomFilter {
+	return &bloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+func (b *bloomFilter) positions(key []byte, fn func(word uint64, mask uint64) bool) bool {
+	h1 := xxhash.Checksum64S(key, 0)
+	h2 := xxhash.Checksum64S(key, 1) | 1
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		if !fn(pos/64, 1<<(pos%64)) {
+			return false
+		}
+	}
+	return true
+}
+
+// test returns true if the key is possibly within the filter.
+func (b *bloomFilter) test(key []byte) bool {
+	return b.positions(key, func(word, mask uint64) bool {
+		return b.bits[word]&mask != 0
+	})
+}
+
+// add a key to the filter, returns true if the key was possibly already
+// present.
+func (b *bloomFilter) add(key []byte) bool {
+	present := true
+	b.positions(key, func(word, mask uint64) bool {
+		if b.bits[word]&mask == 0 {
+			present = false
+			b.bits[word] |= mask
+		}
+		return true
+	})
+	return present
+}
+
+//------------------------------------------------------------------------------
+
+const dedupeFilterSnapshotVersion = uint32(1)
+
+var dedupeFilterSnapshotMagic = [4]byte{'B', 'D', 'F', 'S'}
+
+// dedupeFilter is a rotating pair of Bloom filters. Keys are added to the
+// current generation and tested against both the current and previous
+// generations. When a rotation interval is configured the previous generation
+// is discarded and the current generation takes its place each interval,
+// meaning keys are remembered for at least one interval and at most two.
+type dedupeFilter struct {
+	m, k uint64
+
+	rotateInterval time.Duration
+	rotatedAt      time.Time
+	current        *bloomFilter
+	previous       *bloomFilter
+
+	fs               ifs.FS
+	snapshotPath     string
+	snapshotInterval time.Duration
+	dirty            bool
+
+	closeChan chan struct{}
+	loopDone  chan struct{}
+
+	mut      sync.Mutex
+	writeMut sync.Mutex
+}
+
+func newDedupeFilter(
+	capacity int,
+	fpRate float64,
+	rotateInterval time.Duration,
+	fsys ifs.FS,
+	snapshotPath string,
+	snapshotInterval time.Duration,
+) (*dedupeFilter, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("capacity must be greater than zero, got %v", capacity)
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		return nil, fmt.Errorf("false positive rate must be between 0 and 1, got %v", fpRate)
+	}
+	m, k := bloomFilterParams(capacity, fpRate)
+	f := &dedupeFilter{
+		m: m, k: k,
+		rotateInterval:   rotateInterval,
+		rotatedAt:        time.Now(),
+		current:          newBloomFilter(m, k),
+		fs:               fsys,
+		snapshotPath:     snapshotPath,
+		snapshotInterval: snapshotInterval,
+	}
+	return f, nil
+}
+
+func (f *dedupeFilter) rotate(now time.Time) {
+	if f.rotateInterval <= 0 {
+		return
+	}
+	elapsed := now.Sub(f.rotatedAt)
+	if elapsed < f.rotateInterval {
+		return
+	}
+	if elapsed >= 2*f.rotateInterval {
+		f.previous = nil
+	} else {
+		f.previous = f.current
+	}
+	f.current = newBloomFilter(f.m, f.k)
+	f.rotatedAt = now
+	f.dirty = true
+}
+
+// addIfAbsent adds a key to the filter and returns false if the key was
+// (probably) already present.
+func (f *dedupeFilter) addIfAbsent(key []byte) bool {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	f.rotate(time.Now())
+	if f.previous != nil && f.previous.test(key) {
+		return false
+	}
+	if f.current.add(key) {
+		return false
+	}
+	f.dirty = true
+	return true
+}
+
+// snapshotLoop periodically writes a snapshot of the filter to disk until
+// closed.
+func (f *dedupeFilter) snapshotLoop(log log.Modular) {
+	defer close(f.loopDone)
+
+	ticker := time.NewTicker(f.snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := f.snapshot(); err != nil {
+				log.Errorf("Failed to write bloom filter snapshot: %v\n", err)
+			}
+		case <-f.closeChan:
+			return
+		}
+	}
+}
+
+// startSnapshots begins writing snapshots of the filter in the background at
+// the snapshot interval, if a snapshot path and interval are configured.
+func (f *dedupeFilter) startSnapshots(log log.Modular) {
+	if f.snapshotPath == "" || f.snapshotInterval <= 0 {
+		return
+	}
+	f.closeChan = make(chan struct{})
+	f.loopDone = make(chan struct{})
+	go f.snapshotLoop(log)
+}
+
+// close stops any background snapshots and writes a final snapshot of the
+// filter.
+func (f *dedupeFilter) close() error {
+	if f.closeChan != nil {
+		close(f.closeChan)
+		<-f.loopDone
+		f.closeChan = nil
+	}
+	return f.snapshot()
+}
+
+// snapshot writes a snapshot of the filter to disk if it has changed since the
+// last snapshot. The filter is only locked for long enough to copy its state,
+// which is then serialised and written without blocking deduplication.
+func (f *dedupeFilter) snapshot() error {
+	if f.snapshotPath == "" {
+		return nil
+	}
+
+	f.writeMut.Lock()
+	defer f.writeMut.Unlock()
+
+	f.mut.Lock()
+	if !f.dirty {
+		f.mut.Unlock()
+		return nil
+	}
+	rotatedAt := f.rotatedAt
+	current := append([]uint64(nil), f.current.bits...)
+	var previous []uint64
+	if f.previous != nil {
+		previous = append([]uint64(nil), f.previous.bits...)
+	}
+	f.dirty = false
+	f.mut.Unlock()
+
+	if err := f.writeSnapshot(rotatedAt, current, previous); err != nil {
+		f.mut.Lock()
+		f.dirty = true
+		f.mut.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (f *dedupeFilter) writeSnapshot(rotatedAt time.Time, current, previous []uint64) error {
+	return ifs.WriteFileAtomic(f.fs, f.snapshotPath, 0o644, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		_, _ = bw.Write(dedupeFilterSnapshotMagic[:])
+
+		hasPrevious := uint8(0)
+		if previous != nil {
+			hasPrevious = 1
+		}
+		for _, v := range []any{
+			dedupeFilterSnapshotVersion,
+			f.m, f.k,
+			rotatedAt.UnixNano(),
+			hasPrevious,
+			current,
+		} {
+			if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+				return err
+			}
+		}
+		if previous != nil {
+			if err := binary.Write(bw, binary.LittleEndian, previous); err != nil {
+				return err
+			}
+		}
+		return bw.Flush()
+	})
+}
+
+var errDedupeFilterSnapshotMismatch = errors.New("snapshot parameters do not match the configured capacity and false positive rate")
+
+// loadSnapshot attempts to restore the state of the filter from a snapshot
+// previously written to disk. A snapshot that does not exist is not considered
+// an error.
+func (f *dedupeFilter) loadSnapshot() error {
+	if f.snapshotPath == "" {
+		return nil
+	}
+
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	data, err := ifs.ReadFile(f.fs, f.snapshotPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	r := bytes.NewReader(data)
+
+	var magic [4]byte
+	var version uint32
+	var m, k uint64
+	var rotatedAt int64
+	var hasPrevious uint8
+	for _, v := range []any{&magic, &version, &m, &k, &rotatedAt, &hasPrevious} {
+		if err := binary.Read(r, binary.LittleEndian, v); err != nil {
+			return fmt.Errorf("malformed snapshot: %w", err)
+		}
+	}
+	if magic != dedupeFilterSnapshotMagic {
+		return errors.New("malformed snapshot: unrecognised header")
+	}
+	if version != dedupeFilterSnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version: %v", version)
+	}
+	if m != f.m || k != f.k {
+		return errDedupeFilterSnapshotMismatch
+	}
+
+	current := newBloomFilter(m, k)
+	if err := binary.Read(r, binary.LittleEndian, current.bits); err != nil {
+		return fmt.Errorf("malformed snapshot: %w", err)
+	}
+	var previous *bloomFilter
+	if hasPrevious == 1 {
+		previous = newBloomFilter(m, k)
+		if err := binary.Read(r, binary.LittleEndian, previous.bits); err != nil {
+			return fmt.Errorf("malformed snapshot: %w", err)
+		}
+	}
+
+	f.current, f.previous = current, previous
+	f.rotatedAt = time.Unix(0, rotatedAt)
+	f.rotate(time.Now())
+	return nil
+}
@@ -0,0 +1,47 @@
+package pure
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+)
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	m, k := bloomFilterParams(10000, 0.01)
+	f := newBloomFilter(m, k)
+
+	for i := 0; i < 10000; i++ {
+		f.add([]byte(fmt.Sprintf("key-%v", i)))
+	}
+	for i := 0; i < 10000; i++ {
+		require.True(t, f.test([]byte(fmt.Sprintf("key-%v", i))))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if f.test([]byte(fmt.Sprintf("other-%v", i))) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 200)
+}
+
+func TestDedupeFilterRotation(t *testing.T) {
+	f, err := newDedupeFilter(1000, 0.001, time.Millisecond*50, ifs.OS(), "", 0)
+	require.NoError(t, err)
+
+	assert.True(t, f.addIfAbsent([]byte("foo")))
+	assert.False(t, f.addIfAbsent([]byte("foo")))
+
+	<-time.After(time.Millisecond * 60)
+	assert.False(t, f.addIfAbsent([]byte("foo")), "key should be retained by the previous generation")
+	assert.True(t, f.addIfAbsent([]byte("bar")))
+
+	<-time.After(time.Millisecond * 120)
+	assert.True(t, f.addIfAbsent([]byte("foo")), "key should have been forgotten")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
@@ -36,7 +37,7 @@ For use cases where you wish to cache the result of processors consider using th
 This processor will interpolate functions within the ` + "`key` and `value`" + ` fields individually for each message. This allows you to specify dynamic keys and values based on the contents of the message payloads and metadata. You can find a list of functions [here](/docs/configuration/interpolation#bloblang-queries).`,
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldString("resource", "The [`cache` resource](/docs/components/caches/about) to target with this processor."),
-			docs.FieldString("operator", "The [operation](#operators) to perform with the cache.").HasOptions("set", "add", "get", "delete"),
+			docs.FieldString("operator", "The [operation](#operators) to perform with the cache.").HasOptions("set", "add", "get", "delete", "incr", "get_multi"),
 			docs.FieldString("key", "A key to use with the cache.").IsInterpolated(),
 			docs.FieldString("value", "A value to use with the cache (when applicable).").IsInterpolated(),
 			docs.FieldString(
@@ -143,7 +144,23 @@ can be detected with [processor error handling](/docs/configuration/error_handli
 ### ` + "`delete`" + `
 
 Delete a key and its contents from the cache.  If the key does not exist the
-action is a no-op and will not fail with an error.`,
+action is a no-op and will not fail with an error.
+
+### ` + "`incr`" + `
+
+Atomically increment the integer value of a key by the amount specified in the
+` + "`value`" + ` field (defaulting to 1 when empty), and replace the original
+message payload with the result. A key that does not exist is treated as zero.
+Caches that are unable to perform this operation atomically will fail with an
+error.
+
+### ` + "`get_multi`" + `
+
+Retrieve the contents of the cached keys of all messages of a batch in as few
+requests as the cache allows, and replace the payload of each message with its
+result. If the key of a message does not exist then that message fails with an
+error, which can be detected with
+[processor error handling](/docs/configuration/error_handling).`,
 	})
 	if err != nil {
 		panic(err)
@@ -160,6 +177,7 @@ type cacheProc struct {
 	mgr       bundle.NewManagement
 	cacheName string
 	operator  cacheOperator
+	getMulti  bool
 }
 
 func newCache(conf processor.CacheConfig, mgr bundle.NewManagement) (*cacheProc, error) {
@@ -168,9 +186,13 @@ func newCache(conf processor.CacheConfig, mgr bundle.NewManagement) (*cacheProc,
 		return nil, errors.New("cache name must be specified")
 	}
 
-	op, err := cacheOperatorFromString(conf.Operator)
-	if err != nil {
-		return nil, err
+	var op cacheOperator
+	getMulti := conf.Operator == "get_multi"
+	if !getMulti {
+		var err error
+		if op, err = cacheOperatorFromString(conf.Operator); err != nil {
+			return nil, err
+		}
 	}
 
 	key, err := mgr.BloblEnvironment().NewField(conf.Key)
@@ -200,6 +222,7 @@ func newCache(conf processor.CacheConfig, mgr bundle.NewManagement) (*cacheProc,
 		mgr:       mgr,
 		cacheName: cacheName,
 		operator:  op,
+		getMulti:  getMulti,
 	}, nil
 }
 
@@ -235,6 +258,23 @@ func newCacheDeleteOperator() cacheOperator {
 	}
 }
 
+func newCacheIncrOperator() cacheOperator {
+	return func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+		delta := int64(1)
+		if len(value) > 0 {
+			var err error
+			if delta, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return nil, false, fmt.Errorf("value must be an integer: %w", err)
+			}
+		}
+		result, err := cache.Incr(ctx, key, delta, ttl)
+		if err != nil {
+			return nil, false, err
+		}
+		return []byte(strconv.FormatInt(result, 10)), true, nil
+	}
+}
+
 func cacheOperatorFromString(operator string) (cacheOperator, error) {
 	switch operator {
 	case "set":
@@ -245,13 +285,69 @@ func cacheOperatorFromString(operator string) (cacheOperator, error) {
 		return newCacheGetOperator(), nil
 	case "delete":
 		return newCacheDeleteOperator(), nil
+	case "incr":
+		return newCacheIncrOperator(), nil
 	}
 	return nil, fmt.Errorf("operator not recognised: %v", operator)
 }
 
 //------------------------------------------------------------------------------
 
+func (c *cacheProc) processGetMulti(spans []*tracing.Span, msg message.Batch) {
+	keys := make([]string, msg.Len())
+	validKeys := make([]string, 0, msg.Len())
+	invalid := make([]bool, msg.Len())
+	_ = msg.Iter(func(index int, part *message.Part) error {
+		key, err := c.key.String(index, msg)
+		if err != nil {
+			err = fmt.Errorf("key interpolation error: %w", err)
+			c.mgr.Logger().Debugf(err.Error())
+			processor.MarkErr(part, spans[index], err)
+			invalid[index] = true
+			return nil
+		}
+		keys[index] = key
+		validKeys = append(validKeys, key)
+		return nil
+	})
+	if len(validKeys) == 0 {
+		return
+	}
+
+	var results map[string][]byte
+	var err error
+	if cerr := c.mgr.AccessCache(context.Background(), c.cacheName, func(cache cache.V1) {
+		results, err = cache.GetMulti(context.Background(), validKeys...)
+	}); cerr != nil {
+		err = cerr
+	}
+
+	_ = msg.Iter(func(index int, part *message.Part) error {
+		if invalid[index] {
+			return nil
+		}
+		if err != nil {
+			c.mgr.Logger().Debugf("Operator failed for key '%s': %v\n", keys[index], err)
+			processor.MarkErr(part, spans[index], err)
+			return nil
+		}
+		result, exists := results[keys[index]]
+		if !exists {
+			c.mgr.Logger().Debugf("Operator failed for key '%s': %v\n", keys[index], component.ErrKeyNotFound)
+			processor.MarkErr(part, spans[index], component.ErrKeyNotFound)
+			return nil
+		}
+		part.SetBytes(result)
+		return nil
+	})
+}
+
 func (c *cacheProc) ProcessBatch(ctx context.Context, spans []*tracing.Span, msg message.Batch) ([]message.Batch, error) {
+	if c.getMulti {
+		c.processGetMulti(spans, msg)
+		return []message.Batch{msg}, nil
+	}
+
 	_ = msg.Iter(func(index int, part *message.Part) error {
 		key, err := c.key.String(index, msg)
 		if err != nil {
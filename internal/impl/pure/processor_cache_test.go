@@ -202,3 +202,82 @@ func TestCacheDelete(t *testing.T) {
 	_, ok = mgr.Caches["foocache"]["3"]
 	require.False(t, ok)
 }
+
+func TestCacheIncr(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "10"},
+		"2": {Value: "not a number"},
+	}
+
+	conf := processor.NewConfig()
+	conf.Type = "cache"
+	conf.Cache.Key = "${!json(\"key\")}"
+	conf.Cache.Value = "${!json(\"delta\")}"
+	conf.Cache.Resource = "foocache"
+	conf.Cache.Operator = "incr"
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","delta":5}`),
+		[]byte(`{"key":"2","delta":1}`),
+		[]byte(`{"key":"3","delta":-2}`),
+		[]byte(`{"key":"1","delta":1}`),
+	})
+	expParts := [][]byte{
+		[]byte(`15`),
+		[]byte(`{"key":"2","delta":1}`),
+		[]byte(`-2`),
+		[]byte(`16`),
+	}
+
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	assert.Equal(t, expParts, message.GetAllBytes(output[0]))
+
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.Error(t, output[0].Get(1).ErrorGet())
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+	assert.NoError(t, output[0].Get(3).ErrorGet())
+
+	assert.Equal(t, "16", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "-2", mgr.Caches["foocache"]["3"].Value)
+}
+
+func TestCacheGetMulti(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "foo 1"},
+		"2": {Value: "foo 2"},
+	}
+
+	conf := processor.NewConfig()
+	conf.Type = "cache"
+	conf.Cache.Key = "${!json(\"key\")}"
+	conf.Cache.Resource = "foocache"
+	conf.Cache.Operator = "get_multi"
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1"}`),
+		[]byte(`{"key":"3"}`),
+		[]byte(`{"key":"2"}`),
+	})
+	expParts := [][]byte{
+		[]byte(`foo 1`),
+		[]byte(`{"key":"3"}`),
+		[]byte(`foo 2`),
+	}
+
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	assert.Equal(t, expParts, message.GetAllBytes(output[0]))
+
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.Error(t, output[0].Get(1).ErrorGet())
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+}
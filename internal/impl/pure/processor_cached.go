@@ -6,10 +6,13 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 
+	"github.com/benthosdev/benthos/v4/public/bloblang"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
 
@@ -18,7 +21,19 @@ func newCachedProcessorConfigSpec() *service.ConfigSpec {
 		Version("4.3.0").
 		Categories("Utility").
 		Summary("Cache the result of applying one or more processors to messages identified by a key. If the key already exists within the cache the contents of the message will be replaced with the cached result instead of applying the processors. This component is therefore useful in situations where an expensive set of processors need only be executed periodically.").
-		Description("The format of the data when stored within the cache is a custom and versioned schema chosen to balance performance and storage space. It is therefore not possible to point this processor to a cache that is pre-populated with data that this processor has not created itself.").
+		Description(`The format of the data when stored within the cache is a custom and versioned schema chosen to balance performance and storage space. It is therefore not possible to point this processor to a cache that is pre-populated with data that this processor has not created itself.
+
+### Stale While Revalidate
+
+When the field `+"`stale_after`"+` is set a cached result that is older than this period is still used, but the processors are also executed asynchronously in order to refresh the cached result for subsequent messages. In this case the `+"`ttl`"+` should be set higher than `+"`stale_after`"+`, as it determines how long a stale result remains usable.
+
+### Error Caching
+
+Errors flagged on messages by the child processors are cached along with the result and restored when the cached result is used. The field `+"`error_ttl`"+` can be used in order to cache results containing errors for a different period than successful results.
+
+### Request Coalescing
+
+When `+"`coalesce`"+` is enabled concurrent messages that miss the cache with the same key will result in the processors being executed only once, with the remaining messages waiting for and then using that result as if it were cached.`).
 		Field(service.NewStringField("cache").Description("The cache resource to read and write processor results from.")).
 		Field(service.NewInterpolatedStringField("key").
 			Description("A key to be resolved for each message, if the key already exists in the cache then the cached result is used, otherwise the processors are applied and the result is cached under this key. The key could be static and therefore apply generally to all messages or it could be an interpolated expression that is potentially unique for each message.").
@@ -27,6 +42,10 @@ func newCachedProcessorConfigSpec() *service.ConfigSpec {
 			Example(`${! meta("kafka_key") }`).
 			Example(`${! meta("kafka_topic") }`)).
 		Field(service.NewDurationField("ttl").Description("An optional expiry period to set for each cache entry. Some caches only have a general TTL and will therefore ignore this setting.").Optional()).
+		Field(service.NewDurationField("error_ttl").Description("An optional expiry period to set for cache entries where the result contains messages flagged with errors. When not set the `ttl` is used instead.").Optional().Advanced()).
+		Field(service.NewDurationField("stale_after").Description("An optional period after which a cached result is considered stale, at which point it is still used but the processors are executed asynchronously in order to refresh it.").Optional().Advanced()).
+		Field(service.NewBoolField("coalesce").Description("Whether concurrent cache misses for the same key should share a single execution of the processors.").Default(false).Advanced()).
+		Field(service.NewBloblangField("skip_on").Description("An optional [Bloblang query](/docs/guides/bloblang/about) that should return a boolean value indicating whether a message should bypass the cache, in which case the processors are applied to it directly and the result is not cached.").Example(`meta("nocache") == "true"`).Optional().Advanced()).
 		Field(service.NewProcessorListField("processors").Description("The list of processors whose result will be cached.")).
 		Example(
 			"Cached Enrichment",
@@ -96,13 +115,28 @@ type cachedProcessor struct {
 	cacheName  string
 	key        *service.InterpolatedString
 	ttl        *time.Duration
+	errorTTL   *time.Duration
+	staleAfter time.Duration
+	skipOn     *bloblang.Executor
 	processors []*service.OwnedProcessor
+
+	coalesce bool
+	flight   singleflight.Group
+
+	refreshCtx    context.Context
+	refreshDone   func()
+	refreshMut    sync.Mutex
+	refreshing    map[string]struct{}
+	refreshWG     sync.WaitGroup
+	refreshClosed bool
 }
 
 func newCachedProcessorFromParsedConf(manager *service.Resources, conf *service.ParsedConfig) (proc *cachedProcessor, err error) {
 	proc = &cachedProcessor{
-		manager: manager,
+		manager:    manager,
+		refreshing: map[string]struct{}{},
 	}
+	proc.refreshCtx, proc.refreshDone = context.WithCancel(context.Background())
 
 	if proc.cacheName, err = conf.FieldString("cache"); err != nil {
 		return nil, err
@@ -124,15 +158,69 @@ func newCachedProcessorFromParsedConf(manager *service.Resources, conf *service.
 		proc.ttl = &ttl
 	}
 
+	if conf.Contains("error_ttl") {
+		var errorTTL time.Duration
+		if errorTTL, err = conf.FieldDuration("error_ttl"); err != nil {
+			return nil, err
+		}
+
+		proc.errorTTL = &errorTTL
+	}
+
+	if conf.Contains("stale_after") {
+		if proc.staleAfter, err = conf.FieldDuration("stale_after"); err != nil {
+			return nil, err
+		}
+	}
+
+	if proc.coalesce, err = conf.FieldBool("coalesce"); err != nil {
+		return nil, err
+	}
+
+	if conf.Contains("skip_on") {
+		if proc.skipOn, err = conf.FieldBloblang("skip_on"); err != nil {
+			return nil, err
+		}
+	}
+
 	proc.processors, err = conf.FieldProcessorList("processors")
 	return
 }
 
+func (proc *cachedProcessor) shouldSkip(msg *service.Message) (bool, error) {
+	if proc.skipOn == nil {
+		return false, nil
+	}
+	res, err := msg.BloblangQuery(proc.skipOn)
+	if err != nil {
+		return false, fmt.Errorf("skip_on query failed: %w", err)
+	}
+	if res == nil {
+		return false, nil
+	}
+	v, err := res.AsStructured()
+	if err != nil {
+		return false, fmt.Errorf("skip_on query failed: %w", err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("skip_on query resulted in a non-boolean value: %T", v)
+	}
+	return b, nil
+}
+
 func (proc *cachedProcessor) Process(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
+	skip, err := proc.shouldSkip(msg)
+	if err != nil {
+		return nil, err
+	}
+	if skip {
+		return proc.executeProcessors(ctx, msg)
+	}
+
 	cacheKey := proc.key.String(msg)
 
 	var cachedBytes []byte
-	var err error
 	if cerr := proc.manager.AccessCache(ctx, proc.cacheName, func(cache service.Cache) {
 		cachedBytes, err = cache.Get(ctx, cacheKey)
 	}); cerr != nil {
@@ -141,11 +229,15 @@ func (proc *cachedProcessor) Process(ctx context.Context, msg *service.Message)
 
 	// Return early if we have a cached result
 	if err == nil {
-		batch, err := cachedProcResultToBatch(msg, cachedBytes)
+		batch, createdAt, err := cachedProcResultToBatch(msg, cachedBytes)
 		if err != nil {
 			err = fmt.Errorf("failed to parsed cached result, this indicates the data was not set by this processor: %w", err)
+			return batch, err
 		}
-		return batch, err
+		if proc.staleAfter > 0 && !createdAt.IsZero() && time.Since(createdAt) >= proc.staleAfter {
+			proc.refreshAsync(msg.Copy(), cacheKey)
+		}
+		return batch, nil
 	}
 
 	// Or if an error occurred that wasn't ErrKeyNotFound
@@ -154,6 +246,14 @@ func (proc *cachedProcessor) Process(ctx context.Context, msg *service.Message)
 	}
 
 	// Result is not cached, so execute processors and cache the result
+	if !proc.coalesce {
+		batch, _, err := proc.executeAndCache(ctx, msg, cacheKey)
+		return batch, err
+	}
+	return proc.executeCoalesced(ctx, msg, cacheKey)
+}
+
+func (proc *cachedProcessor) executeProcessors(ctx context.Context, msg *service.Message) (service.MessageBatch, error) {
 	resultBatch, err := service.ExecuteProcessors(ctx, proc.processors, service.MessageBatch{msg})
 	if err != nil {
 		return nil, err
@@ -163,31 +263,138 @@ func (proc *cachedProcessor) Process(ctx context.Context, msg *service.Message)
 	for _, b := range resultBatch {
 		collapsedBatch = append(collapsedBatch, b...)
 	}
+	return collapsedBatch, nil
+}
+
+// executeAndCache applies the processors to a message and caches the result,
+// returning both the resulting batch and its serialised form, which is nil if
+// serialisation failed.
+func (proc *cachedProcessor) executeAndCache(ctx context.Context, msg *service.Message, cacheKey string) (service.MessageBatch, []byte, error) {
+	collapsedBatch, err := proc.executeProcessors(ctx, msg)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Any errors in creating a serialised batch or caching are non-fatal and
 	// should be logged but otherwise regarded as insignificant to the flowing
 	// messages.
-	result, err := cachedProcSerialiseBatch(collapsedBatch)
+	result, err := cachedProcSerialiseBatch(collapsedBatch, time.Now())
 	if err != nil {
-		proc.manager.Logger().Errorf("failed to serialise resulting batch for caching: %w", err)
-		return collapsedBatch, nil
+		proc.manager.Logger().Errorf("failed to serialise resulting batch for caching: %v", err)
+		return collapsedBatch, nil, nil
+	}
+
+	ttl := proc.ttl
+	if proc.errorTTL != nil {
+		for _, m := range collapsedBatch {
+			if m.GetError() != nil {
+				ttl = proc.errorTTL
+				break
+			}
+		}
 	}
 
 	var setErr error
 	cerr := proc.manager.AccessCache(ctx, proc.cacheName, func(cache service.Cache) {
-		setErr = cache.Set(ctx, cacheKey, result, proc.ttl)
+		setErr = cache.Set(ctx, cacheKey, result, ttl)
 	})
 	if cerr != nil {
-		proc.manager.Logger().Errorf("failed to access cache for result: %w", err)
+		proc.manager.Logger().Errorf("failed to access cache for result: %v", cerr)
 	}
 	if setErr != nil {
-		proc.manager.Logger().Errorf("failed to write result to cache: %w", err)
+		proc.manager.Logger().Errorf("failed to write result to cache: %v", setErr)
 	}
 
-	return collapsedBatch, nil
+	return collapsedBatch, result, nil
+}
+
+// executeCoalesced applies the processors to a message and caches the result,
+// but if an execution is already in flight for the same key then its result is
+// used instead. The shared execution is bound by the lifetime of the processor
+// rather than the context of whichever caller started it, so that cancelling
+// one caller does not fail the others, and each caller only stops waiting
+// for the result once its own context is cancelled.
+func (proc *cachedProcessor) executeCoalesced(ctx context.Context, msg *service.Message, cacheKey string) (service.MessageBatch, error) {
+	var leaderBatch service.MessageBatch
+	resChan := proc.flight.DoChan(cacheKey, func() (any, error) {
+		batch, result, err := proc.executeAndCache(proc.refreshCtx, msg, cacheKey)
+		leaderBatch = batch
+		return result, err
+	})
+
+	var res singleflight.Result
+	select {
+	case res = <-resChan:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	if leaderBatch != nil {
+		return leaderBatch, nil
+	}
+
+	result, _ := res.Val.([]byte)
+	if result == nil {
+		// The shared result could not be serialised, so we have no choice but
+		// to execute the processors ourselves.
+		return proc.executeProcessors(ctx, msg)
+	}
+	batch, _, err := cachedProcResultToBatch(msg, result)
+	return batch, err
+}
+
+// refreshAsync executes the processors on a message in the background in order
+// to refresh a stale cached result. Only one refresh is performed at a time for
+// any given key.
+func (proc *cachedProcessor) refreshAsync(msg *service.Message, cacheKey string) {
+	proc.refreshMut.Lock()
+	if _, exists := proc.refreshing[cacheKey]; exists || proc.refreshClosed {
+		proc.refreshMut.Unlock()
+		return
+	}
+	proc.refreshing[cacheKey] = struct{}{}
+	proc.refreshWG.Add(1)
+	proc.refreshMut.Unlock()
+
+	go func() {
+		defer func() {
+			proc.refreshMut.Lock()
+			delete(proc.refreshing, cacheKey)
+			proc.refreshMut.Unlock()
+			proc.refreshWG.Done()
+		}()
+
+		var err error
+		if proc.coalesce {
+			_, err = proc.executeCoalesced(proc.refreshCtx, msg, cacheKey)
+		} else {
+			_, _, err = proc.executeAndCache(proc.refreshCtx, msg, cacheKey)
+		}
+		if err != nil {
+			proc.manager.Logger().Errorf("failed to refresh stale cached result: %v", err)
+		}
+	}()
 }
 
 func (proc *cachedProcessor) Close(ctx context.Context) error {
+	proc.refreshMut.Lock()
+	proc.refreshClosed = true
+	proc.refreshMut.Unlock()
+
+	proc.refreshDone()
+	refreshesDone := make(chan struct{})
+	go func() {
+		proc.refreshWG.Wait()
+		close(refreshesDone)
+	}()
+	select {
+	case <-refreshesDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
 	var group errgroup.Group
 	for _, ownedProc := range proc.processors {
 		op := ownedProc
@@ -220,12 +427,33 @@ func cachedProcUint32ToBytes(n uint32) []byte {
 	return newBytes
 }
 
-func cachedProcSerialiseBatch(batch service.MessageBatch) ([]byte, error) {
+func cachedProcUint64ToBytes(n uint64) []byte {
+	newBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(newBytes, n)
+	return newBytes
+}
+
+func cachedProcExtractUint64(b []byte) (n uint64, remaining []byte, err error) {
+	if len(b) < 8 {
+		err = errors.New("message is too small to extract number")
+		return
+	}
+	n = binary.BigEndian.Uint64(b[:8])
+	remaining = b[8:]
+	return
+}
+
+func cachedProcSerialiseBatch(batch service.MessageBatch, createdAt time.Time) ([]byte, error) {
 	var buf bytes.Buffer
 
 	// Insert schema version
 	// TODO: Increment this on any schema change
-	if _, err := buf.Write(cachedProcUint32ToBytes(1)); err != nil {
+	if _, err := buf.Write(cachedProcUint32ToBytes(2)); err != nil {
+		return nil, err
+	}
+
+	// Insert the time at which the result was created
+	if _, err := buf.Write(cachedProcUint64ToBytes(uint64(createdAt.UnixNano()))); err != nil {
 		return nil, err
 	}
 
@@ -250,6 +478,21 @@ func cachedProcSerialiseBatch(batch service.MessageBatch) ([]byte, error) {
 		if _, err := buf.Write(mBytes); err != nil {
 			return nil, err
 		}
+
+		var errBytes []byte
+		if mErr := msg.GetError(); mErr != nil {
+			errBytes = []byte(mErr.Error())
+		}
+
+		// Insert size of error, which is zero for messages without errors
+		if _, err := buf.Write(cachedProcUint32ToBytes(uint32(len(errBytes)))); err != nil {
+			return nil, err
+		}
+
+		// Write error
+		if _, err := buf.Write(errBytes); err != nil {
+			return nil, err
+		}
 	}
 
 	return buf.Bytes(), nil
@@ -284,13 +527,71 @@ func cachedProcV1DeserialiseBatch(msg *service.Message, data []byte) (resBatch s
 	return
 }
 
-func cachedProcResultToBatch(msg *service.Message, cachedResult []byte) (service.MessageBatch, error) {
+func cachedProcV2DeserialiseBatch(msg *service.Message, data []byte) (resBatch service.MessageBatch, createdAt time.Time, err error) {
+	// Extract the time at which the result was created
+	var createdAtNanos uint64
+	if createdAtNanos, data, err = cachedProcExtractUint64(data); err != nil {
+		return nil, createdAt, fmt.Errorf("failed to extract creation time: %w", err)
+	}
+	createdAt = time.Unix(0, int64(createdAtNanos))
+
+	// Extract number of batch messages
+	var nBatches uint32
+	if nBatches, data, err = cachedProcExtractUint32(data); err != nil {
+		return nil, createdAt, fmt.Errorf("failed to extract batch size: %w", err)
+	}
+
+	for i := 0; i < int(nBatches); i++ {
+		// Extract message length
+		var msgSize uint32
+		if msgSize, data, err = cachedProcExtractUint32(data); err != nil {
+			return nil, createdAt, fmt.Errorf("failed to extract message %v size: %w", i, err)
+		}
+
+		if len(data) < int(msgSize) {
+			return nil, createdAt, fmt.Errorf("failed to extract message %v size: input data ended unexpectedly", i)
+		}
+
+		// Copy message with bytes
+		msgCopy := msg.Copy()
+		msgCopy.SetBytes(data[:msgSize])
+		data = data[msgSize:]
+
+		// Extract error length
+		var errSize uint32
+		if errSize, data, err = cachedProcExtractUint32(data); err != nil {
+			return nil, createdAt, fmt.Errorf("failed to extract message %v error size: %w", i, err)
+		}
+
+		if len(data) < int(errSize) {
+			return nil, createdAt, fmt.Errorf("failed to extract message %v error: input data ended unexpectedly", i)
+		}
+
+		if errSize > 0 {
+			msgCopy.SetError(errors.New(string(data[:errSize])))
+		}
+		data = data[errSize:]
+
+		resBatch = append(resBatch, msgCopy)
+	}
+
+	return
+}
+
+// cachedProcResultToBatch deserialises a cached result into a batch of
+// messages, along with the time at which the result was created, which is zero
+// for results cached with a format that did not include it.
+func cachedProcResultToBatch(msg *service.Message, cachedResult []byte) (service.MessageBatch, time.Time, error) {
 	verID, remaining, err := cachedProcExtractUint32(cachedResult)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract serialisation format version: %w", err)
+		return nil, time.Time{}, fmt.Errorf("failed to extract serialisation format version: %w", err)
 	}
-	if verID == 1 {
-		return cachedProcV1DeserialiseBatch(msg, remaining)
+	switch verID {
+	case 1:
+		batch, err := cachedProcV1DeserialiseBatch(msg, remaining)
+		return batch, time.Time{}, err
+	case 2:
+		return cachedProcV2DeserialiseBatch(msg, remaining)
 	}
-	return nil, fmt.Errorf("invalid format version: %v", verID)
+	return nil, time.Time{}, fmt.Errorf("invalid format version: %v", verID)
 }
@@ -2,7 +2,9 @@ package pure
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -146,3 +148,207 @@ processors:
 
 	assert.NoError(t, proc.Close(tCtx))
 }
+
+func TestCachedErrorsRestored(t *testing.T) {
+	conf, err := newCachedProcessorConfigSpec().ParseYAML(`
+key: ${! content() }
+cache: foo
+error_ttl: 1m
+processors:
+  - bloblang: 'root = content().string() + " " + uuid_v4()'
+  - bloblang: 'root = throw("nope")'
+`, nil)
+	require.NoError(t, err)
+
+	mRes := service.MockResources(service.MockResourcesOptAddCache("foo"))
+
+	proc, err := newCachedProcessorFromParsedConf(mRes, conf)
+	require.NoError(t, err)
+
+	tCtx := context.Background()
+
+	resBatch1, err := proc.Process(tCtx, service.NewMessage([]byte("keya")))
+	require.NoError(t, err)
+	require.Len(t, resBatch1, 1)
+	require.Error(t, resBatch1[0].GetError())
+
+	resBatch2, err := proc.Process(tCtx, service.NewMessage([]byte("keya")))
+	require.NoError(t, err)
+	require.Len(t, resBatch2, 1)
+	require.Error(t, resBatch2[0].GetError())
+	assert.Equal(t, resBatch1[0].GetError().Error(), resBatch2[0].GetError().Error())
+
+	resBytes1, err := resBatch1[0].AsBytes()
+	require.NoError(t, err)
+	resBytes2, err := resBatch2[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, string(resBytes1), string(resBytes2))
+}
+
+func TestCachedStaleWhileRevalidate(t *testing.T) {
+	conf, err := newCachedProcessorConfigSpec().ParseYAML(`
+key: ${! content() }
+cache: foo
+stale_after: 1ns
+processors:
+  - bloblang: 'root = content().string() + " " + uuid_v4()'
+`, nil)
+	require.NoError(t, err)
+
+	mRes := service.MockResources(service.MockResourcesOptAddCache("foo"))
+
+	proc, err := newCachedProcessorFromParsedConf(mRes, conf)
+	require.NoError(t, err)
+
+	tCtx := context.Background()
+
+	resBatch1, err := proc.Process(tCtx, service.NewMessage([]byte("keya")))
+	require.NoError(t, err)
+	require.Len(t, resBatch1, 1)
+	resBytes1, err := resBatch1[0].AsBytes()
+	require.NoError(t, err)
+
+	// The stale result is served immediately whilst a refresh is triggered
+	resBatch2, err := proc.Process(tCtx, service.NewMessage([]byte("keya")))
+	require.NoError(t, err)
+	require.Len(t, resBatch2, 1)
+	resBytes2, err := resBatch2[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, string(resBytes1), string(resBytes2))
+
+	assert.Eventually(t, func() bool {
+		resBatch, err := proc.Process(tCtx, service.NewMessage([]byte("keya")))
+		if err != nil || len(resBatch) != 1 {
+			return false
+		}
+		resBytes, err := resBatch[0].AsBytes()
+		if err != nil {
+			return false
+		}
+		return string(resBytes) != string(resBytes1)
+	}, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, proc.Close(tCtx))
+}
+
+func TestCachedCoalesce(t *testing.T) {
+	conf, err := newCachedProcessorConfigSpec().ParseYAML(`
+key: ${! content() }
+cache: foo
+coalesce: true
+processors:
+  - sleep:
+      duration: 100ms
+  - bloblang: 'root = content().string() + " " + uuid_v4()'
+`, nil)
+	require.NoError(t, err)
+
+	mRes := service.MockResources(service.MockResourcesOptAddCache("foo"))
+
+	proc, err := newCachedProcessorFromParsedConf(mRes, conf)
+	require.NoError(t, err)
+
+	tCtx := context.Background()
+
+	results := make([]string, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resBatch, err := proc.Process(tCtx, service.NewMessage([]byte("keya")))
+			require.NoError(t, err)
+			require.Len(t, resBatch, 1)
+			resBytes, err := resBatch[0].AsBytes()
+			require.NoError(t, err)
+			results[i] = string(resBytes)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, res := range results[1:] {
+		assert.Equal(t, results[0], res)
+	}
+}
+
+func TestCachedCoalesceLeaderCancelled(t *testing.T) {
+	conf, err := newCachedProcessorConfigSpec().ParseYAML(`
+key: ${! content() }
+cache: foo
+coalesce: true
+processors:
+  - sleep:
+      duration: 200ms
+  - bloblang: 'root = content().string() + " done"'
+`, nil)
+	require.NoError(t, err)
+
+	mRes := service.MockResources(service.MockResourcesOptAddCache("foo"))
+
+	proc, err := newCachedProcessorFromParsedConf(mRes, conf)
+	require.NoError(t, err)
+
+	leaderCtx, leaderDone := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer leaderDone()
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := proc.Process(leaderCtx, service.NewMessage([]byte("keya")))
+		leaderErr <- err
+	}()
+	<-time.After(time.Millisecond * 10)
+
+	// The follower joins the execution started by the leader, which must not
+	// fail when the leader gives up waiting.
+	resBatch, err := proc.Process(context.Background(), service.NewMessage([]byte("keya")))
+	require.NoError(t, err)
+	require.Len(t, resBatch, 1)
+
+	resBytes, err := resBatch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "keya done", string(resBytes))
+
+	assert.ErrorIs(t, <-leaderErr, context.DeadlineExceeded)
+}
+
+func TestCachedSkipOn(t *testing.T) {
+	conf, err := newCachedProcessorConfigSpec().ParseYAML(`
+key: ${! content() }
+cache: foo
+skip_on: 'meta("nocache") == "true"'
+processors:
+  - bloblang: 'root = content().string() + " " + uuid_v4()'
+`, nil)
+	require.NoError(t, err)
+
+	mRes := service.MockResources(service.MockResourcesOptAddCache("foo"))
+
+	proc, err := newCachedProcessorFromParsedConf(mRes, conf)
+	require.NoError(t, err)
+
+	tCtx := context.Background()
+
+	msg := service.NewMessage([]byte("keya"))
+	msg.MetaSetMut("nocache", "true")
+
+	resBatch, err := proc.Process(tCtx, msg)
+	require.NoError(t, err)
+	require.Len(t, resBatch, 1)
+
+	resBytes, err := resBatch[0].AsBytes()
+	require.NoError(t, err)
+	assert.Contains(t, string(resBytes), "keya ")
+
+	require.NoError(t, mRes.AccessCache(tCtx, "foo", func(c service.Cache) {
+		_, err = c.Get(tCtx, "keya")
+		assert.ErrorIs(t, err, service.ErrKeyNotFound)
+	}))
+
+	_, err = proc.Process(tCtx, service.NewMessage([]byte("keya")))
+	require.NoError(t, err)
+
+	require.NoError(t, mRes.AccessCache(tCtx, "foo", func(c service.Cache) {
+		_, err = c.Get(tCtx, "keya")
+		assert.NoError(t, err)
+	}))
+}
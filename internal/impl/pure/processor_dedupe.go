@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
@@ -32,11 +33,19 @@ func init() {
 		Description: `
 Caches must be configured as resources, for more information check out the [cache documentation here](/docs/components/caches/about).
 
+## Bloom Filter
+
+As an alternative to a cache resource, which stores every key and therefore grows with the cardinality of the stream, it's possible to enable a built-in [Bloom filter](https://en.wikipedia.org/wiki/Bloom_filter) with the field ` + "`bloom_filter.enabled`" + `. The filter has a fixed memory footprint determined by its ` + "`capacity`" + ` and ` + "`false_positive_rate`" + `, at the cost of occasionally dropping a message that is not a duplicate. Whilst the filter holds fewer than ` + "`capacity`" + ` keys the rate of such false positives will not exceed ` + "`false_positive_rate`" + `.
+
+When a ` + "`rotate_interval`" + ` is specified the filter is split into two generations, where each interval the oldest generation is discarded. This means keys are remembered for at least one interval and at most two, which both bounds the number of keys held by the filter and results in deduplication over a time window.
+
+The state of the filter can be preserved across restarts by specifying a ` + "`snapshot_path`" + `, where it is written periodically as well as during a graceful shutdown, and is restored from when the processor is created.
+
 When using this processor with an output target that might fail you should always wrap the output within an indefinite ` + "[`retry`](/docs/components/outputs/retry)" + ` block. This ensures that during outages your messages aren't reprocessed after failures, which would result in messages being dropped.
 
 ## Batch Deduplication
 
-This processor enacts on individual messages only, in order to perform a deduplication on behalf of a batch (or window) of messages instead use the ` + "[`cache` processor](/docs/components/processors/cache#examples)" + `.
+By default keys are deduplicated against the entire history held by the cache or filter. Setting ` + "`scope`" + ` to ` + "`batch`" + ` instead deduplicates messages against only the other messages of the same batch, in which case neither a cache nor a Bloom filter can be configured.
 
 ## Delivery Guarantees
 
@@ -44,9 +53,21 @@ Performing deduplication on a stream using a distributed cache voids any at-leas
 
 This problem can be mitigated by using an in-memory cache and distributing messages to horizontally scaled Benthos pipelines partitioned by the deduplication key. However, in situations where at-least-once delivery guarantees are important it is worth avoiding deduplication in favour of implement idempotent behaviour at the edge of your stream pipelines.`,
 		Config: docs.FieldComponent().WithChildren(
-			docs.FieldString("cache", "The [`cache` resource](/docs/components/caches/about) to target with this processor."),
+			docs.FieldString("cache", "The [`cache` resource](/docs/components/caches/about) to target with this processor. Must not be set when the `bloom_filter` is enabled or when `scope` is `batch`."),
 			docs.FieldString("key", "An interpolated string yielding the key to deduplicate by for each message.", `${! meta("kafka_key") }`, `${! content().hash("xxhash64") }`).IsInterpolated(),
 			docs.FieldBool("drop_on_err", "Whether messages should be dropped when the cache returns a general error such as a network issue."),
+			docs.FieldString("scope", "The scope within which messages are deduplicated.").HasAnnotatedOptions(
+				"global", "Deduplicate against all keys held by the cache or Bloom filter.",
+				"batch", "Deduplicate against only the other messages of the same batch.",
+			).Advanced(),
+			docs.FieldObject("bloom_filter", "A built-in probabilistic alternative to a cache resource with a fixed memory footprint.").WithChildren(
+				docs.FieldBool("enabled", "Whether to deduplicate using a Bloom filter instead of a cache resource. Must not be enabled when `scope` is `batch`."),
+				docs.FieldInt("capacity", "The number of keys the filter (or each generation of it when rotating) is expected to hold before the false positive rate is exceeded."),
+				docs.FieldFloat("false_positive_rate", "The target probability of a message being considered a duplicate when it is not.", 0.01, 0.0001),
+				docs.FieldString("rotate_interval", "An optional interval after which the oldest generation of the filter is discarded, resulting in keys being remembered for between one and two intervals. When empty keys are never forgotten.", "1h", "24h"),
+				docs.FieldString("snapshot_path", "An optional file path to periodically write the state of the filter to, from which it is restored on startup.", "./dedupe.snapshot"),
+				docs.FieldString("snapshot_interval", "The period between snapshots when a `snapshot_path` is set, snapshots are written in the background and only when the filter has changed. When empty or zero the filter is only snapshotted during a graceful shutdown.").Advanced(),
+			).Advanced(),
 		).ChildDefaultAndTypesFromStruct(processor.NewDedupeConfig()),
 		Examples: []docs.AnnotatedExample{
 			{
@@ -63,6 +84,22 @@ cache_resources:
   - label: keycache
     memory:
       default_ttl: 60s
+`,
+			},
+			{
+				Title:   "Deduplicate with a Bloom filter",
+				Summary: "The following configuration deduplicates messages by an ID field within a window of between one and two hours using a Bloom filter, which is snapshotted to disk in order to survive restarts.",
+				Config: `
+pipeline:
+  processors:
+    - dedupe:
+        key: ${! json("id") }
+        bloom_filter:
+          enabled: true
+          capacity: 10000000
+          false_positive_rate: 0.0001
+          rotate_interval: 1h
+          snapshot_path: ./dedupe.snapshot
 `,
 			},
 		},
@@ -75,10 +112,12 @@ cache_resources:
 type dedupeProc struct {
 	log log.Modular
 
-	dropOnErr bool
-	key       *field.Expression
-	mgr       bundle.NewManagement
-	cacheName string
+	dropOnErr  bool
+	key        *field.Expression
+	mgr        bundle.NewManagement
+	cacheName  string
+	batchScope bool
+	filter     *dedupeFilter
 }
 
 func newDedupe(conf processor.DedupeConfig, mgr bundle.NewManagement) (*dedupeProc, error) {
@@ -90,22 +129,80 @@ func newDedupe(conf processor.DedupeConfig, mgr bundle.NewManagement) (*dedupePr
 		return nil, fmt.Errorf("failed to parse key expression: %v", err)
 	}
 
-	if !mgr.ProbeCache(conf.Cache) {
-		return nil, fmt.Errorf("cache resource '%v' was not found", conf.Cache)
-	}
-
-	return &dedupeProc{
+	d := &dedupeProc{
 		log:       mgr.Logger(),
 		dropOnErr: conf.DropOnCacheErr,
 		key:       key,
 		mgr:       mgr,
 		cacheName: conf.Cache,
-	}, nil
+	}
+
+	switch conf.Scope {
+	case "global", "":
+	case "batch":
+		d.batchScope = true
+	default:
+		return nil, fmt.Errorf("scope not recognised: %v", conf.Scope)
+	}
+
+	if d.batchScope || conf.BloomFilter.Enabled {
+		if conf.Cache != "" {
+			return nil, errors.New("a cache resource must not be specified when the bloom filter is enabled or the scope is batch")
+		}
+		if d.batchScope {
+			if conf.BloomFilter.Enabled {
+				return nil, errors.New("the bloom filter must not be enabled when the scope is batch")
+			}
+			return d, nil
+		}
+		if d.filter, err = newDedupeFilterFromConfig(conf.BloomFilter, mgr); err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+
+	if !mgr.ProbeCache(conf.Cache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", conf.Cache)
+	}
+	return d, nil
+}
+
+func newDedupeFilterFromConfig(conf processor.DedupeBloomFilterConfig, mgr bundle.NewManagement) (*dedupeFilter, error) {
+	var rotateInterval, snapshotInterval time.Duration
+	var err error
+	if conf.RotateInterval != "" {
+		if rotateInterval, err = time.ParseDuration(conf.RotateInterval); err != nil {
+			return nil, fmt.Errorf("failed to parse rotate_interval: %w", err)
+		}
+	}
+	if conf.SnapshotInterval != "" {
+		if snapshotInterval, err = time.ParseDuration(conf.SnapshotInterval); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot_interval: %w", err)
+		}
+	}
+
+	f, err := newDedupeFilter(conf.Capacity, conf.FalsePositiveRate, rotateInterval, mgr.FS(), conf.SnapshotPath, snapshotInterval)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.loadSnapshot(); err != nil {
+		if !errors.Is(err, errDedupeFilterSnapshotMismatch) {
+			return nil, fmt.Errorf("failed to load bloom filter snapshot: %w", err)
+		}
+		mgr.Logger().Warnf("Ignoring bloom filter snapshot at '%v': %v\n", conf.SnapshotPath, err)
+	}
+	f.startSnapshots(mgr.Logger())
+	return f, nil
 }
 
 //------------------------------------------------------------------------------
 
 func (d *dedupeProc) ProcessBatch(ctx context.Context, spans []*tracing.Span, batch message.Batch) ([]message.Batch, error) {
+	var batchKeys map[string]struct{}
+	if d.batchScope {
+		batchKeys = make(map[string]struct{}, batch.Len())
+	}
+
 	newBatch := message.QuickBatch(nil)
 	_ = batch.Iter(func(i int, p *message.Part) error {
 		key, err := d.key.String(i, batch)
@@ -115,10 +212,22 @@ func (d *dedupeProc) ProcessBatch(ctx context.Context, spans []*tracing.Span, ba
 			return nil
 		}
 
-		if cerr := d.mgr.AccessCache(context.Background(), d.cacheName, func(cache cache.V1) {
-			err = cache.Add(context.Background(), key, []byte{'t'}, nil)
-		}); cerr != nil {
-			err = cerr
+		switch {
+		case batchKeys != nil:
+			if _, exists := batchKeys[key]; exists {
+				err = component.ErrKeyAlreadyExists
+			}
+			batchKeys[key] = struct{}{}
+		case d.filter != nil:
+			if !d.filter.addIfAbsent([]byte(key)) {
+				err = component.ErrKeyAlreadyExists
+			}
+		default:
+			if cerr := d.mgr.AccessCache(context.Background(), d.cacheName, func(cache cache.V1) {
+				err = cache.Add(context.Background(), key, []byte{'t'}, nil)
+			}); cerr != nil {
+				err = cerr
+			}
 		}
 		if err != nil {
 			if errors.Is(err, component.ErrKeyAlreadyExists) {
@@ -151,5 +260,8 @@ func (d *dedupeProc) ProcessBatch(ctx context.Context, spans []*tracing.Span, ba
 }
 
 func (d *dedupeProc) Close(context.Context) error {
+	if d.filter != nil {
+		return d.filter.close()
+	}
 	return nil
 }
@@ -2,7 +2,10 @@ package pure_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -95,3 +98,130 @@ func TestDedupeCacheErrors(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, msgs, 1)
 }
+
+func TestDedupeBatchScope(t *testing.T) {
+	conf := processor.NewConfig()
+	conf.Type = "dedupe"
+	conf.Dedupe.Key = "${! content() }"
+	conf.Dedupe.Scope = "batch"
+
+	mgr := mock.NewManager()
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgOut, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("foo"), []byte("bar"), []byte("foo"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, msgOut, 1)
+	assert.Equal(t, [][]byte{[]byte("foo"), []byte("bar")}, message.GetAllBytes(msgOut[0]))
+
+	msgOut, err = proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("foo"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, msgOut, 1)
+	assert.Equal(t, [][]byte{[]byte("foo")}, message.GetAllBytes(msgOut[0]))
+
+	conf.Dedupe.Cache = "foocache"
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{}
+	_, err = mgr.NewProcessor(conf)
+	require.Error(t, err)
+
+	conf.Dedupe.Cache = ""
+	conf.Dedupe.BloomFilter.Enabled = true
+	_, err = mgr.NewProcessor(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bloom filter must not be enabled")
+}
+
+func TestDedupeBloomFilter(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "dedupe.snapshot")
+
+	conf := processor.NewConfig()
+	conf.Type = "dedupe"
+	conf.Dedupe.Key = "${! content() }"
+	conf.Dedupe.BloomFilter.Enabled = true
+	conf.Dedupe.BloomFilter.Capacity = 1000
+	conf.Dedupe.BloomFilter.SnapshotPath = snapshotPath
+
+	mgr := mock.NewManager()
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgOut, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("foo"), []byte("bar"), []byte("foo"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, msgOut, 1)
+	assert.Equal(t, [][]byte{[]byte("foo"), []byte("bar")}, message.GetAllBytes(msgOut[0]))
+
+	msgOut, err = proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("bar"), []byte("baz"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, msgOut, 1)
+	assert.Equal(t, [][]byte{[]byte("baz")}, message.GetAllBytes(msgOut[0]))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+	require.NoError(t, proc.Close(ctx))
+
+	// A new processor should restore the filter from its snapshot.
+	proc, err = mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgOut, err = proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("foo"), []byte("baz"), []byte("buz"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, msgOut, 1)
+	assert.Equal(t, [][]byte{[]byte("buz")}, message.GetAllBytes(msgOut[0]))
+
+	// A snapshot with mismatched parameters is ignored.
+	conf.Dedupe.BloomFilter.Capacity = 2000
+	proc, err = mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgOut, err = proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("foo"),
+	}))
+	require.NoError(t, err)
+	require.Len(t, msgOut, 1)
+}
+
+func TestDedupeBloomFilterBackgroundSnapshot(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "dedupe.snapshot")
+
+	conf := processor.NewConfig()
+	conf.Type = "dedupe"
+	conf.Dedupe.Key = "${! content() }"
+	conf.Dedupe.BloomFilter.Enabled = true
+	conf.Dedupe.BloomFilter.Capacity = 1000
+	conf.Dedupe.BloomFilter.SnapshotPath = snapshotPath
+	conf.Dedupe.BloomFilter.SnapshotInterval = "10ms"
+
+	proc, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	_, err = proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("foo"),
+	}))
+	require.NoError(t, err)
+
+	// The snapshot is written by a background loop rather than the call to
+	// process, and is never left as a partially written temporary file.
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(snapshotPath)
+		return err == nil
+	}, time.Second*5, time.Millisecond*10)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+	require.NoError(t, proc.Close(ctx))
+
+	_, err = os.Stat(snapshotPath + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -42,6 +42,22 @@ Notice that we mutate the value of `+"`invitees`"+` in the resulting document by
 
 Mapping documents is advantageous in situations where the result is a document with a dramatically different shape to the input document, since we are effectively rebuilding the document in its entirety and might as well keep a reference to the unchanged input document throughout. However, in situations where we are only performing minor alterations to the input document, the rest of which is unchanged, it might be more efficient to use the `+"[`mutation` processor](/docs/components/processors/mutation)"+` instead.
 
+## Large Arrays
+
+Referencing the input document within a mapping parses it in its entirety, which for very large documents such as an array of many thousands of records can consume a significant amount of memory. When the records can be mapped individually the `+"[`mapping_json_array` processor](/docs/components/processors/mapping_json_array)"+` executes a mapping on each element of an array as it is decoded, emitting each mapped element as its own message. The fields that precede a nested array, such as an ID of the batch of records, remain available to the mapping of each element with `+"`@json_array_context`"+`:
+
+`+"```yaml"+`
+pipeline:
+  processors:
+    - mapping_json_array:
+        path: data.records
+        mapping: |
+          root = this
+          root.batch_id = @json_array_context.data.batch_id
+`+"```"+`
+
+Alternatively, the records can be split into their own messages before reaching any processors with the input codec `+"`json_array`"+` (or `+"`json_array:<path>`"+` for an array nested within objects), or with the `+"[`unarchive` processor](/docs/components/processors/unarchive)"+` using the format `+"`json_array:<path>`"+`.
+
 ## Error Handling
 
 Bloblang mappings can fail, in which case the message remains unchanged, errors are logged, and the message is flagged as having failed, allowing you to use [standard processor error handling patterns](/docs/configuration/error_handling).
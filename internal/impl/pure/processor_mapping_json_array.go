@@ -0,0 +1,165 @@
+package pure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jeffail/gabs/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/component/interop"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/tracing"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	mjaFieldMapping = "mapping"
+	mjaFieldPath    = "path"
+)
+
+func mappingJSONArrayProcSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Version("4.12.0").
+		Categories("Mapping", "Parsing").
+		Summary("Executes a [Bloblang](/docs/guides/bloblang/about) mapping on each element of a JSON array within messages, where elements are decoded and mapped one at a time rather than parsing the document as a whole, and each mapped element becomes its own message.").
+		Description(`
+This processor is a lazy counterpart to the `+"[`mapping` processor](/docs/components/processors/mapping)"+` for messages that contain very large JSON arrays, such as many thousands of records. Rather than the mapping referencing the document as a whole, which requires it to be parsed into memory in its entirety, the array is read with a JSON token reader and the mapping is executed against each element as it is reached, with the document being the element itself.
+
+The array can be nested within objects, in which case it is located by a dot path of object keys. The fields of parent objects that precede the array, such as an ID of the batch of records, are available to the mapping of each element with the metadata field `+"`json_array_context`"+`, which is also kept on the resulting messages. Any fields that follow the array are never read.
+
+The messages resulting from each element are emitted in place of the original message within the batch, and elements deleted by the mapping are dropped. An element that fails its mapping is emitted unchanged and flagged as having failed, and if the array itself cannot be decoded then the original message remains unchanged and is flagged as having failed, allowing you to use [standard processor error handling patterns](/docs/configuration/error_handling).`).
+		Fields(
+			service.NewBloblangField(mjaFieldMapping).
+				Description("A [Bloblang mapping](/docs/guides/bloblang/about) to execute on each element of the array."),
+			service.NewStringField(mjaFieldPath).
+				Description("A dot path of object keys locating the array within each message. When empty the root of each message must be the array.").
+				Example("data.records").
+				Default(""),
+		).
+		Example("Mapping Nested Records", `
+Given large JSON documents of the form:
+
+`+"```json"+`
+{
+  "batch_id": "b1",
+  "records": [
+    {"name": "bev", "age": 28},
+    {"name": "ali", "age": 45}
+  ]
+}
+`+"```"+`
+
+We can map each record into its own message, labelled with the ID of the batch, without parsing the document in its entirety:`,
+			`
+pipeline:
+  processors:
+    - mapping_json_array:
+        path: records
+        mapping: |
+          root.name = this.name.uppercase()
+          root.batch_id = @json_array_context.batch_id
+`)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"mapping_json_array", mappingJSONArrayProcSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchProcessor, error) {
+			exec, err := conf.FieldBloblang(mjaFieldMapping)
+			if err != nil {
+				return nil, err
+			}
+			pathStr, err := conf.FieldString(mjaFieldPath)
+			if err != nil {
+				return nil, err
+			}
+			var path []string
+			if pathStr != "" {
+				path = gabs.DotPathToSlice(pathStr)
+			}
+
+			v1Proc := processor.NewV2BatchedToV1Processor("mapping_json_array", newMappingJSONArray(exec, path, mgr.Logger()), interop.UnwrapManagement(mgr))
+			return interop.NewUnwrapInternalBatchProcessor(v1Proc), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type mappingJSONArrayProc struct {
+	exec *mapping.Executor
+	path []string
+	log  *service.Logger
+}
+
+func newMappingJSONArray(exec *bloblang.Executor, path []string, log *service.Logger) *mappingJSONArrayProc {
+	uw := exec.XUnwrapper().(interface {
+		Unwrap() *mapping.Executor
+	}).Unwrap()
+
+	return &mappingJSONArrayProc{
+		exec: uw,
+		path: path,
+		log:  log,
+	}
+}
+
+// mapElements decodes the array of a message one element at a time, executing
+// the mapping on each element before the next is decoded.
+func (m *mappingJSONArrayProc) mapElements(msg *message.Part) (message.Batch, error) {
+	var mapped message.Batch
+	iter := query.NewJSONArrayIterator(bytes.NewReader(msg.AsBytes()), m.path)
+	for {
+		ele, err := iter.NextRaw()
+		if errors.Is(err, query.ErrEndOfIter) {
+			return mapped, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse message into JSON array: %w", err)
+		}
+
+		elePart := msg.ShallowCopy()
+		elePart.SetBytes(ele)
+		elePart.MetaSetMut("json_array_context", iter.Context())
+
+		newPart, err := m.exec.MapPart(0, message.Batch{elePart})
+		if err != nil {
+			m.log.Error(err.Error())
+			elePart.ErrorSet(err)
+			mapped = append(mapped, elePart)
+			continue
+		}
+		if newPart != nil {
+			mapped = append(mapped, newPart)
+		}
+	}
+}
+
+func (m *mappingJSONArrayProc) ProcessBatch(ctx context.Context, _ []*tracing.Span, b message.Batch) ([]message.Batch, error) {
+	newBatch := make(message.Batch, 0, len(b))
+	for _, msg := range b {
+		mapped, err := m.mapElements(msg)
+		if err != nil {
+			m.log.Error(err.Error())
+			msg.ErrorSet(err)
+			newBatch = append(newBatch, msg)
+			continue
+		}
+		newBatch = append(newBatch, mapped...)
+	}
+	if len(newBatch) == 0 {
+		return nil, nil
+	}
+	return []message.Batch{newBatch}, nil
+}
+
+func (m *mappingJSONArrayProc) Close(context.Context) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package pure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func TestMappingJSONArrayNested(t *testing.T) {
+	exec, err := bloblang.Parse(`
+root.name = this.name.uppercase()
+root.batch_id = @json_array_context.data.batch_id
+root = if this.name == "vic" { deleted() }
+`)
+	require.NoError(t, err)
+
+	proc := newMappingJSONArray(exec, []string{"data", "records"}, nil)
+
+	inMsg := message.NewPart([]byte(`{"data":{"batch_id":"b1","records":[{"name":"bev"},{"name":"vic"},{"name":"ali"}],"after":"ignored"}}`))
+	inMsg.MetaSetMut("foo", "bar")
+
+	outBatches, err := proc.ProcessBatch(context.Background(), nil, message.Batch{inMsg})
+	require.NoError(t, err)
+	require.Len(t, outBatches, 1)
+	require.Len(t, outBatches[0], 2)
+
+	assert.Equal(t, `{"batch_id":"b1","name":"BEV"}`, string(outBatches[0][0].AsBytes()))
+	assert.Equal(t, `{"batch_id":"b1","name":"ALI"}`, string(outBatches[0][1].AsBytes()))
+	for _, p := range outBatches[0] {
+		assert.Equal(t, "bar", p.MetaGetStr("foo"))
+		v, _ := p.MetaGetMut("json_array_context")
+		assert.Equal(t, map[string]any{"data": map[string]any{"batch_id": "b1"}}, v)
+	}
+
+	_, exists := inMsg.MetaGetMut("json_array_context")
+	assert.False(t, exists)
+}
+
+func TestMappingJSONArrayRoot(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this * 2`)
+	require.NoError(t, err)
+
+	proc := newMappingJSONArray(exec, nil, nil)
+
+	outBatches, err := proc.ProcessBatch(context.Background(), nil, message.Batch{
+		message.NewPart([]byte(`[1,2,3]`)),
+		message.NewPart([]byte(`[]`)),
+		message.NewPart([]byte(`[4]`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, outBatches, 1)
+
+	var results []string
+	for _, p := range outBatches[0] {
+		results = append(results, string(p.AsBytes()))
+	}
+	assert.Equal(t, []string{"2", "4", "6", "8"}, results)
+}
+
+func TestMappingJSONArrayErrors(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this.number() + 1`)
+	require.NoError(t, err)
+
+	proc := newMappingJSONArray(exec, []string{"items"}, nil)
+
+	outBatches, err := proc.ProcessBatch(context.Background(), nil, message.Batch{
+		message.NewPart([]byte(`{"items":[1,"nope",3]}`)),
+		message.NewPart([]byte(`{"items":{"not":"an array"}}`)),
+	})
+	require.NoError(t, err)
+	require.Len(t, outBatches, 1)
+	require.Len(t, outBatches[0], 4)
+
+	assert.Equal(t, "2", string(outBatches[0][0].AsBytes()))
+	assert.NoError(t, outBatches[0][0].ErrorGet())
+
+	assert.Equal(t, `"nope"`, string(outBatches[0][1].AsBytes()))
+	assert.Error(t, outBatches[0][1].ErrorGet())
+
+	assert.Equal(t, "4", string(outBatches[0][2].AsBytes()))
+	assert.NoError(t, outBatches[0][2].ErrorGet())
+
+	assert.Equal(t, `{"items":{"not":"an array"}}`, string(outBatches[0][3].AsBytes()))
+	assert.EqualError(t, outBatches[0][3].ErrorGet(), "failed to parse message into JSON array: expected array at path items, found object")
+}
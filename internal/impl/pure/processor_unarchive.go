@@ -12,6 +12,9 @@ import (
 	"io"
 	"strings"
 
+	"github.com/Jeffail/gabs/v2"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/public/service"
 )
@@ -33,6 +36,7 @@ For the unarchive formats that contain file information (tar, zip), a metadata f
 			`lines`:          `Extract the lines of a message each into their own message.`,
 			`json_documents`: `Attempt to parse a message as a stream of concatenated JSON documents. Each parsed document is expanded into a new message.`,
 			`json_array`:     `Attempt to parse a message as a JSON array, and extract each element into its own message.`,
+			`json_array:x`:   `Attempt to parse the array found at a dot path of object keys within a JSON message, and extract each element into its own message, e.g. the format "json_array:data.items" would extract the elements of the array at ` + "`data.items`" + `. Elements are decoded one at a time rather than parsing the message as a whole, and the fields of parent objects that precede the array are added to each message as a metadata field called ` + "`json_array_context`" + `.`,
 			`json_map`:       `Attempt to parse the message as a JSON map and for each element of the map expands its contents into a new message. A metadata field is added to each message called ` + "`archive_key`" + ` with the relevant key from the top-level map.`,
 			`csv`:            `Attempt to parse the message as a csv file (header required) and for each row in the file expands its contents into a json object in a new message.`,
 			`csv:x`:          `Attempt to parse the message as a csv file (header required) and for each row in the file expands its contents into a json object in a new message using a custom delimiter. The custom delimiter must be a single character, e.g. the format "csv:\t" would consume a tab delimited file.`,
@@ -199,6 +203,31 @@ func jsonArrayUnarchive(part *service.Message) (service.MessageBatch, error) {
 	return parts, nil
 }
 
+func jsonArrayPathUnarchive(path []string) func(*service.Message) (service.MessageBatch, error) {
+	return func(part *service.Message) (service.MessageBatch, error) {
+		pBytes, err := part.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+
+		var parts service.MessageBatch
+		iter := query.NewJSONArrayIterator(bytes.NewReader(pBytes), path)
+		for {
+			ele, err := iter.NextRaw()
+			if errors.Is(err, query.ErrEndOfIter) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse message into JSON array: %w", err)
+			}
+			newPart := part.Copy()
+			newPart.SetBytes(ele)
+			newPart.MetaSetMut("json_array_context", iter.Context())
+			parts = append(parts, newPart)
+		}
+		return parts, nil
+	}
+}
+
 func jsonMapUnarchive(part *service.Message) (service.MessageBatch, error) {
 	jDoc, err := part.AsStructuredMut()
 	if err != nil {
@@ -301,6 +330,14 @@ func strToUnarchiver(str string) (unarchiveFunc, error) {
 		return csvUnarchive(nil), nil
 	}
 
+	if strings.HasPrefix(str, "json_array:") {
+		by := strings.TrimPrefix(str, "json_array:")
+		if by == "" {
+			return nil, errors.New("json_array format requires a non-empty path")
+		}
+		return jsonArrayPathUnarchive(gabs.DotPathToSlice(by)), nil
+	}
+
 	if strings.HasPrefix(str, "csv:") {
 		by := strings.TrimPrefix(str, "csv:")
 		if by == "" {
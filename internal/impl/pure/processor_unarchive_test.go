@@ -5,6 +5,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -235,6 +236,40 @@ format: json_array
 	}
 }
 
+func TestUnarchiveJSONArrayPath(t *testing.T) {
+	conf, err := unarchiveProcConfig().ParseYAML(`
+format: json_array:data.items
+`, nil)
+	require.NoError(t, err)
+
+	proc, err := newUnarchiveFromParsed(conf, service.MockResources())
+	require.NoError(t, err)
+
+	msgs, res := proc.Process(context.Background(), service.NewMessage([]byte(
+		`{"source":"foo","data":{"page":2,"items":[{"id":1},{"id":2}]},"after":true}`,
+	)))
+	require.NoError(t, res)
+	require.Len(t, msgs, 2)
+
+	for i, e := range []string{`{"id":1}`, `{"id":2}`} {
+		mBytes, err := msgs[i].AsBytes()
+		require.NoError(t, err)
+		assert.Equal(t, e, string(mBytes))
+
+		v, exists := msgs[i].MetaGetMut("json_array_context")
+		require.True(t, exists)
+		assert.Equal(t, map[string]any{
+			"source": "foo",
+			"data":   map[string]any{"page": json.Number("2")},
+		}, v)
+	}
+
+	_, res = proc.Process(context.Background(), service.NewMessage([]byte(
+		`{"source":"foo","data":{"page":2}}`,
+	)))
+	require.EqualError(t, res, "failed to parse message into JSON array: path data.items was not found")
+}
+
 func TestUnarchiveJSONMap(t *testing.T) {
 	conf, err := unarchiveProcConfig().ParseYAML(`
 format: json_map
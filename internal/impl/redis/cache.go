@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -237,6 +238,149 @@ func (r *redisCache) Delete(ctx context.Context, key string) error {
 	}
 }
 
+func (r *redisCache) withRetries(ctx context.Context, fn func() error) error {
+	boff := r.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		r.boffPool.Put(boff)
+	}()
+
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (r *redisCache) ttlFor(ttl *time.Duration) time.Duration {
+	if ttl != nil {
+		return *ttl
+	}
+	return r.defaultTTL
+}
+
+func (r *redisCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if len(keys) == 0 {
+		return map[string][]byte{}, nil
+	}
+
+	prefixedKeys := make([]string, len(keys))
+	for i, k := range keys {
+		prefixedKeys[i] = r.prefix + k
+	}
+
+	var values []any
+	if err := r.withRetries(ctx, func() (err error) {
+		values, err = r.client.MGet(ctx, prefixedKeys...).Result()
+		return
+	}); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]byte, len(keys))
+	for i, v := range values {
+		if str, ok := v.(string); ok {
+			res[keys[i]] = []byte(str)
+		}
+	}
+	return res, nil
+}
+
+// Incr is not retried as a failed attempt may have already been applied.
+func (r *redisCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	key = r.prefix + key
+	t := r.ttlFor(ttl)
+
+	var incrCmd *redis.IntCmd
+	if _, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incrCmd = pipe.IncrBy(ctx, key, delta)
+		if t > 0 {
+			pipe.PExpire(ctx, key, t)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return incrCmd.Val(), nil
+}
+
+var redisCASScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == false then
+  return -1
+end
+if current ~= ARGV[1] then
+  return 0
+end
+if tonumber(ARGV[3]) > 0 then
+  redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+  redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CompareAndSwap is not retried as a failed attempt may have already been
+// applied, in which case a retry would report a mismatch for a swap that
+// succeeded.
+func (r *redisCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	key = r.prefix + key
+	t := r.ttlFor(ttl)
+
+	res, err := redisCASScript.Run(ctx, r.client, []string{key}, old, value, t.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+
+	switch res {
+	case -1:
+		return service.ErrKeyNotFound
+	case 0:
+		return service.ErrKeyValueMismatch
+	}
+	return nil
+}
+
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+func (r *redisCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	match := redisGlobEscaper.Replace(r.prefix+prefix) + "*"
+
+	var cursor uint64
+	for {
+		// Only a successful scan advances the cursor, as a failed call resets
+		// it and retrying from there would revisit keys that were already seen.
+		var keys []string
+		var next uint64
+		if err := r.withRetries(ctx, func() (err error) {
+			keys, next, err = r.client.Scan(ctx, cursor, match, 0).Result()
+			return
+		}); err != nil {
+			return err
+		}
+		cursor = next
+		for _, k := range keys {
+			if err := fn(strings.TrimPrefix(k, r.prefix)); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
 func (r *redisCache) Close(ctx context.Context) error {
 	return r.client.Close()
 }
@@ -27,7 +27,29 @@ For basic inserts use the ` + "[`sql_insert`](/docs/components/outputs/sql)" + `
 			Description("The maximum number of inserts to run in parallel.").
 			Default(64)).
 		Field(service.NewBatchPolicyField("batching")).
-		Version("3.65.0")
+		Version("3.65.0").
+		MigrationRule(`
+let insert = this.query.re_find_object("(?i)^\\s*INSERT\\s+INTO\\s+([^\\s(]+)\\s*\\(([^)]*)\\)\\s*VALUES\\s*\\(([^)]*)\\)\\s*;?\\s*$")
+let columns = ($insert."2" | "").split(",").map_each(c -> c.trim())
+let placeholders = ($insert."3" | "").split(",").map_each(p -> p.trim())
+let is_basic_insert = $insert.length() > 0 && $columns.length() == $placeholders.length() && $placeholders.enumerated().all(p -> p.value == "?" || p.value == "$" + (p.index + 1).string())
+
+root = if $is_basic_insert {
+  {
+    "sql_insert": this.without("data_source_name", "query").assign({
+      "dsn": this.data_source_name,
+      "table": $insert."1",
+      "columns": $columns,
+    })
+  }
+} else {
+  {
+    "sql_raw": this.without("data_source_name").assign({
+      "dsn": this.data_source_name,
+    })
+  }
+}
+`)
 }
 
 func init() {
@@ -33,7 +33,12 @@ For basic inserts or select queries use use either the ` + "[`sql_insert`](/docs
 		Field(service.NewStringField("result_codec").
 			Description("Result codec.").
 			Default("none")).
-		Version("3.65.0")
+		Version("3.65.0").
+		MigrationRule(`
+root.sql_raw = this.without("data_source_name", "result_codec")
+root.sql_raw.dsn = this.data_source_name
+root.sql_raw.exec_only = if (this.result_codec | "none") == "none" { true } else { deleted() }
+`)
 	// TODO: Add example
 }
 
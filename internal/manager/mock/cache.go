@@ -1,7 +1,10 @@
 package mock
 
 import (
+	"bytes"
 	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
@@ -66,6 +69,63 @@ func (c *Cache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// GetMulti returns multiple mock cache items.
+func (c *Cache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		if i, ok := c.Values[k]; ok {
+			res[k] = []byte(i.Value)
+		}
+	}
+	return res, nil
+}
+
+// Incr increments a mock cache item.
+func (c *Cache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	var v int64
+	if i, ok := c.Values[key]; ok {
+		var err error
+		if v, err = strconv.ParseInt(i.Value, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	v += delta
+	c.Values[key] = CacheItem{
+		Value: strconv.FormatInt(v, 10),
+		TTL:   ttl,
+	}
+	return v, nil
+}
+
+// CompareAndSwap sets a mock cache item if its value matches old.
+func (c *Cache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	i, ok := c.Values[key]
+	if !ok {
+		return component.ErrKeyNotFound
+	}
+	if !bytes.Equal([]byte(i.Value), old) {
+		return component.ErrKeyValueMismatch
+	}
+	c.Values[key] = CacheItem{
+		Value: string(value),
+		TTL:   ttl,
+	}
+	return nil
+}
+
+// IterKeys walks each mock cache key with a given prefix.
+func (c *Cache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	for k := range c.Values {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if err := fn(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Close does nothing.
 func (c *Cache) Close(ctx context.Context) error {
 	return nil
@@ -98,3 +98,29 @@ func TestConcurrentMutationsFromStructured(t *testing.T) {
 	close(kickOffChan)
 	wg.Wait()
 }
+
+func TestStructuredMutClonesOnce(t *testing.T) {
+	source := newMessageBytes(nil)
+	source.SetStructured(map[string]any{
+		"foo": "bar",
+	})
+
+	local := source.ShallowCopy()
+
+	vOne, err := local.AsStructuredMut()
+	require.NoError(t, err)
+	vOne.(map[string]any)["foo"] = "baz"
+
+	// A second mutable access must return the already cloned value rather than
+	// cloning it again, which would discard the previous mutation.
+	vTwo, err := local.AsStructuredMut()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": "baz"}, vTwo)
+	vTwo.(map[string]any)["bar"] = "qux"
+
+	assert.Equal(t, `{"bar":"qux","foo":"baz"}`, string(local.AsBytes()))
+
+	sourceV, err := source.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": "bar"}, sourceV)
+}
@@ -0,0 +1,149 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Jeffail/gabs/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// The prefix of encrypted secrets files, which identifies the format version.
+const encryptedPrefix = "benthos:secrets:v1:"
+
+// MasterKeyEnv is the environment variable from which the master key of
+// encrypted secrets files is read when one is not otherwise provided.
+const MasterKeyEnv = "BENTHOS_MASTER_KEY"
+
+// ErrNoMasterKey is returned when an encrypted secrets file is used without a
+// master key.
+var ErrNoMasterKey = errors.New("a master key is required in order to decrypt secrets")
+
+// NewMasterKey generates a random master key suitable for encrypting secrets
+// files.
+func NewMasterKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func newAEAD(masterKey []byte) (cipher.AEAD, error) {
+	masterKey = bytes.TrimSpace(masterKey)
+	if len(masterKey) == 0 {
+		return nil, ErrNoMasterKey
+	}
+	key := sha256.Sum256(masterKey)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt a secrets document with a master key.
+func Encrypt(masterKey, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return []byte(encryptedPrefix + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Decrypt an encrypted secrets document with a master key.
+func Decrypt(masterKey, ciphertext []byte) ([]byte, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext = bytes.TrimSpace(ciphertext)
+	if !bytes.HasPrefix(ciphertext, []byte(encryptedPrefix)) {
+		return nil, errors.New("unrecognised encrypted secrets format")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(ciphertext[len(encryptedPrefix):]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted secrets: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted secrets are truncated")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt secrets, the master key may be incorrect")
+	}
+	return plaintext, nil
+}
+
+// NewEncryptedFileProvider returns a provider that reads secrets from a file
+// encrypted with a master key, where the decrypted file is a YAML or JSON
+// document and the path of a secret is a dot path to a field within it.
+//
+// When the master key is nil it is read from the environment variable
+// BENTHOS_MASTER_KEY. The file is decrypted on first use.
+func NewEncryptedFileProvider(path string, masterKey []byte) Provider {
+	var doc *gabs.Container
+	var docErr error
+	var once sync.Once
+
+	load := func() {
+		if path == "" {
+			docErr = errors.New("an encrypted secrets file has not been configured")
+			return
+		}
+		key := masterKey
+		if key == nil {
+			key = []byte(os.Getenv(MasterKeyEnv))
+		}
+		fileBytes, err := os.ReadFile(path)
+		if err != nil {
+			docErr = err
+			return
+		}
+		plaintext, err := Decrypt(key, fileBytes)
+		if err != nil {
+			docErr = err
+			return
+		}
+		var v any
+		if err := yaml.Unmarshal(plaintext, &v); err != nil {
+			docErr = fmt.Errorf("failed to parse decrypted secrets: %w", err)
+			return
+		}
+		doc = gabs.Wrap(v)
+	}
+
+	return ProviderFunc(func(ctx context.Context, secretPath string) (string, error) {
+		once.Do(load)
+		if docErr != nil {
+			return "", docErr
+		}
+		v := doc.Path(secretPath).Data()
+		switch t := v.(type) {
+		case nil:
+			return "", ErrNotFound
+		case string:
+			return t, nil
+		case map[string]any, []any:
+			return "", fmt.Errorf("secret at path '%v' is not a value", secretPath)
+		default:
+			return strings.TrimSpace(fmt.Sprintf("%v", t)), nil
+		}
+	})
+}
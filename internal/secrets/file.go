@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewFileProvider returns a provider that reads secrets from files, where the
+// path of a secret is the path of the file containing it relative to the
+// provided directory, which allows secrets mounted as a directory of files (as
+// is common with Kubernetes) to be referenced by name. When the directory is
+// empty paths are relative to the working directory.
+//
+// Absolute paths and paths that lead outside of the directory are rejected.
+// A single trailing newline is removed from the contents of a file.
+func NewFileProvider(dir string) Provider {
+	return ProviderFunc(func(ctx context.Context, path string) (string, error) {
+		cleaned := filepath.Clean(path)
+		if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("path '%v' must be relative to the secrets directory", path)
+		}
+		if dir != "" {
+			cleaned = filepath.Join(dir, cleaned)
+		}
+		b, err := os.ReadFile(cleaned)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return "", ErrNotFound
+			}
+			return "", err
+		}
+		value := strings.TrimSuffix(string(b), "\n")
+		value = strings.TrimSuffix(value, "\r")
+		return value, nil
+	})
+}
@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Values shorter than this are not redacted as they would match too much
+// unrelated content.
+const minRedactLen = 4
+
+var (
+	redactions    = map[string]string{}
+	redactor      *strings.Replacer
+	redactionsMut sync.RWMutex
+)
+
+func addRedaction(value, ref string) {
+	if len(value) < minRedactLen {
+		return
+	}
+
+	redactionsMut.Lock()
+	defer redactionsMut.Unlock()
+
+	variants := []string{value}
+
+	// Values are also redacted in their escaped forms, as found within JSON
+	// documents and YAML configs.
+	if jBytes, err := json.Marshal(value); err == nil {
+		variants = append(variants, string(jBytes[1:len(jBytes)-1]))
+	}
+	variants = append(variants, strings.ReplaceAll(value, "\n", "\\n"))
+
+	changed := false
+	for _, v := range variants {
+		if existing, exists := redactions[v]; !exists || existing != ref {
+			redactions[v] = ref
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// Longer values are replaced first so that values containing other
+	// values are redacted entirely.
+	keys := make([]string, 0, len(redactions))
+	for k := range redactions {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) == len(keys[j]) {
+			return keys[i] < keys[j]
+		}
+		return len(keys[i]) > len(keys[j])
+	})
+
+	pairs := make([]string, 0, len(keys)*2)
+	for _, k := range keys {
+		pairs = append(pairs, k, redactions[k])
+	}
+	redactor = strings.NewReplacer(pairs...)
+}
+
+func getRedactor() *strings.Replacer {
+	redactionsMut.RLock()
+	defer redactionsMut.RUnlock()
+	return redactor
+}
+
+// Redact replaces the values of all resolved secrets within a string with the
+// references used to resolve them.
+func Redact(s string) string {
+	if r := getRedactor(); r != nil {
+		return r.Replace(s)
+	}
+	return s
+}
+
+// RedactBytes replaces the values of all resolved secrets within a blob of data
+// with the references used to resolve them.
+func RedactBytes(b []byte) []byte {
+	if r := getRedactor(); r != nil {
+		return []byte(r.Replace(string(b)))
+	}
+	return b
+}
+
+type redactWriter struct {
+	w io.Writer
+}
+
+// RedactWriter wraps an io.Writer such that the values of resolved secrets are
+// redacted from each write. Values are only redacted when written within a
+// single call, which is the case for log entries.
+func RedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w: w}
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	if getRedactor() == nil {
+		return r.w.Write(p)
+	}
+	if _, err := r.w.Write(RedactBytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
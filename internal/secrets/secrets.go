@@ -0,0 +1,266 @@
+// Package secrets provides the resolution of secret references within configs,
+// of the form `${secret:provider:path}`, along with the redaction of resolved
+// secret values from the outputs of the service.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by providers when a secret does not exist.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider resolves the value of secrets by their path.
+type Provider interface {
+	Lookup(ctx context.Context, path string) (string, error)
+}
+
+// ProviderFunc is a closure that implements Provider.
+type ProviderFunc func(ctx context.Context, path string) (string, error)
+
+// Lookup a secret by its path.
+func (f ProviderFunc) Lookup(ctx context.Context, path string) (string, error) {
+	return f(ctx, path)
+}
+
+var providerNameRegex = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+// Registry is a collection of secret providers by the names used to reference
+// them within configs.
+type Registry struct {
+	providers map[string]Provider
+	mut       sync.RWMutex
+}
+
+// NewRegistry creates an empty registry of secret providers.
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: map[string]Provider{},
+	}
+}
+
+var globalRegistry = func() *Registry {
+	r := NewRegistry()
+	_ = r.Register("file", NewFileProvider(""))
+	_ = r.Register("encrypted", NewEncryptedFileProvider("", nil))
+	return r
+}()
+
+// GlobalRegistry returns the registry of secret providers used by the package
+// level functions, which contains the standard providers `file` and
+// `encrypted`.
+func GlobalRegistry() *Registry {
+	return globalRegistry
+}
+
+// Clone the registry, creating a new registry containing the same providers
+// that can be modified independently of the source.
+func (r *Registry) Clone() *Registry {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	c := NewRegistry()
+	for k, v := range r.providers {
+		c.providers[k] = v
+	}
+	return c
+}
+
+// Register a provider by a name used to reference it within configs. If a
+// provider of the same name already exists it is replaced.
+func (r *Registry) Register(name string, p Provider) error {
+	if !providerNameRegex.MatchString(name) {
+		return fmt.Errorf("secret provider name '%v' must only contain alphanumeric, underscore and dash characters", name)
+	}
+
+	r.mut.Lock()
+	r.providers[name] = p
+	r.mut.Unlock()
+	return nil
+}
+
+// Providers returns the names of all registered providers.
+func (r *Registry) Providers() []string {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for k := range r.providers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register a provider by a name within the global registry.
+func Register(name string, p Provider) error {
+	return globalRegistry.Register(name, p)
+}
+
+// Providers returns the names of all providers within the global registry.
+func Providers() []string {
+	return globalRegistry.Providers()
+}
+
+//------------------------------------------------------------------------------
+
+// Reference returns the config syntax that references a secret.
+func Reference(provider, path string) string {
+	return "${secret:" + provider + ":" + path + "}"
+}
+
+// Resolve the value of a secret from a provider, the value is registered for
+// redaction.
+func (r *Registry) Resolve(ctx context.Context, provider, path string) (string, error) {
+	r.mut.RLock()
+	p, exists := r.providers[provider]
+	r.mut.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("secret provider '%v' was not recognised", provider)
+	}
+
+	value, err := p.Lookup(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret '%v' from provider '%v': %w", path, provider, err)
+	}
+	addRedaction(value, Reference(provider, path))
+	return value, nil
+}
+
+// Resolve the value of a secret from a provider of the global registry.
+func Resolve(ctx context.Context, provider, path string) (string, error) {
+	return globalRegistry.Resolve(ctx, provider, path)
+}
+
+var (
+	refRegex     = regexp.MustCompile(`\$\{\{secret:[0-9A-Za-z_-]+:[^}]+\}\}|\$\{secret:([0-9A-Za-z_-]+):([^}]+)\}`)
+	fullRefRegex = regexp.MustCompile(`^\$\{secret:[0-9A-Za-z_-]+:[^}]+\}$`)
+)
+
+// IsReference returns whether a blob of data is, in its entirety, a reference
+// to a secret of the form `${secret:provider:path}`.
+func IsReference(b []byte) bool {
+	return fullRefRegex.Match(b)
+}
+
+// unescapeReference returns the literal reference of an escaped reference of
+// the form `${{secret:provider:path}}`, or nil if the reference is not escaped.
+func unescapeReference(content []byte) []byte {
+	if !bytes.HasPrefix(content, []byte("${{")) {
+		return nil
+	}
+	return append([]byte("$"), content[2:len(content)-1]...)
+}
+
+// Replace all secret references within a blob of data of the form
+// `${secret:provider:path}` with the values of the secrets. References escaped
+// in the same way as environment variables, e.g. `${{secret:provider:path}}`,
+// are unescaped into literal references.
+//
+// Secrets are resolved after environment variable interpolation, and values
+// are inserted as they are, other than newlines being escaped in the same way
+// as environment variable values.
+func (r *Registry) Replace(ctx context.Context, inBytes []byte) ([]byte, error) {
+	return r.ReplaceExcept(ctx, inBytes)
+}
+
+// Replace secret references using the providers of the global registry.
+func Replace(ctx context.Context, inBytes []byte) ([]byte, error) {
+	return globalRegistry.Replace(ctx, inBytes)
+}
+
+// ReplaceExcept replaces secret references in the same way as Replace, except
+// that references to any of the excluded providers result in an error. This
+// allows configs from less trusted sources, such as those submitted via an
+// API, to be prevented from reading secrets they should not have access to.
+func (r *Registry) ReplaceExcept(ctx context.Context, inBytes []byte, excluded ...string) ([]byte, error) {
+	var rErr error
+	replaced := refRegex.ReplaceAllFunc(inBytes, func(content []byte) []byte {
+		if rErr != nil {
+			return content
+		}
+		if unescaped := unescapeReference(content); unescaped != nil {
+			return unescaped
+		}
+		groups := refRegex.FindSubmatch(content)
+		for _, e := range excluded {
+			if string(groups[1]) == e {
+				rErr = fmt.Errorf("secret provider '%v' is not permitted within this config", e)
+				return content
+			}
+		}
+		value, err := r.Resolve(ctx, string(groups[1]), string(groups[2]))
+		if err != nil {
+			rErr = err
+			return content
+		}
+		return []byte(strings.ReplaceAll(value, "\n", "\\n"))
+	})
+	if rErr != nil {
+		return nil, rErr
+	}
+	return replaced, nil
+}
+
+// ReplaceExcept replaces secret references using the providers of the global
+// registry.
+func ReplaceExcept(ctx context.Context, inBytes []byte, excluded ...string) ([]byte, error) {
+	return globalRegistry.ReplaceExcept(ctx, inBytes, excluded...)
+}
+
+// ReplaceResolvable replaces secret references in the same way as Replace,
+// except that references which cannot be resolved are left in place as
+// literal references, and the errors of each are returned instead of aborting.
+// This is useful for reading configs without running them, where secrets might
+// not be available.
+func (r *Registry) ReplaceResolvable(ctx context.Context, inBytes []byte) (replaced []byte, errs []ReferenceError) {
+	var offset int
+	replaced = refRegex.ReplaceAllFunc(inBytes, func(content []byte) []byte {
+		index := bytes.Index(inBytes[offset:], content) + offset
+		offset = index + len(content)
+
+		if unescaped := unescapeReference(content); unescaped != nil {
+			return unescaped
+		}
+		groups := refRegex.FindSubmatch(content)
+		value, err := r.Resolve(ctx, string(groups[1]), string(groups[2]))
+		if err != nil {
+			errs = append(errs, ReferenceError{
+				Line: bytes.Count(inBytes[:index], []byte("\n")) + 1,
+				Err:  err,
+			})
+			return content
+		}
+		return []byte(strings.ReplaceAll(value, "\n", "\\n"))
+	})
+	return
+}
+
+// ReplaceResolvable replaces secret references using the providers of the
+// global registry.
+func ReplaceResolvable(ctx context.Context, inBytes []byte) (replaced []byte, errs []ReferenceError) {
+	return globalRegistry.ReplaceResolvable(ctx, inBytes)
+}
+
+// ReferenceError describes a secret reference that could not be resolved.
+type ReferenceError struct {
+	Line int
+	Err  error
+}
+
+// Error returns a human readable description of the error.
+func (r ReferenceError) Error() string {
+	return r.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (r ReferenceError) Unwrap() error {
+	return r.Err
+}
@@ -0,0 +1,225 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplace(t *testing.T) {
+	require.NoError(t, Register("testmap", ProviderFunc(func(ctx context.Context, path string) (string, error) {
+		switch path {
+		case "foo":
+			return "foo value", nil
+		case "multi":
+			return "first line\nsecond line", nil
+		}
+		return "", ErrNotFound
+	})))
+
+	tests := []struct {
+		name        string
+		input       string
+		output      string
+		errContains string
+	}{
+		{
+			name:   "no references",
+			input:  `foo: ${BAR}`,
+			output: `foo: ${BAR}`,
+		},
+		{
+			name:   "single reference",
+			input:  `foo: ${secret:testmap:foo}`,
+			output: `foo: foo value`,
+		},
+		{
+			name:   "multiple references",
+			input:  `foo: ${secret:testmap:foo} and ${secret:testmap:multi}`,
+			output: `foo: foo value and first line\nsecond line`,
+		},
+		{
+			name:   "escaped reference",
+			input:  `foo: ${{secret:testmap:foo}}`,
+			output: `foo: ${secret:testmap:foo}`,
+		},
+		{
+			name:        "missing secret",
+			input:       `foo: ${secret:testmap:bar}`,
+			errContains: "failed to resolve secret 'bar' from provider 'testmap': secret not found",
+		},
+		{
+			name:        "unknown provider",
+			input:       `foo: ${secret:nope:bar}`,
+			errContains: "secret provider 'nope' was not recognised",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			out, err := Replace(context.Background(), []byte(test.input))
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(out))
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	require.NoError(t, Register("testredact", ProviderFunc(func(ctx context.Context, path string) (string, error) {
+		switch path {
+		case "short":
+			return "abc", nil
+		case "quoted":
+			return `a "quoted" value`, nil
+		}
+		return "", ErrNotFound
+	})))
+
+	_, err := Replace(context.Background(), []byte(`${secret:testredact:short} ${secret:testredact:quoted}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, `abc ${secret:testredact:quoted}`, Redact(`abc a "quoted" value`))
+	assert.Equal(t, `{"v":"${secret:testredact:quoted}"}`, Redact(`{"v":"a \"quoted\" value"}`))
+
+	var buf bytes.Buffer
+	w := RedactWriter(&buf)
+	n, err := w.Write([]byte(`msg="a "quoted" value"`))
+	require.NoError(t, err)
+	assert.Equal(t, 22, n)
+	assert.Equal(t, `msg="${secret:testredact:quoted}"`, buf.String())
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("hunter2\n"), 0o600))
+
+	p := NewFileProvider(dir)
+
+	v, err := p.Lookup(context.Background(), "password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	v, err = p.Lookup(context.Background(), "./foo/../password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	_, err = p.Lookup(context.Background(), "nope")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for _, path := range []string{
+		filepath.Join(dir, "password"),
+		"../password",
+		"foo/../../password",
+		"..",
+	} {
+		_, err = p.Lookup(context.Background(), path)
+		assert.Error(t, err, path)
+		assert.NotErrorIs(t, err, ErrNotFound, path)
+	}
+
+	_, err = NewFileProvider("").Lookup(context.Background(), filepath.Join(dir, "password"))
+	assert.Error(t, err)
+}
+
+func TestReplaceExcept(t *testing.T) {
+	require.NoError(t, Register("testexcept", ProviderFunc(func(ctx context.Context, path string) (string, error) {
+		return "value of " + path, nil
+	})))
+
+	out, err := ReplaceExcept(context.Background(), []byte(`a: ${secret:testexcept:foo}`), "file")
+	require.NoError(t, err)
+	assert.Equal(t, `a: value of foo`, string(out))
+
+	_, err = ReplaceExcept(context.Background(), []byte(`a: ${secret:file:foo}`), "file")
+	require.EqualError(t, err, "secret provider 'file' is not permitted within this config")
+}
+
+func TestEncryptedFileProvider(t *testing.T) {
+	key, err := NewMasterKey()
+	require.NoError(t, err)
+
+	encBytes, err := Encrypt([]byte(key), []byte(`
+db:
+  password: hunter2
+  port: 5432
+`))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encBytes), "hunter2")
+
+	_, err = Decrypt([]byte("wrong key"), encBytes)
+	require.Error(t, err)
+
+	secretsPath := filepath.Join(t.TempDir(), "secrets.enc")
+	require.NoError(t, os.WriteFile(secretsPath, encBytes, 0o600))
+
+	p := NewEncryptedFileProvider(secretsPath, []byte(key+"\n"))
+
+	v, err := p.Lookup(context.Background(), "db.password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	v, err = p.Lookup(context.Background(), "db.port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", v)
+
+	_, err = p.Lookup(context.Background(), "db.nope")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = p.Lookup(context.Background(), "db")
+	require.Error(t, err)
+
+	_, err = NewEncryptedFileProvider(secretsPath, []byte("wrong key")).Lookup(context.Background(), "db.password")
+	require.Error(t, err)
+}
+
+func TestReplaceResolvable(t *testing.T) {
+	require.NoError(t, Register("testresolvable", ProviderFunc(func(ctx context.Context, path string) (string, error) {
+		if path == "nope" {
+			return "", ErrNotFound
+		}
+		return "value of " + path, nil
+	})))
+
+	out, errs := ReplaceResolvable(context.Background(), []byte(`a: ${secret:testresolvable:foo}
+b: ${secret:testresolvable:nope}
+c: ${secret:testresolvable:nope}`))
+	assert.Equal(t, `a: value of foo
+b: ${secret:testresolvable:nope}
+c: ${secret:testresolvable:nope}`, string(out))
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.ErrorIs(t, errs[0], ErrNotFound)
+	assert.Equal(t, 3, errs[1].Line)
+}
+
+func TestRegistryIsolation(t *testing.T) {
+	reg := GlobalRegistry().Clone()
+	require.NoError(t, reg.Register("testisolated", ProviderFunc(func(ctx context.Context, path string) (string, error) {
+		return "isolated " + path, nil
+	})))
+
+	assert.Contains(t, reg.Providers(), "file")
+	assert.Contains(t, reg.Providers(), "testisolated")
+	assert.NotContains(t, Providers(), "testisolated")
+
+	out, err := reg.Replace(context.Background(), []byte(`foo: ${secret:testisolated:bar}`))
+	require.NoError(t, err)
+	assert.Equal(t, `foo: isolated bar`, string(out))
+
+	_, err = Replace(context.Background(), []byte(`foo: ${secret:testisolated:bar}`))
+	require.Error(t, err)
+
+	assert.Empty(t, NewRegistry().Providers())
+}
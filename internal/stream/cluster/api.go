@@ -0,0 +1,661 @@
+package cluster
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/stream"
+	"github.com/benthosdev/benthos/v4/internal/stream/manager"
+)
+
+// forwardedHeader is set on requests forwarded from one node to another in
+// order to prevent them from being forwarded again. The value is a signature of
+// the request derived from the secret of the cluster, and requests with a
+// signature that does not match are treated as external requests.
+const forwardedHeader = "X-Benthos-Cluster-Forwarded"
+
+// forwardSignature returns the signature set on a request that is forwarded
+// to another node of the cluster.
+func (n *Node) forwardSignature(r *http.Request) string {
+	mac := hmac.New(sha256.New, []byte(n.conf.Secret))
+	_, _ = mac.Write([]byte(r.Method + " " + r.URL.Path))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// isForwarded returns whether a request was forwarded by another node of the
+// cluster. A forwarded header that fails authentication is removed from the
+// request so that it cannot reach the local handlers.
+func (n *Node) isForwarded(r *http.Request) bool {
+	sig := r.Header.Get(forwardedHeader)
+	if sig == "" {
+		return false
+	}
+	r.Header.Del(forwardedHeader)
+	return hmac.Equal([]byte(sig), []byte(n.forwardSignature(r)))
+}
+
+func (n *Node) registerEndpoints() {
+	n.mgr.RegisterEndpoint(
+		"/cluster",
+		"GET the members, leader and stream assignments of the cluster.",
+		n.HandleClusterStatus,
+	)
+	n.mgr.RegisterEndpoint(
+		"/cluster/streams",
+		"GET the streams running on this node of the cluster along with their status and uptimes.",
+		n.HandleLocalStreams,
+	)
+	if !n.apiEnabled {
+		return
+	}
+	n.mgr.RegisterEndpoint(
+		"/streams",
+		"GET: List all streams of the cluster along with their status, uptimes and assigned nodes."+
+			" POST: Post an object of stream ids to stream configs, all"+
+			" streams of the cluster will be replaced by this new set.",
+		n.HandleStreamsCRUD,
+	)
+	n.mgr.RegisterEndpoint(
+		"/streams/{id}",
+		"Perform CRUD operations on the streams of the cluster, supporting POST (Create),"+
+			" GET (Read), PUT (Update), PATCH (Patch update)"+
+			" and DELETE (Delete).",
+		n.HandleStreamCRUD,
+	)
+	n.mgr.RegisterEndpoint(
+		"/streams/{id}/stats",
+		"GET a structured JSON object containing metrics for the stream from the node it is assigned to.",
+		n.HandleStreamForward,
+	)
+	n.mgr.RegisterEndpoint(
+		"/streams/{id}/tap/{label}",
+		"Attach a live tap to a component of a stream on the node it is assigned to.",
+		n.HandleStreamForward,
+	)
+	for _, action := range []string{"pause", "resume", "drain"} {
+		n.mgr.RegisterEndpoint(
+			"/streams/{id}/"+action,
+			stream.ControlDescriptions[action]+" The request is served by the node that the stream is assigned to.",
+			n.HandleStreamControl(action),
+		)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// owner returns the first live node that a stream is assigned to.
+func (n *Node) owner(id string) (Member, bool) {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+
+	for _, nodeID := range n.assigned.Streams[id] {
+		for _, m := range n.members {
+			if m.ID == nodeID {
+				return m, true
+			}
+		}
+	}
+	return Member{}, false
+}
+
+func (n *Node) isLocal(id string) bool {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+
+	_, exists := n.running[id]
+	return exists
+}
+
+// forward serves a request for a stream locally when the stream runs on this
+// node, or proxies it to the node that the stream is assigned to. Returns false
+// if the stream is not assigned to any live node.
+func (n *Node) forward(w http.ResponseWriter, r *http.Request, local http.HandlerFunc) bool {
+	id := mux.Vars(r)["id"]
+	if n.isForwarded(r) || n.isLocal(id) {
+		local(w, r)
+		return true
+	}
+
+	owner, exists := n.owner(id)
+	if !exists {
+		return false
+	}
+	if owner.ID == n.conf.NodeID {
+		local(w, r)
+		return true
+	}
+
+	target, err := url.Parse(owner.Address)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: failed to parse address of node '%v': %v", owner.ID, err), http.StatusBadGateway)
+		return true
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Header.Set(forwardedHeader, n.forwardSignature(r))
+	}
+	proxy.ServeHTTP(w, r)
+	return true
+}
+
+// HandleStreamForward is an http.HandleFunc that serves requests for a stream
+// from the node it is assigned to.
+func (n *Node) HandleStreamForward(w http.ResponseWriter, r *http.Request) {
+	local := func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/tap/") {
+			n.streams.HandleStreamTap(w, r)
+			return
+		}
+		n.streams.HandleStreamStats(w, r)
+	}
+	if !n.forward(w, r, local) {
+		http.Error(w, "Stream not found", http.StatusNotFound)
+	}
+}
+
+// HandleStreamControl returns an http.HandleFunc that performs a control action
+// (pause, resume or drain) on a stream from the node it is assigned to.
+func (n *Node) HandleStreamControl(action string) http.HandlerFunc {
+	local := n.streams.HandleStreamControl(action)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !n.forward(w, r, local) {
+			http.Error(w, "Stream not found", http.StatusNotFound)
+		}
+	}
+}
+
+// HandleClusterStatus is an http.HandleFunc for returning the members, leader
+// and stream assignments of the cluster as seen by this node.
+func (n *Node) HandleClusterStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+
+	n.mut.Lock()
+	resBytes, err := json.Marshal(struct {
+		Node    string              `json:"node"`
+		Leader  string              `json:"leader"`
+		Members []Member            `json:"members"`
+		Streams map[string][]string `json:"streams"`
+	}{
+		Node:    n.conf.NodeID,
+		Leader:  n.leader,
+		Members: n.members,
+		Streams: n.assigned.Streams,
+	})
+	n.mut.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
+
+type streamInfo struct {
+	Active    bool     `json:"active"`
+	Uptime    float64  `json:"uptime"`
+	UptimeStr string   `json:"uptime_str"`
+	Revision  int64    `json:"revision"`
+	Nodes     []string `json:"nodes"`
+}
+
+func (n *Node) localInfos() map[string]streamInfo {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+
+	infos := map[string]streamInfo{}
+	for id, rev := range n.running {
+		status, err := n.streams.Read(id)
+		if err != nil {
+			continue
+		}
+		infos[id] = streamInfo{
+			Active:    status.IsRunning(),
+			Uptime:    status.Uptime().Seconds(),
+			UptimeStr: status.Uptime().String(),
+			Revision:  rev,
+			Nodes:     []string{n.conf.NodeID},
+		}
+	}
+	return infos
+}
+
+// HandleLocalStreams is an http.HandleFunc for returning the streams running
+// on this node along with their status and uptimes.
+func (n *Node) HandleLocalStreams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+	resBytes, err := json.Marshal(n.localInfos())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
+
+// remoteInfos obtains the streams running on all other live nodes of the
+// cluster.
+func (n *Node) remoteInfos(ctx context.Context) []map[string]streamInfo {
+	n.mut.Lock()
+	members := n.members
+	n.mut.Unlock()
+
+	var wg sync.WaitGroup
+	results := make([]map[string]streamInfo, len(members))
+	for i, m := range members {
+		if m.ID == n.conf.NodeID {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, m Member) {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(m.Address, "/")+"/cluster/streams", http.NoBody)
+			if err != nil {
+				return
+			}
+			res, err := n.client.Do(req)
+			if err != nil {
+				n.log.Warnf("Failed to obtain streams of node '%v': %v\n", m.ID, err)
+				return
+			}
+			defer res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				n.log.Warnf("Failed to obtain streams of node '%v': status %v\n", m.ID, res.StatusCode)
+				return
+			}
+			_ = json.NewDecoder(res.Body).Decode(&results[i])
+		}(i, m)
+	}
+	wg.Wait()
+	return results
+}
+
+//------------------------------------------------------------------------------
+
+// readConfig reads a stream config from the body of a request, returning the
+// config in its raw form, which is stored within the registry so that
+// environment variables are resolved by the nodes that run the stream.
+func (n *Node) readConfig(r *http.Request) (raw []byte, lints []string, err error) {
+	if raw, err = io.ReadAll(r.Body); err != nil {
+		return
+	}
+
+	var resolved []byte
+	if resolved, err = n.streams.ResolveAPIConfig(raw); err != nil {
+		return
+	}
+
+	var node yaml.Node
+	if err = yaml.Unmarshal(resolved, &node); err != nil {
+		return
+	}
+	if r.URL.Query().Get("chilled") != "true" {
+		if lints = manager.LintStreamConfigNode(&node); len(lints) > 0 {
+			return
+		}
+	}
+	conf := stream.NewConfig()
+	err = node.Decode(&conf)
+	return
+}
+
+func writeLintErrors(w http.ResponseWriter, lints []string) {
+	errBytes, _ := json.Marshal(struct {
+		LintErrs []string `json:"lint_errors"`
+	}{
+		LintErrs: lints,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(errBytes)
+}
+
+var errConflict = errors.New("stream was modified concurrently, try again")
+
+// HandleStreamsCRUD is an http.HandleFunc for returning maps of the streams of
+// the cluster by their id, status, uptime and assigned nodes, or overwriting
+// the entire set of streams.
+func (n *Node) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			n.log.Errorf("Streams CRUD Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			n.log.Debugf("Streams request CRUD Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	ctx := r.Context()
+
+	var defs map[string]definition
+	if defs, serverErr = n.state.definitions(ctx); serverErr != nil {
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		n.mut.Lock()
+		assigned := n.assigned.Streams
+		n.mut.Unlock()
+
+		infos := map[string]streamInfo{}
+		for id, def := range defs {
+			infos[id] = streamInfo{
+				Revision: def.Revision,
+				Nodes:    assigned[id],
+			}
+		}
+		for _, nodeInfos := range append(n.remoteInfos(ctx), n.localInfos()) {
+			for id, remote := range nodeInfos {
+				info, exists := infos[id]
+				if !exists || info.Active {
+					continue
+				}
+				info.Active = remote.Active
+				info.Uptime = remote.Uptime
+				info.UptimeStr = remote.UptimeStr
+				infos[id] = info
+			}
+		}
+
+		var resBytes []byte
+		if resBytes, serverErr = json.Marshal(infos); serverErr == nil {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(resBytes)
+		}
+		return
+	case "POST":
+	default:
+		requestErr = errors.New("method not supported")
+		return
+	}
+
+	var setBytes []byte
+	if setBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		return
+	}
+
+	rawSet := map[string]yaml.Node{}
+	if requestErr = yaml.Unmarshal(setBytes, &rawSet); requestErr != nil {
+		return
+	}
+	var resolvedBytes []byte
+	if resolvedBytes, requestErr = n.streams.ResolveAPIConfig(setBytes); requestErr != nil {
+		return
+	}
+	resolvedSet := map[string]yaml.Node{}
+	if requestErr = yaml.Unmarshal(resolvedBytes, &resolvedSet); requestErr != nil {
+		return
+	}
+	if r.URL.Query().Get("chilled") != "true" {
+		var lints []string
+		for k, node := range resolvedSet {
+			node := node
+			for _, l := range manager.LintStreamConfigNode(&node) {
+				keyLint := fmt.Sprintf("stream '%v': %v", k, l)
+				lints = append(lints, keyLint)
+				n.log.Debugf("Streams request linting error: %v\n", keyLint)
+			}
+		}
+		if len(lints) > 0 {
+			writeLintErrors(w, lints)
+			return
+		}
+	}
+	if requestErr = yaml.Unmarshal(resolvedBytes, &manager.ConfigSet{}); requestErr != nil {
+		return
+	}
+
+	var errs []string
+	for id := range defs {
+		if _, exists := rawSet[id]; exists {
+			continue
+		}
+		if err := n.state.delete(ctx, n.state.streamKey(id)); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to delete stream: %v", err))
+		}
+	}
+	for id, node := range rawSet {
+		node := node
+		confBytes, err := yaml.Marshal(&node)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to serialise stream '%v': %v", id, err))
+			continue
+		}
+		if err := n.putDefinition(ctx, id, string(confBytes), false); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to write stream '%v': %v", id, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		requestErr = errors.New(strings.Join(errs, "\n"))
+	}
+}
+
+// putDefinition writes the config of a stream to the registry, incrementing its
+// revision if it already exists and the config has changed. When mustExist is
+// true manager.ErrStreamDoesNotExist is returned if the stream does not
+// already exist.
+func (n *Node) putDefinition(ctx context.Context, id, conf string, mustExist bool) error {
+	current, raw, err := n.state.definition(ctx, id)
+	if err != nil {
+		return err
+	}
+	if raw == nil && mustExist {
+		return manager.ErrStreamDoesNotExist
+	}
+	if raw != nil && current.Config == conf {
+		return nil
+	}
+	swapped, err := n.state.swap(ctx, n.state.streamKey(id), raw, definition{
+		Revision: current.Revision + 1,
+		Updated:  time.Now(),
+		Config:   conf,
+	})
+	if err != nil {
+		return err
+	}
+	if !swapped {
+		return errConflict
+	}
+	return nil
+}
+
+// Put writes the config of a stream to the registry of the cluster, creating
+// the stream if it does not already exist.
+func (n *Node) Put(ctx context.Context, id string, conf stream.Config) error {
+	sanit, err := conf.Sanitised()
+	if err != nil {
+		return err
+	}
+	confBytes, err := yaml.Marshal(sanit)
+	if err != nil {
+		return err
+	}
+	return n.putDefinition(ctx, id, string(confBytes), false)
+}
+
+// Remove deletes a stream from the registry of the cluster.
+func (n *Node) Remove(ctx context.Context, id string) error {
+	return n.state.delete(ctx, n.state.streamKey(id))
+}
+
+// HandleStreamCRUD is an http.HandleFunc for performing CRUD operations on
+// individual streams of the cluster.
+func (n *Node) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			n.log.Errorf("Streams CRUD Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			n.log.Debugf("Streams request CRUD Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	var current definition
+	var currentRaw []byte
+	if current, currentRaw, serverErr = n.state.definition(ctx, id); serverErr != nil {
+		return
+	}
+
+	var raw []byte
+	var lints []string
+	switch r.Method {
+	case "POST":
+		if raw, lints, requestErr = n.readConfig(r); requestErr != nil {
+			return
+		}
+		if len(lints) > 0 {
+			writeLintErrors(w, lints)
+			return
+		}
+		if currentRaw != nil {
+			serverErr = manager.ErrStreamExists
+			break
+		}
+		var swapped bool
+		if swapped, serverErr = n.state.swap(ctx, n.state.streamKey(id), nil, definition{
+			Revision: 1,
+			Updated:  time.Now(),
+			Config:   string(raw),
+		}); serverErr == nil && !swapped {
+			serverErr = manager.ErrStreamExists
+		}
+	case "GET":
+		if n.forward(w, r, n.streams.HandleStreamCRUD) {
+			return
+		}
+		if currentRaw == nil {
+			serverErr = manager.ErrStreamDoesNotExist
+			break
+		}
+
+		// The stream is not yet running on any node, so we return its
+		// config from the registry.
+		var conf stream.Config
+		if conf, serverErr = parseDefinition(current, true); serverErr != nil {
+			return
+		}
+		sanit, _ := conf.Sanitised()
+
+		var bodyBytes []byte
+		if bodyBytes, serverErr = json.Marshal(struct {
+			Active    bool    `json:"active"`
+			Uptime    float64 `json:"uptime"`
+			UptimeStr string  `json:"uptime_str"`
+			Config    any     `json:"config"`
+		}{
+			UptimeStr: time.Duration(0).String(),
+			Config:    sanit,
+		}); serverErr != nil {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(bodyBytes)
+	case "PUT":
+		if raw, lints, requestErr = n.readConfig(r); requestErr != nil {
+			return
+		}
+		if len(lints) > 0 {
+			writeLintErrors(w, lints)
+			return
+		}
+		serverErr = n.putDefinition(ctx, id, string(raw), true)
+	case "DELETE":
+		if currentRaw == nil {
+			serverErr = manager.ErrStreamDoesNotExist
+			break
+		}
+		serverErr = n.Remove(ctx, id)
+	case "PATCH":
+		if currentRaw == nil {
+			serverErr = manager.ErrStreamDoesNotExist
+			break
+		}
+
+		// Environment variables are not resolved here in order to preserve
+		// references to them within the patched config.
+		var conf stream.Config
+		if conf, serverErr = parseDefinition(current, false); serverErr != nil {
+			return
+		}
+		var patchBytes []byte
+		if patchBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+			return
+		}
+		if conf, requestErr = manager.PatchConfig(conf, patchBytes); requestErr != nil {
+			return
+		}
+
+		// The sanitised form of the config only contains the fields of the
+		// components used, and therefore parses back into the same config.
+		var sanit any
+		if sanit, serverErr = conf.Sanitised(); serverErr != nil {
+			return
+		}
+		if raw, serverErr = yaml.Marshal(sanit); serverErr != nil {
+			return
+		}
+		serverErr = n.putDefinition(ctx, id, string(raw), true)
+	default:
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+	}
+
+	switch {
+	case errors.Is(serverErr, manager.ErrStreamDoesNotExist):
+		serverErr = nil
+		http.Error(w, "Stream not found", http.StatusNotFound)
+	case errors.Is(serverErr, manager.ErrStreamExists):
+		serverErr = nil
+		http.Error(w, "Stream already exists", http.StatusBadRequest)
+	case errors.Is(serverErr, errConflict):
+		serverErr = nil
+		http.Error(w, fmt.Sprintf("Error: %v", errConflict), http.StatusConflict)
+	}
+}
@@ -0,0 +1,51 @@
+package cluster
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// assign each stream to a number of replica nodes using rendezvous hashing,
+// which ensures that only the streams of nodes that join or leave the cluster
+// are moved.
+func assign(streams []string, nodes []string, replicas int) map[string][]string {
+	if replicas < 1 {
+		replicas = 1
+	}
+	if replicas > len(nodes) {
+		replicas = len(nodes)
+	}
+
+	type scored struct {
+		node  string
+		score uint64
+	}
+
+	res := make(map[string][]string, len(streams))
+	if replicas == 0 {
+		return res
+	}
+
+	scores := make([]scored, len(nodes))
+	for _, id := range streams {
+		for i, n := range nodes {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(id))
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write([]byte(n))
+			scores[i] = scored{node: n, score: h.Sum64()}
+		}
+		sort.Slice(scores, func(i, j int) bool {
+			if scores[i].score == scores[j].score {
+				return scores[i].node < scores[j].node
+			}
+			return scores[i].score > scores[j].score
+		})
+		owners := make([]string, replicas)
+		for i := range owners {
+			owners[i] = scores[i].node
+		}
+		res[id] = owners
+	}
+	return res
+}
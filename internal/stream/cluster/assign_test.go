@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssign(t *testing.T) {
+	var streams []string
+	for i := 0; i < 100; i++ {
+		streams = append(streams, fmt.Sprintf("stream%v", i))
+	}
+
+	before := assign(streams, []string{"a", "b", "c"}, 1)
+	counts := map[string]int{}
+	for _, s := range streams {
+		assert.Len(t, before[s], 1)
+		counts[before[s][0]]++
+	}
+	for _, n := range []string{"a", "b", "c"} {
+		assert.Greater(t, counts[n], 10, n)
+	}
+
+	// Only streams of the removed node are moved.
+	after := assign(streams, []string{"a", "c"}, 1)
+	for _, s := range streams {
+		if before[s][0] != "b" {
+			assert.Equal(t, before[s], after[s], s)
+		} else {
+			assert.NotEqual(t, "b", after[s][0], s)
+		}
+	}
+
+	replicated := assign(streams, []string{"a", "b", "c"}, 2)
+	for _, s := range streams {
+		assert.Len(t, replicated[s], 2)
+		assert.NotEqual(t, replicated[s][0], replicated[s][1])
+		assert.Equal(t, before[s][0], replicated[s][0])
+	}
+
+	assert.Len(t, assign(streams, []string{"a"}, 3)["stream0"], 1)
+	assert.Empty(t, assign(streams, nil, 1)["stream0"])
+}
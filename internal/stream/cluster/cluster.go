@@ -0,0 +1,446 @@
+// Package cluster provides a coordination layer that allows multiple Benthos
+// instances running in streams mode to share a registry of streams, where each
+// stream is assigned to one or more nodes of the cluster.
+//
+// Coordination is lease based and uses a cache resource as the shared state,
+// which must support atomic add and compare and swap operations as well as key
+// iteration. Each node periodically renews a membership lease, and a single
+// leader, elected by acquiring a separate lease, assigns streams to the live
+// nodes of the cluster. When a node stops renewing its lease its streams are
+// reassigned to the remaining nodes, and when the leader stops renewing its
+// lease another node takes over.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+	"github.com/benthosdev/benthos/v4/internal/stream/manager"
+)
+
+// Config describes how a node participates in a cluster.
+type Config struct {
+	// A unique identifier of the node within the cluster.
+	NodeID string
+
+	// The base URL at which other nodes of the cluster can reach the HTTP
+	// server of this node.
+	Address string
+
+	// The name of a cache resource holding the shared state of the cluster.
+	Cache string
+
+	// A secret shared by all nodes of the cluster, which is used in order to
+	// authenticate requests forwarded from one node to another.
+	Secret string
+
+	// A prefix added to all keys written to the cache, allowing multiple
+	// clusters to share a cache.
+	KeyPrefix string
+
+	// The number of nodes that each stream is assigned to.
+	Replicas int
+
+	// The interval at which the node renews its leases and reconciles the
+	// streams it is running with the cluster.
+	HeartbeatInterval time.Duration
+
+	// The period after which the lease of a node that has stopped renewing it
+	// expires. This must be greater than the heartbeat interval, and should
+	// account for clock skew between nodes.
+	LeaseTTL time.Duration
+}
+
+// NewConfig returns a cluster config with default values.
+func NewConfig() Config {
+	return Config{
+		KeyPrefix:         "benthos_cluster_",
+		Replicas:          1,
+		HeartbeatInterval: time.Second,
+		LeaseTTL:          time.Second * 5,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Node is a member of a cluster that runs the streams assigned to it within a
+// local stream manager, and serves the streams API for the entire cluster.
+type Node struct {
+	conf    Config
+	mgr     bundle.NewManagement
+	log     log.Modular
+	streams *manager.Type
+	state   *state
+	client  *http.Client
+
+	apiEnabled bool
+
+	// The revisions of the streams currently running on this node, and the
+	// revisions of streams that failed to start, which are not retried until
+	// they change.
+	running map[string]int64
+	failed  map[string]int64
+
+	lastHeartbeat time.Time
+	members       []Member
+	leader        string
+	assigned      assignment
+	mut           sync.Mutex
+
+	shutSig *shutdown.Signaller
+}
+
+// OptAPIEnabled sets whether the node registers API endpoints for CRUD
+// operations on the streams of the cluster. This is enabled by default.
+func OptAPIEnabled(b bool) func(*Node) {
+	return func(n *Node) {
+		n.apiEnabled = b
+	}
+}
+
+// New creates a node that joins a cluster and runs the streams assigned to it
+// within a stream manager, which should be created with its own API disabled.
+// The node begins participating in the cluster immediately.
+func New(mgr bundle.NewManagement, streams *manager.Type, conf Config, opts ...func(*Node)) (*Node, error) {
+	if conf.NodeID == "" {
+		return nil, errors.New("a cluster node id must be specified")
+	}
+	if conf.Address == "" {
+		return nil, errors.New("a cluster node address must be specified")
+	}
+	if conf.Secret == "" {
+		return nil, errors.New("a cluster secret must be specified")
+	}
+	if conf.HeartbeatInterval <= 0 {
+		return nil, errors.New("the cluster heartbeat interval must be greater than zero")
+	}
+	if conf.LeaseTTL <= conf.HeartbeatInterval {
+		return nil, errors.New("the cluster lease TTL must be greater than the heartbeat interval")
+	}
+	if !mgr.ProbeCache(conf.Cache) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", conf.Cache)
+	}
+
+	n := &Node{
+		conf:    conf,
+		mgr:     mgr,
+		log:     mgr.Logger(),
+		streams: streams,
+		state: &state{
+			access:    mgr.AccessCache,
+			cacheName: conf.Cache,
+			prefix:    conf.KeyPrefix,
+		},
+		client:     &http.Client{Timeout: conf.LeaseTTL},
+		apiEnabled: true,
+		running:    map[string]int64{},
+		failed:     map[string]int64{},
+		shutSig:    shutdown.NewSignaller(),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	// Ensure that the cache supports key iteration before we begin.
+	ctx, done := context.WithTimeout(context.Background(), conf.LeaseTTL)
+	defer done()
+	if err := n.state.withCache(ctx, func(c cache.V1) error {
+		return c.IterKeys(ctx, conf.KeyPrefix, func(string) error { return nil })
+	}); err != nil {
+		return nil, fmt.Errorf("cache resource '%v' cannot be used for clustering: %w", conf.Cache, err)
+	}
+
+	n.registerEndpoints()
+	go n.loop()
+	return n, nil
+}
+
+func (n *Node) loop() {
+	defer n.shutSig.ShutdownComplete()
+
+	ticker := time.NewTicker(n.conf.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		n.tick()
+		select {
+		case <-ticker.C:
+		case <-n.shutSig.CloseAtLeisureChan():
+			return
+		}
+	}
+}
+
+func (n *Node) tick() {
+	ctx, done := n.shutSig.CloseAtLeisureCtx(context.Background())
+	defer done()
+
+	now := time.Now()
+	if err := n.heartbeat(ctx, now); err != nil {
+		n.log.Errorf("Failed to renew cluster membership: %v\n", err)
+
+		// If we have been unable to renew our lease for long enough that it
+		// has expired then our streams are likely to have been reassigned to
+		// other nodes, and therefore we stop them in order to avoid running
+		// duplicates.
+		if now.Sub(n.lastHeartbeat) >= n.conf.LeaseTTL {
+			n.reconcile(ctx, nil)
+		}
+		return
+	}
+	n.lastHeartbeat = now
+
+	live, expired, err := n.state.members(ctx, now)
+	if err != nil {
+		n.log.Errorf("Failed to read cluster members: %v\n", err)
+		return
+	}
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].ID < live[j].ID
+	})
+
+	leader, err := n.elect(ctx, now)
+	if err != nil {
+		n.log.Errorf("Failed to elect cluster leader: %v\n", err)
+	}
+
+	n.mut.Lock()
+	if leader != n.leader && leader != "" {
+		n.log.Infof("Cluster leader is now node '%v'\n", leader)
+		n.leader = leader
+	}
+	n.members = live
+	n.mut.Unlock()
+
+	defs, err := n.state.definitions(ctx)
+	if err != nil {
+		n.log.Errorf("Failed to read cluster streams: %v\n", err)
+		return
+	}
+
+	if leader == n.conf.NodeID {
+		if err := n.lead(ctx, now, live, expired, defs); err != nil {
+			n.log.Errorf("Failed to assign cluster streams: %v\n", err)
+		}
+	}
+
+	assigned, err := n.state.assignment(ctx)
+	if err != nil {
+		n.log.Errorf("Failed to read cluster stream assignments: %v\n", err)
+		return
+	}
+
+	n.mut.Lock()
+	n.assigned = assigned
+	n.mut.Unlock()
+
+	desired := map[string]definition{}
+	for id, nodes := range assigned.Streams {
+		if !containsNode(nodes, n.conf.NodeID) {
+			continue
+		}
+		if def, exists := defs[id]; exists {
+			desired[id] = def
+		}
+	}
+	n.reconcile(ctx, desired)
+}
+
+func (n *Node) heartbeat(ctx context.Context, now time.Time) error {
+	return n.state.set(ctx, n.state.memberKey(n.conf.NodeID), Member{
+		ID:      n.conf.NodeID,
+		Address: n.conf.Address,
+		Expires: now.Add(n.conf.LeaseTTL),
+	}, nil)
+}
+
+// elect attempts to acquire or renew the leadership lease and returns the
+// identifier of the current leader.
+func (n *Node) elect(ctx context.Context, now time.Time) (string, error) {
+	var current lease
+	raw, err := n.state.get(ctx, n.state.leaderKey(), &current)
+	if err != nil {
+		return "", err
+	}
+	if raw != nil && current.Node != n.conf.NodeID && current.Expires.After(now) {
+		return current.Node, nil
+	}
+
+	acquired, err := n.state.swap(ctx, n.state.leaderKey(), raw, lease{
+		Node:    n.conf.NodeID,
+		Expires: now.Add(n.conf.LeaseTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	if acquired {
+		return n.conf.NodeID, nil
+	}
+
+	// Another node acquired the lease before us.
+	if _, err := n.state.get(ctx, n.state.leaderKey(), &current); err != nil {
+		return "", err
+	}
+	return current.Node, nil
+}
+
+// lead assigns all streams to the live nodes of the cluster, and removes the
+// members of nodes whose leases expired long ago.
+func (n *Node) lead(ctx context.Context, now time.Time, live, expired []Member, defs map[string]definition) error {
+	for _, m := range expired {
+		if now.Sub(m.Expires) > n.conf.LeaseTTL {
+			if err := n.state.delete(ctx, n.state.memberKey(m.ID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	streamIDs := make([]string, 0, len(defs))
+	for id := range defs {
+		streamIDs = append(streamIDs, id)
+	}
+	nodeIDs := make([]string, 0, len(live))
+	for _, m := range live {
+		nodeIDs = append(nodeIDs, m.ID)
+	}
+
+	next := assignment{
+		Leader:  n.conf.NodeID,
+		Streams: assign(streamIDs, nodeIDs, n.conf.Replicas),
+	}
+
+	current, err := n.state.assignment(ctx)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(current, next) {
+		return nil
+	}
+	return n.state.set(ctx, n.state.assignmentKey(), next, nil)
+}
+
+// reconcile the streams running on this node with those desired.
+func (n *Node) reconcile(ctx context.Context, desired map[string]definition) {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+
+	for id := range n.running {
+		if _, exists := desired[id]; exists {
+			continue
+		}
+		if err := n.streams.Delete(ctx, id); err != nil && !errors.Is(err, manager.ErrStreamDoesNotExist) {
+			n.log.Errorf("Failed to stop stream '%v': %v\n", id, err)
+			continue
+		}
+		n.log.Infof("Stopped stream '%v' as it is no longer assigned to this node\n", id)
+		delete(n.running, id)
+	}
+	for id := range n.failed {
+		if _, exists := desired[id]; !exists {
+			delete(n.failed, id)
+		}
+	}
+
+	for id, def := range desired {
+		if rev, exists := n.running[id]; exists && rev == def.Revision {
+			continue
+		}
+		if rev, exists := n.failed[id]; exists && rev == def.Revision {
+			continue
+		}
+
+		conf, err := parseDefinition(def, true)
+		if err == nil {
+			if _, exists := n.running[id]; exists {
+				if err = n.streams.Update(ctx, id, conf); errors.Is(err, manager.ErrStreamDoesNotExist) {
+					err = n.streams.Create(id, conf)
+				}
+			} else {
+				if err = n.streams.Create(id, conf); errors.Is(err, manager.ErrStreamExists) {
+					err = n.streams.Update(ctx, id, conf)
+				}
+			}
+		}
+		if err != nil {
+			n.log.Errorf("Failed to run stream '%v' revision %v: %v\n", id, def.Revision, err)
+			n.failed[id] = def.Revision
+			if _, rerr := n.streams.Read(id); rerr != nil {
+				delete(n.running, id)
+			}
+			continue
+		}
+
+		n.log.Infof("Running stream '%v' revision %v\n", id, def.Revision)
+		n.running[id] = def.Revision
+		delete(n.failed, id)
+	}
+}
+
+// Stop the node from participating in the cluster, which relinquishes its
+// streams to the remaining nodes, and stops all streams running on it.
+func (n *Node) Stop(ctx context.Context) error {
+	n.shutSig.CloseAtLeisure()
+	select {
+	case <-n.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Removing our membership and releasing leadership allows the remaining
+	// nodes to take over our streams without waiting for our leases to
+	// expire.
+	if err := n.state.delete(ctx, n.state.memberKey(n.conf.NodeID)); err != nil {
+		n.log.Errorf("Failed to leave cluster: %v\n", err)
+	}
+	var current lease
+	if raw, err := n.state.get(ctx, n.state.leaderKey(), &current); err == nil && raw != nil && current.Node == n.conf.NodeID {
+		_, _ = n.state.swap(ctx, n.state.leaderKey(), raw, lease{})
+	}
+
+	n.mut.Lock()
+	n.running = map[string]int64{}
+	n.mut.Unlock()
+	return n.streams.Stop(ctx)
+}
+
+//------------------------------------------------------------------------------
+
+func containsNode(nodes []string, id string) bool {
+	for _, n := range nodes {
+		if n == id {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDefinition parses the config of a stream definition, optionally
+// replacing secret and environment variable references with their values.
+func parseDefinition(def definition, resolveEnv bool) (stream.Config, error) {
+	confBytes := []byte(def.Config)
+	if resolveEnv {
+		var err error
+		if confBytes, err = config.ReplaceSecretsAndEnvVariables(confBytes); err != nil {
+			return stream.NewConfig(), err
+		}
+	}
+	conf := stream.NewConfig()
+	if err := yaml.Unmarshal(confBytes, &conf); err != nil {
+		return conf, fmt.Errorf("failed to parse stream config: %w", err)
+	}
+	return conf, nil
+}
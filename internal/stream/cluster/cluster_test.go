@@ -0,0 +1,383 @@
+package cluster_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	bmanager "github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/stream/cluster"
+	"github.com/benthosdev/benthos/v4/internal/stream/manager"
+
+	_ "github.com/benthosdev/benthos/v4/public/components/pure"
+)
+
+// sharedCacheMgr provides each node with its own resources while sharing the
+// cache holding the state of the cluster, emulating a shared cache service.
+type sharedCacheMgr struct {
+	*bmanager.Type
+	shared *bmanager.Type
+}
+
+func (s sharedCacheMgr) ProbeCache(name string) bool {
+	return s.shared.ProbeCache(name)
+}
+
+func (s sharedCacheMgr) AccessCache(ctx context.Context, name string, fn func(cache.V1)) error {
+	return s.shared.AccessCache(ctx, name, fn)
+}
+
+// routerReg allows endpoints to be registered while requests are being
+// served, as streams register endpoints when they are created.
+type routerReg struct {
+	mut sync.RWMutex
+	r   *mux.Router
+}
+
+func (r *routerReg) RegisterEndpoint(path, desc string, h http.HandlerFunc) {
+	r.mut.Lock()
+	r.r.HandleFunc(path, h)
+	r.mut.Unlock()
+}
+
+func (r *routerReg) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mut.RLock()
+	var match mux.RouteMatch
+	matched := r.r.Match(req, &match)
+	r.mut.RUnlock()
+	if !matched {
+		http.NotFound(w, req)
+		return
+	}
+	match.Handler.ServeHTTP(w, mux.SetURLVars(req, match.Vars))
+}
+
+type testNode struct {
+	id     string
+	node   *cluster.Node
+	server *httptest.Server
+}
+
+func (n *testNode) do(t testing.TB, verb, path string, body any) (int, []byte) {
+	t.Helper()
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(verb, n.server.URL+path, reqBody)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, resBody
+}
+
+func (n *testNode) status(t testing.TB) (s struct {
+	Leader  string              `json:"leader"`
+	Streams map[string][]string `json:"streams"`
+},
+) {
+	t.Helper()
+	code, body := n.do(t, "GET", "/cluster", nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+	require.NoError(t, json.Unmarshal(body, &s))
+	return
+}
+
+func (n *testNode) stop(t testing.TB) {
+	t.Helper()
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.NoError(t, n.node.Stop(ctx))
+	n.server.Close()
+}
+
+func startNodes(t testing.TB, replicas int, ids ...string) map[string]*testNode {
+	t.Helper()
+
+	sharedConf := bmanager.NewResourceConfig()
+	stateCache := cache.NewConfig()
+	stateCache.Label = "state"
+	sharedConf.ResourceCaches = append(sharedConf.ResourceCaches, stateCache)
+
+	shared, err := bmanager.New(sharedConf)
+	require.NoError(t, err)
+
+	nodes := map[string]*testNode{}
+	for _, id := range ids {
+		router := &routerReg{r: mux.NewRouter()}
+		server := httptest.NewServer(router)
+
+		res, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetAPIReg(router))
+		require.NoError(t, err)
+
+		mgr := sharedCacheMgr{Type: res, shared: shared}
+
+		conf := cluster.NewConfig()
+		conf.NodeID = id
+		conf.Address = server.URL
+		conf.Cache = "state"
+		conf.Secret = "meow"
+		conf.Replicas = replicas
+		conf.HeartbeatInterval = time.Millisecond * 20
+		conf.LeaseTTL = time.Millisecond * 200
+
+		n, err := cluster.New(mgr, manager.New(mgr, manager.OptAPIEnabled(false)), conf)
+		require.NoError(t, err)
+
+		tn := &testNode{id: id, node: n, server: server}
+		nodes[id] = tn
+		t.Cleanup(func() {
+			ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+			defer done()
+			_ = tn.node.Stop(ctx)
+			tn.server.Close()
+		})
+	}
+	return nodes
+}
+
+func streamConf() any {
+	return map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "meow"`,
+				"interval": "10ms",
+			},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	}
+}
+
+func TestClusterFailover(t *testing.T) {
+	nodes := startNodes(t, 1, "a", "b", "c")
+
+	code, body := nodes["a"].do(t, "POST", "/streams/foo", streamConf())
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	code, body = nodes["b"].do(t, "POST", "/streams/foo", streamConf())
+	require.Equal(t, http.StatusBadRequest, code, string(body))
+
+	var owner string
+	require.Eventually(t, func() bool {
+		owners := nodes["c"].status(t).Streams["foo"]
+		if len(owners) != 1 {
+			return false
+		}
+		owner = owners[0]
+		return true
+	}, time.Second*5, time.Millisecond*10)
+
+	// The stream can be read from any node.
+	for id, n := range nodes {
+		assert.Eventually(t, func() bool {
+			code, body := n.do(t, "GET", "/streams/foo", nil)
+			if code != http.StatusOK {
+				return false
+			}
+			var info struct {
+				Active bool `json:"active"`
+			}
+			require.NoError(t, json.Unmarshal(body, &info))
+			return info.Active
+		}, time.Second*5, time.Millisecond*10, id)
+	}
+
+	code, body = nodes["b"].do(t, "GET", "/streams", nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	var list map[string]struct {
+		Active   bool     `json:"active"`
+		Revision int64    `json:"revision"`
+		Nodes    []string `json:"nodes"`
+	}
+	require.NoError(t, json.Unmarshal(body, &list))
+	require.Contains(t, list, "foo")
+	assert.True(t, list["foo"].Active)
+	assert.Equal(t, int64(1), list["foo"].Revision)
+	assert.Equal(t, []string{owner}, list["foo"].Nodes)
+
+	// When the owner leaves the stream is moved to another node.
+	nodes[owner].stop(t)
+	delete(nodes, owner)
+
+	var survivor *testNode
+	for _, n := range nodes {
+		survivor = n
+	}
+	require.Eventually(t, func() bool {
+		s := survivor.status(t)
+		return len(s.Streams["foo"]) == 1 && s.Streams["foo"][0] != owner && s.Leader != owner
+	}, time.Second*5, time.Millisecond*10)
+
+	assert.Eventually(t, func() bool {
+		code, _ := survivor.do(t, "GET", "/streams/foo/stats", nil)
+		return code == http.StatusOK
+	}, time.Second*5, time.Millisecond*10)
+
+	code, body = survivor.do(t, "DELETE", "/streams/foo", nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	code, body = survivor.do(t, "DELETE", "/streams/foo", nil)
+	require.Equal(t, http.StatusNotFound, code, string(body))
+
+	assert.Eventually(t, func() bool {
+		for _, n := range nodes {
+			code, body := n.do(t, "GET", "/cluster/streams", nil)
+			if code != http.StatusOK || string(body) != "{}" {
+				return false
+			}
+		}
+		return true
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestClusterUpdates(t *testing.T) {
+	nodes := startNodes(t, 2, "a", "b", "c")
+
+	code, body := nodes["a"].do(t, "PUT", "/streams/foo", streamConf())
+	require.Equal(t, http.StatusNotFound, code, string(body))
+
+	code, body = nodes["a"].do(t, "POST", "/streams", map[string]any{
+		"foo": streamConf(),
+		"bar": streamConf(),
+	})
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	localRevisions := func(n *testNode) map[string]int64 {
+		code, body := n.do(t, "GET", "/cluster/streams", nil)
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		var infos map[string]struct {
+			Revision int64 `json:"revision"`
+		}
+		require.NoError(t, json.Unmarshal(body, &infos))
+
+		revs := map[string]int64{}
+		for k, v := range infos {
+			revs[k] = v.Revision
+		}
+		return revs
+	}
+
+	// Each stream runs on two nodes.
+	require.Eventually(t, func() bool {
+		counts := map[string]int{}
+		for _, n := range nodes {
+			for id := range localRevisions(n) {
+				counts[id]++
+			}
+		}
+		return counts["foo"] == 2 && counts["bar"] == 2
+	}, time.Second*5, time.Millisecond*10)
+
+	code, body = nodes["b"].do(t, "PATCH", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping":  `root = "woof"`,
+				"interval": "10ms",
+			},
+		},
+	})
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	require.Eventually(t, func() bool {
+		count := 0
+		for _, n := range nodes {
+			if localRevisions(n)["foo"] == 2 {
+				count++
+			}
+		}
+		return count == 2
+	}, time.Second*5, time.Millisecond*10)
+
+	assert.Eventually(t, func() bool {
+		code, body := nodes["c"].do(t, "GET", "/streams/foo", nil)
+		if code != http.StatusOK {
+			return false
+		}
+		var info struct {
+			Config struct {
+				Input struct {
+					Generate struct {
+						Mapping string `json:"mapping"`
+					} `json:"generate"`
+				} `json:"input"`
+			} `json:"config"`
+		}
+		require.NoError(t, json.Unmarshal(body, &info))
+		return info.Config.Input.Generate.Mapping == `root = "woof"`
+	}, time.Second*5, time.Millisecond*10)
+
+	// Replacing the set removes streams that are not present.
+	code, body = nodes["c"].do(t, "POST", "/streams", map[string]any{
+		"bar": streamConf(),
+	})
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	require.Eventually(t, func() bool {
+		for _, n := range nodes {
+			revs := localRevisions(n)
+			if _, exists := revs["foo"]; exists {
+				return false
+			}
+		}
+		return true
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestClusterForwardedHeaderFromClients(t *testing.T) {
+	nodes := startNodes(t, 1, "a", "b")
+
+	code, body := nodes["a"].do(t, "POST", "/streams/foo", streamConf())
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	var owner string
+	require.Eventually(t, func() bool {
+		owners := nodes["a"].status(t).Streams["foo"]
+		if len(owners) != 1 {
+			return false
+		}
+		owner = owners[0]
+		return true
+	}, time.Second*5, time.Millisecond*10)
+
+	other := nodes["a"]
+	if owner == "a" {
+		other = nodes["b"]
+	}
+
+	// A forwarded header set by a client is not trusted, and so the request is
+	// still forwarded to the node running the stream rather than being served
+	// by the node that received it.
+	assert.Eventually(t, func() bool {
+		req, err := http.NewRequest("GET", other.server.URL+"/streams/foo/stats", http.NoBody)
+		require.NoError(t, err)
+		req.Header.Set("X-Benthos-Cluster-Forwarded", owner)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, time.Second*5, time.Millisecond*10)
+}
@@ -0,0 +1,199 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+)
+
+// Member describes a node that is participating in a cluster.
+type Member struct {
+	ID      string    `json:"id"`
+	Address string    `json:"address"`
+	Expires time.Time `json:"expires"`
+}
+
+// lease describes the current leader of a cluster.
+type lease struct {
+	Node    string    `json:"node"`
+	Expires time.Time `json:"expires"`
+}
+
+// definition is the config of a stream within the shared registry of a
+// cluster, along with a revision that is incremented each time it changes.
+type definition struct {
+	Revision int64     `json:"revision"`
+	Updated  time.Time `json:"updated"`
+	Config   string    `json:"config"`
+}
+
+// assignment describes which nodes each stream of a cluster is assigned to,
+// and is written by the leader.
+type assignment struct {
+	Leader  string              `json:"leader"`
+	Streams map[string][]string `json:"streams"`
+}
+
+// state provides access to the shared state of a cluster held within a cache
+// resource.
+type state struct {
+	access    func(ctx context.Context, name string, fn func(cache.V1)) error
+	cacheName string
+	prefix    string
+}
+
+func (s *state) memberKey(id string) string {
+	return s.prefix + "member_" + id
+}
+
+func (s *state) streamKey(id string) string {
+	return s.prefix + "stream_" + id
+}
+
+func (s *state) leaderKey() string {
+	return s.prefix + "leader"
+}
+
+func (s *state) assignmentKey() string {
+	return s.prefix + "assignment"
+}
+
+func (s *state) withCache(ctx context.Context, fn func(c cache.V1) error) (err error) {
+	if cerr := s.access(ctx, s.cacheName, func(c cache.V1) {
+		err = fn(c)
+	}); cerr != nil {
+		return cerr
+	}
+	return
+}
+
+// get a key and decode its JSON value into v, returning the raw value, or nil
+// if the key does not exist.
+func (s *state) get(ctx context.Context, key string, v any) (raw []byte, err error) {
+	err = s.withCache(ctx, func(c cache.V1) (gerr error) {
+		raw, gerr = c.Get(ctx, key)
+		return
+	})
+	if err != nil {
+		if errors.Is(err, component.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return raw, json.Unmarshal(raw, v)
+}
+
+// list all keys beginning with a prefix and decode their values with fn.
+func (s *state) list(ctx context.Context, prefix string, fn func(key string, raw []byte) error) error {
+	return s.withCache(ctx, func(c cache.V1) error {
+		var keys []string
+		if err := c.IterKeys(ctx, prefix, func(key string) error {
+			keys = append(keys, key)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		values, err := c.GetMulti(ctx, keys...)
+		if err != nil {
+			return err
+		}
+		for k, v := range values {
+			if err := fn(strings.TrimPrefix(k, prefix), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *state) set(ctx context.Context, key string, v any, ttl *time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.withCache(ctx, func(c cache.V1) error {
+		return c.Set(ctx, key, data, ttl)
+	})
+}
+
+// swap sets the JSON value of a key only if its current value matches old, or
+// only if the key does not exist when old is nil. Returns false if the value
+// was modified concurrently.
+func (s *state) swap(ctx context.Context, key string, old []byte, v any) (bool, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	err = s.withCache(ctx, func(c cache.V1) error {
+		if old == nil {
+			return c.Add(ctx, key, data, nil)
+		}
+		return c.CompareAndSwap(ctx, key, old, data, nil)
+	})
+	if err != nil {
+		if errors.Is(err, component.ErrKeyAlreadyExists) ||
+			errors.Is(err, component.ErrKeyNotFound) ||
+			errors.Is(err, component.ErrKeyValueMismatch) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *state) delete(ctx context.Context, key string) error {
+	return s.withCache(ctx, func(c cache.V1) error {
+		return c.Delete(ctx, key)
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// members returns the nodes of the cluster split by whether their lease has
+// expired.
+func (s *state) members(ctx context.Context, now time.Time) (live, expired []Member, err error) {
+	err = s.list(ctx, s.memberKey(""), func(_ string, raw []byte) error {
+		var m Member
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return err
+		}
+		if m.Expires.After(now) {
+			live = append(live, m)
+		} else {
+			expired = append(expired, m)
+		}
+		return nil
+	})
+	return
+}
+
+func (s *state) definitions(ctx context.Context) (map[string]definition, error) {
+	defs := map[string]definition{}
+	err := s.list(ctx, s.streamKey(""), func(id string, raw []byte) error {
+		var d definition
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return err
+		}
+		defs[id] = d
+		return nil
+	})
+	return defs, err
+}
+
+func (s *state) definition(ctx context.Context, id string) (d definition, raw []byte, err error) {
+	raw, err = s.get(ctx, s.streamKey(id), &d)
+	return
+}
+
+func (s *state) assignment(ctx context.Context) (a assignment, err error) {
+	_, err = s.get(ctx, s.assignmentKey(), &a)
+	return
+}
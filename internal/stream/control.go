@@ -0,0 +1,102 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// State describes whether a stream is running, paused, drained or closed.
+type State string
+
+// Various states of a stream.
+var (
+	StateRunning State = "running"
+	StatePaused  State = "paused"
+	StateDrained State = "drained"
+	StateClosed  State = "closed"
+)
+
+// ErrDrained is returned when attempting to resume a stream that has been
+// drained, as a drained stream must be restarted.
+var ErrDrained = errors.New("stream has been drained and must be restarted")
+
+// ErrNotPausable is returned when attempting to pause a stream that was not
+// created with support for pausing.
+var ErrNotPausable = errors.New("stream does not support pausing")
+
+// Controller is a stream that can be paused, resumed and drained.
+type Controller interface {
+	// Pause stops the stream from consuming messages from its input, whilst
+	// keeping the input connected. Messages already consumed continue to be
+	// processed and delivered.
+	Pause() error
+
+	// Resume a paused or drained stream.
+	Resume(ctx context.Context) error
+
+	// Drain stops the input of a stream and blocks until all buffered and
+	// in-flight messages have been delivered to the output.
+	Drain(ctx context.Context) error
+
+	// State returns the current state of the stream.
+	State() State
+}
+
+// ControlDescriptions describes the endpoints used for controlling a stream by
+// their action.
+var ControlDescriptions = map[string]string{
+	"pause":  "POST: Stop consuming messages from the input whilst keeping it connected, messages already consumed continue to be processed and delivered.",
+	"resume": "POST: Resume consuming messages from the input after the stream has been paused or drained.",
+	"drain":  "POST: Stop the input and wait until all buffered and in-flight messages have been delivered to the output. The query parameter `timeout` specifies the maximum period to wait, which defaults to `30s`.",
+}
+
+// ServeControl performs a control action (pause, resume or drain) on a stream
+// and writes the resulting state of the stream as a JSON object.
+func ServeControl(w http.ResponseWriter, r *http.Request, c Controller, action string) {
+	if r.Method != "POST" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch action {
+	case "pause":
+		err = c.Pause()
+	case "resume":
+		err = c.Resume(r.Context())
+	case "drain":
+		timeout := time.Second * 30
+		if tStr := r.URL.Query().Get("timeout"); tStr != "" {
+			if timeout, err = time.ParseDuration(tStr); err != nil {
+				http.Error(w, fmt.Sprintf("Error: failed to parse timeout: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		ctx, done := context.WithTimeout(r.Context(), timeout)
+		err = c.Drain(ctx)
+		done()
+	default:
+		http.Error(w, fmt.Sprintf("Error: action not supported: %v", action), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, context.DeadlineExceeded) {
+			status = http.StatusGatewayTimeout
+		} else if errors.Is(err, ErrNotPausable) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("Error: %v", err), status)
+		return
+	}
+
+	resBytes, _ := json.Marshal(struct {
+		State State `json:"state"`
+	}{State: c.State()})
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
@@ -0,0 +1,127 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// pausableInput wraps an input and allows the consumption of transactions from
+// it to be paused. Whilst paused the input is blocked by back pressure, and
+// therefore stops reading from its source whilst keeping its connection.
+type pausableInput struct {
+	input.Streamed
+
+	outChan      chan message.Transaction
+	closeNowChan chan struct{}
+	closeNowOnce sync.Once
+
+	mut        sync.Mutex
+	resumeChan chan struct{} // Non-nil and open whilst paused
+	pauseChan  chan struct{} // Closed in order to interrupt a pending read
+	released   bool
+}
+
+func newPausableInput(in input.Streamed) *pausableInput {
+	p := &pausableInput{
+		Streamed:     in,
+		outChan:      make(chan message.Transaction),
+		closeNowChan: make(chan struct{}),
+		pauseChan:    make(chan struct{}),
+	}
+	go p.loop()
+	return p
+}
+
+func (p *pausableInput) loop() {
+	defer close(p.outChan)
+
+	inChan := p.Streamed.TransactionChan()
+	for {
+		p.mut.Lock()
+		resumeChan, pauseChan := p.resumeChan, p.pauseChan
+		p.mut.Unlock()
+
+		if resumeChan != nil {
+			<-resumeChan
+			continue
+		}
+
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-inChan:
+			if !open {
+				return
+			}
+		case <-pauseChan:
+			continue
+		}
+
+		// Once read a transaction is always forwarded, and a pause takes
+		// effect afterwards.
+		select {
+		case p.outChan <- tran:
+		case <-p.closeNowChan:
+			return
+		}
+	}
+}
+
+// Pause the consumption of transactions from the input, this is a no-op if the
+// input is already paused or has been instructed to shut down.
+func (p *pausableInput) Pause() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if p.resumeChan != nil || p.released {
+		return
+	}
+	p.resumeChan = make(chan struct{})
+	close(p.pauseChan)
+	p.pauseChan = make(chan struct{})
+}
+
+// Resume the consumption of transactions from the input.
+func (p *pausableInput) Resume() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if p.resumeChan != nil {
+		close(p.resumeChan)
+		p.resumeChan = nil
+	}
+}
+
+// Paused returns whether the input is currently paused.
+func (p *pausableInput) Paused() bool {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return p.resumeChan != nil
+}
+
+// release resumes the input permanently so that it can shut down.
+func (p *pausableInput) release() {
+	p.mut.Lock()
+	p.released = true
+	p.mut.Unlock()
+	p.Resume()
+}
+
+func (p *pausableInput) TransactionChan() <-chan message.Transaction {
+	return p.outChan
+}
+
+func (p *pausableInput) TriggerStopConsuming() {
+	p.release()
+	p.Streamed.TriggerStopConsuming()
+}
+
+func (p *pausableInput) TriggerCloseNow() {
+	p.release()
+	p.closeNowOnce.Do(func() {
+		close(p.closeNowChan)
+	})
+	p.Streamed.TriggerCloseNow()
+}
@@ -1,17 +1,21 @@
 package manager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bundle/tap"
 	"github.com/benthosdev/benthos/v4/internal/component/buffer"
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
@@ -21,6 +25,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/pipeline"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
 
@@ -54,6 +59,32 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 		"GET a structured JSON object containing metrics for the stream.",
 		m.HandleStreamStats,
 	)
+	for _, action := range []string{"pause", "resume", "drain"} {
+		m.manager.RegisterEndpoint(
+			"/streams/{id}/"+action,
+			stream.ControlDescriptions[action],
+			m.HandleStreamControl(action),
+		)
+	}
+	if m.store != nil {
+		m.manager.RegisterEndpoint(
+			"/streams/{id}/versions",
+			"GET the version history of a stream from the stream store.",
+			m.HandleStreamVersions,
+		)
+		m.manager.RegisterEndpoint(
+			"/streams/{id}/rollback",
+			"POST: Replace the config of a stream with a previous version from the stream store, specified with the query parameter `version`, which defaults to the version prior to the current version.",
+			m.HandleStreamRollback,
+		)
+	}
+	if m.taps != nil {
+		m.manager.RegisterEndpoint(
+			"/streams/{id}/tap/{label}",
+			tap.EndpointDescription,
+			m.HandleStreamTap,
+		)
+	}
 	m.manager.RegisterEndpoint(
 		"/resources/{type}/{id}",
 		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`.",
@@ -86,13 +117,24 @@ type lintErrors struct {
 	LintErrs []string `json:"lint_errors"`
 }
 
-func lintStreamConfigNode(node *yaml.Node) (lints []string) {
+// LintStreamConfigNode returns a list of linting errors for a stream config.
+func LintStreamConfigNode(node *yaml.Node) (lints []string) {
 	for _, dLint := range stream.Spec().LintYAML(docs.NewLintContext(), node) {
 		lints = append(lints, dLint.Error())
 	}
 	return
 }
 
+// ResolveAPIConfig replaces secret and environment variable references within a
+// config submitted via the API. References to secrets of the `file` provider
+// result in an error unless enabled with OptAPIFileSecrets.
+func (m *Type) ResolveAPIConfig(confBytes []byte) ([]byte, error) {
+	if m.apiFileSecrets {
+		return config.ReplaceSecretsAndEnvVariables(confBytes)
+	}
+	return config.ReplaceSecretsAndEnvVariablesExcept(confBytes, "file")
+}
+
 // HandleStreamsCRUD is an http.HandleFunc for returning maps of active benthos
 // streams by their id, status and uptime or overwriting the entire set of
 // streams.
@@ -157,7 +199,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 		}
 		var lints []string
 		for k, n := range nodeSet {
-			for _, l := range lintStreamConfigNode(&n) {
+			for _, l := range LintStreamConfigNode(&n) {
 				keyLint := fmt.Sprintf("stream '%v': %v", k, l)
 				lints = append(lints, keyLint)
 				m.manager.Logger().Debugf("Streams request linting error: %v\n", keyLint)
@@ -207,7 +249,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 
 	for i, id := range toDelete {
 		go func(sid string, j int) {
-			errDelete[j] = m.Delete(r.Context(), sid)
+			errDelete[j] = m.DeleteAndPersist(r.Context(), sid)
 			wg.Done()
 		}(id, i)
 	}
@@ -215,7 +257,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, conf := range toUpdate {
 		newConf := conf
 		go func(sid string, sconf *stream.Config, j int) {
-			errUpdate[j] = m.Update(r.Context(), sid, *sconf)
+			errUpdate[j] = m.UpdateAndPersist(r.Context(), sid, *sconf)
 			wg.Done()
 		}(id, &newConf, i)
 		i++
@@ -224,7 +266,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, conf := range toCreate {
 		newConf := conf
 		go func(sid string, sconf *stream.Config, j int) {
-			errCreate[j] = m.Create(sid, *sconf)
+			errCreate[j] = m.CreateAndPersist(r.Context(), sid, *sconf)
 			wg.Done()
 		}(id, &newConf, i)
 		i++
@@ -254,6 +296,38 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// PatchConfig applies a patch containing only changes to be made to a stream
+// config, where fields not present within the patch retain their existing
+// values.
+func PatchConfig(confIn stream.Config, patchBytes []byte) (confOut stream.Config, err error) {
+	type aliasedIn input.Config
+	type aliasedBuf buffer.Config
+	type aliasedPipe pipeline.Config
+	type aliasedOut output.Config
+
+	aliasedConf := struct {
+		Input    aliasedIn   `json:"input"`
+		Buffer   aliasedBuf  `json:"buffer"`
+		Pipeline aliasedPipe `json:"pipeline"`
+		Output   aliasedOut  `json:"output"`
+	}{
+		Input:    aliasedIn(confIn.Input),
+		Buffer:   aliasedBuf(confIn.Buffer),
+		Pipeline: aliasedPipe(confIn.Pipeline),
+		Output:   aliasedOut(confIn.Output),
+	}
+	if err = yaml.Unmarshal(patchBytes, &aliasedConf); err != nil {
+		return
+	}
+	confOut = stream.Config{
+		Input:    input.Config(aliasedConf.Input),
+		Buffer:   buffer.Config(aliasedConf.Buffer),
+		Pipeline: pipeline.Config(aliasedConf.Pipeline),
+		Output:   output.Config(aliasedConf.Output),
+	}
+	return
+}
+
 // HandleStreamCRUD is an http.HandleFunc for performing CRUD operations on
 // individual streams.
 func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
@@ -285,14 +359,16 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		if confBytes, err = io.ReadAll(r.Body); err != nil {
 			return
 		}
-		confBytes = config.ReplaceEnvVariables(confBytes)
+		if confBytes, err = m.ResolveAPIConfig(confBytes); err != nil {
+			return
+		}
 
 		if r.URL.Query().Get("chilled") != "true" {
 			var node yaml.Node
 			if err = yaml.Unmarshal(confBytes, &node); err != nil {
 				return
 			}
-			lints = lintStreamConfigNode(&node)
+			lints = LintStreamConfigNode(&node)
 			for _, l := range lints {
 				m.manager.Logger().Infof("Stream '%v' config: %v\n", id, l)
 			}
@@ -307,33 +383,7 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		if patchBytes, err = io.ReadAll(r.Body); err != nil {
 			return
 		}
-
-		type aliasedIn input.Config
-		type aliasedBuf buffer.Config
-		type aliasedPipe pipeline.Config
-		type aliasedOut output.Config
-
-		aliasedConf := struct {
-			Input    aliasedIn   `json:"input"`
-			Buffer   aliasedBuf  `json:"buffer"`
-			Pipeline aliasedPipe `json:"pipeline"`
-			Output   aliasedOut  `json:"output"`
-		}{
-			Input:    aliasedIn(confIn.Input),
-			Buffer:   aliasedBuf(confIn.Buffer),
-			Pipeline: aliasedPipe(confIn.Pipeline),
-			Output:   aliasedOut(confIn.Output),
-		}
-		if err = yaml.Unmarshal(patchBytes, &aliasedConf); err != nil {
-			return
-		}
-		confOut = stream.Config{
-			Input:    input.Config(aliasedConf.Input),
-			Buffer:   buffer.Config(aliasedConf.Buffer),
-			Pipeline: pipeline.Config(aliasedConf.Pipeline),
-			Output:   output.Config(aliasedConf.Output),
-		}
-		return
+		return PatchConfig(confIn, patchBytes)
 	}
 
 	var conf stream.Config
@@ -352,7 +402,7 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write(errBytes)
 			return
 		}
-		serverErr = m.Create(id, conf)
+		serverErr = m.CreateAndPersist(r.Context(), id, conf)
 	case "GET":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
@@ -360,12 +410,14 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 
 			var bodyBytes []byte
 			if bodyBytes, serverErr = json.Marshal(struct {
-				Active    bool    `json:"active"`
-				Uptime    float64 `json:"uptime"`
-				UptimeStr string  `json:"uptime_str"`
-				Config    any     `json:"config"`
+				Active    bool         `json:"active"`
+				State     stream.State `json:"state"`
+				Uptime    float64      `json:"uptime"`
+				UptimeStr string       `json:"uptime_str"`
+				Config    any          `json:"config"`
 			}{
 				Active:    info.IsRunning(),
+				State:     info.State(),
 				Uptime:    info.Uptime().Seconds(),
 				UptimeStr: info.Uptime().String(),
 				Config:    sanit,
@@ -374,7 +426,7 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			}
 
 			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write(bodyBytes)
+			_, _ = w.Write(secrets.RedactBytes(bodyBytes))
 		}
 	case "PUT":
 		if conf, lints, requestErr = readConfig(); requestErr != nil {
@@ -389,16 +441,16 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			_, _ = w.Write(errBytes)
 			return
 		}
-		serverErr = m.Update(r.Context(), id, conf)
+		serverErr = m.UpdateAndPersist(r.Context(), id, conf)
 	case "DELETE":
-		serverErr = m.Delete(r.Context(), id)
+		serverErr = m.DeleteAndPersist(r.Context(), id)
 	case "PATCH":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
 			if conf, requestErr = patchConfig(info.Config()); requestErr != nil {
 				return
 			}
-			serverErr = m.Update(r.Context(), id, conf)
+			serverErr = m.UpdateAndPersist(r.Context(), id, conf)
 		}
 	default:
 		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
@@ -505,7 +557,9 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 		if confBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
 			return
 		}
-		confBytes = config.ReplaceEnvVariables(confBytes)
+		if confBytes, requestErr = m.ResolveAPIConfig(confBytes); requestErr != nil {
+			return
+		}
 
 		var node yaml.Node
 		if requestErr = yaml.Unmarshal(confBytes, &node); requestErr != nil {
@@ -599,6 +653,178 @@ func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleStreamVersions is an http.HandleFunc for reading the version history of
+// a stream from the stream store.
+func (m *Type) HandleStreamVersions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+	if r.Method != "GET" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+
+	versions, err := m.Versions(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrStreamDoesNotExist) {
+			http.Error(w, "Stream not found", http.StatusNotFound)
+			return
+		}
+		m.manager.Logger().Errorf("Stream versions Error: %v\n", err)
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	type versionInfo struct {
+		Version   int    `json:"version"`
+		CreatedAt string `json:"created_at"`
+		Deleted   bool   `json:"deleted"`
+		Config    any    `json:"config,omitempty"`
+	}
+	infos := make([]versionInfo, 0, len(versions))
+	for _, v := range versions {
+		info := versionInfo{
+			Version:   v.Version,
+			CreatedAt: v.CreatedAt.Format(time.RFC3339Nano),
+			Deleted:   v.Deleted,
+		}
+		if !v.Deleted {
+			if conf, err := parseStoredConfig(v); err == nil {
+				info.Config, _ = conf.Sanitised()
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	resBytes, err := json.Marshal(infos)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
+
+// HandleStreamRollback is an http.HandleFunc for replacing the config of a
+// stream with a previous version from the stream store.
+func (m *Type) HandleStreamRollback(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+
+	var version int
+	if vStr := r.URL.Query().Get("version"); vStr != "" {
+		var err error
+		if version, err = strconv.Atoi(vStr); err != nil {
+			http.Error(w, fmt.Sprintf("Error: failed to parse version: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	v, err := m.Rollback(r.Context(), id, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrStreamDoesNotExist):
+			http.Error(w, "Stream not found", http.StatusNotFound)
+		case errors.Is(err, ErrVersionDoesNotExist):
+			http.Error(w, "Version not found", http.StatusNotFound)
+		default:
+			m.manager.Logger().Errorf("Stream rollback Error: %v\n", err)
+			http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		}
+		return
+	}
+
+	resBytes, _ := json.Marshal(struct {
+		Version int `json:"version"`
+	}{Version: v.Version})
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
+
+// HandleStreamTap is an http.HandleFunc for attaching a live tap to a labelled
+// component of a stream and streaming the messages it observes.
+func (m *Type) HandleStreamTap(w http.ResponseWriter, r *http.Request) {
+	id, label := mux.Vars(r)["id"], mux.Vars(r)["label"]
+	if id == "" || label == "" {
+		http.Error(w, "Vars `id` and `label` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := m.Read(id); err != nil {
+		if err == ErrStreamDoesNotExist {
+			http.Error(w, "Stream not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	m.taps.Serve(w, r, id, label)
+}
+
+// streamController exposes the control actions of a managed stream.
+type streamController struct {
+	m  *Type
+	id string
+}
+
+func (s streamController) Pause() error {
+	return s.m.Pause(s.id)
+}
+
+func (s streamController) Resume(ctx context.Context) error {
+	return s.m.Resume(ctx, s.id)
+}
+
+func (s streamController) Drain(ctx context.Context) error {
+	return s.m.Drain(ctx, s.id)
+}
+
+func (s streamController) State() stream.State {
+	wrapper, err := s.m.Read(s.id)
+	if err != nil {
+		return stream.StateClosed
+	}
+	return wrapper.State()
+}
+
+// HandleStreamControl returns an http.HandleFunc for performing a control
+// action (pause, resume or drain) on a stream.
+func (m *Type) HandleStreamControl(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		if id == "" {
+			http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := m.Read(id); err != nil {
+			if err == ErrStreamDoesNotExist {
+				http.Error(w, "Stream not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		stream.ServeControl(w, r, streamController{m: m, id: id}, action)
+	}
+}
+
 // HandleStreamReady is an http.HandleFunc for providing a ready check across
 // all streams.
 func (m *Type) HandleStreamReady(w http.ResponseWriter, r *http.Request) {
@@ -2,7 +2,9 @@ package manager_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,9 +22,12 @@ import (
 	"github.com/stretchr/testify/require"
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/bundle/tap"
 	bmanager "github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 	"github.com/benthosdev/benthos/v4/internal/stream/manager"
 
@@ -36,6 +41,12 @@ func router(m *manager.Type) *mux.Router {
 	router.HandleFunc("/streams", m.HandleStreamsCRUD)
 	router.HandleFunc("/streams/{id}", m.HandleStreamCRUD)
 	router.HandleFunc("/streams/{id}/stats", m.HandleStreamStats)
+	router.HandleFunc("/streams/{id}/tap/{label}", m.HandleStreamTap)
+	router.HandleFunc("/streams/{id}/versions", m.HandleStreamVersions)
+	router.HandleFunc("/streams/{id}/rollback", m.HandleStreamRollback)
+	router.HandleFunc("/streams/{id}/pause", m.HandleStreamControl("pause"))
+	router.HandleFunc("/streams/{id}/resume", m.HandleStreamControl("resume"))
+	router.HandleFunc("/streams/{id}/drain", m.HandleStreamControl("drain"))
 	router.HandleFunc("/resources/{type}/{id}", m.HandleResourceCRUD)
 	return router
 }
@@ -104,6 +115,7 @@ func parseListBody(data *bytes.Buffer) listBody {
 
 type getBody struct {
 	Active    bool    `json:"active"`
+	State     string  `json:"state"`
 	Uptime    float64 `json:"uptime"`
 	UptimeStr string  `json:"uptime_str"`
 	Config    any     `json:"config"`
@@ -980,3 +992,338 @@ output:
 		return response.Code == http.StatusServiceUnavailable
 	}, time.Second*10, time.Millisecond*50)
 }
+
+func TestTypeAPITap(t *testing.T) {
+	taps := tap.NewRegistry()
+
+	res, err := bmanager.New(
+		bmanager.NewResourceConfig(),
+		bmanager.OptSetEnvironment(tap.TappedBundle(bundle.GlobalEnvironment, taps)),
+	)
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptTapRegistry(taps))
+
+	server := httptest.NewServer(router(mgr))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/streams/foo/tap/bar")
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	request := genRequest("POST", "/streams/foo", map[string]any{
+		"input": map[string]any{
+			"label": "bar",
+			"generate": map[string]any{
+				"mapping":  `root.id = "meow"`,
+				"interval": "10ms",
+			},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	})
+	recorder := httptest.NewRecorder()
+	router(mgr).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	response, err = http.Get(server.URL + "/streams/foo/tap/bar?count=2")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	dec := json.NewDecoder(response.Body)
+	for i := 0; i < 2; i++ {
+		var e tap.Event
+		require.NoError(t, dec.Decode(&e))
+		assert.Equal(t, "foo", e.Stream)
+		assert.Equal(t, "bar", e.Label)
+		assert.Equal(t, tap.ComponentInput, e.Component)
+		assert.Equal(t, `{"id":"meow"}`, e.Content)
+	}
+
+	request = genRequest("DELETE", "/streams/foo", nil)
+	recorder = httptest.NewRecorder()
+	router(mgr).ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestTypeAPIVersions(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	storeDir := t.TempDir()
+	store, err := manager.NewStoreFromURI(res, "dir:"+storeDir)
+	require.NoError(t, err)
+
+	mgr := manager.New(res, manager.OptStore(store))
+	r := router(mgr)
+
+	confWithMapping := func(mapping string) any {
+		conf := harmlessConf()
+		_, _ = gabs.Wrap(conf).Set(mapping, "input", "generate", "mapping")
+		return conf
+	}
+
+	type versionBody struct {
+		Version int  `json:"version"`
+		Deleted bool `json:"deleted"`
+		Config  any  `json:"config"`
+	}
+	getVersions := func() (v []versionBody) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/foo/versions", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &v))
+		return
+	}
+	currentMapping := func() any {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/foo", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		return gabs.Wrap(parseGetBody(t, response.Body).Config).S("input", "generate", "mapping").Data()
+	}
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/foo/versions", nil))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo", confWithMapping("root = deleted()")))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("PUT", "/streams/foo", confWithMapping("# v2\nroot = deleted()")))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	versions := getVersions()
+	require.Len(t, versions, 2)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "root = deleted()", gabs.Wrap(versions[0].Config).S("input", "generate", "mapping").Data())
+	assert.Equal(t, 2, versions[1].Version)
+	assert.Equal(t, "# v2\nroot = deleted()", gabs.Wrap(versions[1].Config).S("input", "generate", "mapping").Data())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/rollback?version=5", nil))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/rollback", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, `{"version":3}`, response.Body.String())
+	assert.Equal(t, "root = deleted()", currentMapping())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("DELETE", "/streams/foo", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	versions = getVersions()
+	require.Len(t, versions, 4)
+	assert.True(t, versions[3].Deleted)
+
+	// A deleted stream can be brought back by rolling back to a version.
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo/rollback?version=2", nil))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "# v2\nroot = deleted()", currentMapping())
+
+	// Streams are restored from the store by a new manager.
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	require.NoError(t, mgr.Stop(ctx))
+
+	store, err = manager.NewStoreFromURI(res, "dir:"+storeDir)
+	require.NoError(t, err)
+
+	mgr = manager.New(res, manager.OptStore(store))
+	r = router(mgr)
+	require.NoError(t, mgr.Restore(ctx))
+	assert.Equal(t, "# v2\nroot = deleted()", currentMapping())
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+type failingStore struct {
+	manager.Store
+	fail bool
+}
+
+func (f *failingStore) Append(ctx context.Context, id string, v manager.StoredVersion) (manager.StoredVersion, error) {
+	if f.fail {
+		return manager.StoredVersion{}, errors.New("store is broken")
+	}
+	return f.Store.Append(ctx, id, v)
+}
+
+func TestTypeAPIPersistFailure(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	dirStore, err := manager.NewStoreFromURI(res, "dir:"+t.TempDir())
+	require.NoError(t, err)
+	store := &failingStore{Store: dirStore}
+
+	mgr := manager.New(res, manager.OptStore(store))
+	r := router(mgr)
+
+	confWithMapping := func(mapping string) any {
+		conf := harmlessConf()
+		_, _ = gabs.Wrap(conf).Set(mapping, "input", "generate", "mapping")
+		return conf
+	}
+	currentMapping := func() any {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/foo", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		return gabs.Wrap(parseGetBody(t, response.Body).Config).S("input", "generate", "mapping").Data()
+	}
+
+	// A stream that cannot be persisted is not created.
+	store.fail = true
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo", confWithMapping("root = deleted()")))
+	assert.Equal(t, http.StatusBadGateway, response.Code, response.Body.String())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/foo", nil))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	store.fail = false
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo", confWithMapping("root = deleted()")))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	// Updates and deletions that cannot be persisted are reverted.
+	store.fail = true
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("PUT", "/streams/foo", confWithMapping("# v2\nroot = deleted()")))
+	assert.Equal(t, http.StatusBadGateway, response.Code, response.Body.String())
+	assert.Equal(t, "root = deleted()", currentMapping())
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("DELETE", "/streams/foo", nil))
+	assert.Equal(t, http.StatusBadGateway, response.Code, response.Body.String())
+	assert.Equal(t, "root = deleted()", currentMapping())
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeAPIControl(t *testing.T) {
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := manager.New(res)
+	r := router(mgr)
+
+	control := func(action string) (int, string) {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("POST", "/streams/foo/"+action, nil))
+		return response.Code, response.Body.String()
+	}
+	getState := func() getBody {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, genRequest("GET", "/streams/foo", nil))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		return parseGetBody(t, response.Body)
+	}
+
+	code, _ := control("pause")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("POST", "/streams/foo", harmlessConf()))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, "running", getState().State)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, genRequest("GET", "/streams/foo/pause", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	code, body := control("pause")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, `{"state":"paused"}`, body)
+	assert.Equal(t, "paused", getState().State)
+
+	code, body = control("resume")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, `{"state":"running"}`, body)
+
+	code, body = control("drain?timeout=nope")
+	assert.Equal(t, http.StatusBadRequest, code, body)
+
+	code, body = control("drain?timeout=10s")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, `{"state":"drained"}`, body)
+
+	state := getState()
+	assert.Equal(t, "drained", state.State)
+	assert.False(t, state.Active)
+
+	// A drained stream is restarted when resumed.
+	code, body = control("resume")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, `{"state":"running"}`, body)
+
+	state = getState()
+	assert.Equal(t, "running", state.State)
+	assert.True(t, state.Active)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeAPIFileSecrets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mapping"), []byte("root.meow = 5\n"), 0o600))
+
+	require.NoError(t, secrets.Register("file", secrets.NewFileProvider(dir)))
+	t.Cleanup(func() {
+		_ = secrets.Register("file", secrets.NewFileProvider(""))
+	})
+
+	conf := map[string]any{
+		"input": map[string]any{
+			"generate": map[string]any{
+				"mapping": "${secret:file:mapping}",
+			},
+		},
+		"output": map[string]any{
+			"drop": map[string]any{},
+		},
+	}
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	r := router(manager.New(res))
+
+	request := genRequest("POST", "/streams/foo", conf)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), "secret provider 'file' is not permitted")
+
+	mgr := manager.New(res, manager.OptAPIFileSecrets(true))
+	r = router(mgr)
+
+	request = genRequest("POST", "/streams/foo", conf)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	status, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "root.meow = 5", status.Config().Input.Generate.Mapping)
+	require.NoError(t, mgr.Stop(ctx))
+}
@@ -0,0 +1,75 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+)
+
+// StoredVersion is a single version of a stream config held within a Store.
+type StoredVersion struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Deleted   bool      `json:"deleted,omitempty"`
+
+	// The config of the stream in YAML format, which is empty for versions
+	// that mark the deletion of a stream.
+	Config []byte `json:"config,omitempty"`
+}
+
+// Store persists the configs of streams along with their version history, so
+// that streams can be restored after a restart and rolled back to previous
+// versions.
+type Store interface {
+	// Append adds a new version of a stream to the store. The version number
+	// and creation time of the provided version are ignored and assigned by
+	// the store, and the resulting version is returned.
+	Append(ctx context.Context, id string, v StoredVersion) (StoredVersion, error)
+
+	// Versions returns all versions of a stream ordered from oldest to newest,
+	// or ErrStreamDoesNotExist if the stream has no versions.
+	Versions(ctx context.Context, id string) ([]StoredVersion, error)
+
+	// IDs returns the identifiers of all streams that have versions within the
+	// store, including streams that have been deleted.
+	IDs(ctx context.Context) ([]string, error)
+
+	// Close the store.
+	Close(ctx context.Context) error
+}
+
+// ErrVersionDoesNotExist is returned when attempting to rollback a stream to a
+// version that is not present within the store.
+var ErrVersionDoesNotExist = errors.New("stream version does not exist")
+
+// NewStoreFromURI creates a stream config store from a URI of the form
+// `<type>:<target>`, where type is one of `dir` (a local directory), `sqlite`
+// (a SQLite database DSN) or `cache` (the name of a cache resource).
+func NewStoreFromURI(mgr bundle.NewManagement, uri string) (Store, error) {
+	kind, target, ok := strings.Cut(uri, ":")
+	if !ok || target == "" {
+		return nil, fmt.Errorf("stream store '%v' must be of the form <type>:<target>", uri)
+	}
+	switch kind {
+	case "dir":
+		return newDirStore(target)
+	case "sqlite":
+		return newSQLStore("sqlite", target)
+	case "cache":
+		return newCacheStore(mgr, target)
+	}
+	return nil, fmt.Errorf("stream store type '%v' not recognised, expected one of: dir, sqlite, cache", kind)
+}
+
+// latestVersion returns the most recent version of a stream that is not a
+// deletion, or false if the stream is currently deleted.
+func latestVersion(versions []StoredVersion) (StoredVersion, bool) {
+	if len(versions) == 0 || versions[len(versions)-1].Deleted {
+		return StoredVersion{}, false
+	}
+	return versions[len(versions)-1], true
+}
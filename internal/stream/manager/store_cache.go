@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+)
+
+const (
+	cacheStoreIDsKey           = "benthos_streams"
+	cacheStoreVersionKeyPrefix = "benthos_stream_versions_"
+)
+
+// cacheStore persists stream versions within a cache resource, where the
+// versions of each stream are stored as a JSON array under a key per stream
+// and the list of stream identifiers is stored under a separate key.
+//
+// Writes are serialised within this process only, and therefore a cache must
+// not be shared by multiple instances.
+type cacheStore struct {
+	mgr       bundle.NewManagement
+	cacheName string
+	mut       sync.Mutex
+}
+
+func newCacheStore(mgr bundle.NewManagement, cacheName string) (*cacheStore, error) {
+	if !mgr.ProbeCache(cacheName) {
+		return nil, fmt.Errorf("cache resource '%v' was not found", cacheName)
+	}
+	return &cacheStore{mgr: mgr, cacheName: cacheName}, nil
+}
+
+func (c *cacheStore) get(ctx context.Context, key string, v any) (exists bool, err error) {
+	var data []byte
+	if cerr := c.mgr.AccessCache(ctx, c.cacheName, func(cache cache.V1) {
+		data, err = cache.Get(ctx, key)
+	}); cerr != nil {
+		return false, cerr
+	}
+	if err != nil {
+		if errors.Is(err, component.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (c *cacheStore) set(ctx context.Context, key string, v any) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// Stream versions must outlive any default TTL of the cache, an explicit
+	// TTL of zero is interpreted by caches as no expiry.
+	noExpiry := time.Duration(0)
+	if cerr := c.mgr.AccessCache(ctx, c.cacheName, func(cache cache.V1) {
+		err = cache.Set(ctx, key, data, &noExpiry)
+	}); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func (c *cacheStore) Append(ctx context.Context, id string, v StoredVersion) (StoredVersion, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var versions []StoredVersion
+	if _, err := c.get(ctx, cacheStoreVersionKeyPrefix+id, &versions); err != nil {
+		return v, err
+	}
+
+	v.Version = 1
+	if len(versions) > 0 {
+		v.Version = versions[len(versions)-1].Version + 1
+	} else {
+		var ids []string
+		if _, err := c.get(ctx, cacheStoreIDsKey, &ids); err != nil {
+			return v, err
+		}
+		if err := c.set(ctx, cacheStoreIDsKey, append(ids, id)); err != nil {
+			return v, err
+		}
+	}
+	v.CreatedAt = time.Now().UTC()
+
+	if err := c.set(ctx, cacheStoreVersionKeyPrefix+id, append(versions, v)); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+func (c *cacheStore) Versions(ctx context.Context, id string) ([]StoredVersion, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var versions []StoredVersion
+	exists, err := c.get(ctx, cacheStoreVersionKeyPrefix+id, &versions)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || len(versions) == 0 {
+		return nil, ErrStreamDoesNotExist
+	}
+	return versions, nil
+}
+
+func (c *cacheStore) IDs(ctx context.Context) ([]string, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var ids []string
+	if _, err := c.get(ctx, cacheStoreIDsKey, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (c *cacheStore) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,143 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dirStore persists stream versions within a local directory, where each
+// stream has a sub directory containing a JSON file per version.
+type dirStore struct {
+	dir string
+	mut sync.Mutex
+}
+
+func newDirStore(dir string) (*dirStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create stream store directory: %w", err)
+	}
+	return &dirStore{dir: dir}, nil
+}
+
+func (d *dirStore) streamDir(id string) string {
+	return filepath.Join(d.dir, url.PathEscape(id))
+}
+
+func (d *dirStore) Append(ctx context.Context, id string, v StoredVersion) (StoredVersion, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	versions, err := d.versions(id)
+	if err != nil && !errors.Is(err, ErrStreamDoesNotExist) {
+		return v, err
+	}
+
+	v.Version = 1
+	if len(versions) > 0 {
+		v.Version = versions[len(versions)-1].Version + 1
+	}
+	v.CreatedAt = time.Now().UTC()
+
+	vBytes, err := json.Marshal(v)
+	if err != nil {
+		return v, err
+	}
+
+	sDir := d.streamDir(id)
+	if err := os.MkdirAll(sDir, 0o755); err != nil {
+		return v, err
+	}
+
+	// Write to a temporary file first so that a partially written version is
+	// never read.
+	target := filepath.Join(sDir, fmt.Sprintf("%08d.json", v.Version))
+	if err := os.WriteFile(target+".tmp", vBytes, 0o644); err != nil {
+		return v, err
+	}
+	if err := os.Rename(target+".tmp", target); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+func (d *dirStore) versions(id string) ([]StoredVersion, error) {
+	entries, err := os.ReadDir(d.streamDir(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrStreamDoesNotExist
+		}
+		return nil, err
+	}
+
+	var versions []StoredVersion
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSuffix(name, ".json")); err != nil {
+			continue
+		}
+		vBytes, err := os.ReadFile(filepath.Join(d.streamDir(id), name))
+		if err != nil {
+			return nil, err
+		}
+		var v StoredVersion
+		if err := json.Unmarshal(vBytes, &v); err != nil {
+			return nil, fmt.Errorf("failed to parse stream version %v: %w", name, err)
+		}
+		versions = append(versions, v)
+	}
+	if len(versions) == 0 {
+		return nil, ErrStreamDoesNotExist
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+func (d *dirStore) Versions(ctx context.Context, id string) ([]StoredVersion, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	return d.versions(id)
+}
+
+func (d *dirStore) IDs(ctx context.Context) ([]string, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		id, err := url.PathUnescape(e.Name())
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (d *dirStore) Close(ctx context.Context) error {
+	return nil
+}
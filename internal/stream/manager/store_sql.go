@@ -0,0 +1,116 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// sqlStore persists stream versions within a SQL database table.
+type sqlStore struct {
+	db *sql.DB
+}
+
+func newSQLStore(driver, dsn string) (*sqlStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream store database: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS benthos_stream_versions (
+  id TEXT NOT NULL,
+  version INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  deleted INTEGER NOT NULL,
+  config BLOB,
+  PRIMARY KEY (id, version)
+)`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create stream store table: %w", err)
+	}
+	return &sqlStore{db: db}, nil
+}
+
+func (s *sqlStore) Append(ctx context.Context, id string, v StoredVersion) (StoredVersion, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return v, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var latest int
+	if err := tx.QueryRowContext(
+		ctx, "SELECT COALESCE(MAX(version), 0) FROM benthos_stream_versions WHERE id = ?", id,
+	).Scan(&latest); err != nil {
+		return v, err
+	}
+
+	v.Version = latest + 1
+	v.CreatedAt = time.Now().UTC()
+
+	deleted := 0
+	if v.Deleted {
+		deleted = 1
+	}
+	if _, err := tx.ExecContext(
+		ctx, "INSERT INTO benthos_stream_versions (id, version, created_at, deleted, config) VALUES (?, ?, ?, ?, ?)",
+		id, v.Version, v.CreatedAt.UnixNano(), deleted, v.Config,
+	); err != nil {
+		return v, err
+	}
+	return v, tx.Commit()
+}
+
+func (s *sqlStore) Versions(ctx context.Context, id string) ([]StoredVersion, error) {
+	rows, err := s.db.QueryContext(
+		ctx, "SELECT version, created_at, deleted, config FROM benthos_stream_versions WHERE id = ? ORDER BY version ASC", id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []StoredVersion
+	for rows.Next() {
+		var v StoredVersion
+		var createdAt int64
+		var deleted int
+		if err := rows.Scan(&v.Version, &createdAt, &deleted, &v.Config); err != nil {
+			return nil, err
+		}
+		v.CreatedAt = time.Unix(0, createdAt).UTC()
+		v.Deleted = deleted == 1
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrStreamDoesNotExist
+	}
+	return versions, nil
+}
+
+func (s *sqlStore) IDs(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT id FROM benthos_stream_versions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *sqlStore) Close(ctx context.Context) error {
+	return s.db.Close()
+}
@@ -0,0 +1,21 @@
+//go:build (darwin && (amd64 || arm64)) || (freebsd && (amd64 || arm64)) || (linux && (386 || amd64 || arm || arm64 || riscv64)) || (windows && (amd64 || arm64))
+
+package manager_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/stream/manager"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestStoreSQLite(t *testing.T) {
+	store, err := manager.NewStoreFromURI(mock.NewManager(), "sqlite:"+filepath.Join(t.TempDir(), "streams.db"))
+	require.NoError(t, err)
+	testStore(t, store)
+}
@@ -0,0 +1,107 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/stream/manager"
+)
+
+func testStore(t *testing.T, store manager.Store) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	_, err := store.Versions(ctx, "foo")
+	assert.ErrorIs(t, err, manager.ErrStreamDoesNotExist)
+
+	ids, err := store.IDs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	v, err := store.Append(ctx, "foo", manager.StoredVersion{Config: []byte("a: first")})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.Version)
+	assert.False(t, v.CreatedAt.IsZero())
+
+	v, err = store.Append(ctx, "foo", manager.StoredVersion{Config: []byte("a: second")})
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.Version)
+
+	v, err = store.Append(ctx, "foo/bar", manager.StoredVersion{Config: []byte("b: first")})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.Version)
+
+	v, err = store.Append(ctx, "foo", manager.StoredVersion{Deleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, v.Version)
+
+	ids, err = store.IDs(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"foo", "foo/bar"}, ids)
+
+	versions, err := store.Versions(ctx, "foo")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	for i, v := range versions {
+		assert.Equal(t, i+1, v.Version)
+	}
+	assert.Equal(t, "a: first", string(versions[0].Config))
+	assert.Equal(t, "a: second", string(versions[1].Config))
+	assert.True(t, versions[2].Deleted)
+	assert.Empty(t, versions[2].Config)
+
+	versions, err = store.Versions(ctx, "foo/bar")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "b: first", string(versions[0].Config))
+
+	require.NoError(t, store.Close(ctx))
+}
+
+func TestStoreDir(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := manager.NewStoreFromURI(mock.NewManager(), "dir:"+dir)
+	require.NoError(t, err)
+	testStore(t, store)
+
+	// Versions must survive reopening the store.
+	store, err = manager.NewStoreFromURI(mock.NewManager(), "dir:"+dir)
+	require.NoError(t, err)
+
+	versions, err := store.Versions(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+}
+
+func TestStoreCache(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{}
+
+	_, err := manager.NewStoreFromURI(mgr, "cache:nope")
+	require.Error(t, err)
+
+	store, err := manager.NewStoreFromURI(mgr, "cache:foocache")
+	require.NoError(t, err)
+	testStore(t, store)
+
+	require.NotEmpty(t, mgr.Caches["foocache"])
+	for k, v := range mgr.Caches["foocache"] {
+		require.NotNil(t, v.TTL, k)
+		assert.Equal(t, time.Duration(0), *v.TTL, k)
+	}
+}
+
+func TestStoreBadURI(t *testing.T) {
+	for _, uri := range []string{"", "dir", "dir:", "nope:foo"} {
+		_, err := manager.NewStoreFromURI(mock.NewManager(), uri)
+		assert.Error(t, err, uri)
+	}
+}
@@ -5,14 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/bundle/tap"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
 
@@ -67,6 +72,11 @@ func (s *StreamStatus) Metrics() *metrics.Local {
 	return s.metrics
 }
 
+// State returns the current state of the stream.
+func (s *StreamStatus) State() stream.State {
+	return s.strm.State()
+}
+
 // setClosed sets the flag indicating that the stream is closed.
 func (s *StreamStatus) setClosed() {
 	atomic.SwapInt64(&s.stoppedAfter, int64(time.Since(s.createdAt)))
@@ -88,6 +98,12 @@ type Type struct {
 
 	manager    bundle.NewManagement
 	apiEnabled bool
+	taps       *tap.Registry
+	swappable  bool
+	pausable   bool
+	store      Store
+
+	apiFileSecrets bool
 
 	lock sync.Mutex
 }
@@ -97,6 +113,7 @@ func New(mgr bundle.NewManagement, opts ...func(*Type)) *Type {
 	t := &Type{
 		streams:    map[string]*StreamStatus{},
 		apiEnabled: true,
+		pausable:   true,
 		manager:    mgr,
 	}
 	for _, opt := range opts {
@@ -116,6 +133,53 @@ func OptAPIEnabled(b bool) func(*Type) {
 	}
 }
 
+// OptAPIFileSecrets sets whether stream configs submitted via the API are
+// permitted to reference secrets of the `file` provider, which gives anyone
+// with access to the API the ability to read files within the secrets
+// directory. This is disabled by default.
+func OptAPIFileSecrets(b bool) func(*Type) {
+	return func(t *Type) {
+		t.apiFileSecrets = b
+	}
+}
+
+// OptTapRegistry sets a registry used for attaching live taps to the components
+// of streams, which enables an API endpoint for doing so.
+func OptTapRegistry(r *tap.Registry) func(*Type) {
+	return func(t *Type) {
+		t.taps = r
+	}
+}
+
+// OptSwappablePipelines sets whether streams are created with processing
+// pipelines that can be replaced without restarting the stream. When enabled
+// updates that only modify the processing pipeline of a stream are applied in
+// place, and updates that fail to create a stream restore the previous version
+// of the stream.
+func OptSwappablePipelines(b bool) func(*Type) {
+	return func(t *Type) {
+		t.swappable = b
+	}
+}
+
+// OptPausableStreams sets whether streams are created with support for pausing
+// the consumption of their inputs, which adds a small overhead to each stream.
+// This is enabled by default.
+func OptPausableStreams(b bool) func(*Type) {
+	return func(t *Type) {
+		t.pausable = b
+	}
+}
+
+// OptStore sets a store used for persisting the configs of streams created,
+// updated or deleted via the API, which also enables API endpoints for reading
+// the version history of streams and rolling them back.
+func OptStore(s Store) func(*Type) {
+	return func(t *Type) {
+		t.store = s
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Errors specifically returned by a stream manager.
@@ -149,9 +213,18 @@ func (m *Type) Create(id string, conf stream.Config) error {
 	// This seems a bit wonky but we can't rule out a race condition between
 	// the stream terminating and setClosed and actually initialising a status.
 	wrapper := newStreamStatus(conf, strmFlatMetrics)
-	strm, err := stream.New(conf, sMgr, stream.OptOnClose(func() {
-		wrapper.setClosed()
-	}))
+	opts := []func(*stream.Type){
+		stream.OptOnClose(func() {
+			wrapper.setClosed()
+		}),
+	}
+	if m.swappable {
+		opts = append(opts, stream.OptSwappablePipeline())
+	}
+	if m.pausable {
+		opts = append(opts, stream.OptPausable())
+	}
+	strm, err := stream.New(conf, sMgr, opts...)
 	if err != nil {
 		return err
 	}
@@ -198,10 +271,32 @@ func (m *Type) Update(ctx context.Context, id string, conf stream.Config) error
 		return nil
 	}
 
+	if m.swappable {
+		err := wrapper.strm.Update(ctx, conf)
+		if err == nil {
+			m.lock.Lock()
+			wrapper.config = conf
+			m.lock.Unlock()
+			return nil
+		}
+		if !errors.Is(err, stream.ErrUpdateRequiresRestart) {
+			return err
+		}
+	}
+
 	if err := m.Delete(ctx, id); err != nil {
 		return err
 	}
-	return m.Create(id, conf)
+	if err := m.Create(id, conf); err != nil {
+		if m.swappable {
+			// Restore the previous version of the stream.
+			if rerr := m.Create(id, wrapper.config); rerr != nil {
+				m.manager.Logger().Errorf("Failed to restore stream (%v) after a failed update: %v", id, rerr)
+			}
+		}
+		return err
+	}
+	return nil
 }
 
 // Delete attempts to stop and remove a stream by its ID. Returns an error if
@@ -231,6 +326,269 @@ func (m *Type) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Pause stops a stream from consuming messages from its input whilst keeping the
+// input connected.
+func (m *Type) Pause(id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	return wrapper.strm.Pause()
+}
+
+// Resume a paused stream. If the stream has been drained it is restarted with
+// its current config.
+func (m *Type) Resume(ctx context.Context, id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if err = wrapper.strm.Resume(ctx); !errors.Is(err, stream.ErrDrained) {
+		return err
+	}
+	if err := m.Delete(ctx, id); err != nil {
+		return err
+	}
+	return m.Create(id, wrapper.config)
+}
+
+// Drain stops the input of a stream and blocks until all of its buffered and
+// in-flight messages have been delivered, the stream remains within the
+// manager until it is either resumed or deleted.
+func (m *Type) Drain(ctx context.Context, id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	return wrapper.strm.Drain(ctx)
+}
+
+//------------------------------------------------------------------------------
+
+// Persist records the current config of a stream as a new version within the
+// store, this is a no-op if the manager does not have a store. The values of
+// resolved secrets are stored as their references.
+func (m *Type) Persist(ctx context.Context, id string, conf stream.Config) error {
+	if m.store == nil {
+		return nil
+	}
+	confBytes, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	confBytes = secrets.RedactBytes(confBytes)
+	if _, err = m.store.Append(ctx, id, StoredVersion{Config: confBytes}); err != nil {
+		return fmt.Errorf("failed to persist stream: %w", err)
+	}
+	return nil
+}
+
+// PersistDelete records the deletion of a stream as a new version within the
+// store, this is a no-op if the manager does not have a store.
+func (m *Type) PersistDelete(ctx context.Context, id string) error {
+	if m.store == nil {
+		return nil
+	}
+	if _, err := m.store.Append(ctx, id, StoredVersion{Deleted: true}); err != nil {
+		return fmt.Errorf("failed to persist stream deletion: %w", err)
+	}
+	return nil
+}
+
+// CreateAndPersist creates a new stream and persists its config within the
+// store. If the config cannot be persisted the stream is deleted again so that
+// the store remains consistent with the running streams.
+func (m *Type) CreateAndPersist(ctx context.Context, id string, conf stream.Config) error {
+	if err := m.Create(id, conf); err != nil {
+		return err
+	}
+	if err := m.Persist(ctx, id, conf); err != nil {
+		if rerr := m.Delete(ctx, id); rerr != nil {
+			m.manager.Logger().Errorf("Failed to remove stream (%v) after failing to persist it: %v", id, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateAndPersist updates an existing stream and persists its new config
+// within the store. If the config cannot be persisted the stream is reverted to
+// its previous config.
+func (m *Type) UpdateAndPersist(ctx context.Context, id string, conf stream.Config) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	prevConf := wrapper.Config()
+
+	if err := m.Update(ctx, id, conf); err != nil {
+		return err
+	}
+	if err := m.Persist(ctx, id, conf); err != nil {
+		if rerr := m.Update(ctx, id, prevConf); rerr != nil {
+			m.manager.Logger().Errorf("Failed to revert stream (%v) after failing to persist it: %v", id, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteAndPersist deletes a stream and records its deletion within the store.
+// If the deletion cannot be persisted the stream is recreated with its previous
+// config.
+func (m *Type) DeleteAndPersist(ctx context.Context, id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	prevConf := wrapper.Config()
+
+	if err := m.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := m.PersistDelete(ctx, id); err != nil {
+		if rerr := m.Create(id, prevConf); rerr != nil {
+			m.manager.Logger().Errorf("Failed to restore stream (%v) after failing to persist its deletion: %v", id, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Versions returns the version history of a stream from the store.
+func (m *Type) Versions(ctx context.Context, id string) ([]StoredVersion, error) {
+	if m.store == nil {
+		return nil, errors.New("stream store is not configured")
+	}
+	return m.store.Versions(ctx, id)
+}
+
+func parseStoredConfig(v StoredVersion) (stream.Config, error) {
+	conf := stream.NewConfig()
+	confBytes, err := secrets.Replace(context.Background(), v.Config)
+	if err != nil {
+		return conf, fmt.Errorf("failed to resolve secrets of version %v: %w", v.Version, err)
+	}
+	if err := yaml.Unmarshal(confBytes, &conf); err != nil {
+		return conf, fmt.Errorf("failed to parse stored config of version %v: %w", v.Version, err)
+	}
+	return conf, nil
+}
+
+// Rollback replaces the active config of a stream with the config of a
+// previous version from the store, which is then recorded as a new version. If
+// the version is zero the most recent version prior to the current version is
+// used. A stream that has been deleted is recreated.
+func (m *Type) Rollback(ctx context.Context, id string, version int) (StoredVersion, error) {
+	versions, err := m.Versions(ctx, id)
+	if err != nil {
+		return StoredVersion{}, err
+	}
+
+	var target *StoredVersion
+	if version == 0 {
+		// Skip the current version, unless the stream is deleted in which
+		// case the latest config is the one we want.
+		latest := len(versions) - 1
+		if !versions[latest].Deleted {
+			latest--
+		}
+		for i := latest; i >= 0; i-- {
+			if !versions[i].Deleted {
+				target = &versions[i]
+				break
+			}
+		}
+	} else {
+		for i := range versions {
+			if versions[i].Version == version && !versions[i].Deleted {
+				target = &versions[i]
+				break
+			}
+		}
+	}
+	if target == nil {
+		return StoredVersion{}, ErrVersionDoesNotExist
+	}
+
+	conf, err := parseStoredConfig(*target)
+	if err != nil {
+		return StoredVersion{}, err
+	}
+
+	var prevConf *stream.Config
+	if wrapper, rerr := m.Read(id); rerr == nil {
+		c := wrapper.Config()
+		prevConf = &c
+	}
+
+	if err = m.Update(ctx, id, conf); errors.Is(err, ErrStreamDoesNotExist) {
+		err = m.Create(id, conf)
+	}
+	if err != nil {
+		return StoredVersion{}, err
+	}
+
+	v, err := m.store.Append(ctx, id, StoredVersion{Config: target.Config})
+	if err != nil {
+		// Revert the stream so that it reflects the latest stored version.
+		var rerr error
+		if prevConf != nil {
+			rerr = m.Update(ctx, id, *prevConf)
+		} else {
+			rerr = m.Delete(ctx, id)
+		}
+		if rerr != nil {
+			m.manager.Logger().Errorf("Failed to revert stream (%v) after failing to persist its rollback: %v", id, rerr)
+		}
+		return StoredVersion{}, fmt.Errorf("failed to persist stream: %w", err)
+	}
+	return v, nil
+}
+
+// Restore creates all streams persisted within the store that are not deleted.
+// Streams that already exist are skipped, and therefore streams created from
+// config files take precedence.
+func (m *Type) Restore(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	ids, err := m.store.IDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read stored streams: %w", err)
+	}
+
+	var errs []string
+	for _, id := range ids {
+		versions, err := m.store.Versions(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("stream '%v': %v", id, err))
+			continue
+		}
+		latest, exists := latestVersion(versions)
+		if !exists {
+			continue
+		}
+		conf, err := parseStoredConfig(latest)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("stream '%v': %v", id, err))
+			continue
+		}
+		if err := m.Create(id, conf); err != nil {
+			if errors.Is(err, ErrStreamExists) {
+				m.manager.Logger().Warnf("Skipping restore of stream '%v' as it already exists", id)
+				continue
+			}
+			errs = append(errs, fmt.Sprintf("stream '%v': %v", id, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to restore streams: %v", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
 //------------------------------------------------------------------------------
 
 // Stop attempts to gracefully shut down all active streams and close the
@@ -261,6 +619,12 @@ func (m *Type) Stop(ctx context.Context) error {
 	m.streams = map[string]*StreamStatus{}
 	m.closed = true
 
+	if m.store != nil {
+		if err := m.store.Close(ctx); err != nil {
+			m.manager.Logger().Errorf("Failed to close stream store: %v", err)
+		}
+	}
+
 	if len(failedStreams) > 0 {
 		return fmt.Errorf("failed to gracefully stop the following streams: %v", failedStreams)
 	}
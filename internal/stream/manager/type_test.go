@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
 	bmanager "github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
@@ -105,3 +106,56 @@ func TestTypeBasicClose(t *testing.T) {
 		t.Errorf("Unexpected error: %v != %v", act, exp)
 	}
 }
+
+func TestTypeSwappableUpdates(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res, OptSwappablePipelines(true))
+	require.NoError(t, mgr.Create("foo", harmlessConf()))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	strm := info.strm
+
+	// Pipeline changes are applied in place
+	newConf := harmlessConf()
+	newConf.Pipeline.Processors = append(newConf.Pipeline.Processors, processor.NewConfig())
+	newConf.Pipeline.Processors[0].Type = "bloblang"
+	newConf.Pipeline.Processors[0].Bloblang = "root = this"
+	require.NoError(t, mgr.Update(ctx, "foo", newConf))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	require.True(t, info.IsRunning())
+	require.Equal(t, strm, info.strm)
+	require.Equal(t, newConf, info.Config())
+
+	// Invalid pipeline changes are rejected
+	badConf := harmlessConf()
+	badConf.Pipeline.Processors = append(badConf.Pipeline.Processors, processor.NewConfig())
+	badConf.Pipeline.Processors[0].Type = "bloblang"
+	badConf.Pipeline.Processors[0].Bloblang = "root = this."
+	require.Error(t, mgr.Update(ctx, "foo", badConf))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	require.True(t, info.IsRunning())
+	require.Equal(t, strm, info.strm)
+	require.Equal(t, newConf, info.Config())
+
+	// Invalid input changes restore the previous stream
+	badConf = newConf
+	badConf.Input.Generate.Mapping = "root = this."
+	require.Error(t, mgr.Update(ctx, "foo", badConf))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	require.True(t, info.IsRunning())
+	require.Equal(t, newConf, info.Config())
+
+	require.NoError(t, mgr.Stop(ctx))
+}
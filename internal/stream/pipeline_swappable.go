@@ -0,0 +1,230 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+type pipelineSwap struct {
+	pipe    processor.Pipeline
+	resChan chan error
+}
+
+// swappablePipeline is a processing pipeline that wraps another, and allows
+// the wrapped pipeline to be replaced without closing the transaction channels
+// that it is connected to. When swapped the active pipeline is drained of all
+// in-flight transactions before the new pipeline begins consuming.
+type swappablePipeline struct {
+	current processor.Pipeline
+
+	swapChan chan pipelineSwap
+	outChan  chan message.Transaction
+
+	shutSig *shutdown.Signaller
+
+	mut      sync.Mutex
+	started  bool
+	closeErr error
+}
+
+func newSwappablePipeline(pipe processor.Pipeline) *swappablePipeline {
+	return &swappablePipeline{
+		current:  pipe,
+		swapChan: make(chan pipelineSwap),
+		outChan:  make(chan message.Transaction),
+		shutSig:  shutdown.NewSignaller(),
+	}
+}
+
+// forward transactions from a pipeline to the output channel until the
+// pipeline closes its channel.
+func (s *swappablePipeline) forward(pipe processor.Pipeline) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case tran, open := <-pipe.TransactionChan():
+				if !open {
+					return
+				}
+				select {
+				case s.outChan <- tran:
+				case <-s.shutSig.CloseNowChan():
+					return
+				}
+			case <-s.shutSig.CloseNowChan():
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// drain closes the feed of a pipeline and waits for it to flush all remaining
+// transactions and close.
+func (s *swappablePipeline) drain(feed chan message.Transaction, pipe processor.Pipeline, forwarded <-chan struct{}) error {
+	close(feed)
+	select {
+	case <-forwarded:
+	case <-s.shutSig.CloseNowChan():
+		pipe.TriggerCloseNow()
+	}
+	return pipe.WaitForClose(context.Background())
+}
+
+func (s *swappablePipeline) loop(inChan <-chan message.Transaction) {
+	defer func() {
+		close(s.outChan)
+		s.shutSig.ShutdownComplete()
+	}()
+
+	feed := make(chan message.Transaction)
+	pipe := s.current
+	if err := pipe.Consume(feed); err != nil {
+		s.mut.Lock()
+		s.closeErr = err
+		s.mut.Unlock()
+		return
+	}
+	forwarded := s.forward(pipe)
+
+	var pending *message.Transaction
+	for {
+		var tran message.Transaction
+		if pending != nil {
+			tran, pending = *pending, nil
+		} else {
+			select {
+			case t, open := <-inChan:
+				if !open {
+					_ = s.drain(feed, pipe, forwarded)
+					return
+				}
+				tran = t
+			case swap := <-s.swapChan:
+				if !s.handleSwap(swap, &feed, &pipe, &forwarded) {
+					return
+				}
+				continue
+			case <-s.shutSig.CloseNowChan():
+				pipe.TriggerCloseNow()
+				_ = pipe.WaitForClose(context.Background())
+				return
+			}
+		}
+
+		select {
+		case feed <- tran:
+		case swap := <-s.swapChan:
+			// Hold onto the transaction until the new pipeline is consuming.
+			pending = &tran
+			if !s.handleSwap(swap, &feed, &pipe, &forwarded) {
+				return
+			}
+		case <-s.shutSig.CloseNowChan():
+			pipe.TriggerCloseNow()
+			_ = pipe.WaitForClose(context.Background())
+			return
+		}
+	}
+}
+
+// handleSwap performs a swap and returns false if the loop should exit as a
+// result of the pipeline being closed during the swap. A swap that fails is
+// reported to the caller and leaves the active pipeline running.
+func (s *swappablePipeline) handleSwap(swap pipelineSwap, feed *chan message.Transaction, pipe *processor.Pipeline, forwarded *<-chan struct{}) bool {
+	// The new pipeline is started before the active one is touched so that a
+	// failure can be rejected without interrupting the flow of transactions.
+	newFeed := make(chan message.Transaction)
+	if err := swap.pipe.Consume(newFeed); err != nil {
+		swap.pipe.TriggerCloseNow()
+		swap.resChan <- err
+		return true
+	}
+
+	// From this point the swap can no longer fail, and therefore we report
+	// success before draining the active pipeline.
+	swap.resChan <- nil
+
+	_ = s.drain(*feed, *pipe, *forwarded)
+
+	*feed, *pipe = newFeed, swap.pipe
+	*forwarded = s.forward(swap.pipe)
+
+	s.mut.Lock()
+	s.current = swap.pipe
+	s.mut.Unlock()
+
+	select {
+	case <-s.shutSig.CloseNowChan():
+		swap.pipe.TriggerCloseNow()
+		_ = swap.pipe.WaitForClose(context.Background())
+		return false
+	default:
+	}
+	return true
+}
+
+// Swap replaces the active pipeline with a new one. Once the new pipeline has
+// been accepted the active pipeline is drained of all in-flight transactions
+// before the new pipeline begins consuming. The provided context bounds the
+// time spent waiting for the swap to be accepted, if it is cancelled before
+// then the active pipeline remains unchanged.
+func (s *swappablePipeline) Swap(ctx context.Context, newPipe processor.Pipeline) error {
+	resChan := make(chan error, 1)
+	select {
+	case s.swapChan <- pipelineSwap{pipe: newPipe, resChan: resChan}:
+	case <-s.shutSig.HasClosedChan():
+		newPipe.TriggerCloseNow()
+		return component.ErrTypeClosed
+	case <-ctx.Done():
+		newPipe.TriggerCloseNow()
+		return ctx.Err()
+	}
+
+	// The swap has been handed to the loop, which always responds without
+	// blocking, and so we must wait for the outcome regardless of the context
+	// in order for the caller to know which pipeline is active.
+	return <-resChan
+}
+
+func (s *swappablePipeline) TransactionChan() <-chan message.Transaction {
+	return s.outChan
+}
+
+func (s *swappablePipeline) Consume(inChan <-chan message.Transaction) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.started {
+		return component.ErrAlreadyStarted
+	}
+	s.started = true
+	go s.loop(inChan)
+	return nil
+}
+
+func (s *swappablePipeline) TriggerCloseNow() {
+	s.shutSig.CloseNow()
+}
+
+func (s *swappablePipeline) WaitForClose(ctx context.Context) error {
+	select {
+	case <-s.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.closeErr
+}
+
+// ErrUpdateRequiresRestart is returned when attempting to update a running
+// stream with a config that changes more than its processing pipeline.
+var ErrUpdateRequiresRestart = errors.New("config changes cannot be applied without restarting the stream")
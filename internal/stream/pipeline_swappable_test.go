@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/pipeline"
+)
+
+func TestSwappablePipelineSwaps(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	inChan := make(chan message.Transaction)
+	pipe := newSwappablePipeline(pipeline.NewProcessor())
+	require.NoError(t, pipe.Consume(inChan))
+
+	sendOne := func(content string) {
+		t.Helper()
+		resChan := make(chan error, 1)
+		select {
+		case inChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(content)}), resChan):
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		select {
+		case tran, open := <-pipe.TransactionChan():
+			require.True(t, open)
+			require.Equal(t, content, string(tran.Payload.Get(0).AsBytes()))
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		require.NoError(t, <-resChan)
+	}
+
+	sendOne("first")
+
+	// A pipeline that cannot consume is rejected and the active pipeline
+	// continues running.
+	started := pipeline.NewProcessor()
+	require.NoError(t, started.Consume(make(chan message.Transaction)))
+	assert.ErrorIs(t, pipe.Swap(ctx, started), component.ErrAlreadyStarted)
+
+	sendOne("second")
+
+	replacement := pipeline.NewProcessor()
+	require.NoError(t, pipe.Swap(ctx, replacement))
+
+	// The swap is accepted before the previous pipeline is drained, but
+	// transactions are only fed to the replacement once it has completed.
+	sendOne("third")
+
+	pipe.mut.Lock()
+	assert.Same(t, replacement, pipe.current)
+	pipe.mut.Unlock()
+
+	close(inChan)
+	require.NoError(t, pipe.WaitForClose(ctx))
+}
+
+func TestSwappablePipelineSwapCancelled(t *testing.T) {
+	inChan := make(chan message.Transaction)
+	original := pipeline.NewProcessor()
+	pipe := newSwappablePipeline(original)
+
+	// The loop isn't running and so the swap is never accepted.
+	ctx, done := context.WithCancel(context.Background())
+	done()
+	assert.ErrorIs(t, pipe.Swap(ctx, pipeline.NewProcessor()), context.Canceled)
+
+	require.NoError(t, pipe.Consume(inChan))
+
+	pipe.mut.Lock()
+	assert.Same(t, original, pipe.current)
+	pipe.mut.Unlock()
+
+	close(inChan)
+	require.NoError(t, pipe.WaitForClose(context.Background()))
+}
@@ -6,10 +6,14 @@ import (
 	"errors"
 	"net/http"
 	"runtime/pprof"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/buffer"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
@@ -22,15 +26,22 @@ import (
 type Type struct {
 	conf Config
 
-	inputLayer    input.Streamed
-	bufferLayer   buffer.Streamed
-	pipelineLayer processor.Pipeline
-	outputLayer   output.Streamed
+	inputLayer      input.Streamed
+	pausableEnabled bool
+	pausable        *pausableInput
+	bufferLayer     buffer.Streamed
+	pipelineLayer   processor.Pipeline
+	outputLayer     output.Streamed
+
+	swappable     bool
+	pipelineSwaps *swappablePipeline
+	confMut       sync.Mutex
 
 	manager bundle.NewManagement
 
 	onClose func()
 	closed  uint32
+	drained uint32
 }
 
 // New creates a new stream.Type.
@@ -87,6 +98,24 @@ func OptOnClose(onClose func()) func(*Type) {
 	}
 }
 
+// OptPausable enables pausing the consumption of messages from the input of the
+// stream with Pause. This adds a small overhead to the stream even when it is
+// never paused.
+func OptPausable() func(*Type) {
+	return func(t *Type) {
+		t.pausableEnabled = true
+	}
+}
+
+// OptSwappablePipeline enables replacing the processing pipeline of the stream
+// whilst it is running with Update. This adds a small overhead to the stream
+// even when the pipeline is not replaced.
+func OptSwappablePipeline() func(*Type) {
+	return func(t *Type) {
+		t.swappable = true
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // IsReady returns a boolean indicating whether both the input and output layers
@@ -101,17 +130,25 @@ func (t *Type) start() (err error) {
 	if t.inputLayer, err = iMgr.NewInput(t.conf.Input); err != nil {
 		return
 	}
+	if t.pausableEnabled {
+		t.pausable = newPausableInput(t.inputLayer)
+		t.inputLayer = t.pausable
+	}
 	if t.conf.Buffer.Type != "none" {
 		bMgr := t.manager.IntoPath("buffer")
 		if t.bufferLayer, err = bMgr.NewBuffer(t.conf.Buffer); err != nil {
 			return
 		}
 	}
-	if tLen := len(t.conf.Pipeline.Processors); tLen > 0 {
+	if tLen := len(t.conf.Pipeline.Processors); tLen > 0 || t.swappable {
 		pMgr := t.manager.IntoPath("pipeline")
 		if t.pipelineLayer, err = pipeline.New(t.conf.Pipeline, pMgr); err != nil {
 			return
 		}
+		if t.swappable {
+			t.pipelineSwaps = newSwappablePipeline(t.pipelineLayer)
+			t.pipelineLayer = t.pipelineSwaps
+		}
 	}
 	oMgr := t.manager.IntoPath("output")
 	if t.outputLayer, err = oMgr.NewOutput(t.conf.Output); err != nil {
@@ -151,6 +188,132 @@ func (t *Type) start() (err error) {
 	return nil
 }
 
+// Update attempts to apply a new config to the stream whilst it is running. This
+// is only possible when the stream was created with OptSwappablePipeline and
+// the new config differs from the current config only within its processing
+// pipeline, otherwise ErrUpdateRequiresRestart is returned.
+//
+// The new pipeline is constructed and started before the running stream is
+// modified, and therefore an invalid config results in an error without
+// affecting the stream. Once the new pipeline has been accepted the current
+// pipeline is drained of in-flight transactions and then replaced, whilst the
+// input, buffer and output layers continue running uninterrupted.
+func (t *Type) Update(ctx context.Context, conf Config) error {
+	t.confMut.Lock()
+	defer t.confMut.Unlock()
+
+	if t.pipelineSwaps == nil {
+		return ErrUpdateRequiresRestart
+	}
+
+	for _, pair := range [][2]any{
+		{t.conf.Input, conf.Input},
+		{t.conf.Buffer, conf.Buffer},
+		{t.conf.Output, conf.Output},
+	} {
+		equal, err := configsEqual(pair[0], pair[1])
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return ErrUpdateRequiresRestart
+		}
+	}
+
+	if equal, err := configsEqual(t.conf.Pipeline, conf.Pipeline); err != nil {
+		return err
+	} else if equal {
+		t.conf = conf
+		return nil
+	}
+
+	newPipe, err := pipeline.New(conf.Pipeline, t.manager.IntoPath("pipeline"))
+	if err != nil {
+		return err
+	}
+	if err := t.pipelineSwaps.Swap(ctx, newPipe); err != nil {
+		return err
+	}
+	t.conf = conf
+	return nil
+}
+
+// configsEqual compares two component configs by their YAML representation,
+// which ignores differences such as the line numbers of nested plugin configs.
+func configsEqual(a, b any) (bool, error) {
+	aBytes, err := yaml.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bBytes, err := yaml.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aBytes, bBytes), nil
+}
+
+// Pause stops the stream from consuming messages from its input whilst keeping
+// the input connected. Messages that have already been consumed continue to be
+// processed and delivered to the output. Returns ErrNotPausable unless the
+// stream was created with OptPausable.
+func (t *Type) Pause() error {
+	if atomic.LoadUint32(&t.closed) == 1 {
+		return component.ErrTypeClosed
+	}
+	if t.pausable == nil {
+		return ErrNotPausable
+	}
+	t.pausable.Pause()
+	return nil
+}
+
+// Resume consuming messages from the input of a paused stream. A stream that
+// has been drained cannot be resumed and must be recreated instead, in which
+// case ErrDrained is returned.
+func (t *Type) Resume(ctx context.Context) error {
+	if atomic.LoadUint32(&t.drained) == 1 {
+		return ErrDrained
+	}
+	if atomic.LoadUint32(&t.closed) == 1 {
+		return component.ErrTypeClosed
+	}
+	if t.pausable != nil {
+		t.pausable.Resume()
+	}
+	return nil
+}
+
+// Drain stops the input of the stream and blocks until all buffered and
+// in-flight messages have been delivered to the output, at which point the
+// stream is closed.
+func (t *Type) Drain(ctx context.Context) error {
+	atomic.StoreUint32(&t.drained, 1)
+	if err := t.StopGracefully(ctx); err != nil {
+		return err
+	}
+	atomic.StoreUint32(&t.closed, 1)
+	return nil
+}
+
+// State returns the current state of the stream.
+func (t *Type) State() State {
+	if atomic.LoadUint32(&t.closed) == 1 {
+		if atomic.LoadUint32(&t.drained) == 1 {
+			return StateDrained
+		}
+		return StateClosed
+	}
+	if t.pausable != nil && t.pausable.Paused() {
+		return StatePaused
+	}
+	return StateRunning
+}
+
+// Drained returns whether the stream was closed by a call to Drain.
+func (t *Type) Drained() bool {
+	return atomic.LoadUint32(&t.drained) == 1
+}
+
 // StopGracefully attempts to close the stream in the most graceful way by only
 // closing the input layer and waiting for all other layers to terminate by
 // proxy. This should guarantee that all in-flight and buffered data is resolved
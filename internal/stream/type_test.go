@@ -206,3 +206,182 @@ func TestHealthCheck(t *testing.T) {
 
 	validateHealthCheckResponse(t, mockAPIReg.server.URL, "Stream terminated\n")
 }
+
+func TestTypeUpdatePipeline(t *testing.T) {
+	conf := stream.NewConfig()
+	conf.Input.Type = "generate"
+	conf.Input.Generate.Mapping = `root = "hello world"`
+	conf.Input.Generate.Interval = "1ms"
+	conf.Output.Type = "inproc"
+	conf.Output.Inproc = "foo"
+
+	newMgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	strm, err := stream.New(conf, newMgr, stream.OptSwappablePipeline())
+	require.NoError(t, err)
+
+	tChan, err := newMgr.GetPipe("foo")
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	readOne := func() string {
+		t.Helper()
+		select {
+		case tran, open := <-tChan:
+			require.True(t, open)
+			require.NoError(t, tran.Ack(ctx, nil))
+			require.Len(t, tran.Payload, 1)
+			return string(tran.Payload[0].AsBytes())
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		}
+		return ""
+	}
+
+	assert.Equal(t, "hello world", readOne())
+
+	// Invalid processor configs are rejected without affecting the stream.
+	badConf := conf
+	badConf.Pipeline.Processors = []processor.Config{processor.NewConfig()}
+	badConf.Pipeline.Processors[0].Type = "bloblang"
+	badConf.Pipeline.Processors[0].Bloblang = `root = this.`
+	require.Error(t, strm.Update(ctx, badConf))
+	assert.Equal(t, "hello world", readOne())
+
+	newConf := conf
+	newConf.Pipeline.Processors = []processor.Config{processor.NewConfig()}
+	newConf.Pipeline.Processors[0].Type = "bloblang"
+	newConf.Pipeline.Processors[0].Bloblang = `root = content().uppercase()`
+
+	updateErr := make(chan error, 1)
+	go func() {
+		updateErr <- strm.Update(ctx, newConf)
+	}()
+
+	// Consume until the update has been applied, which requires in-flight
+	// messages of the previous pipeline to flush.
+	for readOne() != "HELLO WORLD" {
+	}
+	require.NoError(t, <-updateErr)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "HELLO WORLD", readOne())
+	}
+
+	restartConf := newConf
+	restartConf.Output.Inproc = "bar"
+	assert.ErrorIs(t, strm.Update(ctx, restartConf), stream.ErrUpdateRequiresRestart)
+
+	go func() {
+		for tran := range tChan {
+			_ = tran.Ack(ctx, nil)
+		}
+	}()
+	require.NoError(t, strm.Stop(ctx))
+}
+
+func TestTypeUpdateNotSwappable(t *testing.T) {
+	conf := stream.NewConfig()
+	conf.Input.Type = "generate"
+	conf.Input.Generate.Mapping = `root = "hello world"`
+	conf.Output.Type = "drop"
+
+	newMgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	strm, err := stream.New(conf, newMgr)
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	assert.ErrorIs(t, strm.Update(ctx, conf), stream.ErrUpdateRequiresRestart)
+	require.NoError(t, strm.Stop(ctx))
+}
+
+func TestTypePauseResumeDrain(t *testing.T) {
+	conf := stream.NewConfig()
+	conf.Input.Type = "generate"
+	conf.Input.Generate.Mapping = `root = "hello world"`
+	conf.Input.Generate.Interval = "1ms"
+	conf.Output.Type = "inproc"
+	conf.Output.Inproc = "foo"
+
+	newMgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	strm, err := stream.New(conf, newMgr, stream.OptPausable())
+	require.NoError(t, err)
+
+	tChan, err := newMgr.GetPipe("foo")
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	readOne := func(timeout time.Duration) bool {
+		t.Helper()
+		select {
+		case tran, open := <-tChan:
+			require.True(t, open)
+			require.NoError(t, tran.Ack(ctx, nil))
+			return true
+		case <-time.After(timeout):
+		}
+		return false
+	}
+
+	require.True(t, readOne(time.Second*5))
+	assert.Equal(t, stream.StateRunning, strm.State())
+
+	require.NoError(t, strm.Pause())
+	assert.Equal(t, stream.StatePaused, strm.State())
+
+	// Messages consumed prior to the pause are still delivered.
+	for readOne(time.Millisecond * 100) {
+	}
+	assert.False(t, readOne(time.Millisecond*200))
+
+	require.NoError(t, strm.Resume(ctx))
+	assert.Equal(t, stream.StateRunning, strm.State())
+	require.True(t, readOne(time.Second*5))
+
+	drainErr := make(chan error, 1)
+	go func() {
+		drainErr <- strm.Drain(ctx)
+	}()
+
+	// The output pipe is closed once the drain completes.
+	for tran := range tChan {
+		require.NoError(t, tran.Ack(ctx, nil))
+	}
+	require.NoError(t, <-drainErr)
+
+	assert.Equal(t, stream.StateDrained, strm.State())
+	assert.ErrorIs(t, strm.Resume(ctx), stream.ErrDrained)
+	require.NoError(t, strm.Stop(ctx))
+}
+
+func TestTypePauseNotEnabled(t *testing.T) {
+	conf := stream.NewConfig()
+	conf.Input.Type = "generate"
+	conf.Input.Generate.Mapping = `root = "hello world"`
+	conf.Input.Generate.Interval = "1ms"
+	conf.Output.Type = "drop"
+
+	newMgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	strm, err := stream.New(conf, newMgr)
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	assert.ErrorIs(t, strm.Pause(), stream.ErrNotPausable)
+	assert.Equal(t, stream.StateRunning, strm.State())
+	require.NoError(t, strm.Resume(ctx))
+	require.NoError(t, strm.Stop(ctx))
+}
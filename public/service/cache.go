@@ -14,6 +14,11 @@ import (
 var (
 	ErrKeyAlreadyExists = errors.New("key already exists")
 	ErrKeyNotFound      = errors.New("key does not exist")
+	ErrKeyValueMismatch = errors.New("key value does not match")
+
+	// ErrCacheOpNotSupported is returned by optional cache operations when the
+	// underlying cache implementation is unable to support them.
+	ErrCacheOpNotSupported = errors.New("operation not supported by cache")
 )
 
 // Cache is an interface implemented by Benthos caches.
@@ -55,17 +60,62 @@ type batchedCache interface {
 	SetMulti(ctx context.Context, keyValues ...CacheItem) error
 }
 
+// multiGetCache represents a cache where the underlying implementation is able
+// to benefit from batched get requests. This interface is optional for caches
+// and when implemented will automatically be utilised where possible.
+type multiGetCache interface {
+	// GetMulti attempts to get multiple cache items in as few requests as
+	// possible. Keys that do not exist are omitted from the result.
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+}
+
+// incrCache represents a cache that is able to atomically increment integer
+// values. This interface is optional for caches, and when not implemented the
+// operation is considered unsupported.
+type incrCache interface {
+	// Incr atomically increments the integer value of a key by delta and
+	// returns the result. A key that does not exist is treated as zero.
+	Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// casCache represents a cache that is able to atomically compare and swap
+// values. This interface is optional for caches, and when not implemented the
+// operation is considered unsupported.
+type casCache interface {
+	// CompareAndSwap sets the value of a key only if its current value matches
+	// old. ErrKeyNotFound should be returned if the key does not exist and
+	// ErrKeyValueMismatch if the current value differs.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error
+}
+
+// keyIterCache represents a cache that is able to enumerate its keys. This
+// interface is optional for caches, and when not implemented the operation is
+// considered unsupported.
+type keyIterCache interface {
+	// IterKeys calls fn for each key beginning with prefix, stopping at the
+	// first error returned by fn.
+	IterKeys(ctx context.Context, prefix string, fn func(key string) error) error
+}
+
 //------------------------------------------------------------------------------
 
 // Implements types.Cache.
 type airGapCache struct {
-	c  Cache
-	cm batchedCache
+	c   Cache
+	cm  batchedCache
+	cmg multiGetCache
+	ci  incrCache
+	ccs casCache
+	cki keyIterCache
 }
 
 func newAirGapCache(c Cache, stats metrics.Type) cache.V1 {
 	ag := &airGapCache{c: c, cm: nil}
 	ag.cm, _ = c.(batchedCache)
+	ag.cmg, _ = c.(multiGetCache)
+	ag.ci, _ = c.(incrCache)
+	ag.ccs, _ = c.(casCache)
+	ag.cki, _ = c.(keyIterCache)
 	return cache.MetricsForCache(ag, stats)
 }
 
@@ -113,6 +163,62 @@ func (a *airGapCache) Delete(ctx context.Context, key string) error {
 	return a.c.Delete(ctx, key)
 }
 
+func (a *airGapCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if a.cmg != nil {
+		return a.cmg.GetMulti(ctx, keys...)
+	}
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		b, err := a.c.Get(ctx, k)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		res[k] = b
+	}
+	return res, nil
+}
+
+func (a *airGapCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if a.ci == nil {
+		return 0, component.ErrCacheOpNotSupported
+	}
+	v, err := a.ci.Incr(ctx, key, delta, ttl)
+	if errors.Is(err, ErrCacheOpNotSupported) {
+		err = component.ErrCacheOpNotSupported
+	}
+	return v, err
+}
+
+func (a *airGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	if a.ccs == nil {
+		return component.ErrCacheOpNotSupported
+	}
+	err := a.ccs.CompareAndSwap(ctx, key, old, value, ttl)
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		err = component.ErrKeyNotFound
+	case errors.Is(err, ErrKeyValueMismatch):
+		err = component.ErrKeyValueMismatch
+	case errors.Is(err, ErrCacheOpNotSupported):
+		err = component.ErrCacheOpNotSupported
+	}
+	return err
+}
+
+func (a *airGapCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if a.cki == nil {
+		return component.ErrCacheOpNotSupported
+	}
+	err := a.cki.IterKeys(ctx, prefix, fn)
+	if errors.Is(err, ErrCacheOpNotSupported) {
+		err = component.ErrCacheOpNotSupported
+	}
+	return err
+}
+
 func (a *airGapCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
@@ -151,6 +257,39 @@ func (r *reverseAirGapCache) Delete(ctx context.Context, key string) error {
 	return r.c.Delete(ctx, key)
 }
 
+func (r *reverseAirGapCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	return r.c.GetMulti(ctx, keys...)
+}
+
+func (r *reverseAirGapCache) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	v, err := r.c.Incr(ctx, key, delta, ttl)
+	if errors.Is(err, component.ErrCacheOpNotSupported) {
+		err = ErrCacheOpNotSupported
+	}
+	return v, err
+}
+
+func (r *reverseAirGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	err := r.c.CompareAndSwap(ctx, key, old, value, ttl)
+	switch {
+	case errors.Is(err, component.ErrKeyNotFound):
+		err = ErrKeyNotFound
+	case errors.Is(err, component.ErrKeyValueMismatch):
+		err = ErrKeyValueMismatch
+	case errors.Is(err, component.ErrCacheOpNotSupported):
+		err = ErrCacheOpNotSupported
+	}
+	return err
+}
+
+func (r *reverseAirGapCache) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	err := r.c.IterKeys(ctx, prefix, fn)
+	if errors.Is(err, component.ErrCacheOpNotSupported) {
+		err = ErrCacheOpNotSupported
+	}
+	return err
+}
+
 func (r *reverseAirGapCache) Close(ctx context.Context) error {
 	return r.c.Close(ctx)
 }
@@ -351,6 +351,22 @@ func (c *closableCacheType) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (c *closableCacheType) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *closableCacheType) Incr(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (c *closableCacheType) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	return errors.New("not implemented")
+}
+
+func (c *closableCacheType) IterKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	return errors.New("not implemented")
+}
+
 func (c *closableCacheType) Close(ctx context.Context) error {
 	c.closed = true
 	return nil
@@ -457,6 +457,26 @@ func (c *ConfigSpec) LintRule(blobl string) *ConfigSpec {
 	return c
 }
 
+// MigrationRule adds a rule for automatically migrating configs of the
+// component to a successor component, which is applied to configs by the
+// `benthos migrate` subcommand. The rule is a bloblang mapping that is provided
+// the value of the fields within the ConfigSpec as the context `this`, and must
+// assign to `root` an object with a single key, the name of the successor
+// component, where the value is the config of the successor.
+//
+// If a config cannot be migrated then the mapping should either delete the root
+// with `deleted()` or throw an error describing why it is not possible.
+//
+// For example, if we had deprecated a component in favour of `meow` where the
+// field `woof` has been renamed to `bark` we might use the following:
+//
+// root.meow = this.without("woof")
+// root.meow.bark = this.woof | deleted()
+func (c *ConfigSpec) MigrationRule(blobl string) *ConfigSpec {
+	c.component.Migration = blobl
+	return c
+}
+
 //------------------------------------------------------------------------------
 
 // ConfigView is a struct returned by a Benthos service environment when walking
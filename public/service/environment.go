@@ -22,6 +22,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/filepath/ifs"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 	"github.com/benthosdev/benthos/v4/public/bloblang"
 )
 
@@ -33,12 +34,14 @@ import (
 type Environment struct {
 	internal    *bundle.Environment
 	bloblangEnv *bloblang.Environment
+	secrets     *secrets.Registry
 	fs          ifs.FS
 }
 
 var globalEnvironment = &Environment{
 	internal:    bundle.GlobalEnvironment,
 	bloblangEnv: bloblang.GlobalEnvironment(),
+	secrets:     secrets.GlobalRegistry(),
 	fs:          ifs.OS(),
 }
 
@@ -61,6 +64,7 @@ func (e *Environment) Clone() *Environment {
 	return &Environment{
 		internal:    e.internal.Clone(),
 		bloblangEnv: e.bloblangEnv.WithoutFunctions().WithoutMethods(),
+		secrets:     e.secrets.Clone(),
 		fs:          e.fs,
 	}
 }
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/benthosdev/benthos/v4/internal/secrets"
+)
+
+// ErrSecretNotFound should be returned by secret providers when a secret does
+// not exist.
+var ErrSecretNotFound = secrets.ErrNotFound
+
+// SecretProvider is an interface implemented by plugins that resolve the values
+// of secrets referenced within configs with the syntax
+// `${secret:provider:path}`.
+type SecretProvider interface {
+	// LookupSecret returns the value of a secret identified by a path, the
+	// format of which is specific to the provider. If the secret does not
+	// exist then ErrSecretNotFound should be returned.
+	LookupSecret(ctx context.Context, path string) (string, error)
+}
+
+// RegisterSecretProvider registers a secret provider by a name, which is then
+// used to reference secrets resolved by the provider from configs in the form
+// `${secret:name:path}`. Secrets are resolved when configs are read, and the
+// values of resolved secrets are redacted from echoed configs, logs and debug
+// endpoints.
+//
+// Registering a provider with the name of an existing provider replaces it,
+// which includes the standard providers `file` and `encrypted`. The name must
+// only contain alphanumeric, underscore and dash characters.
+func (e *Environment) RegisterSecretProvider(name string, p SecretProvider) error {
+	return e.secrets.Register(name, secrets.ProviderFunc(p.LookupSecret))
+}
+
+// RegisterSecretProvider registers a secret provider by a name within the
+// global environment, which is then used to reference secrets resolved by the
+// provider from configs in the form `${secret:name:path}`.
+func RegisterSecretProvider(name string, p SecretProvider) error {
+	return globalEnvironment.RegisterSecretProvider(name, p)
+}
@@ -0,0 +1,74 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+type mapSecretProvider map[string]string
+
+func (m mapSecretProvider) LookupSecret(ctx context.Context, path string) (string, error) {
+	v, exists := m[path]
+	if !exists {
+		return "", service.ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func TestSecretProviderPlugin(t *testing.T) {
+	require.NoError(t, service.RegisterSecretProvider("servicetest", mapSecretProvider{
+		"foo": "foo value",
+	}))
+	require.Error(t, service.RegisterSecretProvider("not valid", mapSecretProvider{}))
+
+	b := service.NewStreamBuilder()
+	require.NoError(t, b.AddInputYAML(`
+generate:
+  mapping: 'root = "${secret:servicetest:foo}"'
+`))
+
+	act, err := b.AsYAML()
+	require.NoError(t, err)
+	assert.Contains(t, act, `mapping: root = "foo value"`)
+
+	err = service.NewStreamBuilder().AddInputYAML(`
+generate:
+  mapping: 'root = "${secret:servicetest:bar}"'
+`)
+	require.ErrorIs(t, err, service.ErrSecretNotFound)
+}
+
+func TestSecretProviderPluginEnvironment(t *testing.T) {
+	env := service.NewEnvironment()
+	require.NoError(t, env.RegisterSecretProvider("serviceenvtest", mapSecretProvider{
+		"foo": "foo value",
+	}))
+
+	b := env.NewStreamBuilder()
+	require.NoError(t, b.AddInputYAML(`
+generate:
+  mapping: 'root = "${secret:serviceenvtest:foo}"'
+`))
+
+	act, err := b.AsYAML()
+	require.NoError(t, err)
+	assert.Contains(t, act, `mapping: root = "foo value"`)
+
+	err = service.NewStreamBuilder().AddInputYAML(`
+generate:
+  mapping: 'root = "${secret:serviceenvtest:foo}"'
+`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret provider 'serviceenvtest' was not recognised")
+
+	err = service.NewEnvironment().NewStreamBuilder().AddInputYAML(`
+generate:
+  mapping: 'root = "${secret:serviceenvtest:foo}"'
+`)
+	require.Error(t, err)
+}
@@ -21,6 +21,7 @@ module.exports = {
       items: [
         'configuration/about',
         'configuration/resources',
+        'configuration/composition',
         'configuration/batching',
         'configuration/windowed_processing',
         'configuration/metadata',
@@ -104,6 +105,7 @@ module.exports = {
         'guides/monitoring',
         'guides/performance_tuning',
         'guides/sync_responses',
+        'guides/editor_support',
         {
           type: 'category',
           label: 'Cloud Credentials',
@@ -128,6 +130,7 @@ module.exports = {
             'guides/streams_mode/using_config_files',
             'guides/streams_mode/using_rest_api',
             'guides/streams_mode/streams_api',
+            'guides/streams_mode/clustering',
           ],
         },
         {